@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
 
 	flags "github.com/jessevdk/go-flags"
 	"github.com/lczyk/gitgum/internal/ui"
@@ -25,7 +28,19 @@ type Options struct {
 	Empty      commands.EmptyCommand      `command:"empty" description:"Create an empty commit and optionally push it"`
 	Release    commands.ReleaseCommand    `command:"release" description:"Bump VERSION (or latest tag), commit, and tag"`
 	Tree       commands.TreeCommand       `command:"tree" description:"Print a colored commit graph across all branches"`
-	Diff       commands.DiffCommand       `command:"diff" description:"Show working-tree diff with --compact-summary"`
+	Diff       commands.DiffCommand       `command:"diff" description:"Show working-tree changes, or a revision range, as a file tree and coloured hunks"`
+	Stage      commands.StageCommand      `command:"stage" description:"Stage changes a hunk or a line at a time"`
+	Undo       commands.UndoCommand       `command:"undo" description:"Reverse a recent gitgum operation"`
+}
+
+// GlobalOptions are flags that apply to every command. They're kept out of
+// Options, which holds only commands.
+type GlobalOptions struct {
+	Trace     string `long:"trace" optional:"yes" optional-value:"-" value-name:"FILE" description:"log every git call with its timing to stderr (or FILE), then the slowest ones; same as GG_TRACE=1|FILE"`
+	TraceJSON string `long:"trace-json" value-name:"FILE" description:"write every git call as Chrome trace-event JSON to FILE; same as GG_TRACE_JSON=FILE"`
+	DryRun    string `long:"dry-run" optional:"yes" optional-value:"-" value-name:"SCRIPT" description:"print git writes instead of running them, and save them to SCRIPT if given; same as GG_DRY_RUN=1|SCRIPT"`
+
+	NetworkTimeout string `long:"network-timeout" value-name:"DURATION" description:"give up on a fetch, push or ls-remote after DURATION (default 2m, 0 for never); same as GG_NETWORK_TIMEOUT"`
 }
 
 func main() {
@@ -57,9 +72,46 @@ func main() {
 	}
 
 	var opts Options
+	var globals GlobalOptions
 	parser := flags.NewParser(&opts, flags.Default)
 	parser.Name = "gitgum"
 	parser.Usage = "[OPTIONS] COMMAND"
+	if _, err := parser.AddGroup("Global Options", "", &globals); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	parser.CommandHandler = func(cmd flags.Commander, args []string) error {
+		if cmd == nil {
+			return nil
+		}
+		// the first Ctrl-C cancels the command's context, so in-flight git
+		// calls stop and the finishers below still run; a second one kills
+		// gg outright
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		context.AfterFunc(ctx, stop)
+		ctx, err := commands.WithNetworkTimeout(ctx, globals.NetworkTimeout)
+		if err != nil {
+			return err
+		}
+		if c, ok := cmd.(interface{ SetContext(context.Context) }); ok {
+			c.SetContext(ctx)
+		}
+
+		finishTrace, err := commands.StartTrace(globals.Trace, globals.TraceJSON)
+		if err != nil {
+			return err
+		}
+		finishDryRun, err := commands.StartDryRun(globals.DryRun)
+		if err != nil {
+			return errors.Join(err, finishTrace())
+		}
+		if _, ok := cmd.(interface{ KeepsDoubleDash() }); ok {
+			args = withDoubleDash(os.Args[1:], args)
+		}
+		runErr := cmd.Execute(args)
+		return errors.Join(runErr, finishDryRun(), finishTrace())
+	}
 
 	_, err := parser.Parse()
 	if err != nil {
@@ -71,3 +123,18 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// withDoubleDash puts back the "--" go-flags drops from a command's args,
+// for the commands that tell paths from revisions by it. go-flags passes
+// everything after it on as is, so those are the last of args.
+func withDoubleDash(cmdline, args []string) []string {
+	i := slices.Index(cmdline, "--")
+	if i < 0 {
+		return args
+	}
+	at := len(args) - (len(cmdline) - i - 1)
+	if at < 0 {
+		return args
+	}
+	return slices.Concat(args[:at], []string{"--"}, args[at:])
+}
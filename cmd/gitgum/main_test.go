@@ -34,3 +34,17 @@ func TestAllCommandsImplementCommander(t *testing.T) {
 			"%s (%s) does not implement flags.Commander — check Execute signature is Execute(args []string) error", field.Name, field.Type)
 	}
 }
+
+func TestWithDoubleDash(t *testing.T) {
+	cases := []struct {
+		cmdline, args, want []string
+	}{
+		{[]string{"diff", "main"}, []string{"main"}, []string{"main"}},
+		{[]string{"diff", "--", "topic"}, []string{"topic"}, []string{"--", "topic"}},
+		{[]string{"diff", "--split", "main", "--", "a", "b"}, []string{"main", "a", "b"}, []string{"main", "--", "a", "b"}},
+		{[]string{"diff", "main", "--"}, []string{"main"}, []string{"main", "--"}},
+	}
+	for _, tc := range cases {
+		assert.EqualArrays(t, withDoubleDash(tc.cmdline, tc.args), tc.want, "for %v", tc.cmdline)
+	}
+}
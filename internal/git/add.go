@@ -8,8 +8,13 @@ import (
 // Add stages the given paths. Empty list errors (matches `git add` with no
 // pathspec).
 func (r Repo) Add(paths ...string) error {
+	return r.AddCtx(context.Background(), paths...)
+}
+
+// AddCtx is Add with a context.
+func (r Repo) AddCtx(ctx context.Context, paths ...string) error {
 	args := append([]string{"add"}, paths...)
-	_, stderr, err := r.runWrite(context.Background(), args...)
+	_, stderr, err := r.runWrite(ctx, args...)
 	if err != nil {
 		return fmt.Errorf("git add: %w: %s", err, stderr)
 	}
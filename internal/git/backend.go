@@ -0,0 +1,71 @@
+package git
+
+import "context"
+
+// Backend is the set of repository operations gg's commands run. Repo
+// implements it by shelling out to git; Fake keeps a repo's refs in memory
+// and can script failures, and Recorder wraps either to log each call.
+// Commands take a Backend so their error paths can be tested without
+// coaxing a real git into failing.
+type Backend interface {
+	CheckInRepo() error
+
+	// refs and branches
+	GetCurrentBranch() (string, error)
+	GetCurrentBranchUpstream() (string, error)
+	GetBranchUpstream(branch string) (remote, remoteBranch string, err error)
+	GetBranchTrackingRemote(branch string) (string, error)
+	GetCommitHash(ref string) (string, error)
+	GetDefaultBranch() (string, error)
+	GetLocalBranches() ([]string, error)
+	GetRemotes() ([]string, error)
+	BranchExists(branch string) bool
+	TagExists(name string) bool
+	IsBranchAheadOfRemote(localBranch, remoteBranch string) (bool, error)
+	RefOids(names ...string) (map[string]string, error)
+	RefsWithUpstreamsCtx(ctx context.Context) ([]Ref, error)
+	CommitInfo(revs ...string) ([]CommitInfo, error)
+
+	// worktree
+	Status() (Status, error)
+	StatusWith(opts StatusOptions) (Status, error)
+	DirtyTrackedLines() ([]string, error)
+	Submodules() ([]Submodule, error)
+	SubmoduleDiff(args ...string) ([]SubmoduleChange, error)
+	Patch(args ...string) (string, error)
+	Worktrees() ([]Worktree, error)
+
+	// writes
+	Add(paths ...string) error
+	ApplyPatch(patch string, cached, reverse bool) error
+	Checkout(branch string) error
+	CheckoutNewBranch(branch, startPoint string) error
+	ResetHard(ref string) error
+	Commit(message string) error
+	CommitEmpty(message string) error
+	TagAnnotated(name, message string) error
+	StashPush(message string) error
+	StashPopIndex() error
+	StashCreate() (string, error)
+	SubmoduleUpdateCtx(ctx context.Context, paths ...string) error
+	PruneWorktrees() error
+
+	// network
+	FetchCtx(ctx context.Context, remote, refspec string) error
+	PushCtx(ctx context.Context) error
+	LsRemoteCtx(ctx context.Context, remote string) (string, error)
+	RemoteBranchExistsCtx(ctx context.Context, remote, branch string) (bool, error)
+
+	// journal
+	Journal() ([]JournalEntry, error)
+	AppendJournal(e JournalEntry) error
+
+	// raw invocations, for one-offs without a dedicated helper
+	Run(args ...string) (string, string, error)
+	RunLines(fn func(line string) error, args ...string) error
+	RunWrite(args ...string) (string, string, error)
+	RunWriteStream(args ...string) (string, string, error)
+	RunWriteStreamCtx(ctx context.Context, args ...string) (string, string, error)
+}
+
+var _ Backend = Repo{}
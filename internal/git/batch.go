@@ -0,0 +1,238 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// batchCommandVersion is the first git with `cat-file --batch-command`.
+// Below it (down to minGitVersion) the session falls back to plain --batch,
+// which answers the same `contents` requests one object per input line.
+var batchCommandVersion = [3]int{2, 36, 0}
+
+// catFile is a long-lived `git cat-file` co-process for one repo dir.
+// Requests are written in bulk and answered in one round trip, so reading
+// a few thousand objects costs one fork instead of one per object.
+//
+// The process is started lazily, and restarted if it dies: an I/O error
+// mid-request kills it and the request is retried once on a fresh one.
+// Cancelling a request's ctx kills the process too, since a half-read
+// response leaves the pipe out of sync.
+type catFile struct {
+	dir string
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	in      io.WriteCloser
+	out     *bufio.Reader
+	command bool     // --batch-command rather than --batch
+	args    []string // the mode args and full argv, for tracing
+	argv    []string
+}
+
+// object is one cat-file answer. Missing is set, and the rest left empty,
+// when name didn't resolve.
+type object struct {
+	oid, typ string
+	data     []byte
+	missing  bool
+}
+
+var catFiles = struct {
+	sync.Mutex
+	m map[string]*catFile
+}{m: map[string]*catFile{}}
+
+func (r Repo) catFile() *catFile {
+	catFiles.Lock()
+	defer catFiles.Unlock()
+	c, ok := catFiles.m[r.Dir]
+	if !ok {
+		c = &catFile{dir: r.Dir}
+		catFiles.m[r.Dir] = c
+	}
+	return c
+}
+
+// Close stops the Repo's cat-file process, if one is running. The next
+// bulk read starts a fresh one, so Close is only needed to release the
+// process early -- tests, or a long-lived caller done with a repo.
+func (r Repo) Close() error {
+	catFiles.Lock()
+	c, ok := catFiles.m[r.Dir]
+	delete(catFiles.m, r.Dir)
+	catFiles.Unlock()
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stop()
+	return nil
+}
+
+func (c *catFile) start(ctx context.Context) error {
+	if err := ensureMinVersion(ctx); err != nil {
+		return err
+	}
+	return c.startMode(compareVersion(detectedVersion(), batchCommandVersion) >= 0)
+}
+
+func (c *catFile) startMode(command bool) error {
+	c.command = command
+	mode := []string{"cat-file", "--batch"}
+	if command {
+		mode = []string{"cat-file", "--batch-command", "--buffer"}
+	}
+	// not CommandContext: the process outlives any one request
+	c.args, c.argv = mode, buildArgs(c.dir, readPrelude, mode, true)
+	cmd := exec.Command("git", c.argv...)
+	cmd.Env = readEnv()
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting git cat-file: %w", err)
+	}
+	c.cmd, c.in, c.out = cmd, in, bufio.NewReaderSize(out, 64*1024)
+	return nil
+}
+
+// stop kills the process and forgets it. Callers hold c.mu.
+func (c *catFile) stop() {
+	if c.cmd == nil {
+		return
+	}
+	_ = c.in.Close()
+	_ = c.cmd.Process.Kill()
+	_ = c.cmd.Wait()
+	c.cmd, c.in, c.out = nil, nil, nil
+}
+
+// contents looks up every name (anything rev-parse accepts) and returns
+// the answers in order.
+func (c *catFile) contents(ctx context.Context, names []string) ([]object, error) {
+	for _, n := range names {
+		if n == "" || strings.ContainsAny(n, "\n") {
+			return nil, fmt.Errorf("cat-file: bad object name %q", n)
+		}
+	}
+	// checked up front too: git can answer before the kill lands
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if c.cmd == nil {
+			if err := c.start(ctx); err != nil {
+				return nil, err
+			}
+		}
+		start := time.Now()
+		objs, err := c.roundTrip(ctx, names)
+		// one traced call per round trip: the process itself lives on
+		traceCall("batch", c.dir, c.argv, c.args, start, "", err)
+		if err == nil {
+			return objs, nil
+		}
+		c.stop()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("git cat-file: %w", lastErr)
+}
+
+func (c *catFile) roundTrip(ctx context.Context, names []string) ([]object, error) {
+	// a ctx cancelled mid-read kills the process so the read below fails
+	// instead of waiting on git forever
+	cmd := c.cmd
+	stop := context.AfterFunc(ctx, func() { _ = cmd.Process.Kill() })
+	defer stop()
+
+	// write from a goroutine: with thousands of requests git fills the
+	// stdout pipe before we'd finish writing, and both sides would block
+	writeErr := make(chan error, 1)
+	go func() {
+		w := bufio.NewWriter(c.in)
+		for _, n := range names {
+			if c.command {
+				w.WriteString("contents ")
+			}
+			w.WriteString(n)
+			w.WriteByte('\n')
+		}
+		if c.command {
+			w.WriteString("flush\n")
+		}
+		writeErr <- w.Flush()
+	}()
+
+	objs := make([]object, len(names))
+	for i := range names {
+		o, err := c.readObject()
+		if err != nil {
+			_ = cmd.Process.Kill() // unblocks the writer if git is still alive
+			<-writeErr
+			return nil, err
+		}
+		objs[i] = o
+	}
+	if err := <-writeErr; err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// readObject reads one response: "<oid> <type> <size>\n<data>\n", or
+// "<name> missing\n" / "<name> ambiguous\n" when the name didn't resolve.
+func (c *catFile) readObject() (object, error) {
+	header, err := c.out.ReadString('\n')
+	if err != nil {
+		return object{}, err
+	}
+	header = strings.TrimSuffix(header, "\n")
+	if strings.HasSuffix(header, " missing") || strings.HasSuffix(header, " ambiguous") {
+		return object{missing: true}, nil
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return object{}, fmt.Errorf("unexpected cat-file header %q", header)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return object{}, fmt.Errorf("unexpected cat-file header %q", header)
+	}
+	data := make([]byte, size+1) // + the trailing LF
+	if _, err := io.ReadFull(c.out, data); err != nil {
+		return object{}, err
+	}
+	return object{oid: fields[0], typ: fields[1], data: data[:size]}, nil
+}
+
+// errNotCommit builds the *Error CommitInfo returns for a rev that doesn't
+// name a commit. ExitCode stays 0: the batch process itself didn't fail.
+func errNotCommit(rev string) error {
+	return &Error{
+		Args: []string{"cat-file", "commit", rev},
+		Kind: KindBadRef,
+		err:  errors.New("not a valid commit: " + rev),
+	}
+}
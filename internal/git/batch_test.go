@@ -0,0 +1,151 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func TestCommitInfo(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a", "feat: a\n\nwith a body")
+	temp_repo.RunGit(t, dir, "tag", "-a", "v1", "-m", "v1")
+	r := Repo{Dir: dir}
+	t.Cleanup(func() { _ = r.Close() })
+
+	head := strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "HEAD"))
+	root := strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "HEAD~1"))
+	got, err := r.CommitInfo("HEAD", root, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, len(got), 3)
+
+	assert.Equal(t, got[0].Oid, head)
+	assert.EqualArrays(t, got[0].Parents, []string{root})
+	assert.Equal(t, got[0].Subject(), "feat: a")
+	assert.Equal(t, got[0].Message, "feat: a\n\nwith a body\n")
+	assert.Equal(t, got[0].Author.Name, "Test User")
+	assert.Equal(t, got[0].Author.Email, "test@example.com")
+	assert.That(t, time.Since(got[0].Committer.When) < time.Hour, "committer time %v", got[0].Committer.When)
+
+	assert.Equal(t, got[1].Oid, root)
+	assert.Equal(t, len(got[1].Parents), 0)
+	assert.Equal(t, got[1].Subject(), "chore: init")
+	// annotated tag peels to its commit
+	assert.Equal(t, got[2].Oid, head)
+}
+
+func TestCommitInfo_BadRev(t *testing.T) {
+	t.Parallel()
+	r := Repo{Dir: temp_repo.NewRepo(t)}
+	t.Cleanup(func() { _ = r.Close() })
+
+	_, err := r.CommitInfo("HEAD", "nope")
+	assert.That(t, KindOf(err) == KindBadRef, "got %v", err)
+	assert.ContainsString(t, err.Error(), "nope")
+	// the session survives a miss
+	got, err := r.CommitInfo("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, got[0].Subject(), "chore: init")
+}
+
+// a cat-file process that dies between requests is replaced transparently.
+func TestCommitInfo_RestartsAfterCrash(t *testing.T) {
+	t.Parallel()
+	r := Repo{Dir: temp_repo.NewRepo(t)}
+	t.Cleanup(func() { _ = r.Close() })
+
+	_, err := r.CommitInfo("HEAD")
+	require.NoError(t, err)
+	c := r.catFile()
+	c.mu.Lock()
+	require.NoError(t, c.cmd.Process.Kill())
+	_ = c.cmd.Wait()
+	c.mu.Unlock()
+
+	got, err := r.CommitInfo("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, got[0].Subject(), "chore: init")
+}
+
+func TestCommitInfo_ContextCancel(t *testing.T) {
+	t.Parallel()
+	r := Repo{Dir: temp_repo.NewRepo(t)}
+	t.Cleanup(func() { _ = r.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := r.commitInfo(ctx, []string{"HEAD"})
+	assert.That(t, err != nil, "want an error from a cancelled ctx")
+	// and the next call gets a working process
+	_, err = r.CommitInfo("HEAD")
+	require.NoError(t, err)
+}
+
+// the plain --batch fallback for git below 2.36 answers the same requests.
+func TestCatFile_BatchFallback(t *testing.T) {
+	t.Parallel()
+	c := &catFile{dir: temp_repo.NewRepo(t)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.NoError(t, c.startMode(false))
+	defer c.stop()
+
+	objs, err := c.roundTrip(context.Background(), []string{"HEAD^{commit}", "nope"})
+	require.NoError(t, err)
+	assert.Equal(t, objs[0].typ, "commit")
+	assert.That(t, objs[1].missing, "nope should be missing")
+}
+
+func TestRefsWithUpstreams(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepoWithBranches(t, 3)
+	temp_repo.RunGit(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/b00002")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "b00003")
+
+	refs, err := Repo{Dir: dir}.RefsWithTracking()
+	require.NoError(t, err)
+	byName := map[string]Ref{}
+	for _, ref := range refs {
+		byName[ref.Name] = ref
+	}
+	_, hasSymref := byName["refs/remotes/origin/HEAD"]
+	assert.That(t, !hasSymref, "origin/HEAD should be skipped")
+
+	b1 := byName["refs/heads/b00001"]
+	assert.Equal(t, b1.Upstream, "refs/remotes/origin/b00001")
+	assert.That(t, b1.Gone, "b00001's upstream ref doesn't exist")
+
+	b3 := byName["refs/heads/b00003"]
+	assert.Equal(t, b3.Remote, "origin")
+	assert.Equal(t, b3.Ahead, 1)
+	assert.Equal(t, b3.Behind, 0)
+	assert.That(t, b3.Head, "b00003 is checked out")
+	assert.Equal(t, b3.Worktree, dir)
+	assert.Equal(t, b3.Short(), "b00003")
+
+	o3 := byName["refs/remotes/origin/b00003"]
+	assert.That(t, o3.IsRemote(), "origin/b00003 is remote")
+	assert.Equal(t, o3.Short(), "origin/b00003")
+	assert.Equal(t, o3.Upstream, "")
+
+	main := byName["refs/heads/main"]
+	assert.Equal(t, main.Upstream, "")
+	assert.That(t, !main.Head, "main is not checked out")
+
+	// the cheap listing has the same refs, minus the counts
+	cheap, err := Repo{Dir: dir}.RefsWithUpstreams()
+	require.NoError(t, err)
+	assert.Equal(t, len(cheap), len(refs))
+	for _, ref := range cheap {
+		want := byName[ref.Name]
+		assert.That(t, ref.Upstream == want.Upstream && ref.Gone == want.Gone && ref.Head == want.Head,
+			"%s: %+v vs %+v", ref.Name, ref, want)
+		assert.Equal(t, ref.Ahead, 0)
+	}
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+// benchBranches is the synthetic repo size: big enough that per-ref forks
+// dominate, like a monorepo nobody prunes.
+const benchBranches = 5000
+
+// forkSample caps the fork-per-ref benchmarks, which would otherwise take
+// minutes an op; both sides report ns/ref so they still compare.
+const forkSample = 200
+
+func benchRepo(b *testing.B) Repo {
+	b.Helper()
+	r := Repo{Dir: temp_repo.NewRepoWithBranches(b, benchBranches)}
+	b.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+// what streamBranches used to do: list, then one upstream lookup per branch.
+func BenchmarkUpstreams_PerBranch(b *testing.B) {
+	r := benchRepo(b)
+	branches, err := r.GetLocalBranches()
+	if err != nil {
+		b.Fatal(err)
+	}
+	branches = branches[:forkSample]
+	b.ResetTimer()
+	for b.Loop() {
+		for _, br := range branches {
+			if _, err := r.GetBranchTrackingRemote(br); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(branches)), "ns/ref")
+}
+
+func BenchmarkUpstreams_RefsWithUpstreams(b *testing.B) {
+	r := benchRepo(b)
+	var n int
+	for b.Loop() {
+		refs, err := r.RefsWithUpstreams()
+		if err != nil {
+			b.Fatal(err)
+		}
+		n = len(refs)
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/ref")
+}
+
+func BenchmarkUpstreams_RefsWithTracking(b *testing.B) {
+	r := benchRepo(b)
+	var n int
+	for b.Loop() {
+		refs, err := r.RefsWithTracking()
+		if err != nil {
+			b.Fatal(err)
+		}
+		n = len(refs)
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/ref")
+}
+
+func benchRevs() []string {
+	revs := make([]string, benchBranches)
+	for i := range revs {
+		revs[i] = fmt.Sprintf("b%05d", i+1)
+	}
+	return revs
+}
+
+// one `git log -1` per commit, the pattern release and tree used for
+// single-commit lookups.
+func BenchmarkCommitInfo_PerCommit(b *testing.B) {
+	r := benchRepo(b)
+	revs := benchRevs()[:forkSample]
+	b.ResetTimer()
+	for b.Loop() {
+		for _, rev := range revs {
+			if _, _, err := r.Run("log", "-1", "--format=%H%x00%an%x00%ct%x00%s", rev); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(revs)), "ns/ref")
+}
+
+func BenchmarkCommitInfo_Batch(b *testing.B) {
+	r := benchRepo(b)
+	revs := benchRevs()
+	if _, err := r.CommitInfo(revs[0]); err != nil { // warm the process
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for b.Loop() {
+		if _, err := r.CommitInfo(revs...); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(revs)), "ns/ref")
+}
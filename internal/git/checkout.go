@@ -9,7 +9,12 @@ import (
 // Caller should provide the user-facing context; the returned error wraps
 // stderr so it surfaces in the message chain.
 func (r Repo) Checkout(branch string) error {
-	_, stderr, err := r.runWrite(context.Background(), "checkout", "--quiet", branch)
+	return r.CheckoutCtx(context.Background(), branch)
+}
+
+// CheckoutCtx is Checkout with a context.
+func (r Repo) CheckoutCtx(ctx context.Context, branch string) error {
+	_, stderr, err := r.runWrite(ctx, "checkout", "--quiet", branch)
 	if err != nil {
 		return fmt.Errorf("git checkout %s: %w: %s", branch, err, stderr)
 	}
@@ -21,11 +26,16 @@ func Checkout(branch string) error { return CWD().Checkout(branch) }
 // CheckoutNewBranch creates a new branch off startPoint and switches to it
 // (`git checkout -b <branch> <startPoint>`).
 func (r Repo) CheckoutNewBranch(branch, startPoint string) error {
+	return r.CheckoutNewBranchCtx(context.Background(), branch, startPoint)
+}
+
+// CheckoutNewBranchCtx is CheckoutNewBranch with a context.
+func (r Repo) CheckoutNewBranchCtx(ctx context.Context, branch, startPoint string) error {
 	args := []string{"checkout", "-b", branch}
 	if startPoint != "" {
 		args = append(args, startPoint)
 	}
-	_, stderr, err := r.runWrite(context.Background(), args...)
+	_, stderr, err := r.runWrite(ctx, args...)
 	if err != nil {
 		return fmt.Errorf("git checkout -b %s: %w: %s", branch, err, stderr)
 	}
@@ -39,7 +49,12 @@ func CheckoutNewBranch(branch, startPoint string) error {
 // ResetHard performs `git reset --hard <ref>`. Destructive: discards
 // uncommitted changes; caller is responsible for asking the user first.
 func (r Repo) ResetHard(ref string) error {
-	_, stderr, err := r.runWrite(context.Background(), "reset", "--hard", ref)
+	return r.ResetHardCtx(context.Background(), ref)
+}
+
+// ResetHardCtx is ResetHard with a context.
+func (r Repo) ResetHardCtx(ctx context.Context, ref string) error {
+	_, stderr, err := r.runWrite(ctx, "reset", "--hard", ref)
 	if err != nil {
 		return fmt.Errorf("git reset --hard %s: %w: %s", ref, err, stderr)
 	}
@@ -11,7 +11,12 @@ import (
 // (commit.gpgsign, gpg.program) is honoured because runWrite preserves
 // user identity. The captured stderr tail is included in error messages.
 func (r Repo) Commit(message string) error {
-	if _, stderr, err := r.runWriteStreaming(context.Background(), "commit", "-m", message); err != nil {
+	return r.CommitCtx(context.Background(), message)
+}
+
+// CommitCtx is Commit with a context.
+func (r Repo) CommitCtx(ctx context.Context, message string) error {
+	if _, stderr, err := r.runWriteStreaming(ctx, "commit", "-m", message); err != nil {
 		return fmt.Errorf("git commit: %w: %s", err, strings.TrimSpace(stderr))
 	}
 	return nil
@@ -20,7 +25,12 @@ func (r Repo) Commit(message string) error {
 // CommitEmpty is like Commit but adds --allow-empty so the commit succeeds
 // even when the index has no changes.
 func (r Repo) CommitEmpty(message string) error {
-	if _, stderr, err := r.runWriteStreaming(context.Background(), "commit", "--allow-empty", "-m", message); err != nil {
+	return r.CommitEmptyCtx(context.Background(), message)
+}
+
+// CommitEmptyCtx is CommitEmpty with a context.
+func (r Repo) CommitEmptyCtx(ctx context.Context, message string) error {
+	if _, stderr, err := r.runWriteStreaming(ctx, "commit", "--allow-empty", "-m", message); err != nil {
 		return fmt.Errorf("git commit --allow-empty: %w: %s", err, strings.TrimSpace(stderr))
 	}
 	return nil
@@ -0,0 +1,144 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitInfo is a parsed commit object.
+type CommitInfo struct {
+	Oid       string
+	Tree      string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	Message   string // full message, headers stripped
+}
+
+// Signature is an author or committer line.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time // in the signer's own zone
+}
+
+// Subject returns the first line of the message.
+func (c CommitInfo) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// CommitInfo resolves each rev (a sha, a ref, or anything rev-parse takes)
+// to the commit it names and parses it, all in one round trip through the
+// Repo's cat-file process. Annotated tags are peeled. A rev that doesn't
+// name a commit fails the whole call with a KindBadRef *Error.
+func (r Repo) CommitInfo(revs ...string) ([]CommitInfo, error) {
+	return r.commitInfo(context.Background(), revs)
+}
+
+// CommitInfoCtx is CommitInfo with a context. Cancelling it mid-read
+// restarts the cat-file process on the next call.
+func (r Repo) CommitInfoCtx(ctx context.Context, revs ...string) ([]CommitInfo, error) {
+	return r.commitInfo(ctx, revs)
+}
+
+func (r Repo) commitInfo(ctx context.Context, revs []string) ([]CommitInfo, error) {
+	if len(revs) == 0 {
+		return nil, nil
+	}
+	names := make([]string, len(revs))
+	for i, rev := range revs {
+		names[i] = rev
+		if !strings.HasSuffix(rev, "^{commit}") {
+			names[i] += "^{commit}"
+		}
+	}
+	objs, err := r.catFile().contents(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CommitInfo, len(objs))
+	for i, o := range objs {
+		if o.missing || o.typ != "commit" {
+			return nil, errNotCommit(revs[i])
+		}
+		c, err := parseCommit(o.oid, o.data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing commit %s: %w", o.oid, err)
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+// parseCommit reads a raw commit object: header lines up to the first blank
+// line, then the message. Continuation lines (gpgsig, mergetag) start with
+// a space and are skipped along with headers we don't model.
+func parseCommit(oid string, data []byte) (CommitInfo, error) {
+	c := CommitInfo{Oid: oid}
+	headers, msg, _ := bytes.Cut(data, []byte("\n\n"))
+	c.Message = string(msg)
+	for line := range strings.SplitSeq(string(headers), "\n") {
+		key, val, _ := strings.Cut(line, " ")
+		var err error
+		switch key {
+		case "tree":
+			c.Tree = val
+		case "parent":
+			c.Parents = append(c.Parents, val)
+		case "author":
+			c.Author, err = parseSignature(val)
+		case "committer":
+			c.Committer, err = parseSignature(val)
+		}
+		if err != nil {
+			return CommitInfo{}, err
+		}
+	}
+	if c.Tree == "" {
+		return CommitInfo{}, fmt.Errorf("no tree header")
+	}
+	return c, nil
+}
+
+// parseSignature splits "Name <email> 1700000000 +0100".
+func parseSignature(s string) (Signature, error) {
+	lt := strings.LastIndexByte(s, '<')
+	gt := strings.LastIndexByte(s, '>')
+	if lt < 0 || gt < lt {
+		return Signature{}, fmt.Errorf("malformed signature %q", s)
+	}
+	sig := Signature{
+		Name:  strings.TrimSpace(s[:lt]),
+		Email: s[lt+1 : gt],
+	}
+	stamp, tz, _ := strings.Cut(strings.TrimSpace(s[gt+1:]), " ")
+	secs, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil {
+		return Signature{}, fmt.Errorf("malformed signature time %q", s)
+	}
+	sig.When = time.Unix(secs, 0).In(parseZone(tz))
+	return sig, nil
+}
+
+// parseZone turns git's "+hhmm" / "-hhmm" into a fixed zone; anything
+// unparseable is UTC.
+func parseZone(tz string) *time.Location {
+	if len(tz) != 5 || (tz[0] != '+' && tz[0] != '-') {
+		return time.UTC
+	}
+	hh, err1 := strconv.Atoi(tz[1:3])
+	mm, err2 := strconv.Atoi(tz[3:5])
+	if err1 != nil || err2 != nil {
+		return time.UTC
+	}
+	off := hh*3600 + mm*60
+	if tz[0] == '-' {
+		off = -off
+	}
+	return time.FixedZone(tz, off)
+}
@@ -15,7 +15,12 @@ import (
 // callers can distinguish ` M` (unstaged), `M ` (staged), and `MM`
 // (partial-hunk staging).
 func (r Repo) DirtyTrackedLines() ([]string, error) {
-	stdout, stderr, err := r.runRead(context.Background(), "status", "--porcelain")
+	return r.DirtyTrackedLinesCtx(context.Background())
+}
+
+// DirtyTrackedLinesCtx is DirtyTrackedLines with a context.
+func (r Repo) DirtyTrackedLinesCtx(ctx context.Context) ([]string, error) {
+	stdout, stderr, err := r.runRead(ctx, "status", "--porcelain")
 	if err != nil {
 		return nil, fmt.Errorf("git status: %w: %s", err, stderr)
 	}
@@ -44,8 +49,13 @@ var stashHooksOff = []string{"-c", "core.hooksPath=/dev/null"}
 // StashPush stashes tracked changes (staged + unstaged) under the given
 // message. Untracked files are not included.
 func (r Repo) StashPush(message string) error {
+	return r.StashPushCtx(context.Background(), message)
+}
+
+// StashPushCtx is StashPush with a context.
+func (r Repo) StashPushCtx(ctx context.Context, message string) error {
 	args := append(stashHooksOff, "stash", "push", "-m", message)
-	_, _, err := r.runWrite(context.Background(), args...)
+	_, _, err := r.runWrite(ctx, args...)
 	if err != nil {
 		return fmt.Errorf("git stash push: %w", err)
 	}
@@ -57,8 +67,13 @@ func (r Repo) StashPush(message string) error {
 // conflict, git leaves the stash entry in place; the caller should treat
 // that as a manual-resolution situation rather than retrying.
 func (r Repo) StashPopIndex() error {
+	return r.StashPopIndexCtx(context.Background())
+}
+
+// StashPopIndexCtx is StashPopIndex with a context.
+func (r Repo) StashPopIndexCtx(ctx context.Context) error {
 	args := append(stashHooksOff, "stash", "pop", "--index")
-	_, _, err := r.runWrite(context.Background(), args...)
+	_, _, err := r.runWrite(ctx, args...)
 	if err != nil {
 		return fmt.Errorf("git stash pop --index: %w", err)
 	}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DryRun stands in for every write while installed with SetDryRun. Each
+// runWrite / runWriteStreaming prints the exact git argv it would have run,
+// returns empty output and no error, and is kept for Script. Reads still
+// run, so anything a command reads back after a skipped write sees the
+// repo as it was.
+type DryRun struct {
+	mu    sync.Mutex
+	log   io.Writer
+	dir   string // cwd when the dry run started; the script cds there
+	lines []string
+}
+
+// NewDryRun returns a DryRun printing each skipped write to log (nil for
+// silent).
+func NewDryRun(log io.Writer) *DryRun {
+	dir, _ := os.Getwd()
+	return &DryRun{log: log, dir: dir}
+}
+
+var activeDryRun atomic.Pointer[DryRun]
+
+// SetDryRun installs d for every Repo in the process; nil turns it off.
+func SetDryRun(d *DryRun) { activeDryRun.Store(d) }
+
+// Len is the number of writes skipped so far.
+func (d *DryRun) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.lines)
+}
+
+// Script returns the skipped writes as a POSIX shell script that replays
+// them in order from the directory gg was run in.
+func (d *DryRun) Script() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n# recorded by gg --dry-run\nset -e\n")
+	fmt.Fprintf(&b, "cd %s\n", shellQuote(d.dir))
+	for _, l := range d.lines {
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (d *DryRun) record(line, shown string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lines = append(d.lines, line)
+	if d.log != nil {
+		fmt.Fprintf(d.log, "dry-run: %s\n", shown)
+	}
+}
+
+// dryRunWrite records argv with the active DryRun, if any, and reports
+// whether it did -- in which case the caller skips running git.
+func dryRunWrite(argv []string) bool {
+	d := activeDryRun.Load()
+	if d == nil {
+		return false
+	}
+	line := "git " + shellJoin(argv)
+	d.record(line, line)
+	return true
+}
+
+// dryRunWriteInput is dryRunWrite for a write fed input on stdin. The
+// script pipes the input in; the log only says how much there was.
+func dryRunWriteInput(argv []string, input string) bool {
+	d := activeDryRun.Load()
+	if d == nil {
+		return false
+	}
+	line := "git " + shellJoin(argv)
+	d.record(fmt.Sprintf("printf %%s %s | %s", shellQuote(input), line),
+		fmt.Sprintf("%s < (%d bytes)", line, len(input)))
+	return true
+}
+
+// WriteFile is os.WriteFile for worktree files a command edits alongside
+// its git writes (release's VERSION bump), so a dry run skips and records
+// them too. The script recreates the file's full contents.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	d := activeDryRun.Load()
+	if d == nil {
+		return os.WriteFile(path, data, perm)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	d.record(fmt.Sprintf("printf %%s %s > %s", shellQuote(string(data)), shellQuote(path)),
+		fmt.Sprintf("write %s (%d bytes)", path, len(data)))
+	return nil
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+// not parallel: the dry run is process-wide
+func TestDryRun_SkipsWritesAndReplays(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	r := Repo{Dir: dir}
+	head, err := r.GetCommitHash("HEAD")
+	require.NoError(t, err)
+
+	var log strings.Builder
+	dr := NewDryRun(&log)
+	SetDryRun(dr)
+	require.NoError(t, WriteFile(filepath.Join(dir, "new.txt"), []byte("it's new\n"), 0o644))
+	require.NoError(t, r.Add("new.txt"))
+	require.NoError(t, r.Commit("feat: new"))
+	// reads still run
+	branch, err := r.GetCurrentBranch()
+	SetDryRun(nil)
+	require.NoError(t, err)
+	assert.Equal(t, branch, "main")
+
+	assert.Equal(t, dr.Len(), 3)
+	_, err = os.Stat(filepath.Join(dir, "new.txt"))
+	assert.That(t, os.IsNotExist(err), "file write should have been skipped")
+	after, err := r.GetCommitHash("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, after, head)
+	assert.ContainsString(t, log.String(), "dry-run: git --no-pager")
+	assert.ContainsString(t, log.String(), "commit -m 'feat: new'")
+
+	script := filepath.Join(t.TempDir(), "replay.sh")
+	require.NoError(t, os.WriteFile(script, []byte(dr.Script()), 0o755))
+	out, err := exec.Command("sh", script).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	content, err := os.ReadFile(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, string(content), "it's new\n")
+	subject, _, err := r.Run("log", "-1", "--format=%s")
+	require.NoError(t, err)
+	assert.Equal(t, subject, "feat: new")
+}
+
+// not parallel: the dry run is process-wide
+func TestDryRun_StashCreateIsNotAWrite(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	temp_repo.WriteFile(t, dir, "README.md", "changed\n")
+	r := Repo{Dir: dir}
+
+	var log strings.Builder
+	dr := NewDryRun(&log)
+	SetDryRun(dr)
+	sha, err := r.StashCreate()
+	SetDryRun(nil)
+	require.NoError(t, err)
+	assert.Equal(t, sha, "")
+	assert.Equal(t, dr.Len(), 0)
+	assert.Equal(t, log.String(), "")
+}
+
+func TestDryRun_StreamingWrite(t *testing.T) {
+	local, remote := temp_repo.NewRepoWithRemote(t)
+	temp_repo.CreateCommit(t, local, "a.txt", "a", "feat: a")
+	dr := NewDryRun(nil)
+	SetDryRun(dr)
+	err := Repo{Dir: local}.Push()
+	SetDryRun(nil)
+	require.NoError(t, err)
+	assert.Equal(t, dr.Len(), 1)
+
+	localHead, _, err := Repo{Dir: local}.Run("rev-parse", "HEAD")
+	require.NoError(t, err)
+	remoteHead, _, err := Repo{Dir: remote}.Run("rev-parse", "HEAD")
+	require.NoError(t, err)
+	assert.That(t, localHead != remoteHead, "push should not have reached the remote")
+}
+
+// not parallel: the dry run is process-wide
+func TestDryRun_WriteWithInput(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "one\n", "chore: add a")
+	temp_repo.WriteFile(t, dir, "a.txt", "it's one\n")
+	r := Repo{Dir: dir}
+	p, err := r.Patch()
+	require.NoError(t, err)
+
+	var log strings.Builder
+	dr := NewDryRun(&log)
+	SetDryRun(dr)
+	err = r.ApplyPatch(p, true, false)
+	SetDryRun(nil)
+	require.NoError(t, err)
+	assert.ContainsString(t, log.String(), fmt.Sprintf("apply --cached - < (%d bytes)", len(p)))
+	staged, err := r.Patch("--cached")
+	require.NoError(t, err)
+	assert.Equal(t, staged, "", "the apply should have been skipped")
+
+	script := filepath.Join(t.TempDir(), "replay.sh")
+	require.NoError(t, os.WriteFile(script, []byte(dr.Script()), 0o755))
+	out, err := exec.Command("sh", script).CombinedOutput()
+	require.NoError(t, err, string(out))
+	staged, err = r.Patch("--cached")
+	require.NoError(t, err)
+	assert.Equal(t, staged, p)
+}
@@ -0,0 +1,237 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// ErrorKind classifies why a git invocation failed, so callers can branch
+// on the failures they know how to recover from instead of matching
+// stderr themselves.
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindNonFastForward
+	KindMergeConflict
+	KindLockExists
+	KindBadRef
+	KindDirtyOverwrite
+	KindRemoteUnreachable
+	KindNotFullyMerged
+	KindRefExists
+)
+
+var kindNames = [...]string{
+	KindUnknown:           "unknown",
+	KindNonFastForward:    "non-fast-forward",
+	KindMergeConflict:     "merge conflict",
+	KindLockExists:        "lock file exists",
+	KindBadRef:            "bad ref",
+	KindDirtyOverwrite:    "would overwrite local changes",
+	KindRemoteUnreachable: "remote unreachable",
+	KindNotFullyMerged:    "not fully merged",
+	KindRefExists:         "ref exists",
+}
+
+func (k ErrorKind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// Error is returned by every Repo helper whose git process exits non-zero.
+// Args is the argv after gg's prelude (so Args[0] is the subcommand), and
+// Stderr the trimmed tail of what git wrote there. ExitCode is 0 for a
+// failure a long-lived batch process reported without exiting.
+//
+// Error() reports only the exit status, as the bare *exec.ExitError did:
+// the existing call sites already append stderr to their wrapped message.
+type Error struct {
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Kind     ErrorKind
+
+	err error
+}
+
+func (e *Error) Error() string {
+	if e.err == nil {
+		// built by hand (tests, fakes) rather than from a real exit
+		return fmt.Sprintf("git %s: exit status %d", strings.Join(e.Args, " "), e.ExitCode)
+	}
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error { return e.err }
+
+// KindOf returns the Kind of the first *Error in err's chain, or
+// KindUnknown when there isn't one (context cancellation, git missing).
+func KindOf(err error) ErrorKind {
+	var ge *Error
+	if errors.As(err, &ge) {
+		return ge.Kind
+	}
+	return KindUnknown
+}
+
+// newError wraps a failed run in an *Error. Anything other than a non-zero
+// exit -- git not on PATH, a cancelled context -- passes through as-is.
+func newError(args []string, stdout, stderr string, err error) error {
+	var ee *exec.ExitError
+	if err == nil || !errors.As(err, &ee) {
+		return err
+	}
+	stderr = strings.TrimSpace(stderr)
+	if len(stderr) > tailBufSize {
+		stderr = stderr[len(stderr)-tailBufSize:]
+	}
+	code := ee.ExitCode()
+	return &Error{
+		Args:     slices.Clone(args),
+		ExitCode: code,
+		Stderr:   stderr,
+		Kind:     classify(args, code, stdout, stderr, detectedVersion()),
+		err:      err,
+	}
+}
+
+// phrase is one stderr wording git uses for a failure kind. since / until
+// bound the git releases that print it (zero = open-ended), so a rewording
+// upstream becomes a new row rather than a wider match. Text is compared
+// case-insensitively; LC_ALL=C in the env keeps it english.
+type phrase struct {
+	kind  ErrorKind
+	text  string
+	since [3]int
+	until [3]int
+}
+
+// phrases is checked in order; the first hit wins. Dirty-overwrite comes
+// first: a merge or checkout refused over local changes can go on to print
+// wording the later rows would claim. Each row notes the git release its
+// wording was last checked against; when one drifts, bound the old row with
+// until and add the new wording with since.
+var phrases = []phrase{
+	{kind: KindDirtyOverwrite, text: "would be overwritten by"},                  // confirmed: git 2.39.5, checkout / merge over local changes
+	{kind: KindDirtyOverwrite, text: "please commit your changes or stash them"}, // confirmed: git 2.39.5, checkout
+
+	{kind: KindLockExists, text: ".lock': file exists"},                     // confirmed: git 2.39.5, add with index.lock present
+	{kind: KindLockExists, text: "another git process seems to be running"}, // confirmed: git 2.39.5, same message
+
+	{kind: KindNonFastForward, text: "(non-fast-forward)"},                    // confirmed: git 2.39.5, push
+	{kind: KindNonFastForward, text: "(fetch first)"},                         // confirmed: git 2.39.5, push
+	{kind: KindNonFastForward, text: "(stale info)"},                          // confirmed: git 2.39.5, push --force-with-lease
+	{kind: KindNonFastForward, text: "updates were rejected because the tip"}, // confirmed: git 2.39.5, push hint
+	{kind: KindNonFastForward, text: "not possible to fast-forward"},          // confirmed: git 2.39.5, merge / pull --ff-only
+
+	{kind: KindMergeConflict, text: "automatic merge failed"},  // confirmed: git 2.39.5, merge
+	{kind: KindMergeConflict, text: "could not apply"},         // confirmed: git 2.39.5, cherry-pick / rebase
+	{kind: KindMergeConflict, text: "you have unmerged paths"}, // in git 2.39.5's strings; no failing command reproduced it
+	{kind: KindMergeConflict, text: "is unmerged"},             // confirmed: git 2.39.5, checkout -- <path> mid-conflict
+
+	{kind: KindNotFullyMerged, text: "is not fully merged"}, // confirmed: git 2.39.5, branch -d
+
+	{kind: KindRemoteUnreachable, text: "could not read from remote repository"},  // confirmed: git 2.39.5, fetch / ssh transport
+	{kind: KindRemoteUnreachable, text: "does not appear to be a git repository"}, // confirmed: git 2.39.5, fetch from an unknown remote
+	{kind: KindRemoteUnreachable, text: "could not resolve host"},                 // confirmed: git 2.39.5, curl's text over http(s)
+	{kind: KindRemoteUnreachable, text: "failed to connect to"},                   // confirmed: git 2.39.5, curl's text over http(s)
+	{kind: KindRemoteUnreachable, text: "connection refused"},                     // confirmed: git 2.39.5, errno text over git:// and ssh
+	{kind: KindRemoteUnreachable, text: "connection timed out"},                   // errno text, not git's: no git version applies
+	{kind: KindRemoteUnreachable, text: "unable to access '"},                     // confirmed: git 2.39.5, http(s) transport
+	{kind: KindRemoteUnreachable, text: "repository not found"},                   // the server's text (github, gitlab), not git's
+
+	{kind: KindRefExists, text: "' already exists"}, // confirmed: git 2.39.5, tag / branch
+
+	{kind: KindBadRef, text: "not a valid object name"},                          // confirmed: git 2.39.5, cat-file / merge-base
+	{kind: KindBadRef, text: "unknown revision or path not in the working tree"}, // confirmed: git 2.39.5, log / rev-list
+	{kind: KindBadRef, text: "did not match any file(s) known to git"},           // confirmed: git 2.39.5, checkout
+	{kind: KindBadRef, text: "invalid reference:"},                               // confirmed: git 2.39.5, switch -c <start>
+	{kind: KindBadRef, text: "needed a single revision"},                         // confirmed: git 2.39.5, rev-parse --verify
+	{kind: KindBadRef, text: "bad revision"},                                     // confirmed: git 2.39.5, log --no-walk
+	{kind: KindBadRef, text: "not a valid branch name"},                          // confirmed: git 2.39.5, branch
+	{kind: KindBadRef, text: "remote ref does not exist"},                        // confirmed: git 2.39.5, push --delete
+	{kind: KindBadRef, text: "couldn't find remote ref"},                         // confirmed: git 2.39.5, fetch / pull
+}
+
+// classify picks a Kind from, in order: porcelain markers on stdout (merge
+// conflicts, `push --porcelain` rejections), the exit codes some commands
+// document, and the phrase table filtered to the running git version.
+func classify(args []string, code int, stdout, stderr string, version [3]int) ErrorKind {
+	if k := classifyPorcelain(stdout); k != KindUnknown {
+		return k
+	}
+	if k := classifyExitCode(args, code); k != KindUnknown {
+		return k
+	}
+	return classifyPhrases(phrases, stderr, version)
+}
+
+func classifyPorcelain(stdout string) ErrorKind {
+	for _, line := range strings.Split(stdout, "\n") {
+		switch {
+		case strings.HasPrefix(line, "CONFLICT ("):
+			return KindMergeConflict
+		case strings.HasPrefix(line, "!\t"):
+			// push --porcelain: "!<tab>src:dst<tab>[rejected] (reason)"
+			if strings.Contains(line, "(non-fast-forward)") || strings.Contains(line, "(fetch first)") ||
+				strings.Contains(line, "(stale info)") {
+				return KindNonFastForward
+			}
+		}
+	}
+	return KindUnknown
+}
+
+func classifyExitCode(args []string, code int) ErrorKind {
+	sub := subcommand(args)
+	switch {
+	case sub == "ls-remote" && code == 2 && slices.Contains(args, "--exit-code"):
+		return KindBadRef // no matching refs
+	case (sub == "rev-parse" || sub == "show-ref") && code == 1 && slices.Contains(args, "--verify") &&
+		(slices.Contains(args, "--quiet") || slices.Contains(args, "-q")):
+		return KindBadRef // quiet verify says nothing, just exits 1
+	}
+	return KindUnknown
+}
+
+func classifyPhrases(table []phrase, stderr string, version [3]int) ErrorKind {
+	if stderr == "" {
+		return KindUnknown
+	}
+	lower := strings.ToLower(stderr)
+	for _, p := range table {
+		if version != ([3]int{}) {
+			if p.since != ([3]int{}) && compareVersion(version, p.since) < 0 {
+				continue
+			}
+			if p.until != ([3]int{}) && compareVersion(version, p.until) >= 0 {
+				continue
+			}
+		}
+		if strings.Contains(lower, p.text) {
+			return p.kind
+		}
+	}
+	return KindUnknown
+}
+
+// subcommand skips leading `-c k=v` / `-C dir` pairs and other global
+// flags to find the git subcommand in args.
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "-c" || a == "-C":
+			i++
+		case strings.HasPrefix(a, "-"):
+		default:
+			return a
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name           string
+		args           []string
+		code           int
+		stdout, stderr string
+		want           ErrorKind
+	}{
+		{"merge conflict marker", []string{"merge", "x"}, 1, "Auto-merging a\nCONFLICT (content): Merge conflict in a\n", "", KindMergeConflict},
+		{"porcelain push rejection", []string{"push", "--porcelain", "origin", "main"}, 1,
+			"To /r\n!\trefs/heads/main:refs/heads/main\t[rejected] (fetch first)\nDone\n", "", KindNonFastForward},
+		{"ls-remote no match", []string{"ls-remote", "--exit-code", "--heads", "origin", "x"}, 2, "", "", KindBadRef},
+		{"quiet verify", []string{"show-ref", "--verify", "--quiet", "refs/heads/x"}, 1, "", "", KindBadRef},
+		{"loud verify falls to phrases", []string{"rev-parse", "--verify", "x"}, 128, "", "fatal: Needed a single revision", KindBadRef},
+		{"push rejected", []string{"push"}, 1, "", " ! [rejected]        main -> main (non-fast-forward)\nerror: failed to push some refs", KindNonFastForward},
+		{"index lock", []string{"commit", "-m", "x"}, 128, "", "fatal: Unable to create '/r/.git/index.lock': File exists.", KindLockExists},
+		{"dirty checkout", []string{"checkout", "x"}, 1, "",
+			"error: Your local changes to the following files would be overwritten by checkout:\n\ta\nPlease commit your changes or stash them before you switch branches.\nAborting", KindDirtyOverwrite},
+		{"not fully merged", []string{"branch", "-d", "x"}, 1, "", "error: The branch 'x' is not fully merged.", KindNotFullyMerged},
+		{"no remote", []string{"fetch", "nowhere"}, 128, "", "fatal: 'nowhere' does not appear to be a git repository", KindRemoteUnreachable},
+		{"tag exists", []string{"tag", "-a", "v1"}, 128, "", "fatal: tag 'v1' already exists", KindRefExists},
+		{"remote ref gone", []string{"push", "--delete", "origin", "x"}, 1, "", "error: unable to delete 'x': remote ref does not exist", KindBadRef},
+		{"global flags before subcommand", []string{"-c", "a=b", "ls-remote", "--exit-code"}, 2, "", "", KindBadRef},
+		{"unrecognised", []string{"status"}, 128, "", "fatal: something new", KindUnknown},
+	}
+	for _, tc := range cases {
+		got := classify(tc.args, tc.code, tc.stdout, tc.stderr, [3]int{2, 43, 0})
+		assert.That(t, got == tc.want, "%s: got %v, want %v", tc.name, got, tc.want)
+	}
+}
+
+func TestClassifyPhrases_VersionBounds(t *testing.T) {
+	t.Parallel()
+	table := []phrase{
+		{kind: KindBadRef, text: "old wording", until: [3]int{2, 40, 0}},
+		{kind: KindBadRef, text: "new wording", since: [3]int{2, 40, 0}},
+	}
+	cases := []struct {
+		stderr  string
+		version [3]int
+		want    ErrorKind
+	}{
+		{"old wording", [3]int{2, 39, 5}, KindBadRef},
+		{"old wording", [3]int{2, 40, 0}, KindUnknown},
+		{"new wording", [3]int{2, 39, 5}, KindUnknown},
+		{"NEW Wording", [3]int{2, 45, 1}, KindBadRef},
+		// version unknown (check not run yet): every row applies
+		{"old wording", [3]int{}, KindBadRef},
+		{"new wording", [3]int{}, KindBadRef},
+	}
+	for _, tc := range cases {
+		got := classifyPhrases(table, tc.stderr, tc.version)
+		assert.That(t, got == tc.want, "%q at %v: got %v, want %v", tc.stderr, tc.version, got, tc.want)
+	}
+}
+
+// end to end: real git failures come back as *Error with the right kind,
+// through the helpers' own fmt.Errorf wrapping.
+func TestError_FromGit(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		run  func(t *testing.T, r Repo) error
+		want ErrorKind
+	}{
+		{"checkout missing branch", func(t *testing.T, r Repo) error {
+			return r.Checkout("nope")
+		}, KindBadRef},
+		{"unmerged branch delete", func(t *testing.T, r Repo) error {
+			temp_repo.RunGit(t, r.Dir, "checkout", "-q", "-b", "x")
+			temp_repo.CreateCommit(t, r.Dir, "x.txt", "x", "x")
+			temp_repo.RunGit(t, r.Dir, "checkout", "-q", "main")
+			_, _, err := r.RunWrite("branch", "-d", "x")
+			return err
+		}, KindNotFullyMerged},
+		{"index lock", func(t *testing.T, r Repo) error {
+			require.NoError(t, os.WriteFile(filepath.Join(r.Dir, ".git", "index.lock"), nil, 0o644))
+			return r.Add("README.md")
+		}, KindLockExists},
+		{"dirty overwrite", func(t *testing.T, r Repo) error {
+			temp_repo.RunGit(t, r.Dir, "checkout", "-q", "-b", "x")
+			temp_repo.CreateCommit(t, r.Dir, "f.txt", "x", "x")
+			temp_repo.RunGit(t, r.Dir, "checkout", "-q", "main")
+			require.NoError(t, os.WriteFile(filepath.Join(r.Dir, "f.txt"), []byte("untracked"), 0o644))
+			return r.Checkout("x")
+		}, KindDirtyOverwrite},
+		{"merge conflict", func(t *testing.T, r Repo) error {
+			temp_repo.RunGit(t, r.Dir, "checkout", "-q", "-b", "x")
+			temp_repo.CreateCommit(t, r.Dir, "f.txt", "x", "x")
+			temp_repo.RunGit(t, r.Dir, "checkout", "-q", "main")
+			temp_repo.CreateCommit(t, r.Dir, "f.txt", "main", "main")
+			_, _, err := r.RunWrite("merge", "--no-edit", "x")
+			return err
+		}, KindMergeConflict},
+		{"unknown remote", func(t *testing.T, r Repo) error {
+			return r.Fetch("nowhere", "")
+		}, KindRemoteUnreachable},
+		{"tag exists", func(t *testing.T, r Repo) error {
+			require.NoError(t, r.TagAnnotated("v1", "one"))
+			return r.TagAnnotated("v1", "again")
+		}, KindRefExists},
+	}
+	for _, tc := range cases {
+		r := Repo{Dir: temp_repo.NewRepo(t)}
+		err := tc.run(t, r)
+		var ge *Error
+		require.That(t, errors.As(err, &ge), "%s: want *git.Error, got %v", tc.name, err)
+		assert.That(t, ge.Kind == tc.want, "%s: got %v, want %v (stderr %q)", tc.name, ge.Kind, tc.want, ge.Stderr)
+		assert.That(t, ge.ExitCode > 0, "%s: exit code %d", tc.name, ge.ExitCode)
+		assert.That(t, len(ge.Args) > 0 && ge.Args[0] != "--no-pager", "%s: args should skip the prelude: %v", tc.name, ge.Args)
+	}
+}
+
+func TestError_NonFastForwardPush(t *testing.T) {
+	t.Parallel()
+	local, remote := temp_repo.NewRepoWithRemote(t)
+	// a second clone moves the remote on
+	other := t.TempDir()
+	temp_repo.RunGit(t, other, "clone", "-q", remote, ".")
+	temp_repo.RunGit(t, other, "config", "user.name", "Other User")
+	temp_repo.RunGit(t, other, "config", "user.email", "other@example.com")
+	temp_repo.CreateCommit(t, other, "theirs.txt", "theirs", "theirs")
+	temp_repo.RunGit(t, other, "push", "-q", "origin", "HEAD")
+
+	temp_repo.CreateCommit(t, local, "ours.txt", "ours", "ours")
+	err := Repo{Dir: local}.Push()
+	assert.That(t, KindOf(err) == KindNonFastForward, "got %v from %v", KindOf(err), err)
+}
+
+func TestKindOf_NotAGitError(t *testing.T) {
+	t.Parallel()
+	assert.That(t, KindOf(nil) == KindUnknown, "nil")
+	assert.That(t, KindOf(context.Canceled) == KindUnknown, "plain error")
+	assert.That(t, KindOf(fmt.Errorf("wrapped: %w", &Error{Kind: KindBadRef, err: errors.New("exit status 1")})) == KindBadRef, "wrapped")
+}
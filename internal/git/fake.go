@@ -0,0 +1,881 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Fake is an in-memory Backend for tests: a repo reduced to its refs,
+// upstreams, remotes' refs and a canned worktree status. Commits are bare
+// oids with a parent, enough for pushes to tell a fast-forward from a
+// non-fast-forward the way git does. Anything else -- conflicts, a remote
+// that can't be reached -- is scripted with FailNext.
+//
+// The fields are the repo's state; set them up before handing the Fake to
+// a command and inspect them afterwards.
+type Fake struct {
+	mu sync.Mutex
+
+	// Head is the checked-out branch.
+	Head string
+	// Refs maps full refnames (refs/heads/x, refs/remotes/origin/x,
+	// refs/tags/x) to oids.
+	Refs map[string]string
+	// Upstreams maps a local branch to its upstream's short name,
+	// origin/main.
+	Upstreams map[string]string
+	// RemoteRefs maps each remote to the branches on the remote itself,
+	// refs/heads/x -> oid. Fetch copies them into refs/remotes/<remote>/.
+	RemoteRefs map[string]map[string]string
+	// Worktree is what Status reports. Commit, ResetHard and StashPush
+	// clear it.
+	Worktree []StatusEntry
+	// Subs is what Submodules reports. SubmoduleUpdate syncs their
+	// CheckedOut to Recorded; nothing else touches them.
+	Subs []Submodule
+	// Trees is what Worktrees reports; a linked one's Branch shows up as
+	// its Worktree in RefsWithUpstreams. PruneWorktrees drops the ones
+	// that are Prunable and not Locked.
+	Trees []Worktree
+	// Patches maps Patch's args, space-joined ("" for the worktree,
+	// "--cached", ...), to the patch it returns. Missing ones are empty.
+	Patches map[string]string
+	// Applied lists the patches ApplyPatch was given, in order. It
+	// doesn't change the worktree or Patches.
+	Applied []AppliedPatch
+	// Exec answers raw Run / RunWrite calls the Fake doesn't model itself.
+	// Nil fails them.
+	Exec func(args []string) (stdout, stderr string, err error)
+
+	parents  map[string]string
+	journal  []JournalEntry
+	stashes  [][]StatusEntry
+	failures map[string][]error
+	seq      int
+}
+
+var _ Backend = (*Fake)(nil)
+
+// NewFake returns a Fake with one commit on main, checked out, and no
+// remotes.
+func NewFake() *Fake {
+	f := &Fake{
+		Head:       "main",
+		Refs:       map[string]string{},
+		Upstreams:  map[string]string{},
+		RemoteRefs: map[string]map[string]string{},
+		Patches:    map[string]string{},
+		parents:    map[string]string{},
+		failures:   map[string][]error{},
+	}
+	f.Refs["refs/heads/main"] = f.newCommit("")
+	return f
+}
+
+// NewCommit returns a new commit's oid. parent is the commit it follows,
+// "" for a root commit.
+func (f *Fake) NewCommit(parent string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.newCommit(parent)
+}
+
+func (f *Fake) newCommit(parent string) string {
+	f.seq++
+	oid := fmt.Sprintf("%040x", f.seq)
+	f.parents[oid] = parent
+	return oid
+}
+
+// isAncestor reports whether a is b or one of its parents.
+func (f *Fake) isAncestor(a, b string) bool {
+	for c := b; c != ""; c = f.parents[c] {
+		if c == a {
+			return true
+		}
+	}
+	return false
+}
+
+// AddRemote adds a remote whose branches are those given (name -> oid),
+// and fetches it.
+func (f *Fake) AddRemote(name string, branches map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	refs := map[string]string{}
+	for b, oid := range branches {
+		refs["refs/heads/"+b] = oid
+	}
+	f.RemoteRefs[name] = refs
+	f.fetch(name, "")
+}
+
+// FailNext makes the next call that runs git subcommand cmd ("push",
+// "checkout", "rev-parse", ...) fail with err. Failures queue up in order.
+func (f *Fake) FailNext(cmd string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[cmd] = append(f.failures[cmd], err)
+}
+
+// FakeError builds the *Error a git run that failed with kind would have
+// returned, for FailNext.
+func FakeError(kind ErrorKind, stderr string, args ...string) *Error {
+	return &Error{Args: args, ExitCode: 1, Stderr: stderr, Kind: kind}
+}
+
+func (f *Fake) fail(cmd string) error {
+	q := f.failures[cmd]
+	if len(q) == 0 {
+		return nil
+	}
+	f.failures[cmd] = q[1:]
+	return q[0]
+}
+
+// resolve looks ref up the way rev-parse would: HEAD, a full refname, or
+// a short branch, tag or remote-tracking name.
+func (f *Fake) resolve(ref string) (string, bool) {
+	if ref == "HEAD" {
+		ref = "refs/heads/" + f.Head
+	}
+	for _, name := range []string{ref, "refs/heads/" + ref, "refs/tags/" + ref, "refs/remotes/" + ref} {
+		if oid, ok := f.Refs[name]; ok {
+			return oid, true
+		}
+	}
+	if _, ok := f.parents[ref]; ok {
+		return ref, true
+	}
+	return "", false
+}
+
+func badRef(ref string, args ...string) error {
+	return FakeError(KindBadRef, fmt.Sprintf("fatal: ambiguous argument '%s': unknown revision", ref), args...)
+}
+
+func (f *Fake) CheckInRepo() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fail("rev-parse")
+}
+
+func (f *Fake) GetCurrentBranch() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Head, f.fail("rev-parse")
+}
+
+func (f *Fake) GetCurrentBranchUpstream() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Upstreams[f.Head], f.fail("rev-parse")
+}
+
+func (f *Fake) GetBranchUpstream(branch string) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("for-each-ref"); err != nil {
+		return "", "", err
+	}
+	remote, rb, _ := strings.Cut(f.Upstreams[branch], "/")
+	return remote, rb, nil
+}
+
+func (f *Fake) GetBranchTrackingRemote(branch string) (string, error) {
+	remote, _, err := f.GetBranchUpstream(branch)
+	return remote, err
+}
+
+func (f *Fake) GetCommitHash(ref string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("rev-parse"); err != nil {
+		return "", err
+	}
+	oid, ok := f.resolve(ref)
+	if !ok {
+		return "", badRef(ref, "rev-parse", ref)
+	}
+	return oid, nil
+}
+
+func (f *Fake) GetDefaultBranch() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, b := range []string{"main", "master"} {
+		if _, ok := f.Refs["refs/heads/"+b]; ok {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine default branch")
+}
+
+func (f *Fake) GetLocalBranches() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("branch"); err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, name := range slices.Sorted(maps.Keys(f.Refs)) {
+		if b, ok := strings.CutPrefix(name, "refs/heads/"); ok {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (f *Fake) GetRemotes() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("remote"); err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(f.RemoteRefs)), nil
+}
+
+func (f *Fake) BranchExists(branch string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.Refs["refs/heads/"+branch]
+	return ok
+}
+
+func (f *Fake) TagExists(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.resolve(name)
+	return ok
+}
+
+func (f *Fake) IsBranchAheadOfRemote(localBranch, remoteBranch string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("log"); err != nil {
+		return false, err
+	}
+	local, ok := f.resolve(localBranch)
+	if !ok {
+		return false, badRef(localBranch, "log", localBranch)
+	}
+	remote, ok := f.resolve(remoteBranch)
+	if !ok {
+		return false, badRef(remoteBranch, "log", remoteBranch)
+	}
+	return !f.isAncestor(local, remote), nil
+}
+
+func (f *Fake) RefOids(names ...string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("for-each-ref"); err != nil {
+		return nil, err
+	}
+	oids := map[string]string{}
+	for _, n := range names {
+		if oid, ok := f.Refs[n]; ok {
+			oids[n] = oid
+		}
+	}
+	return oids, nil
+}
+
+func (f *Fake) RefsWithUpstreamsCtx(ctx context.Context) ([]Ref, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("for-each-ref"); err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for _, name := range slices.Sorted(maps.Keys(f.Refs)) {
+		ref := Ref{Name: name, Oid: f.Refs[name]}
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"):
+			b := ref.Short()
+			if up := f.Upstreams[b]; up != "" {
+				ref.Remote, _, _ = strings.Cut(up, "/")
+				ref.Upstream = "refs/remotes/" + up
+				_, tracked := f.Refs[ref.Upstream]
+				ref.Gone = !tracked
+			}
+			ref.Head = b == f.Head
+			for _, wt := range f.Trees {
+				if wt.Branch == b {
+					ref.Worktree = wt.Path
+				}
+			}
+		case strings.HasPrefix(name, "refs/remotes/"):
+		default:
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// CommitInfo knows only oids and parents; the rest of each CommitInfo is
+// made up from the oid.
+func (f *Fake) CommitInfo(revs ...string) ([]CommitInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("cat-file"); err != nil {
+		return nil, err
+	}
+	out := make([]CommitInfo, len(revs))
+	for i, rev := range revs {
+		oid, ok := f.resolve(strings.TrimSuffix(rev, "^{commit}"))
+		if !ok {
+			return nil, badRef(rev, "cat-file", rev)
+		}
+		out[i] = CommitInfo{Oid: oid, Message: "commit " + oid[len(oid)-7:]}
+		if p := f.parents[oid]; p != "" {
+			out[i].Parents = []string{p}
+		}
+	}
+	return out, nil
+}
+
+func (f *Fake) Status() (Status, error) {
+	return f.StatusWith(StatusOptions{})
+}
+
+// StatusWith ignores opts: Worktree is reported as it is.
+func (f *Fake) StatusWith(opts StatusOptions) (Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("status"); err != nil {
+		return Status{}, err
+	}
+	st := Status{
+		Branch:  BranchStatus{Oid: f.Refs["refs/heads/"+f.Head], Head: f.Head, Upstream: f.Upstreams[f.Head]},
+		Stash:   len(f.stashes),
+		Entries: slices.Clone(f.Worktree),
+	}
+	if len(opts.Paths) > 0 {
+		// plain paths only: an entry is in if it's one, or under one
+		st.Entries = slices.DeleteFunc(st.Entries, func(e StatusEntry) bool {
+			return !slices.ContainsFunc(opts.Paths, func(p string) bool {
+				p = strings.TrimSuffix(p, "/")
+				return e.Path == p || strings.HasPrefix(e.Path, p+"/")
+			})
+		})
+	}
+	if up := st.Branch.Upstream; up != "" {
+		_, tracked := f.Refs["refs/remotes/"+up]
+		st.Branch.Gone = !tracked
+	}
+	return st, nil
+}
+
+func (f *Fake) DirtyTrackedLines() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("status"); err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, e := range f.Worktree {
+		if e.Tracked() {
+			lines = append(lines, e.Code()+" "+e.Path)
+		}
+	}
+	return lines, nil
+}
+
+func (f *Fake) Submodules() ([]Submodule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("ls-files"); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.Subs), nil
+}
+
+func (f *Fake) Worktrees() ([]Worktree, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("worktree"); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.Trees), nil
+}
+
+// SubmoduleDiff reports the worktree's drifted Subs; commits don't carry
+// gitlinks in a Fake, so any other diff has none.
+func (f *Fake) SubmoduleDiff(args ...string) ([]SubmoduleChange, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("diff"); err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		return nil, nil
+	}
+	var changes []SubmoduleChange
+	for _, s := range f.Subs {
+		if s.Drifted() {
+			changes = append(changes, SubmoduleChange{Path: s.Path, Old: s.Recorded, New: s.CheckedOut})
+		}
+	}
+	return changes, nil
+}
+
+func (f *Fake) Patch(args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("diff"); err != nil {
+		return "", err
+	}
+	return f.Patches[strings.Join(args, " ")], nil
+}
+
+func (f *Fake) Add(paths ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fail("add")
+}
+
+// AppliedPatch is one ApplyPatch call on a Fake.
+type AppliedPatch struct {
+	Patch           string
+	Cached, Reverse bool
+}
+
+func (f *Fake) ApplyPatch(patch string, cached, reverse bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("apply"); err != nil {
+		return err
+	}
+	f.Applied = append(f.Applied, AppliedPatch{Patch: patch, Cached: cached, Reverse: reverse})
+	return nil
+}
+
+func (f *Fake) Checkout(branch string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("checkout"); err != nil {
+		return err
+	}
+	if _, ok := f.Refs["refs/heads/"+branch]; !ok {
+		return badRef(branch, "checkout", branch)
+	}
+	f.Head = branch
+	return nil
+}
+
+// CheckoutNewBranch sets the new branch's upstream when startPoint is a
+// remote-tracking branch, as git's default branch.autoSetupMerge does.
+func (f *Fake) CheckoutNewBranch(branch, startPoint string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("checkout"); err != nil {
+		return err
+	}
+	if _, ok := f.Refs["refs/heads/"+branch]; ok {
+		return FakeError(KindRefExists, fmt.Sprintf("fatal: a branch named '%s' already exists", branch), "checkout", "-b", branch)
+	}
+	if startPoint == "" {
+		startPoint = "HEAD"
+	}
+	oid, ok := f.resolve(startPoint)
+	if !ok {
+		return badRef(startPoint, "checkout", "-b", branch, startPoint)
+	}
+	f.Refs["refs/heads/"+branch] = oid
+	if _, ok := f.Refs["refs/remotes/"+startPoint]; ok {
+		f.Upstreams[branch] = startPoint
+	}
+	f.Head = branch
+	return nil
+}
+
+func (f *Fake) ResetHard(ref string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("reset"); err != nil {
+		return err
+	}
+	oid, ok := f.resolve(ref)
+	if !ok {
+		return badRef(ref, "reset", "--hard", ref)
+	}
+	f.Refs["refs/heads/"+f.Head] = oid
+	f.Worktree = nil
+	return nil
+}
+
+func (f *Fake) Commit(message string) error {
+	return f.commit()
+}
+
+func (f *Fake) CommitEmpty(message string) error {
+	return f.commit()
+}
+
+func (f *Fake) commit() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("commit"); err != nil {
+		return err
+	}
+	head := "refs/heads/" + f.Head
+	f.Refs[head] = f.newCommit(f.Refs[head])
+	f.Worktree = nil
+	return nil
+}
+
+func (f *Fake) TagAnnotated(name, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("tag"); err != nil {
+		return err
+	}
+	if _, ok := f.Refs["refs/tags/"+name]; ok {
+		return FakeError(KindRefExists, fmt.Sprintf("fatal: tag '%s' already exists", name), "tag", "-a", name)
+	}
+	f.Refs["refs/tags/"+name] = f.Refs["refs/heads/"+f.Head]
+	return nil
+}
+
+func (f *Fake) StashPush(message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("stash"); err != nil {
+		return err
+	}
+	var kept, stashed []StatusEntry
+	for _, e := range f.Worktree {
+		if e.Tracked() {
+			stashed = append(stashed, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	f.stashes = append(f.stashes, stashed)
+	f.Worktree = kept
+	return nil
+}
+
+func (f *Fake) StashPopIndex() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("stash"); err != nil {
+		return err
+	}
+	if len(f.stashes) == 0 {
+		return FakeError(KindUnknown, "No stash entries found.", "stash", "pop")
+	}
+	last := len(f.stashes) - 1
+	f.Worktree = append(f.Worktree, f.stashes[last]...)
+	f.stashes = f.stashes[:last]
+	return nil
+}
+
+// StashCreate returns a made-up oid when the worktree has tracked
+// changes. Nothing can apply it.
+func (f *Fake) StashCreate() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("stash"); err != nil {
+		return "", err
+	}
+	if !slices.ContainsFunc(f.Worktree, StatusEntry.Tracked) {
+		return "", nil
+	}
+	return f.newCommit(f.Refs["refs/heads/"+f.Head]), nil
+}
+
+func (f *Fake) PruneWorktrees() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("worktree"); err != nil {
+		return err
+	}
+	f.Trees = slices.DeleteFunc(f.Trees, func(wt Worktree) bool { return wt.Prunable && !wt.Locked })
+	return nil
+}
+
+func (f *Fake) SubmoduleUpdateCtx(ctx context.Context, paths ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("submodule"); err != nil {
+		return err
+	}
+	for i, s := range f.Subs {
+		if len(paths) == 0 || slices.Contains(paths, s.Path) {
+			f.Subs[i].CheckedOut = s.Recorded
+		}
+	}
+	return nil
+}
+
+func (f *Fake) FetchCtx(ctx context.Context, remote, refspec string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("fetch"); err != nil {
+		return err
+	}
+	return f.fetch(remote, refspec)
+}
+
+// fetch copies remote's branches (just refspec's, if given) into its
+// remote-tracking refs.
+func (f *Fake) fetch(remote, refspec string) error {
+	refs, ok := f.RemoteRefs[remote]
+	if !ok {
+		return f.unreachable(remote, "fetch", remote)
+	}
+	for name, oid := range refs {
+		b := strings.TrimPrefix(name, "refs/heads/")
+		if refspec == "" || refspec == b || refspec == name {
+			f.Refs["refs/remotes/"+remote+"/"+b] = oid
+		}
+	}
+	return nil
+}
+
+func (f *Fake) unreachable(remote string, args ...string) error {
+	return FakeError(KindRemoteUnreachable, fmt.Sprintf("fatal: '%s' does not appear to be a git repository", remote), args...)
+}
+
+// PushCtx pushes the current branch to its upstream, as a bare `git push`
+// does.
+func (f *Fake) PushCtx(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("push"); err != nil {
+		return err
+	}
+	remote, rb, ok := strings.Cut(f.Upstreams[f.Head], "/")
+	if !ok {
+		return FakeError(KindUnknown, fmt.Sprintf("fatal: The current branch %s has no upstream branch.", f.Head), "push")
+	}
+	return f.push(remote, f.Head, rb, false)
+}
+
+// push updates branch dst on remote to local branch src, refusing a
+// non-fast-forward unless forced (with a lease on the remote-tracking
+// ref).
+func (f *Fake) push(remote, src, dst string, forceWithLease bool) error {
+	refs, ok := f.RemoteRefs[remote]
+	if !ok {
+		return f.unreachable(remote, "push", remote)
+	}
+	oid, ok := f.Refs["refs/heads/"+src]
+	if !ok {
+		return FakeError(KindBadRef, fmt.Sprintf("error: src refspec %s does not match any", src), "push", remote, src)
+	}
+	tracking := "refs/remotes/" + remote + "/" + dst
+	if cur, exists := refs["refs/heads/"+dst]; exists {
+		switch {
+		case forceWithLease && cur != f.Refs[tracking]:
+			return FakeError(KindNonFastForward, fmt.Sprintf(" ! [rejected]        %s -> %s (stale info)", src, dst), "push", remote, src)
+		case !forceWithLease && !f.isAncestor(cur, oid):
+			return FakeError(KindNonFastForward, fmt.Sprintf(" ! [rejected]        %s -> %s (non-fast-forward)", src, dst), "push", remote, src)
+		}
+	}
+	refs["refs/heads/"+dst] = oid
+	f.Refs[tracking] = oid
+	return nil
+}
+
+func (f *Fake) LsRemoteCtx(ctx context.Context, remote string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("ls-remote"); err != nil {
+		return "", err
+	}
+	refs, ok := f.RemoteRefs[remote]
+	if !ok {
+		return "", f.unreachable(remote, "ls-remote", remote)
+	}
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(refs)) {
+		fmt.Fprintf(&b, "%s\t%s\n", refs[name], name)
+	}
+	return b.String(), nil
+}
+
+func (f *Fake) RemoteBranchExistsCtx(ctx context.Context, remote, branch string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("ls-remote"); err != nil {
+		return false, err
+	}
+	_, ok := f.RemoteRefs[remote]["refs/heads/"+branch]
+	return ok, nil
+}
+
+func (f *Fake) Journal() ([]JournalEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.journal), nil
+}
+
+func (f *Fake) AppendJournal(e JournalEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if e.ID == "" {
+		f.seq++
+		e.ID = fmt.Sprintf("fake%d", f.seq)
+	}
+	f.journal = append(f.journal, e)
+	return nil
+}
+
+// Run answers the raw invocations commands make that the Fake models
+// (see runRaw), and hands the rest to Exec.
+func (f *Fake) Run(args ...string) (string, string, error) {
+	return f.runRaw(args)
+}
+
+func (f *Fake) RunLines(fn func(line string) error, args ...string) error {
+	stdout, _, err := f.runRaw(args)
+	if err != nil {
+		return err
+	}
+	for line := range strings.Lines(stdout) {
+		if err := fn(strings.TrimSuffix(line, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Fake) RunWrite(args ...string) (string, string, error) {
+	return f.runRaw(args)
+}
+
+func (f *Fake) RunWriteStream(args ...string) (string, string, error) {
+	return f.runRaw(args)
+}
+
+func (f *Fake) RunWriteStreamCtx(ctx context.Context, args ...string) (string, string, error) {
+	return f.runRaw(args)
+}
+
+// runRaw models the raw invocations whose effect on refs matters to the
+// commands that make them: push (with -u, --force-with-lease, --delete),
+// branch --set-upstream-to, branch -d / -D and rev-parse --verify.
+func (f *Fake) runRaw(args []string) (string, string, error) {
+	f.mu.Lock()
+	cmd := subcommand(args)
+	if err := f.fail(cmd); err != nil {
+		f.mu.Unlock()
+		return "", errorStderr(err), err
+	}
+	stdout, handled, err := f.rawLocked(args)
+	f.mu.Unlock()
+	if handled {
+		return stdout, errorStderr(err), err
+	}
+	if f.Exec == nil {
+		return "", "", fmt.Errorf("git.Fake: no Exec for git %s", strings.Join(args, " "))
+	}
+	return f.Exec(args)
+}
+
+func (f *Fake) rawLocked(args []string) (stdout string, handled bool, err error) {
+	switch {
+	case len(args) > 0 && args[0] == "push":
+		return "", true, f.rawPush(args[1:])
+	case len(args) == 3 && args[0] == "branch" && strings.HasPrefix(args[1], "--set-upstream-to="):
+		up := strings.TrimPrefix(args[1], "--set-upstream-to=")
+		if _, ok := f.Refs["refs/remotes/"+up]; !ok {
+			return "", true, FakeError(KindBadRef, fmt.Sprintf("fatal: the requested upstream branch '%s' does not exist", up), args...)
+		}
+		f.Upstreams[args[2]] = up
+		return "", true, nil
+	case len(args) == 3 && args[0] == "branch" && (args[1] == "-d" || args[1] == "-D"):
+		name := "refs/heads/" + args[2]
+		oid, ok := f.Refs[name]
+		switch {
+		case !ok:
+			return "", true, badRef(args[2], args...)
+		case args[2] == f.Head:
+			return "", true, FakeError(KindUnknown, fmt.Sprintf("error: Cannot delete branch '%s' checked out", args[2]), args...)
+		case args[1] == "-d" && !f.isAncestor(oid, f.Refs["refs/heads/"+f.Head]):
+			return "", true, FakeError(KindNotFullyMerged, fmt.Sprintf("error: The branch '%s' is not fully merged.", args[2]), args...)
+		}
+		delete(f.Refs, name)
+		delete(f.Upstreams, args[2])
+		return "", true, nil
+	case len(args) >= 2 && args[0] == "rev-parse" && args[1] == "--verify":
+		ref := args[len(args)-1]
+		oid, ok := f.resolve(ref)
+		if !ok {
+			return "", true, badRef(ref, args...)
+		}
+		return oid, true, nil
+	}
+	return "", false, nil
+}
+
+func (f *Fake) rawPush(args []string) error {
+	var setUpstream, lease, del bool
+	var pos []string
+	for _, a := range args {
+		switch {
+		case a == "-u" || a == "--set-upstream":
+			setUpstream = true
+		case a == "--force-with-lease" || strings.HasPrefix(a, "--force-with-lease="):
+			lease = true
+		case a == "--delete" || a == "-d":
+			del = true
+		case strings.HasPrefix(a, "-"):
+		default:
+			pos = append(pos, a)
+		}
+	}
+	if len(pos) == 0 {
+		// bare push: the current branch to its upstream
+		remote, rb, ok := strings.Cut(f.Upstreams[f.Head], "/")
+		if !ok {
+			return FakeError(KindUnknown, fmt.Sprintf("fatal: The current branch %s has no upstream branch.", f.Head), "push")
+		}
+		pos = []string{remote, f.Head + ":" + rb}
+	}
+	if len(pos) != 2 {
+		return fmt.Errorf("git.Fake: push wants <remote> <branch>, got %q", args)
+	}
+	remote, spec := pos[0], pos[1]
+	src, dst, ok := strings.Cut(spec, ":")
+	if !ok {
+		dst = src
+	}
+	dst = strings.TrimPrefix(dst, "refs/heads/")
+	if del || src == "" {
+		refs, ok := f.RemoteRefs[remote]
+		if !ok {
+			return f.unreachable(remote, "push", remote)
+		}
+		if _, ok := refs["refs/heads/"+dst]; !ok {
+			return FakeError(KindBadRef, fmt.Sprintf("error: unable to delete '%s': remote ref does not exist", dst), "push", remote)
+		}
+		delete(refs, "refs/heads/"+dst)
+		delete(f.Refs, "refs/remotes/"+remote+"/"+dst)
+		return nil
+	}
+	if err := f.push(remote, src, dst, lease); err != nil {
+		return err
+	}
+	if setUpstream {
+		f.Upstreams[src] = remote + "/" + dst
+	}
+	return nil
+}
+
+func errorStderr(err error) string {
+	var ge *Error
+	if errors.As(err, &ge) {
+		return ge.Stderr
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package git_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// fakeWithOrigin is a Fake whose main tracks origin/main, both at the
+// root commit.
+func fakeWithOrigin(t *testing.T) *git.Fake {
+	t.Helper()
+	f := git.NewFake()
+	f.AddRemote("origin", map[string]string{"main": f.Refs["refs/heads/main"]})
+	f.Upstreams["main"] = "origin/main"
+	return f
+}
+
+func TestFake_Push(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("fast-forward", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithOrigin(t)
+		require.NoError(t, f.Commit("ours"))
+		require.NoError(t, f.PushCtx(ctx))
+		assert.Equal(t, f.RemoteRefs["origin"]["refs/heads/main"], f.Refs["refs/heads/main"])
+		assert.Equal(t, f.Refs["refs/remotes/origin/main"], f.Refs["refs/heads/main"])
+	})
+
+	t.Run("non-fast-forward", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithOrigin(t)
+		theirs := f.NewCommit(f.Refs["refs/heads/main"])
+		f.RemoteRefs["origin"]["refs/heads/main"] = theirs
+		require.NoError(t, f.Commit("ours"))
+
+		err := f.PushCtx(ctx)
+		assert.Equal(t, git.KindOf(err), git.KindNonFastForward)
+		assert.Equal(t, f.RemoteRefs["origin"]["refs/heads/main"], theirs)
+	})
+
+	t.Run("force-with-lease", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithOrigin(t)
+		theirs := f.NewCommit(f.Refs["refs/heads/main"])
+		f.RemoteRefs["origin"]["refs/heads/main"] = theirs
+		require.NoError(t, f.Commit("ours"))
+
+		// the lease is on origin/main, which hasn't seen theirs yet
+		_, _, err := f.RunWrite("push", "--force-with-lease", "origin", "main")
+		assert.Equal(t, git.KindOf(err), git.KindNonFastForward)
+
+		require.NoError(t, f.FetchCtx(ctx, "origin", "main"))
+		_, _, err = f.RunWrite("push", "--force-with-lease", "origin", "main")
+		require.NoError(t, err)
+		assert.Equal(t, f.RemoteRefs["origin"]["refs/heads/main"], f.Refs["refs/heads/main"])
+	})
+
+	t.Run("set upstream", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithOrigin(t)
+		require.NoError(t, f.CheckoutNewBranch("feature", ""))
+		_, _, err := f.RunWrite("push", "-u", "origin", "feature")
+		require.NoError(t, err)
+		up, err := f.GetCurrentBranchUpstream()
+		require.NoError(t, err)
+		assert.Equal(t, up, "origin/feature")
+	})
+
+	t.Run("unknown remote", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithOrigin(t)
+		_, _, err := f.RunWrite("push", "upstream", "main")
+		assert.Equal(t, git.KindOf(err), git.KindRemoteUnreachable)
+	})
+}
+
+func TestFake_FailNext(t *testing.T) {
+	t.Parallel()
+	f := fakeWithOrigin(t)
+	conflict := git.FakeError(git.KindDirtyOverwrite, "error: Your local changes would be overwritten")
+	f.FailNext("checkout", conflict)
+
+	err := f.CheckoutNewBranch("feature", "origin/main")
+	assert.Error(t, err, conflict)
+	assert.Equal(t, f.Head, "main")
+
+	// only the next one
+	require.NoError(t, f.CheckoutNewBranch("feature", "origin/main"))
+	assert.Equal(t, f.Head, "feature")
+	assert.Equal(t, f.Upstreams["feature"], "origin/main")
+}
+
+func TestFake_FailNext_Raw(t *testing.T) {
+	t.Parallel()
+	f := fakeWithOrigin(t)
+	f.FailNext("push", git.FakeError(git.KindRemoteUnreachable, "fatal: unable to access"))
+
+	_, stderr, err := f.RunWriteStream("push", "origin", "main")
+	assert.Equal(t, git.KindOf(err), git.KindRemoteUnreachable)
+	assert.Equal(t, stderr, "fatal: unable to access")
+}
+
+func TestFake_Fetch_UnknownRemote(t *testing.T) {
+	t.Parallel()
+	f := fakeWithOrigin(t)
+	err := f.FetchCtx(context.Background(), "nowhere", "main")
+	assert.Equal(t, git.KindOf(err), git.KindRemoteUnreachable)
+}
+
+func TestFake_Exec(t *testing.T) {
+	t.Parallel()
+	f := git.NewFake()
+
+	_, _, err := f.Run("log", "--oneline")
+	assert.Error(t, err, assert.AnyError, "no Exec should fail unmodelled calls")
+
+	f.Exec = func(args []string) (string, string, error) {
+		return "abc123 init\n", "", nil
+	}
+	var lines []string
+	err = f.RunLines(func(line string) error {
+		lines = append(lines, line)
+		return nil
+	}, "log", "--oneline")
+	require.NoError(t, err)
+	assert.EqualArrays(t, lines, []string{"abc123 init"})
+}
+
+func TestFake_BranchDelete(t *testing.T) {
+	t.Parallel()
+	f := git.NewFake()
+	f.Refs["refs/heads/unmerged"] = f.NewCommit(f.Refs["refs/heads/main"])
+
+	_, _, err := f.RunWrite("branch", "-d", "unmerged")
+	assert.Equal(t, git.KindOf(err), git.KindNotFullyMerged)
+	assert.That(t, f.BranchExists("unmerged"), "-d should keep an unmerged branch")
+
+	_, _, err = f.RunWrite("branch", "-D", "unmerged")
+	require.NoError(t, err)
+	assert.That(t, !f.BranchExists("unmerged"), "-D should delete it")
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+	f := fakeWithOrigin(t)
+	f.FailNext("reset", errors.New("boom"))
+	r := git.NewRecorder(f)
+
+	_, _ = r.GetCurrentBranch()
+	_ = r.ResetHard("origin/main")
+	_ = r.BranchExists("nope")
+
+	assert.EqualArrays(t, r.Methods(), []string{"GetCurrentBranch", "ResetHard", "BranchExists"})
+	calls := r.Calls()
+	assert.EqualArrays(t, calls[1].Args, []string{"origin/main"})
+	assert.Error(t, calls[1].Err, assert.AnyError)
+	assert.Equal(t, calls[2].String(), `BranchExists["nope" "false"]`)
+}
@@ -9,11 +9,16 @@ import (
 // Fetch runs `git fetch <remote> <refspec>` with live progress streamed
 // to the user's terminal. Empty refspec fetches the remote's defaults.
 func (r Repo) Fetch(remote, refspec string) error {
+	return r.FetchCtx(context.Background(), remote, refspec)
+}
+
+// FetchCtx is Fetch with a context.
+func (r Repo) FetchCtx(ctx context.Context, remote, refspec string) error {
 	args := []string{"fetch", remote}
 	if refspec != "" {
 		args = append(args, refspec)
 	}
-	if _, stderr, err := r.runWriteStreaming(context.Background(), args...); err != nil {
+	if _, stderr, err := r.runWriteStreaming(ctx, args...); err != nil {
 		return fmt.Errorf("git fetch: %w: %s", err, strings.TrimSpace(stderr))
 	}
 	return nil
@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -54,7 +55,11 @@ func parseFileStatus(statusCode string) FileStatus {
 // trimmed output (single-line refs, branch names); porcelain consumers
 // that need raw output call runRead directly.
 func (r Repo) run(args ...string) (string, string, error) {
-	stdout, stderr, err := r.runRead(context.Background(), args...)
+	return r.runCtx(context.Background(), args...)
+}
+
+func (r Repo) runCtx(ctx context.Context, args ...string) (string, string, error) {
+	stdout, stderr, err := r.runRead(ctx, args...)
 	return strings.TrimSpace(stdout), strings.TrimSpace(stderr), err
 }
 
@@ -68,8 +73,25 @@ func (r Repo) Run(args ...string) (string, string, error) {
 	return r.run(args...)
 }
 
+// RunCtx is Run with a context.
+func (r Repo) RunCtx(ctx context.Context, args ...string) (string, string, error) {
+	return r.runCtx(ctx, args...)
+}
+
 func Run(args ...string) (string, string, error) { return CWD().Run(args...) }
 
+// RunLines is the streaming counterpart to Run: stdout is handed to fn a
+// line at a time while git is still running. Returning an error from fn
+// stops git early and surfaces that error.
+func (r Repo) RunLines(fn func(line string) error, args ...string) error {
+	return r.runReadLines(context.Background(), fn, args...)
+}
+
+// RunLinesCtx is RunLines with a context.
+func (r Repo) RunLinesCtx(ctx context.Context, fn func(line string) error, args ...string) error {
+	return r.runReadLines(ctx, fn, args...)
+}
+
 // RunWrite is the exported, transitional write entry point. Output is
 // captured (not streamed); callers TrimSpace as needed. Use for write
 // invocations that don't need live progress (e.g. branch -d, reset --hard).
@@ -77,6 +99,11 @@ func (r Repo) RunWrite(args ...string) (string, string, error) {
 	return r.runWrite(context.Background(), args...)
 }
 
+// RunWriteCtx is RunWrite with a context.
+func (r Repo) RunWriteCtx(ctx context.Context, args ...string) (string, string, error) {
+	return r.runWrite(ctx, args...)
+}
+
 func RunWrite(args ...string) (string, string, error) { return CWD().RunWrite(args...) }
 
 // RunWriteStream is the streaming counterpart to RunWrite, for write
@@ -89,13 +116,23 @@ func (r Repo) RunWriteStream(args ...string) (string, string, error) {
 	return r.runWriteStreaming(context.Background(), args...)
 }
 
+// RunWriteStreamCtx is RunWriteStream with a context.
+func (r Repo) RunWriteStreamCtx(ctx context.Context, args ...string) (string, string, error) {
+	return r.runWriteStreaming(ctx, args...)
+}
+
 func RunWriteStream(args ...string) (string, string, error) {
 	return CWD().RunWriteStream(args...)
 }
 
 // GetFileStatus returns the status of a file in git.
 func (r Repo) GetFileStatus(file string) (FileStatus, error) {
-	stdout, _, err := r.runRead(context.Background(), "status", "--porcelain", file)
+	return r.GetFileStatusCtx(context.Background(), file)
+}
+
+// GetFileStatusCtx is GetFileStatus with a context.
+func (r Repo) GetFileStatusCtx(ctx context.Context, file string) (FileStatus, error) {
+	stdout, _, err := r.runRead(ctx, "status", "--porcelain", file)
 	if err != nil || stdout == "" {
 		return FileUnknown, err
 	}
@@ -104,7 +141,12 @@ func (r Repo) GetFileStatus(file string) (FileStatus, error) {
 
 // CheckInRepo verifies we're inside a git repository.
 func (r Repo) CheckInRepo() error {
-	if _, _, err := r.run("rev-parse", "--is-inside-work-tree"); err != nil {
+	return r.CheckInRepoCtx(context.Background())
+}
+
+// CheckInRepoCtx is CheckInRepo with a context.
+func (r Repo) CheckInRepoCtx(ctx context.Context) error {
+	if _, _, err := r.runCtx(ctx, "rev-parse", "--is-inside-work-tree"); err != nil {
 		return fmt.Errorf("not inside a git repository")
 	}
 	return nil
@@ -112,7 +154,12 @@ func (r Repo) CheckInRepo() error {
 
 // GetLocalBranches returns a list of local git branches.
 func (r Repo) GetLocalBranches() ([]string, error) {
-	stdout, _, err := r.run("branch")
+	return r.GetLocalBranchesCtx(context.Background())
+}
+
+// GetLocalBranchesCtx is GetLocalBranches with a context.
+func (r Repo) GetLocalBranchesCtx(ctx context.Context) ([]string, error) {
+	stdout, _, err := r.runCtx(ctx, "branch")
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +177,12 @@ func (r Repo) GetLocalBranches() ([]string, error) {
 
 // GetRemotes returns a list of git remotes.
 func (r Repo) GetRemotes() ([]string, error) {
-	stdout, _, err := r.run("remote")
+	return r.GetRemotesCtx(context.Background())
+}
+
+// GetRemotesCtx is GetRemotes with a context.
+func (r Repo) GetRemotesCtx(ctx context.Context) ([]string, error) {
+	stdout, _, err := r.runCtx(ctx, "remote")
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +191,12 @@ func (r Repo) GetRemotes() ([]string, error) {
 
 // GetRemoteBranches returns branches for a specific remote.
 func (r Repo) GetRemoteBranches(remote string) ([]string, error) {
-	stdout, _, err := r.run("branch", "-r")
+	return r.GetRemoteBranchesCtx(context.Background(), remote)
+}
+
+// GetRemoteBranchesCtx is GetRemoteBranches with a context.
+func (r Repo) GetRemoteBranchesCtx(ctx context.Context, remote string) ([]string, error) {
+	stdout, _, err := r.runCtx(ctx, "branch", "-r")
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +216,12 @@ func (r Repo) GetRemoteBranches(remote string) ([]string, error) {
 // rather than rev-parse @{u} so a "gone" upstream (config still set, remote ref pruned)
 // returns the configured names instead of erroring.
 func (r Repo) GetBranchUpstream(branch string) (remote string, remoteBranch string, err error) {
-	stdout, _, err := r.run("for-each-ref", "--format=%(upstream:short)", "refs/heads/"+branch)
+	return r.GetBranchUpstreamCtx(context.Background(), branch)
+}
+
+// GetBranchUpstreamCtx is GetBranchUpstream with a context.
+func (r Repo) GetBranchUpstreamCtx(ctx context.Context, branch string) (remote string, remoteBranch string, err error) {
+	stdout, _, err := r.runCtx(ctx, "for-each-ref", "--format=%(upstream:short)", "refs/heads/"+branch)
 	if err != nil {
 		return "", "", err
 	}
@@ -175,56 +237,57 @@ func (r Repo) GetBranchUpstream(branch string) (remote string, remoteBranch stri
 
 // GetBranchTrackingRemote returns the remote that a local branch tracks, or "" if none.
 func (r Repo) GetBranchTrackingRemote(branch string) (string, error) {
-	remote, _, err := r.GetBranchUpstream(branch)
-	return remote, err
+	return r.GetBranchTrackingRemoteCtx(context.Background(), branch)
 }
 
-// CheckedOutBranches maps each branch currently checked out in any worktree
-// (including the main worktree) to that worktree's path. Callers use the map
-// for O(1) lookups rather than running a separate subprocess per branch.
-func (r Repo) CheckedOutBranches() (map[string]string, error) {
-	stdout, _, err := r.run("worktree", "list")
-	if err != nil {
-		return nil, err
-	}
-	out := make(map[string]string)
-	for _, line := range strings.Split(stdout, "\n") {
-		// each worktree line is "<path> <sha> [branch]" for named branches;
-		// detached HEADs and bare worktrees use (...) instead of [branch].
-		start := strings.LastIndex(line, "[")
-		end := strings.LastIndex(line, "]")
-		if start != -1 && end > start {
-			fields := strings.Fields(line)
-			if len(fields) > 0 {
-				out[line[start+1:end]] = fields[0]
-			}
-		}
-	}
-	return out, nil
+// GetBranchTrackingRemoteCtx is GetBranchTrackingRemote with a context.
+func (r Repo) GetBranchTrackingRemoteCtx(ctx context.Context, branch string) (string, error) {
+	remote, _, err := r.GetBranchUpstreamCtx(ctx, branch)
+	return remote, err
 }
 
 // GetCommitHash returns the commit hash for a ref.
 func (r Repo) GetCommitHash(ref string) (string, error) {
-	stdout, _, err := r.run("rev-parse", ref)
+	return r.GetCommitHashCtx(context.Background(), ref)
+}
+
+// GetCommitHashCtx is GetCommitHash with a context.
+func (r Repo) GetCommitHashCtx(ctx context.Context, ref string) (string, error) {
+	stdout, _, err := r.runCtx(ctx, "rev-parse", ref)
 	return stdout, err
 }
 
 // BranchExists checks if a local branch exists.
 func (r Repo) BranchExists(branch string) bool {
-	stdout, _, err := r.run("branch", "--list", branch, "--format=%(refname:short)")
+	return r.BranchExistsCtx(context.Background(), branch)
+}
+
+// BranchExistsCtx is BranchExists with a context.
+func (r Repo) BranchExistsCtx(ctx context.Context, branch string) bool {
+	stdout, _, err := r.runCtx(ctx, "branch", "--list", branch, "--format=%(refname:short)")
 	return err == nil && stdout != ""
 }
 
 // GetCurrentBranch returns the name of the current branch.
 func (r Repo) GetCurrentBranch() (string, error) {
-	stdout, _, err := r.run("rev-parse", "--abbrev-ref", "HEAD")
+	return r.GetCurrentBranchCtx(context.Background())
+}
+
+// GetCurrentBranchCtx is GetCurrentBranch with a context.
+func (r Repo) GetCurrentBranchCtx(ctx context.Context) (string, error) {
+	stdout, _, err := r.runCtx(ctx, "rev-parse", "--abbrev-ref", "HEAD")
 	return stdout, err
 }
 
 // GetCurrentBranchUpstream returns the upstream tracking branch for the current branch.
 // Returns ("", nil) if the current branch has no upstream configured.
 func (r Repo) GetCurrentBranchUpstream() (string, error) {
-	stdout, stderr, err := r.run("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	return r.GetCurrentBranchUpstreamCtx(context.Background())
+}
+
+// GetCurrentBranchUpstreamCtx is GetCurrentBranchUpstream with a context.
+func (r Repo) GetCurrentBranchUpstreamCtx(ctx context.Context) (string, error) {
+	stdout, stderr, err := r.runCtx(ctx, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
 	if err != nil && strings.Contains(stderr, "no upstream configured for branch") {
 		return "", nil
 	}
@@ -236,8 +299,18 @@ func (r Repo) GetCurrentBranchUpstream() (string, error) {
 
 // RemoteBranchExists checks if a branch exists on a remote.
 func (r Repo) RemoteBranchExists(remote, branch string) (bool, error) {
-	_, _, err := r.run("ls-remote", "--exit-code", "--heads", remote, branch)
+	return r.RemoteBranchExistsCtx(context.Background(), remote, branch)
+}
+
+// RemoteBranchExistsCtx is RemoteBranchExists with a context.
+func (r Repo) RemoteBranchExistsCtx(ctx context.Context, remote, branch string) (bool, error) {
+	_, _, err := r.runCtx(ctx, "ls-remote", "--exit-code", "--heads", remote, branch)
 	if err != nil {
+		// a remote that timed out or a cancelled call says nothing about
+		// the branch; any other failure reads as "not there"
+		if errors.Is(err, ErrNetworkTimeout) || ctx.Err() != nil {
+			return false, fmt.Errorf("git ls-remote %s: %w", remote, err)
+		}
 		return false, nil
 	}
 	return true, nil
@@ -245,7 +318,12 @@ func (r Repo) RemoteBranchExists(remote, branch string) (bool, error) {
 
 // IsBranchAheadOfRemote reports whether localBranch has commits not in remoteBranch.
 func (r Repo) IsBranchAheadOfRemote(localBranch, remoteBranch string) (bool, error) {
-	stdout, _, err := r.run("log", "--oneline", remoteBranch+".."+localBranch)
+	return r.IsBranchAheadOfRemoteCtx(context.Background(), localBranch, remoteBranch)
+}
+
+// IsBranchAheadOfRemoteCtx is IsBranchAheadOfRemote with a context.
+func (r Repo) IsBranchAheadOfRemoteCtx(ctx context.Context, localBranch, remoteBranch string) (bool, error) {
+	stdout, _, err := r.runCtx(ctx, "log", "--oneline", remoteBranch+".."+localBranch)
 	if err != nil {
 		return false, err
 	}
@@ -260,7 +338,12 @@ func (r Repo) IsBranchAheadOfRemote(localBranch, remoteBranch string) (bool, err
 //
 // Returns an error if none of the above resolve.
 func (r Repo) GetDefaultBranch() (string, error) {
-	remotes, _ := r.GetRemotes()
+	return r.GetDefaultBranchCtx(context.Background())
+}
+
+// GetDefaultBranchCtx is GetDefaultBranch with a context.
+func (r Repo) GetDefaultBranchCtx(ctx context.Context) (string, error) {
+	remotes, _ := r.GetRemotesCtx(ctx)
 	// Prefer origin if listed.
 	ordered := make([]string, 0, len(remotes))
 	for _, name := range remotes {
@@ -271,7 +354,7 @@ func (r Repo) GetDefaultBranch() (string, error) {
 		}
 	}
 	for _, name := range ordered {
-		out, _, err := r.run("symbolic-ref", "--short", "refs/remotes/"+name+"/HEAD")
+		out, _, err := r.runCtx(ctx, "symbolic-ref", "--short", "refs/remotes/"+name+"/HEAD")
 		if err == nil && out != "" {
 			// out is like "origin/main"; strip the remote prefix.
 			if _, branch, ok := strings.Cut(out, "/"); ok {
@@ -280,7 +363,7 @@ func (r Repo) GetDefaultBranch() (string, error) {
 		}
 	}
 	for _, candidate := range []string{"main", "master"} {
-		if r.BranchExists(candidate) {
+		if r.BranchExistsCtx(ctx, candidate) {
 			return candidate, nil
 		}
 	}
@@ -0,0 +1,202 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JournalEntry is one gitgum write operation, recorded with enough state
+// for gg undo to reverse it.
+type JournalEntry struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"` // the gg command: switch, delete, clean, release, undo
+	Summary string    `json:"summary"`
+
+	Refs   []RefUpdate    `json:"refs,omitempty"`
+	Pushed []RemoteUpdate `json:"pushed,omitempty"`
+	// Upstream is a deleted branch's upstream (origin/x), restored with it.
+	Upstream string `json:"upstream,omitempty"`
+	// Stash is a `git stash create` commit holding the tracked changes the
+	// operation discarded. It's referenced from nowhere but here, so git's
+	// usual grace period for unreachable objects (two weeks) applies.
+	Stash string `json:"stash,omitempty"`
+	// Discarded is a patch of the worktree changes the operation threw
+	// away piecemeal (gg stage --reverse), for undo to apply back. A stash
+	// can't do that: the rest of each file's changes are still there.
+	Discarded string `json:"discarded,omitempty"`
+	// Removed lists untracked paths the operation deleted. They were never
+	// in git, so undo can only name them.
+	Removed []string `json:"removed,omitempty"`
+	// Undoes is set on an undo's own entry: the ID it reversed.
+	Undoes string `json:"undoes,omitempty"`
+}
+
+// RefUpdate is a local ref the operation moved. Old is "" for a ref it
+// created, New "" for one it deleted.
+type RefUpdate struct {
+	Name string `json:"name"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// RemoteUpdate is a ref the operation changed on a remote, as a push.
+type RemoteUpdate struct {
+	Remote string `json:"remote"`
+	Name   string `json:"name"` // refs/heads/x on the remote
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// journalFile lives in the common git dir, so all worktrees share one
+// journal -- refs are shared between them too.
+const journalFile = "gitgum/journal"
+
+// JournalPath returns the journal's path: .git/gitgum/journal.
+func (r Repo) JournalPath() (string, error) {
+	return r.JournalPathCtx(context.Background())
+}
+
+// JournalPathCtx is JournalPath with a context.
+func (r Repo) JournalPathCtx(ctx context.Context) (string, error) {
+	dir, _, err := r.runCtx(ctx, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("finding git dir: %w", err)
+	}
+	return filepath.Join(dir, journalFile), nil
+}
+
+// AppendJournal adds e to the journal, one JSON object per line. ID and
+// Time are filled in when empty. Under a DryRun nothing is written, since
+// nothing the entry describes happened.
+func (r Repo) AppendJournal(e JournalEntry) error {
+	return r.AppendJournalCtx(context.Background(), e)
+}
+
+// AppendJournalCtx is AppendJournal with a context.
+func (r Repo) AppendJournalCtx(ctx context.Context, e JournalEntry) error {
+	if activeDryRun.Load() != nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.ID == "" {
+		e.ID = strconv.FormatInt(e.Time.UnixNano(), 36)
+	}
+	path, err := r.JournalPathCtx(ctx)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding journal entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating journal dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening journal: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing journal: %w", err)
+	}
+	return f.Close()
+}
+
+// Journal returns every recorded entry, oldest first. A repo gg never
+// wrote to has none. Lines that don't parse (a write cut short) are
+// skipped.
+func (r Repo) Journal() ([]JournalEntry, error) {
+	return r.JournalCtx(context.Background())
+}
+
+// JournalCtx is Journal with a context.
+func (r Repo) JournalCtx(ctx context.Context) ([]JournalEntry, error) {
+	path, err := r.JournalPathCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading journal: %w", err)
+	}
+	var entries []JournalEntry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var e JournalEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil && e.ID != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// RefOids resolves each full refname to the object it points at, in one
+// for-each-ref. Refs that don't exist are absent from the map.
+func (r Repo) RefOids(names ...string) (map[string]string, error) {
+	return r.RefOidsCtx(context.Background(), names...)
+}
+
+// RefOidsCtx is RefOids with a context.
+func (r Repo) RefOidsCtx(ctx context.Context, names ...string) (map[string]string, error) {
+	oids := make(map[string]string, len(names))
+	if len(names) == 0 {
+		return oids, nil
+	}
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+	args := append([]string{"for-each-ref", "--format=%(refname) %(objectname)"}, names...)
+	stdout, _, err := r.runRead(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading refs: %w", err)
+	}
+	for line := range strings.SplitSeq(strings.TrimSpace(stdout), "\n") {
+		name, oid, ok := strings.Cut(line, " ")
+		// for-each-ref patterns also match refs below the name
+		if ok && want[name] {
+			oids[name] = oid
+		}
+	}
+	return oids, nil
+}
+
+// StashCreate records the tracked changes in the worktree and index as a
+// stash commit without touching either, and returns its oid -- "" when
+// there are no changes. Nothing references the commit; see JournalEntry.
+// Under a DryRun it returns "" without running git: the commit only feeds
+// the journal, which a dry run doesn't write, and isn't one of the writes
+// the command itself makes.
+func (r Repo) StashCreate() (string, error) {
+	return r.StashCreateCtx(context.Background())
+}
+
+// StashCreateCtx is StashCreate with a context.
+func (r Repo) StashCreateCtx(ctx context.Context) (string, error) {
+	if activeDryRun.Load() != nil {
+		return "", nil
+	}
+	stdout, stderr, err := r.runWrite(ctx, "stash", "create")
+	if err != nil {
+		return "", fmt.Errorf("git stash create: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	return strings.TrimSpace(stdout), nil
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func TestJournal_RoundTrip(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	r := Repo{Dir: dir}
+
+	entries, err := r.Journal()
+	require.NoError(t, err)
+	assert.Equal(t, len(entries), 0)
+
+	require.NoError(t, r.AppendJournal(JournalEntry{Op: "switch", Summary: "first",
+		Refs: []RefUpdate{{Name: "refs/heads/main", Old: "aaa", New: "bbb"}}}))
+	require.NoError(t, r.AppendJournal(JournalEntry{Op: "clean", Summary: "second", Removed: []string{"x.txt"}}))
+
+	// a torn write is skipped, not fatal
+	path, err := r.JournalPath()
+	require.NoError(t, err)
+	assert.Equal(t, path, filepath.Join(dir, ".git", "gitgum", "journal"))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"zz","op":"sw`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, err = r.Journal()
+	require.NoError(t, err)
+	require.That(t, len(entries) == 2, "want 2 entries, got %d", len(entries))
+	assert.Equal(t, entries[0].Summary, "first")
+	assert.Equal(t, entries[0].Refs[0], RefUpdate{Name: "refs/heads/main", Old: "aaa", New: "bbb"})
+	assert.Equal(t, entries[1].Summary, "second")
+	assert.EqualArrays(t, entries[1].Removed, []string{"x.txt"})
+	assert.That(t, entries[0].ID != "" && !entries[0].Time.IsZero(), "ID and Time filled in")
+}
+
+func TestRefOids(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	r := Repo{Dir: dir}
+	temp_repo.RunGit(t, dir, "branch", "feature")
+	// a pattern matches refs below it too; only the exact name counts
+	temp_repo.RunGit(t, dir, "branch", "missing/nested")
+	head, err := r.GetCommitHash("HEAD")
+	require.NoError(t, err)
+
+	oids, err := r.RefOids("refs/heads/main", "refs/heads/feature", "refs/heads/missing")
+	require.NoError(t, err)
+	assert.Equal(t, len(oids), 2)
+	assert.Equal(t, oids["refs/heads/main"], head)
+	assert.Equal(t, oids["refs/heads/feature"], head)
+}
+
+func TestStashCreate(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	r := Repo{Dir: dir}
+
+	sha, err := r.StashCreate()
+	require.NoError(t, err)
+	assert.Equal(t, sha, "")
+
+	temp_repo.WriteFile(t, dir, "README.md", "changed\n")
+	sha, err = r.StashCreate()
+	require.NoError(t, err)
+	assert.That(t, sha != "", "stash commit for a dirty tree")
+	// the worktree and stash list are untouched
+	data, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, string(data), "changed\n")
+	assert.Equal(t, temp_repo.RunGit(t, dir, "stash", "list"), "")
+}
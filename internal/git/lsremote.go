@@ -9,7 +9,12 @@ import (
 // but read-shaped (no working-tree mutation), so runs under the read profile
 // for parse-stable output.
 func (r Repo) LsRemote(remote string) (string, error) {
-	stdout, stderr, err := r.runRead(context.Background(), "ls-remote", remote)
+	return r.LsRemoteCtx(context.Background(), remote)
+}
+
+// LsRemoteCtx is LsRemote with a context.
+func (r Repo) LsRemoteCtx(ctx context.Context, remote string) (string, error) {
+	stdout, stderr, err := r.runRead(ctx, "ls-remote", remote)
 	if err != nil {
 		return "", fmt.Errorf("git ls-remote %s: %w: %s", remote, err, stderr)
 	}
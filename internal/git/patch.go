@@ -0,0 +1,70 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// patchFlags pin down everything about `git diff -p` output that the
+// user's diff.* and color.* config could otherwise change, so the patch
+// always parses the same way: a/ and b/ prefixes, no colour or external
+// driver, three lines of context, renames found, submodules as a
+// "Subproject commit" pair.
+var patchFlags = []string{
+	"--patch", "--no-color", "--no-ext-diff", "--no-textconv",
+	"--src-prefix=a/", "--dst-prefix=b/", "--no-relative",
+	"--unified=3", "--inter-hunk-context=0", "--diff-algorithm=myers",
+	"--find-renames", "--submodule=short", "--ignore-submodules=dirty",
+	"-O/dev/null",
+}
+
+// Patch returns `git diff args...` as a unified diff, e.g. no args for
+// the worktree against the index, "--cached" for the index against HEAD,
+// or "A..B". The output is untrimmed: trailing whitespace on the last
+// line is part of the patch.
+func (r Repo) Patch(args ...string) (string, error) {
+	return r.PatchCtx(context.Background(), args...)
+}
+
+// PatchCtx is Patch with a context.
+func (r Repo) PatchCtx(ctx context.Context, args ...string) (string, error) {
+	full := append(append([]string{"diff"}, patchFlags...), args...)
+	stdout, stderr, err := r.runRead(ctx, full...)
+	if err != nil {
+		return "", fmt.Errorf("git diff %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr))
+	}
+	return stdout, nil
+}
+
+// ApplyPatch applies patch, as `git diff` prints it, with `git apply`: to
+// the index alone when cached, else to the worktree. reverse applies it
+// backwards (-R), to take a change the patch describes back out. The
+// patch's paths are from the top of the worktree, as Patch writes them,
+// wherever r points inside it.
+func (r Repo) ApplyPatch(patch string, cached, reverse bool) error {
+	return r.ApplyPatchCtx(context.Background(), patch, cached, reverse)
+}
+
+// ApplyPatchCtx is ApplyPatch with a context.
+func (r Repo) ApplyPatchCtx(ctx context.Context, patch string, cached, reverse bool) error {
+	root, _, err := r.runCtx(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	args := []string{"apply"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	if reverse {
+		args = append(args, "-R")
+	}
+	args = append(args, "-")
+	// run from the top: from a subdirectory, git apply skips every path
+	// outside it and still exits 0
+	_, stderr, err := Repo{Dir: root}.runWriteInput(ctx, patch, args...)
+	if err != nil {
+		return fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr))
+	}
+	return nil
+}
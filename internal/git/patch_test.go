@@ -0,0 +1,68 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func TestPatch_IgnoresDiffConfig(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	for _, kv := range [][2]string{
+		{"diff.noprefix", "true"},
+		{"diff.mnemonicPrefix", "true"},
+		{"diff.context", "0"},
+		{"diff.external", "false"},
+		{"diff.relative", "true"},
+		{"color.diff", "always"},
+	} {
+		temp_repo.RunGit(t, dir, "config", kv[0], kv[1])
+	}
+	temp_repo.CreateCommit(t, dir, "a.txt", "one\ntwo\nthree\n", "chore: add a")
+	temp_repo.WriteFile(t, dir, "a.txt", "one\n2\nthree\n")
+
+	out, err := Repo{Dir: dir}.Patch()
+	require.NoError(t, err)
+	assert.That(t, strings.HasPrefix(out, "diff --git a/a.txt b/a.txt\n"), "prefixes pinned, got:\n", out)
+	assert.ContainsString(t, out, "@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n")
+	assert.That(t, !strings.Contains(out, "\x1b["), "no colour")
+
+	out, err = Repo{Dir: dir}.Patch("--cached")
+	require.NoError(t, err)
+	assert.Equal(t, out, "")
+}
+
+func TestApplyPatch(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "one\ntwo\n", "chore: add a")
+	temp_repo.WriteFile(t, dir, "a.txt", "one\n2\n")
+	r := Repo{Dir: dir}
+	p, err := r.Patch()
+	require.NoError(t, err)
+
+	require.NoError(t, r.ApplyPatch(p, true, false))
+	staged, err := r.Patch("--cached")
+	require.NoError(t, err)
+	assert.Equal(t, staged, p)
+	unstaged, err := r.Patch()
+	require.NoError(t, err)
+	assert.Equal(t, unstaged, "", "the worktree is left alone")
+
+	require.NoError(t, r.ApplyPatch(staged, true, true))
+	staged, err = r.Patch("--cached")
+	require.NoError(t, err)
+	assert.Equal(t, staged, "")
+
+	require.NoError(t, r.ApplyPatch(p, false, true))
+	unstaged, err = r.Patch()
+	require.NoError(t, err)
+	assert.Equal(t, unstaged, "", "the worktree change is discarded")
+
+	err = r.ApplyPatch(p, false, true)
+	assert.That(t, err != nil && strings.Contains(err.Error(), "git apply -R -"), "a patch that doesn't apply fails, got ", err)
+}
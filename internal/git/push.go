@@ -11,7 +11,12 @@ import (
 // GIT_TERMINAL_PROMPT=0 is set in the env, so missing creds fail fast
 // rather than hang on a tty prompt (cred helpers still work).
 func (r Repo) Push() error {
-	if _, stderr, err := r.runWriteStreaming(context.Background(), "push"); err != nil {
+	return r.PushCtx(context.Background())
+}
+
+// PushCtx is Push with a context.
+func (r Repo) PushCtx(ctx context.Context) error {
+	if _, stderr, err := r.runWriteStreaming(ctx, "push"); err != nil {
 		return fmt.Errorf("git push: %w: %s", err, strings.TrimSpace(stderr))
 	}
 	return nil
@@ -0,0 +1,336 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// Recorder is a Backend that logs every call to the Backend it wraps,
+// with its arguments and error, then passes it through. Tests use it to
+// assert what a command did -- or didn't -- ask git for.
+type Recorder struct {
+	b     Backend
+	mu    sync.Mutex
+	calls []BackendCall
+}
+
+// BackendCall is one call a Recorder saw.
+type BackendCall struct {
+	Method string
+	Args   []string
+	Err    error
+}
+
+func (c BackendCall) String() string {
+	return fmt.Sprintf("%s%q", c.Method, c.Args)
+}
+
+var _ Backend = (*Recorder)(nil)
+
+// NewRecorder wraps b.
+func NewRecorder(b Backend) *Recorder {
+	return &Recorder{b: b}
+}
+
+// Calls returns the calls so far, oldest first.
+func (r *Recorder) Calls() []BackendCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
+
+// Methods returns just the method names of Calls.
+func (r *Recorder) Methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]string, len(r.calls))
+	for i, c := range r.calls {
+		out[i] = c.Method
+	}
+	return out
+}
+
+func (r *Recorder) record(method string, err error, args ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, BackendCall{Method: method, Args: args, Err: err})
+}
+
+func (r *Recorder) CheckInRepo() error {
+	err := r.b.CheckInRepo()
+	r.record("CheckInRepo", err)
+	return err
+}
+
+func (r *Recorder) GetCurrentBranch() (string, error) {
+	out, err := r.b.GetCurrentBranch()
+	r.record("GetCurrentBranch", err)
+	return out, err
+}
+
+func (r *Recorder) GetCurrentBranchUpstream() (string, error) {
+	out, err := r.b.GetCurrentBranchUpstream()
+	r.record("GetCurrentBranchUpstream", err)
+	return out, err
+}
+
+func (r *Recorder) GetBranchUpstream(branch string) (string, string, error) {
+	remote, rb, err := r.b.GetBranchUpstream(branch)
+	r.record("GetBranchUpstream", err, branch)
+	return remote, rb, err
+}
+
+func (r *Recorder) GetBranchTrackingRemote(branch string) (string, error) {
+	out, err := r.b.GetBranchTrackingRemote(branch)
+	r.record("GetBranchTrackingRemote", err, branch)
+	return out, err
+}
+
+func (r *Recorder) GetCommitHash(ref string) (string, error) {
+	out, err := r.b.GetCommitHash(ref)
+	r.record("GetCommitHash", err, ref)
+	return out, err
+}
+
+func (r *Recorder) GetDefaultBranch() (string, error) {
+	out, err := r.b.GetDefaultBranch()
+	r.record("GetDefaultBranch", err)
+	return out, err
+}
+
+func (r *Recorder) GetLocalBranches() ([]string, error) {
+	out, err := r.b.GetLocalBranches()
+	r.record("GetLocalBranches", err)
+	return out, err
+}
+
+func (r *Recorder) GetRemotes() ([]string, error) {
+	out, err := r.b.GetRemotes()
+	r.record("GetRemotes", err)
+	return out, err
+}
+
+func (r *Recorder) BranchExists(branch string) bool {
+	ok := r.b.BranchExists(branch)
+	r.record("BranchExists", nil, branch, strconv.FormatBool(ok))
+	return ok
+}
+
+func (r *Recorder) TagExists(name string) bool {
+	ok := r.b.TagExists(name)
+	r.record("TagExists", nil, name, strconv.FormatBool(ok))
+	return ok
+}
+
+func (r *Recorder) IsBranchAheadOfRemote(localBranch, remoteBranch string) (bool, error) {
+	ok, err := r.b.IsBranchAheadOfRemote(localBranch, remoteBranch)
+	r.record("IsBranchAheadOfRemote", err, localBranch, remoteBranch)
+	return ok, err
+}
+
+func (r *Recorder) RefOids(names ...string) (map[string]string, error) {
+	out, err := r.b.RefOids(names...)
+	r.record("RefOids", err, names...)
+	return out, err
+}
+
+func (r *Recorder) RefsWithUpstreamsCtx(ctx context.Context) ([]Ref, error) {
+	out, err := r.b.RefsWithUpstreamsCtx(ctx)
+	r.record("RefsWithUpstreams", err)
+	return out, err
+}
+
+func (r *Recorder) CommitInfo(revs ...string) ([]CommitInfo, error) {
+	out, err := r.b.CommitInfo(revs...)
+	r.record("CommitInfo", err, revs...)
+	return out, err
+}
+
+func (r *Recorder) Status() (Status, error) {
+	out, err := r.b.Status()
+	r.record("Status", err)
+	return out, err
+}
+
+func (r *Recorder) StatusWith(opts StatusOptions) (Status, error) {
+	out, err := r.b.StatusWith(opts)
+	r.record("StatusWith", err, fmt.Sprintf("%+v", opts))
+	return out, err
+}
+
+func (r *Recorder) DirtyTrackedLines() ([]string, error) {
+	out, err := r.b.DirtyTrackedLines()
+	r.record("DirtyTrackedLines", err)
+	return out, err
+}
+
+func (r *Recorder) Submodules() ([]Submodule, error) {
+	out, err := r.b.Submodules()
+	r.record("Submodules", err)
+	return out, err
+}
+
+func (r *Recorder) SubmoduleDiff(args ...string) ([]SubmoduleChange, error) {
+	out, err := r.b.SubmoduleDiff(args...)
+	r.record("SubmoduleDiff", err, args...)
+	return out, err
+}
+
+func (r *Recorder) Patch(args ...string) (string, error) {
+	out, err := r.b.Patch(args...)
+	r.record("Patch", err, args...)
+	return out, err
+}
+
+func (r *Recorder) Add(paths ...string) error {
+	err := r.b.Add(paths...)
+	r.record("Add", err, paths...)
+	return err
+}
+
+func (r *Recorder) ApplyPatch(patch string, cached, reverse bool) error {
+	err := r.b.ApplyPatch(patch, cached, reverse)
+	r.record("ApplyPatch", err, strconv.FormatBool(cached), strconv.FormatBool(reverse))
+	return err
+}
+
+func (r *Recorder) Checkout(branch string) error {
+	err := r.b.Checkout(branch)
+	r.record("Checkout", err, branch)
+	return err
+}
+
+func (r *Recorder) CheckoutNewBranch(branch, startPoint string) error {
+	err := r.b.CheckoutNewBranch(branch, startPoint)
+	r.record("CheckoutNewBranch", err, branch, startPoint)
+	return err
+}
+
+func (r *Recorder) ResetHard(ref string) error {
+	err := r.b.ResetHard(ref)
+	r.record("ResetHard", err, ref)
+	return err
+}
+
+func (r *Recorder) Commit(message string) error {
+	err := r.b.Commit(message)
+	r.record("Commit", err, message)
+	return err
+}
+
+func (r *Recorder) CommitEmpty(message string) error {
+	err := r.b.CommitEmpty(message)
+	r.record("CommitEmpty", err, message)
+	return err
+}
+
+func (r *Recorder) TagAnnotated(name, message string) error {
+	err := r.b.TagAnnotated(name, message)
+	r.record("TagAnnotated", err, name, message)
+	return err
+}
+
+func (r *Recorder) StashPush(message string) error {
+	err := r.b.StashPush(message)
+	r.record("StashPush", err, message)
+	return err
+}
+
+func (r *Recorder) StashPopIndex() error {
+	err := r.b.StashPopIndex()
+	r.record("StashPopIndex", err)
+	return err
+}
+
+func (r *Recorder) StashCreate() (string, error) {
+	out, err := r.b.StashCreate()
+	r.record("StashCreate", err)
+	return out, err
+}
+
+func (r *Recorder) Worktrees() ([]Worktree, error) {
+	out, err := r.b.Worktrees()
+	r.record("Worktrees", err)
+	return out, err
+}
+
+func (r *Recorder) PruneWorktrees() error {
+	err := r.b.PruneWorktrees()
+	r.record("PruneWorktrees", err)
+	return err
+}
+
+func (r *Recorder) SubmoduleUpdateCtx(ctx context.Context, paths ...string) error {
+	err := r.b.SubmoduleUpdateCtx(ctx, paths...)
+	r.record("SubmoduleUpdate", err, paths...)
+	return err
+}
+
+func (r *Recorder) FetchCtx(ctx context.Context, remote, refspec string) error {
+	err := r.b.FetchCtx(ctx, remote, refspec)
+	r.record("Fetch", err, remote, refspec)
+	return err
+}
+
+func (r *Recorder) PushCtx(ctx context.Context) error {
+	err := r.b.PushCtx(ctx)
+	r.record("Push", err)
+	return err
+}
+
+func (r *Recorder) LsRemoteCtx(ctx context.Context, remote string) (string, error) {
+	out, err := r.b.LsRemoteCtx(ctx, remote)
+	r.record("LsRemote", err, remote)
+	return out, err
+}
+
+func (r *Recorder) RemoteBranchExistsCtx(ctx context.Context, remote, branch string) (bool, error) {
+	ok, err := r.b.RemoteBranchExistsCtx(ctx, remote, branch)
+	r.record("RemoteBranchExists", err, remote, branch)
+	return ok, err
+}
+
+func (r *Recorder) Journal() ([]JournalEntry, error) {
+	out, err := r.b.Journal()
+	r.record("Journal", err)
+	return out, err
+}
+
+func (r *Recorder) AppendJournal(e JournalEntry) error {
+	err := r.b.AppendJournal(e)
+	r.record("AppendJournal", err, e.Op, e.Summary)
+	return err
+}
+
+func (r *Recorder) Run(args ...string) (string, string, error) {
+	stdout, stderr, err := r.b.Run(args...)
+	r.record("Run", err, args...)
+	return stdout, stderr, err
+}
+
+func (r *Recorder) RunLines(fn func(line string) error, args ...string) error {
+	err := r.b.RunLines(fn, args...)
+	r.record("RunLines", err, args...)
+	return err
+}
+
+func (r *Recorder) RunWrite(args ...string) (string, string, error) {
+	stdout, stderr, err := r.b.RunWrite(args...)
+	r.record("RunWrite", err, args...)
+	return stdout, stderr, err
+}
+
+func (r *Recorder) RunWriteStream(args ...string) (string, string, error) {
+	stdout, stderr, err := r.b.RunWriteStream(args...)
+	r.record("RunWriteStream", err, args...)
+	return stdout, stderr, err
+}
+
+func (r *Recorder) RunWriteStreamCtx(ctx context.Context, args ...string) (string, string, error) {
+	stdout, stderr, err := r.b.RunWriteStreamCtx(ctx, args...)
+	r.record("RunWriteStream", err, args...)
+	return stdout, stderr, err
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // minGitVersion is the lowest git release gg will run against.
@@ -119,18 +121,47 @@ func (r Repo) runRead(ctx context.Context, args ...string) (string, string, erro
 	if err := ensureMinVersion(ctx); err != nil {
 		return "", "", err
 	}
+	ctx, finish := networkCtx(ctx, args)
 	full := buildArgs(r.Dir, readPrelude, args, true)
-	return runCaptured(ctx, full, readEnv())
+	start := time.Now()
+	stdout, stderr, err := runCaptured(ctx, full, readEnv(), nil)
+	traceCall("read", r.Dir, full, args, start, stderr, err)
+	return stdout, stderr, finish(newError(args, stdout, stderr, err))
 }
 
 // runWrite executes a write git invocation. User identity, signing, and
-// hooks are preserved.
+// hooks are preserved. Under a DryRun it only records the argv.
 func (r Repo) runWrite(ctx context.Context, args ...string) (string, string, error) {
 	if err := ensureMinVersion(ctx); err != nil {
 		return "", "", err
 	}
 	full := buildArgs(r.Dir, writePrelude, args, false)
-	return runCaptured(ctx, full, writeEnv())
+	if dryRunWrite(full) {
+		return "", "", nil
+	}
+	ctx, finish := networkCtx(ctx, args)
+	start := time.Now()
+	stdout, stderr, err := runCaptured(ctx, full, writeEnv(), nil)
+	traceCall("write", r.Dir, full, args, start, stderr, err)
+	return stdout, stderr, finish(newError(args, stdout, stderr, err))
+}
+
+// runWriteInput is runWrite with input fed to git's stdin, for the
+// commands that read what to write from it (`git apply -`). A dry run
+// records the input too, piped in.
+func (r Repo) runWriteInput(ctx context.Context, input string, args ...string) (string, string, error) {
+	if err := ensureMinVersion(ctx); err != nil {
+		return "", "", err
+	}
+	full := buildArgs(r.Dir, writePrelude, args, false)
+	if dryRunWriteInput(full, input) {
+		return "", "", nil
+	}
+	ctx, finish := networkCtx(ctx, args)
+	start := time.Now()
+	stdout, stderr, err := runCaptured(ctx, full, writeEnv(), strings.NewReader(input))
+	traceCall("write", r.Dir, full, args, start, stderr, err)
+	return stdout, stderr, finish(newError(args, stdout, stderr, err))
 }
 
 // runWriteStreaming executes a write that needs live stderr passthrough --
@@ -144,7 +175,74 @@ func (r Repo) runWriteStreaming(ctx context.Context, args ...string) (string, st
 		return "", "", err
 	}
 	full := buildArgs(r.Dir, writePrelude, args, false)
-	return runStreaming(ctx, full, writeEnv())
+	if dryRunWrite(full) {
+		return "", "", nil
+	}
+	ctx, finish := networkCtx(ctx, args)
+	start := time.Now()
+	stdout, stderr, err := runStreaming(ctx, full, writeEnv())
+	traceCall("stream", r.Dir, full, args, start, stderr, err)
+	return stdout, stderr, finish(newError(args, stdout, stderr, err))
+}
+
+// runReadLines executes a read-only git invocation and hands stdout to fn
+// one line at a time (newline stripped) as git produces it, instead of
+// buffering the lot -- for `git log` over whole histories, where the
+// caller wants to start work before git finishes. A non-nil error from fn
+// stops the read, kills git, and is returned as-is. Stderr is kept as a
+// bounded tail and folded into the error if git itself fails.
+func (r Repo) runReadLines(ctx context.Context, fn func(line string) error, args ...string) error {
+	if err := ensureMinVersion(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	full := buildArgs(r.Dir, readPrelude, args, true)
+	cmd := exec.CommandContext(ctx, "git", full...)
+	cmd.Env = readEnv()
+	cmd.WaitDelay = waitDelay
+	errTail := &tailBuffer{n: tailBufSize}
+	cmd.Stderr = errTail
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // long subjects are fine, binary blobs aren't expected
+	var fnErr error
+	for sc.Scan() {
+		if fnErr = fn(sc.Text()); fnErr != nil {
+			break
+		}
+	}
+	scanErr := sc.Err()
+	if fnErr != nil || scanErr != nil {
+		// stop git before Wait, otherwise it blocks writing into a pipe
+		// nobody reads anymore
+		cancel()
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	waitErr := cmd.Wait()
+	traceCall("lines", r.Dir, full, args, start, errTail.String(), waitErr)
+	switch {
+	case fnErr != nil:
+		return fnErr
+	case scanErr != nil:
+		return fmt.Errorf("reading git output: %w", scanErr)
+	case waitErr != nil:
+		waitErr = newError(args, "", errTail.String(), waitErr)
+		if msg := strings.TrimSpace(errTail.String()); msg != "" {
+			return fmt.Errorf("%w: %s", waitErr, msg)
+		}
+		return waitErr
+	}
+	return nil
 }
 
 // buildArgs prepends the prelude and (if dir is non-empty and absolute)
@@ -164,9 +262,18 @@ func buildArgs(dir string, prelude, args []string, strictConfig bool) []string {
 	return full
 }
 
-func runCaptured(ctx context.Context, args, env []string) (string, string, error) {
+// waitDelay bounds how long Wait blocks after ctx kills git. A killed git
+// can leave children behind (ssh, a remote's upload-pack) still holding
+// the output pipes open; without the bound, Wait would sit on them until
+// they exit, which for a hung remote is never.
+const waitDelay = time.Second
+
+// runCaptured runs git with its output captured. stdin is nil for none.
+func runCaptured(ctx context.Context, args, env []string, stdin io.Reader) (string, string, error) {
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Env = env
+	cmd.Stdin = stdin
+	cmd.WaitDelay = waitDelay
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -206,6 +313,7 @@ func (t *tailBuffer) String() string { return string(t.buf) }
 func runStreaming(ctx context.Context, args, env []string) (string, string, error) {
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Env = env
+	cmd.WaitDelay = waitDelay
 	outTail := &tailBuffer{n: tailBufSize}
 	errTail := &tailBuffer{n: tailBufSize}
 	cmd.Stdout = io.MultiWriter(os.Stdout, outTail)
@@ -217,6 +325,7 @@ func runStreaming(ctx context.Context, args, env []string) (string, string, erro
 var (
 	versionOnce sync.Once
 	versionErr  error
+	versionSeen [3]int // what checkVersion parsed; zero until then
 )
 
 // ensureMinVersion checks `git --version` once per process and caches the
@@ -240,6 +349,7 @@ func checkVersion(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	versionSeen = v
 	if compareVersion(v, minGitVersion) < 0 {
 		return fmt.Errorf("git %d.%d.%d is below gg's minimum %d.%d.%d -- please upgrade",
 			v[0], v[1], v[2],
@@ -248,6 +358,10 @@ func checkVersion(ctx context.Context) error {
 	return nil
 }
 
+// detectedVersion is the running git's version, or zero before the first
+// ensureMinVersion. The phrase table in errors.go filters on it.
+func detectedVersion() [3]int { return versionSeen }
+
 // parseGitVersion extracts the X.Y.Z triple from `git --version` output,
 // e.g. "git version 2.43.0\n" -> [2, 43, 0]. Trailing build/commit suffixes
 // (".windows.1", ".gk.something") are tolerated; missing patch defaults to 0.
@@ -289,4 +403,5 @@ func compareVersion(a, b [3]int) int {
 func resetVersionCheck() {
 	versionOnce = sync.Once{}
 	versionErr = nil
+	versionSeen = [3]int{}
 }
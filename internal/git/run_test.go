@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -134,3 +135,35 @@ func TestRunReadContextCancel(t *testing.T) {
 		t.Fatal("runRead did not return after ctx cancel")
 	}
 }
+
+func TestRunReadLines(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	for _, msg := range []string{"one", "two", "three"} {
+		temp_repo.CreateCommit(t, dir, msg+".txt", msg, msg)
+	}
+	r := Repo{Dir: dir}
+
+	var got []string
+	err := r.runReadLines(context.Background(), func(line string) error {
+		got = append(got, line)
+		return nil
+	}, "log", "--format=%s")
+	require.NoError(t, err)
+	assert.EqualArrays(t, got[:3], []string{"three", "two", "one"})
+
+	// fn errors stop the read and come back unwrapped
+	stop := errors.New("stop")
+	n := 0
+	err = r.runReadLines(context.Background(), func(string) error {
+		n++
+		return stop
+	}, "log", "--format=%s")
+	assert.That(t, errors.Is(err, stop), "want fn error back, got ", err)
+	assert.Equal(t, n, 1)
+
+	// git failures carry the stderr tail
+	err = r.runReadLines(context.Background(), func(string) error { return nil }, "log", "no-such-ref")
+	assert.That(t, err != nil, "want error for unknown ref")
+	assert.ContainsString(t, err.Error(), "no-such-ref")
+}
@@ -0,0 +1,288 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Status is a parsed `git status --porcelain=v2 --branch --show-stash`.
+type Status struct {
+	Branch  BranchStatus
+	Stash   int // number of stash entries
+	Entries []StatusEntry
+}
+
+// BranchStatus is the porcelain v2 "# branch.*" header.
+type BranchStatus struct {
+	Oid      string // "" on an unborn branch
+	Head     string // "" when detached
+	Upstream string // short upstream name, e.g. origin/main; "" if none
+	Ahead    int
+	Behind   int
+	Gone     bool // upstream configured but its remote-tracking ref is gone
+}
+
+// EntryKind is the porcelain v2 record type of a StatusEntry.
+type EntryKind int
+
+const (
+	EntryOrdinary  EntryKind = iota // "1": changed tracked path
+	EntryRenamed                    // "2": renamed or copied
+	EntryUnmerged                   // "u": conflicted
+	EntryUntracked                  // "?"
+	EntryIgnored                    // "!"
+)
+
+// StatusEntry is one changed path. X and Y are the staged and worktree
+// status letters, '.' when that side is unchanged ('?' / '!' for untracked
+// and ignored entries). Modes and hashes are only set for tracked kinds.
+type StatusEntry struct {
+	Kind EntryKind
+	X, Y byte
+	Path string
+	Orig string // EntryRenamed: the path it was renamed or copied from
+	// EntryRenamed: 'R' or 'C' and the similarity percentage
+	RenameOp byte
+	Score    int
+	// "N..." for a non-submodule, else "S<c><m><u>": commit changed,
+	// tracked changes, untracked changes
+	Sub string
+
+	ModeHead, ModeIndex, ModeWorktree string
+	HashHead, HashIndex               string
+
+	// EntryUnmerged: stages 1 (base), 2 (ours), 3 (theirs). A side that
+	// doesn't have the path has mode 000000.
+	Stages [3]StatusStage
+}
+
+// StatusStage is one index stage of an unmerged path.
+type StatusStage struct {
+	Mode, Hash string
+}
+
+// Code returns the two-letter short-format code, as `status --short`
+// prints it: '.' becomes a space, untracked is "??", ignored "!!".
+func (e StatusEntry) Code() string {
+	x, y := e.X, e.Y
+	if x == '.' {
+		x = ' '
+	}
+	if y == '.' {
+		y = ' '
+	}
+	return string([]byte{x, y})
+}
+
+// Staged reports whether the entry has changes in the index.
+func (e StatusEntry) Staged() bool {
+	return e.Kind == EntryUnmerged || (e.Tracked() && e.X != '.')
+}
+
+// Unstaged reports whether the entry has worktree changes not in the index.
+func (e StatusEntry) Unstaged() bool {
+	return e.Kind == EntryUnmerged || (e.Tracked() && e.Y != '.')
+}
+
+// Tracked reports whether the path is in the index or HEAD.
+func (e StatusEntry) Tracked() bool {
+	return e.Kind == EntryOrdinary || e.Kind == EntryRenamed || e.Kind == EntryUnmerged
+}
+
+// IsSubmodule reports whether the path is a submodule.
+func (e StatusEntry) IsSubmodule() bool { return strings.HasPrefix(e.Sub, "S") }
+
+// StatusOptions tunes which untracked and ignored paths Status lists.
+type StatusOptions struct {
+	// UntrackedAll lists every file inside an untracked directory instead
+	// of the directory itself (-uall).
+	UntrackedAll bool
+	// Ignored adds EntryIgnored entries. Wholly ignored directories are
+	// listed as the directory, with a trailing slash.
+	Ignored bool
+	// Paths limits the entries to these pathspecs, relative to the
+	// working directory like any git pathspec. The branch header and
+	// stash count are the whole repo's regardless.
+	Paths []string
+}
+
+// Status reads the working tree status: branch header, stash count, and
+// one entry per changed, untracked or unmerged path. Untracked directories
+// are collapsed to "dir/" and ignored paths are left out; see StatusWith.
+func (r Repo) Status() (Status, error) {
+	return r.StatusWith(StatusOptions{})
+}
+
+// StatusCtx is Status with a context.
+func (r Repo) StatusCtx(ctx context.Context) (Status, error) {
+	return r.StatusWithCtx(ctx, StatusOptions{})
+}
+
+// StatusWith is Status with control over untracked and ignored listing.
+func (r Repo) StatusWith(opts StatusOptions) (Status, error) {
+	return r.StatusWithCtx(context.Background(), opts)
+}
+
+// StatusWithCtx is StatusWith with a context.
+func (r Repo) StatusWithCtx(ctx context.Context, opts StatusOptions) (Status, error) {
+	args := []string{"status", "--porcelain=v2", "-z", "--branch", "--show-stash"}
+	if opts.UntrackedAll {
+		args = append(args, "--untracked-files=all")
+	} else {
+		args = append(args, "--untracked-files=normal")
+	}
+	if opts.Ignored {
+		args = append(args, "--ignored")
+	}
+	if len(opts.Paths) > 0 {
+		args = append(append(args, "--"), opts.Paths...)
+	}
+	stdout, stderr, err := r.runRead(ctx, args...)
+	if err != nil {
+		return Status{}, fmt.Errorf("git status: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	return parseStatus(stdout)
+}
+
+// parseStatus parses NUL-terminated porcelain v2 records. A rename record
+// is followed by one extra NUL-terminated field holding the origin path.
+func parseStatus(out string) (Status, error) {
+	var st parsedStatus
+	records := strings.Split(out, "\x00")
+	if records[len(records)-1] == "" {
+		records = records[:len(records)-1]
+	}
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		if rec == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(rec, "# "); ok {
+			if err := parseStatusHeader(&st, rest); err != nil {
+				return Status{}, err
+			}
+			continue
+		}
+		e, err := parseStatusEntry(rec)
+		if err != nil {
+			return Status{}, err
+		}
+		if e.Kind == EntryRenamed {
+			i++
+			if i >= len(records) {
+				return Status{}, fmt.Errorf("status: rename record without origin path: %q", rec)
+			}
+			e.Orig = records[i]
+		}
+		st.Entries = append(st.Entries, e)
+	}
+	// an upstream with no branch.ab line is one whose ref has gone away
+	if st.Branch.Upstream != "" && !st.hasAB {
+		st.Branch.Gone = true
+	}
+	return st.Status, nil
+}
+
+// parsedStatus is a Status plus parse-time state.
+type parsedStatus struct {
+	Status
+	hasAB bool
+}
+
+func parseStatusHeader(st *parsedStatus, line string) error {
+	key, val, _ := strings.Cut(line, " ")
+	switch key {
+	case "branch.oid":
+		if val != "(initial)" {
+			st.Branch.Oid = val
+		}
+	case "branch.head":
+		if val != "(detached)" {
+			st.Branch.Head = val
+		}
+	case "branch.upstream":
+		st.Branch.Upstream = val
+	case "branch.ab":
+		a, b, _ := strings.Cut(val, " ")
+		ahead, err1 := strconv.Atoi(strings.TrimPrefix(a, "+"))
+		behind, err2 := strconv.Atoi(strings.TrimPrefix(b, "-"))
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("status: malformed branch.ab %q", val)
+		}
+		st.Branch.Ahead, st.Branch.Behind = ahead, behind
+		st.hasAB = true
+	case "stash":
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("status: malformed stash count %q", val)
+		}
+		st.Stash = n
+	}
+	// unknown headers are skipped: git may add more
+	return nil
+}
+
+// parseStatusEntry parses one non-header record. Paths come last and may
+// contain spaces, so fields are split with a fixed count.
+func parseStatusEntry(rec string) (StatusEntry, error) {
+	bad := func() (StatusEntry, error) {
+		return StatusEntry{}, fmt.Errorf("status: malformed record %q", rec)
+	}
+	if len(rec) < 3 || rec[1] != ' ' {
+		return bad()
+	}
+	switch rec[0] {
+	case '?':
+		return StatusEntry{Kind: EntryUntracked, X: '?', Y: '?', Path: rec[2:]}, nil
+	case '!':
+		return StatusEntry{Kind: EntryIgnored, X: '!', Y: '!', Path: rec[2:]}, nil
+	case '1':
+		// 1 XY sub mH mI mW hH hI path
+		f := strings.SplitN(rec, " ", 9)
+		if len(f) != 9 || len(f[1]) != 2 {
+			return bad()
+		}
+		return StatusEntry{
+			Kind: EntryOrdinary, X: f[1][0], Y: f[1][1], Sub: f[2],
+			ModeHead: f[3], ModeIndex: f[4], ModeWorktree: f[5],
+			HashHead: f[6], HashIndex: f[7],
+			Path: f[8],
+		}, nil
+	case '2':
+		// 2 XY sub mH mI mW hH hI Xscore path
+		f := strings.SplitN(rec, " ", 10)
+		if len(f) != 10 || len(f[1]) != 2 || len(f[8]) < 2 {
+			return bad()
+		}
+		score, err := strconv.Atoi(f[8][1:])
+		if err != nil {
+			return bad()
+		}
+		return StatusEntry{
+			Kind: EntryRenamed, X: f[1][0], Y: f[1][1], Sub: f[2],
+			ModeHead: f[3], ModeIndex: f[4], ModeWorktree: f[5],
+			HashHead: f[6], HashIndex: f[7],
+			RenameOp: f[8][0], Score: score,
+			Path: f[9],
+		}, nil
+	case 'u':
+		// u XY sub m1 m2 m3 mW h1 h2 h3 path
+		f := strings.SplitN(rec, " ", 11)
+		if len(f) != 11 || len(f[1]) != 2 {
+			return bad()
+		}
+		return StatusEntry{
+			Kind: EntryUnmerged, X: f[1][0], Y: f[1][1], Sub: f[2],
+			ModeWorktree: f[6],
+			Stages: [3]StatusStage{
+				{Mode: f[3], Hash: f[7]},
+				{Mode: f[4], Hash: f[8]},
+				{Mode: f[5], Hash: f[9]},
+			},
+			Path: f[10],
+		}, nil
+	}
+	return bad()
+}
@@ -0,0 +1,190 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+	const h1, h2, h3 = "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222", "3333333333333333333333333333333333333333"
+	out := strings.Join([]string{
+		"# branch.oid " + h1,
+		"# branch.head feature",
+		"# branch.upstream origin/feature",
+		"# branch.ab +2 -3",
+		"# stash 4",
+		"# branch.future ignored",
+		"1 .M N... 100644 100644 100644 " + h1 + " " + h1 + " with space.txt",
+		"1 A. N... 000000 100644 100644 " + h1 + " " + h2 + " new.txt",
+		"2 RM N... 100644 100644 100644 " + h1 + " " + h1 + " R87 to.txt", "from.txt",
+		"1 .M SC.. 160000 160000 160000 " + h1 + " " + h1 + " sub",
+		"u UU N... 100644 100644 100644 100644 " + h1 + " " + h2 + " " + h3 + " both.txt",
+		"u DU N... 100644 000000 100644 100644 " + h1 + " " + h2 + " " + h3 + " gone.txt",
+		"? dir/",
+		"! build/",
+		"",
+	}, "\x00")
+
+	st, err := parseStatus(out)
+	require.NoError(t, err)
+	assert.Equal(t, st.Branch, BranchStatus{Oid: h1, Head: "feature", Upstream: "origin/feature", Ahead: 2, Behind: 3})
+	assert.Equal(t, st.Stash, 4)
+	require.That(t, len(st.Entries) == 8, "got %d entries", len(st.Entries))
+
+	cases := []struct {
+		kind       EntryKind
+		code, path string
+	}{
+		{EntryOrdinary, " M", "with space.txt"},
+		{EntryOrdinary, "A ", "new.txt"},
+		{EntryRenamed, "RM", "to.txt"},
+		{EntryOrdinary, " M", "sub"},
+		{EntryUnmerged, "UU", "both.txt"},
+		{EntryUnmerged, "DU", "gone.txt"},
+		{EntryUntracked, "??", "dir/"},
+		{EntryIgnored, "!!", "build/"},
+	}
+	for i, tc := range cases {
+		e := st.Entries[i]
+		assert.That(t, e.Kind == tc.kind, "%d: kind %v, want %v", i, e.Kind, tc.kind)
+		assert.Equal(t, e.Code(), tc.code)
+		assert.Equal(t, e.Path, tc.path)
+	}
+
+	ren := st.Entries[2]
+	assert.Equal(t, ren.Orig, "from.txt")
+	assert.Equal(t, ren.RenameOp, byte('R'))
+	assert.Equal(t, ren.Score, 87)
+	assert.That(t, ren.Staged() && ren.Unstaged(), "RM is staged and unstaged")
+
+	assert.That(t, st.Entries[3].IsSubmodule(), "sub should be a submodule")
+	assert.That(t, !st.Entries[0].IsSubmodule(), "plain file is not a submodule")
+
+	u := st.Entries[5]
+	assert.Equal(t, u.Stages, [3]StatusStage{{"100644", h1}, {"000000", h2}, {"100644", h3}})
+	assert.That(t, !st.Entries[6].Tracked() && !st.Entries[6].Staged(), "untracked is neither tracked nor staged")
+}
+
+func TestParseStatus_Headers(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		out  string
+		want BranchStatus
+	}{
+		{"unborn", "# branch.oid (initial)\x00# branch.head main\x00", BranchStatus{Head: "main"}},
+		{"detached", "# branch.oid abc\x00# branch.head (detached)\x00", BranchStatus{Oid: "abc"}},
+		{"upstream gone", "# branch.oid abc\x00# branch.head x\x00# branch.upstream origin/x\x00",
+			BranchStatus{Oid: "abc", Head: "x", Upstream: "origin/x", Gone: true}},
+		{"empty", "", BranchStatus{}},
+	}
+	for _, tc := range cases {
+		st, err := parseStatus(tc.out)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, st.Branch, tc.want)
+	}
+}
+
+func TestParseStatus_Malformed(t *testing.T) {
+	t.Parallel()
+	for _, out := range []string{
+		"1 .M N... 100644\x00",
+		"2 R. N... 100644 100644 100644 a b R100 to\x00", // missing origin
+		"# branch.ab +x -1\x00",
+		"x what\x00",
+	} {
+		_, err := parseStatus(out)
+		assert.Error(t, err, assert.AnyError, "%q should fail", out)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+	local, _ := temp_repo.NewRepoWithRemote(t)
+	r := Repo{Dir: local}
+
+	temp_repo.CreateCommit(t, local, "a.txt", "a\n", "feat: a")
+	temp_repo.WriteFile(t, local, "README.md", "changed\n")
+	temp_repo.RunGit(t, local, "stash")
+	temp_repo.RunGit(t, local, "mv", "a.txt", "b.txt")
+	temp_repo.WriteFile(t, local, "README.md", "changed again\n")
+	require.NoError(t, os.Mkdir(filepath.Join(local, "new"), 0o755))
+	temp_repo.WriteFile(t, local, "new/one.txt", "1\n")
+	temp_repo.WriteFile(t, local, "new/two.txt", "2\n")
+	temp_repo.WriteFile(t, local, ".gitignore", "*.log\n")
+	temp_repo.WriteFile(t, local, "x.log", "log\n")
+
+	st, err := r.Status()
+	require.NoError(t, err)
+	assert.Equal(t, st.Branch.Head, "main")
+	assert.Equal(t, st.Branch.Upstream, "origin/main")
+	assert.Equal(t, st.Branch.Ahead, 1)
+	assert.Equal(t, st.Branch.Behind, 0)
+	assert.Equal(t, st.Stash, 1)
+
+	got := map[string]string{}
+	for _, e := range st.Entries {
+		got[e.Path] = e.Code() + " " + e.Orig
+	}
+	assert.Equal(t, got["README.md"], " M ")
+	assert.Equal(t, got["b.txt"], "R  a.txt")
+	assert.Equal(t, got["new/"], "?? ")
+	_, hasLog := got["x.log"]
+	assert.That(t, !hasLog, "ignored files need StatusOptions.Ignored")
+
+	st, err = r.StatusWith(StatusOptions{UntrackedAll: true, Ignored: true})
+	require.NoError(t, err)
+	got = map[string]string{}
+	for _, e := range st.Entries {
+		got[e.Path] = e.Code()
+	}
+	assert.Equal(t, got["new/one.txt"], "??")
+	assert.Equal(t, got["new/two.txt"], "??")
+	assert.Equal(t, got["x.log"], "!!")
+}
+
+func TestStatusWith_Paths(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	r := Repo{Dir: dir}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	temp_repo.WriteFile(t, dir, "README.md", "changed\n")
+	temp_repo.WriteFile(t, dir, "sub/new.txt", "1\n")
+	temp_repo.WriteFile(t, dir, "top.txt", "1\n")
+
+	st, err := r.StatusWith(StatusOptions{UntrackedAll: true, Paths: []string{"sub", "README.md"}})
+	require.NoError(t, err)
+	var paths []string
+	for _, e := range st.Entries {
+		paths = append(paths, e.Path)
+	}
+	assert.EqualArrays(t, paths, []string{"README.md", "sub/new.txt"})
+	assert.Equal(t, st.Branch.Head, "main", "the header is the whole repo's")
+}
+
+func TestStatus_Unmerged(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "x")
+	temp_repo.CreateCommit(t, dir, "f.txt", "x\n", "x")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "main")
+	temp_repo.CreateCommit(t, dir, "f.txt", "main\n", "main")
+	_, _, _ = Repo{Dir: dir}.RunWrite("merge", "--no-edit", "x")
+
+	st, err := Repo{Dir: dir}.Status()
+	require.NoError(t, err)
+	require.That(t, len(st.Entries) == 1, "entries: %+v", st.Entries)
+	e := st.Entries[0]
+	assert.That(t, e.Kind == EntryUnmerged, "kind %v", e.Kind)
+	assert.Equal(t, e.Code(), "AA")
+	assert.Equal(t, e.Stages[0].Mode, "000000") // no common base
+	assert.Equal(t, e.Stages[1].Mode, "100644")
+	assert.Equal(t, e.Stages[2].Mode, "100644")
+}
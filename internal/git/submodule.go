@@ -0,0 +1,247 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Submodule is one submodule of the superproject, as .gitmodules lists it.
+type Submodule struct {
+	Name string
+	Path string // relative to the superproject's top level
+	// Recorded is the commit the superproject's index points the
+	// submodule at; "" if the gitlink isn't staged yet.
+	Recorded string
+	// CheckedOut is the commit checked out inside the submodule; "" when
+	// it isn't initialized (no clone there).
+	CheckedOut string
+	Modified   bool // tracked changes inside the submodule
+	Untracked  bool // untracked files inside the submodule
+}
+
+// Initialized reports whether the submodule has been cloned into place.
+func (s Submodule) Initialized() bool { return s.CheckedOut != "" }
+
+// Drifted reports whether the submodule has a different commit checked out
+// than the superproject records, as after a switch without `submodule
+// update`.
+func (s Submodule) Drifted() bool {
+	return s.Initialized() && s.Recorded != "" && s.CheckedOut != s.Recorded
+}
+
+// Dirty reports whether the submodule has changes of its own.
+func (s Submodule) Dirty() bool { return s.Modified || s.Untracked }
+
+// Submodules lists the superproject's submodules in .gitmodules order.
+// It costs one config read when there are none.
+func (r Repo) Submodules() ([]Submodule, error) {
+	return r.SubmodulesCtx(context.Background())
+}
+
+// SubmodulesCtx is Submodules with a context.
+func (r Repo) SubmodulesCtx(ctx context.Context) ([]Submodule, error) {
+	top, subs, err := r.gitmodules(ctx)
+	if err != nil || len(subs) == 0 {
+		return nil, err
+	}
+	paths := make([]string, len(subs))
+	byPath := make(map[string]*Submodule, len(subs))
+	for i := range subs {
+		paths[i] = subs[i].Path
+		byPath[subs[i].Path] = &subs[i]
+	}
+
+	// recorded commits: the gitlinks in the index
+	stdout, stderr, err := top.runRead(ctx, append([]string{"ls-files", "--stage", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	for _, rec := range strings.Split(stdout, "\x00") {
+		// "<mode> <oid> <stage>\t<path>"
+		meta, path, ok := strings.Cut(rec, "\t")
+		f := strings.Fields(meta)
+		if !ok || len(f) != 3 || f[0] != "160000" {
+			continue
+		}
+		if s := byPath[path]; s != nil && (s.Recorded == "" || f[2] == "0") {
+			s.Recorded = f[1]
+		}
+	}
+
+	// dirt, from the "S<c><m><u>" field status gives submodules
+	stdout, stderr, err = top.runRead(ctx, append([]string{"status", "--porcelain=v2", "-z", "--ignore-submodules=none", "--"}, paths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	st, err := parseStatus(stdout)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range st.Entries {
+		if s := byPath[e.Path]; s != nil && e.IsSubmodule() && len(e.Sub) == 4 {
+			s.Modified = e.Sub[2] == 'M'
+			s.Untracked = e.Sub[3] == 'U'
+		}
+	}
+
+	for i := range subs {
+		subs[i].CheckedOut = top.submoduleHead(ctx, subs[i].Path)
+	}
+	return subs, nil
+}
+
+// gitmodules reads the submodules' names and paths from .gitmodules at
+// the top level, returned as a Repo there.
+func (r Repo) gitmodules(ctx context.Context) (Repo, []Submodule, error) {
+	root, _, err := r.runCtx(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return Repo{}, nil, err
+	}
+	top := Repo{Dir: root}
+	stdout, stderr, err := top.runRead(ctx, "config", "--file", ".gitmodules", "--null", "--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		// exit 1: no .gitmodules, or no submodules in it
+		var ge *Error
+		if errors.As(err, &ge) && ge.ExitCode == 1 {
+			return top, nil, nil
+		}
+		return Repo{}, nil, fmt.Errorf("reading .gitmodules: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	var subs []Submodule
+	for _, rec := range strings.Split(stdout, "\x00") {
+		// "submodule.<name>.path\n<path>"
+		key, path, ok := strings.Cut(rec, "\n")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "submodule."), ".path")
+		subs = append(subs, Submodule{Name: name, Path: path})
+	}
+	return top, subs, nil
+}
+
+// submoduleHead is the commit checked out in the submodule at path, or ""
+// if it isn't initialized. The .git check matters: rev-parse in an empty
+// submodule directory would find the superproject instead.
+func (r Repo) submoduleHead(ctx context.Context, path string) string {
+	dir := filepath.Join(r.Dir, path)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return ""
+	}
+	oid, _, err := Repo{Dir: dir}.runCtx(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return oid
+}
+
+// SubmoduleChange is a submodule whose recorded commit a diff moves.
+type SubmoduleChange struct {
+	Path string
+	Old  string // "" when the diff adds the submodule
+	New  string // "" when the diff removes it
+	// Ahead and Behind count the commits New has that Old doesn't and
+	// vice versa. Counted is false when the submodule's objects aren't
+	// there to count them.
+	Ahead, Behind int
+	Counted       bool
+}
+
+// SubmoduleDiff lists the submodule pointer changes in `git diff args...`
+// (e.g. none for the worktree, "--cached", "HEAD~1..HEAD"). For the
+// worktree side, a submodule's checked-out commit stands in for the
+// gitlink. Submodules with only changes of their own aren't listed.
+func (r Repo) SubmoduleDiff(args ...string) ([]SubmoduleChange, error) {
+	return r.SubmoduleDiffCtx(context.Background(), args...)
+}
+
+// SubmoduleDiffCtx is SubmoduleDiff with a context.
+func (r Repo) SubmoduleDiffCtx(ctx context.Context, args ...string) ([]SubmoduleChange, error) {
+	top, subs, err := r.gitmodules(ctx)
+	if err != nil || len(subs) == 0 {
+		return nil, err
+	}
+	full := append([]string{"diff", "--raw", "-z", "--no-abbrev", "--no-renames", "--ignore-submodules=none"}, args...)
+	full = append(full, "--")
+	for _, s := range subs {
+		full = append(full, s.Path)
+	}
+	stdout, stderr, err := top.runRead(ctx, full...)
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %w: %s", err, strings.TrimSpace(stderr))
+	}
+
+	var changes []SubmoduleChange
+	fields := strings.Split(stdout, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		// ":<old mode> <new mode> <old oid> <new oid> <status>", then path
+		f := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(f) != 5 || (f[0] != "160000" && f[1] != "160000") {
+			continue
+		}
+		c := SubmoduleChange{Path: fields[i+1]}
+		if f[0] == "160000" {
+			c.Old = f[2]
+		}
+		if f[1] == "160000" {
+			c.New = f[3]
+			if strings.Trim(c.New, "0") == "" {
+				// worktree side: git leaves it to us
+				c.New = top.submoduleHead(ctx, c.Path)
+			}
+		}
+		if c.Old == c.New {
+			continue
+		}
+		if c.Old != "" && c.New != "" {
+			c.Ahead, c.Behind, c.Counted = top.countSubmoduleCommits(ctx, c.Path, c.Old, c.New)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+func (r Repo) countSubmoduleCommits(ctx context.Context, path, from, to string) (ahead, behind int, ok bool) {
+	if r.submoduleHead(ctx, path) == "" {
+		return 0, 0, false
+	}
+	out, _, err := Repo{Dir: filepath.Join(r.Dir, path)}.runCtx(ctx, "rev-list", "--left-right", "--count", from+"..."+to)
+	if err != nil {
+		return 0, 0, false
+	}
+	l, rr, _ := strings.Cut(out, "\t")
+	behind, err1 := strconv.Atoi(l)
+	ahead, err2 := strconv.Atoi(rr)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return ahead, behind, true
+}
+
+// SubmoduleUpdate checks out the recorded commit in each submodule at
+// paths (all of them if none), cloning any that aren't initialized and
+// recursing into nested ones. It may fetch, and discards nothing: git
+// refuses where a submodule's own changes would be overwritten.
+func (r Repo) SubmoduleUpdate(paths ...string) error {
+	return r.SubmoduleUpdateCtx(context.Background(), paths...)
+}
+
+// SubmoduleUpdateCtx is SubmoduleUpdate with a context.
+func (r Repo) SubmoduleUpdateCtx(ctx context.Context, paths ...string) error {
+	root, _, err := r.runCtx(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	// paths are top-level relative, so run from there
+	args := append([]string{"submodule", "update", "--init", "--recursive", "--"}, paths...)
+	_, stderr, err := Repo{Dir: root}.runWriteStreaming(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("git submodule update: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	return nil
+}
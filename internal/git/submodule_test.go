@@ -0,0 +1,114 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func TestSubmodules_None(t *testing.T) {
+	t.Parallel()
+	subs, err := Repo{Dir: temp_repo.NewRepo(t)}.Submodules()
+	require.NoError(t, err)
+	assert.Equal(t, len(subs), 0)
+}
+
+func TestSubmodules(t *testing.T) {
+	t.Parallel()
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	sub := filepath.Join(super, "lib")
+	r := Repo{Dir: super}
+	recorded := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+
+	subs, err := r.Submodules()
+	require.NoError(t, err)
+	require.That(t, len(subs) == 1, "want one submodule, got ", subs)
+	assert.Equal(t, subs[0], Submodule{Name: "lib", Path: "lib", Recorded: recorded, CheckedOut: recorded})
+	assert.That(t, !subs[0].Drifted(), "fresh submodule is in sync")
+
+	// a commit inside the submodule drifts it; new files dirty it
+	temp_repo.CreateCommit(t, sub, "new.txt", "new\n", "feat: new")
+	temp_repo.WriteFile(t, sub, "README.md", "changed\n")
+	temp_repo.WriteFile(t, sub, "scratch.txt", "scratch\n")
+
+	// from a subdirectory of the superproject, too
+	require.NoError(t, os.Mkdir(filepath.Join(super, "docs"), 0o755))
+	subs, err = Repo{Dir: filepath.Join(super, "docs")}.Submodules()
+	require.NoError(t, err)
+	require.That(t, len(subs) == 1, "want one submodule, got ", subs)
+	got := subs[0]
+	assert.Equal(t, got.Recorded, recorded)
+	assert.That(t, got.Drifted(), "checked-out commit moved on")
+	assert.That(t, got.Modified, "README.md changed")
+	assert.That(t, got.Untracked, "scratch.txt is untracked")
+}
+
+func TestSubmodules_Uninitialized(t *testing.T) {
+	t.Parallel()
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	clone := t.TempDir()
+	temp_repo.RunGit(t, clone, "clone", "-q", super, ".")
+
+	subs, err := Repo{Dir: clone}.Submodules()
+	require.NoError(t, err)
+	require.That(t, len(subs) == 1, "want one submodule, got ", subs)
+	assert.That(t, !subs[0].Initialized(), "clone without --recurse-submodules leaves it uninitialized")
+	assert.That(t, !subs[0].Drifted(), "an uninitialized submodule can't drift")
+	assert.That(t, subs[0].Recorded != "", "the gitlink is still recorded")
+}
+
+func TestSubmoduleDiff(t *testing.T) {
+	t.Parallel()
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	sub := filepath.Join(super, "lib")
+	r := Repo{Dir: super}
+	before := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+
+	changes, err := r.SubmoduleDiff()
+	require.NoError(t, err)
+	assert.Equal(t, len(changes), 0)
+
+	temp_repo.CreateCommit(t, sub, "a.txt", "a\n", "feat: a")
+	temp_repo.CreateCommit(t, sub, "b.txt", "b\n", "feat: b")
+	after := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+
+	changes, err = r.SubmoduleDiff()
+	require.NoError(t, err)
+	assert.EqualArrays(t, changes, []SubmoduleChange{{Path: "lib", Old: before, New: after, Ahead: 2, Counted: true}})
+
+	// nothing staged yet
+	changes, err = r.SubmoduleDiff("--cached")
+	require.NoError(t, err)
+	assert.Equal(t, len(changes), 0)
+
+	temp_repo.RunGit(t, super, "commit", "-am", "chore: bump lib")
+	changes, err = r.SubmoduleDiff("HEAD~1..HEAD")
+	require.NoError(t, err)
+	assert.EqualArrays(t, changes, []SubmoduleChange{{Path: "lib", Old: before, New: after, Ahead: 2, Counted: true}})
+
+	// the addition itself
+	changes, err = r.SubmoduleDiff("HEAD~2..HEAD~1")
+	require.NoError(t, err)
+	require.That(t, len(changes) == 1, "want one change, got ", changes)
+	assert.Equal(t, changes[0].Old, "")
+	assert.Equal(t, changes[0].New, before)
+}
+
+func TestSubmoduleUpdate(t *testing.T) {
+	t.Parallel()
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	sub := filepath.Join(super, "lib")
+	r := Repo{Dir: super}
+	recorded := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+	temp_repo.CreateCommit(t, sub, "a.txt", "a\n", "feat: a")
+
+	require.NoError(t, r.SubmoduleUpdate("lib"))
+	subs, err := r.Submodules()
+	require.NoError(t, err)
+	assert.Equal(t, subs[0].CheckedOut, recorded)
+}
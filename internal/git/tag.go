@@ -9,7 +9,12 @@ import (
 // TagAnnotated creates an annotated tag with the given message. Output is
 // captured; on error, stderr is included in the wrapped message.
 func (r Repo) TagAnnotated(name, message string) error {
-	if _, stderr, err := r.runWrite(context.Background(), "tag", "-a", name, "-m", message); err != nil {
+	return r.TagAnnotatedCtx(context.Background(), name, message)
+}
+
+// TagAnnotatedCtx is TagAnnotated with a context.
+func (r Repo) TagAnnotatedCtx(ctx context.Context, name, message string) error {
+	if _, stderr, err := r.runWrite(ctx, "tag", "-a", name, "-m", message); err != nil {
 		return fmt.Errorf("git tag %s: %w: %s", name, err, strings.TrimSpace(stderr))
 	}
 	return nil
@@ -18,7 +23,12 @@ func (r Repo) TagAnnotated(name, message string) error {
 // TagExists reports whether a ref of the given name resolves. False on any
 // error (treats unresolvable refs as absent).
 func (r Repo) TagExists(name string) bool {
-	_, _, err := r.run("rev-parse", "--verify", name)
+	return r.TagExistsCtx(context.Background(), name)
+}
+
+// TagExistsCtx is TagExists with a context.
+func (r Repo) TagExistsCtx(ctx context.Context, name string) bool {
+	_, _, err := r.runCtx(ctx, "rev-parse", "--verify", name)
 	return err == nil
 }
 
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DefaultNetworkTimeout bounds each call that talks to a remote when the
+// context doesn't set its own with WithNetworkTimeout. Generous enough for
+// a big fetch; its job is to stop a remote that never answers from
+// hanging gg forever.
+const DefaultNetworkTimeout = 2 * time.Minute
+
+// ErrNetworkTimeout is in the chain of a network call's error when the
+// call ran past its timeout. The call's *Error is classified as
+// KindRemoteUnreachable too, so callers that already fall back on an
+// unreachable remote treat a hung one the same way.
+var ErrNetworkTimeout = errors.New("network timeout")
+
+// networkCommands are the subcommands gg runs that talk to a remote.
+// Of submodule's, only update does: it clones and fetches submodules.
+var networkCommands = []string{"fetch", "pull", "push", "ls-remote", "submodule update"}
+
+type networkTimeoutKey struct{}
+
+// WithNetworkTimeout returns a context under which every network call
+// (LsRemote, Fetch, Push, RemoteBranchExists, SubmoduleUpdate, and fetch /
+// push / ls-remote through RunWrite and friends) is cut off after d. d <= 0 turns the
+// timeout off; the context's own deadline and cancellation still apply.
+func WithNetworkTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, networkTimeoutKey{}, d)
+}
+
+// NetworkTimeout returns the timeout network calls under ctx get.
+func NetworkTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(networkTimeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	return DefaultNetworkTimeout
+}
+
+// networkCommand returns args' subcommand if it talks to a remote, else
+// "".
+func networkCommand(args []string) string {
+	sub := subcommand(args)
+	if sub == "submodule" {
+		i := slices.Index(args, sub)
+		sub += " " + subcommand(args[i+1:])
+	}
+	if slices.Contains(networkCommands, sub) {
+		return sub
+	}
+	return ""
+}
+
+// networkCtx bounds ctx by the network timeout when args is a network
+// call. finish turns the error of a call that ran out of time into one
+// that says so; other errors, including the caller's own cancellation,
+// pass through.
+func networkCtx(ctx context.Context, args []string) (context.Context, func(error) error) {
+	d := NetworkTimeout(ctx)
+	sub := networkCommand(args)
+	if d <= 0 || sub == "" {
+		return ctx, func(err error) error { return err }
+	}
+	cause := fmt.Errorf("%w: git %s got no answer in %s", ErrNetworkTimeout, sub, d)
+	tctx, cancel := context.WithTimeoutCause(ctx, d, cause)
+	return tctx, func(err error) error {
+		defer cancel()
+		if err == nil || ctx.Err() != nil || context.Cause(tctx) != cause {
+			return err
+		}
+		var ge *Error
+		if errors.As(err, &ge) {
+			ge.Kind = KindRemoteUnreachable
+			ge.err = cause
+			return err
+		}
+		return cause
+	}
+}
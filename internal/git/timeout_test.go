@@ -0,0 +1,161 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+// slowRemote is a repo whose origin takes far longer to answer than any
+// test waits: upload-pack and receive-pack sleep before starting.
+func slowRemote(t *testing.T) Repo {
+	t.Helper()
+	local, _ := temp_repo.NewRepoWithRemote(t)
+	temp_repo.RunGit(t, local, "config", "remote.origin.uploadpack", "sleep 30; git-upload-pack")
+	temp_repo.RunGit(t, local, "config", "remote.origin.receivepack", "sleep 30; git-receive-pack")
+	return Repo{Dir: local}
+}
+
+func TestNetworkTimeout_SlowRemote(t *testing.T) {
+	t.Parallel()
+	calls := map[string]func(r Repo, ctx context.Context) error{
+		"ls-remote": func(r Repo, ctx context.Context) error {
+			_, err := r.LsRemoteCtx(ctx, "origin")
+			return err
+		},
+		"fetch": func(r Repo, ctx context.Context) error {
+			return r.FetchCtx(ctx, "origin", "")
+		},
+		"push": func(r Repo, ctx context.Context) error {
+			temp_repo.CreateCommit(t, r.Dir, "new.txt", "new\n", "feat: new")
+			return r.PushCtx(ctx)
+		},
+		"remote branch exists": func(r Repo, ctx context.Context) error {
+			_, err := r.RemoteBranchExistsCtx(ctx, "origin", "main")
+			return err
+		},
+		"raw push": func(r Repo, ctx context.Context) error {
+			_, _, err := r.RunWriteStreamCtx(ctx, "push", "origin", "main")
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			r := slowRemote(t)
+			ctx := WithNetworkTimeout(context.Background(), 300*time.Millisecond)
+
+			start := time.Now()
+			err := call(r, ctx)
+			elapsed := time.Since(start)
+
+			require.That(t, err != nil, "want a timeout error")
+			assert.That(t, errors.Is(err, ErrNetworkTimeout), "want ErrNetworkTimeout, got %v", err)
+			assert.ContainsString(t, err.Error(), "got no answer in 300ms")
+			if name != "remote branch exists" {
+				assert.Equal(t, KindOf(err), KindRemoteUnreachable)
+			}
+			assert.That(t, elapsed < 10*time.Second, "gave up after %s", elapsed)
+		})
+	}
+}
+
+// a submodule update that has to clone from a remote that never answers
+// gives up too.
+func TestNetworkTimeout_SubmoduleUpdate(t *testing.T) {
+	t.Parallel()
+	// a git:// server that takes the connection and never says a word
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { c.Close() })
+		}
+	}()
+
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	temp_repo.RunGit(t, super, "submodule", "deinit", "-q", "-f", "lib")
+	require.NoError(t, os.RemoveAll(filepath.Join(super, ".git", "modules", "lib")))
+	temp_repo.RunGit(t, super, "config", "-f", ".gitmodules", "submodule.lib.url", "git://"+l.Addr().String()+"/lib")
+
+	ctx := WithNetworkTimeout(context.Background(), 300*time.Millisecond)
+	start := time.Now()
+	err = Repo{Dir: super}.SubmoduleUpdateCtx(ctx, "lib")
+	require.That(t, err != nil, "want a timeout error")
+	assert.That(t, errors.Is(err, ErrNetworkTimeout), "want ErrNetworkTimeout, got %v", err)
+	assert.ContainsString(t, err.Error(), "git submodule update got no answer in 300ms")
+	assert.That(t, time.Since(start) < 10*time.Second, "gave up after %s", time.Since(start))
+}
+
+// the caller's own cancellation isn't reported as a timeout.
+func TestNetworkTimeout_CallerCancel(t *testing.T) {
+	t.Parallel()
+	r := slowRemote(t)
+	ctx, cancel := context.WithTimeout(WithNetworkTimeout(context.Background(), 0), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := r.LsRemoteCtx(ctx, "origin")
+	require.That(t, err != nil, "want an error from the cancelled call")
+	assert.That(t, !errors.Is(err, ErrNetworkTimeout), "cancellation reported as a timeout: %v", err)
+	assert.That(t, time.Since(start) < 10*time.Second, "gave up after %s", time.Since(start))
+}
+
+// a remote that answers in time is unaffected, and local calls get no
+// timeout at all.
+func TestNetworkTimeout_FastRemote(t *testing.T) {
+	t.Parallel()
+	local, _ := temp_repo.NewRepoWithRemote(t)
+	r := Repo{Dir: local}
+	ctx := WithNetworkTimeout(context.Background(), 30*time.Second)
+
+	out, err := r.LsRemoteCtx(ctx, "origin")
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "refs/heads/main")
+	ok, err := r.RemoteBranchExistsCtx(ctx, "origin", "main")
+	require.NoError(t, err)
+	assert.That(t, ok, "origin/main exists")
+	ok, err = r.RemoteBranchExistsCtx(ctx, "origin", "nope")
+	require.NoError(t, err)
+	assert.That(t, !ok, "origin/nope doesn't")
+}
+
+func TestNetworkCommand(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"fetch", "origin"}, "fetch"},
+		{[]string{"ls-remote", "--heads", "origin"}, "ls-remote"},
+		{[]string{"-c", "core.hooksPath=/dev/null", "push"}, "push"},
+		{[]string{"submodule", "--quiet", "update", "--init"}, "submodule update"},
+		{[]string{"submodule", "foreach", "git", "fetch"}, ""},
+		{[]string{"status"}, ""},
+		{[]string{"remote"}, ""},
+		{[]string{"-c", "fetch"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, networkCommand(tt.args), tt.want)
+	}
+}
+
+func TestNetworkTimeout_Default(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, NetworkTimeout(context.Background()), DefaultNetworkTimeout)
+	assert.Equal(t, NetworkTimeout(WithNetworkTimeout(context.Background(), 0)), time.Duration(0))
+}
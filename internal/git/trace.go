@@ -0,0 +1,219 @@
+package git
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// traceStderrMax caps how much of a call's stderr the trace keeps.
+const traceStderrMax = 200
+
+// Call is one traced git invocation.
+type Call struct {
+	Kind     string   // read, write, stream, lines or batch
+	Argv     []string // everything after "git", prelude included
+	Args     []string // Argv without the prelude: Args[0] is the subcommand
+	Dir      string
+	Start    time.Time
+	Duration time.Duration
+	ExitCode int    // -1 when git didn't run to an exit (not found, killed)
+	Stderr   string // first traceStderrMax bytes
+}
+
+// Tracer records every git subprocess started through a Repo while it's
+// installed with SetTracer. Each call is written to the log, if there is
+// one, as soon as it finishes.
+type Tracer struct {
+	mu    sync.Mutex
+	log   io.Writer
+	start time.Time
+	calls []Call
+}
+
+// NewTracer returns a Tracer logging each call to log; nil logs nothing
+// and only collects.
+func NewTracer(log io.Writer) *Tracer {
+	return &Tracer{log: log, start: time.Now()}
+}
+
+var activeTracer atomic.Pointer[Tracer]
+
+// SetTracer installs t for every Repo in the process; nil turns tracing
+// off.
+func SetTracer(t *Tracer) { activeTracer.Store(t) }
+
+// Calls returns the calls recorded so far, in finishing order.
+func (t *Tracer) Calls() []Call {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.calls)
+}
+
+// traceCall records a finished invocation with the active tracer, if any.
+// It's called from the run* helpers with the argv they built.
+func traceCall(kind, dir string, argv, args []string, start time.Time, stderr string, err error) {
+	t := activeTracer.Load()
+	if t == nil {
+		return
+	}
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	c := Call{
+		Kind:     kind,
+		Argv:     argv,
+		Args:     args,
+		Dir:      dir,
+		Start:    start,
+		Duration: time.Since(start),
+		ExitCode: exitCode(err),
+		Stderr:   truncate(strings.TrimSpace(stderr), traceStderrMax),
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls = append(t.calls, c)
+	if t.log != nil {
+		fmt.Fprintf(t.log, "gg trace: %s %s exit %d in %s: git %s\n",
+			formatDuration(c.Duration), c.Kind, c.ExitCode, c.Dir, shellJoin(c.Argv))
+		if c.Stderr != "" {
+			fmt.Fprintf(t.log, "gg trace:   stderr: %q\n", c.Stderr)
+		}
+	}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ee.ExitCode() >= 0 {
+		return ee.ExitCode()
+	}
+	return -1
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%7.1fms", float64(d.Microseconds())/1000)
+}
+
+// WriteSummary writes the call count, the summed git time against the wall
+// time since the tracer started, and the n slowest calls.
+func (t *Tracer) WriteSummary(w io.Writer, n int) {
+	calls := t.Calls()
+	var total time.Duration
+	for _, c := range calls {
+		total += c.Duration
+	}
+	fmt.Fprintf(w, "gg trace: %d git calls, %s in git, %s wall\n",
+		len(calls), strings.TrimSpace(formatDuration(total)), strings.TrimSpace(formatDuration(time.Since(t.start))))
+	slices.SortStableFunc(calls, func(a, b Call) int { return cmp.Compare(b.Duration, a.Duration) })
+	if len(calls) > n {
+		calls = calls[:n]
+	}
+	if len(calls) > 0 {
+		fmt.Fprintln(w, "gg trace: slowest:")
+	}
+	for _, c := range calls {
+		fmt.Fprintf(w, "gg trace: %s  git %s\n", formatDuration(c.Duration), shellJoin(c.Args))
+	}
+}
+
+// chromeEvent is one complete ("X") event of the Chrome trace-event format,
+// as chrome://tracing and Perfetto load it.
+type chromeEvent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat"`
+	Ph   string         `json:"ph"`
+	Ts   int64          `json:"ts"`  // microseconds since the tracer started
+	Dur  int64          `json:"dur"` // microseconds
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	Args map[string]any `json:"args"`
+}
+
+// WriteChromeTrace writes the calls as Chrome trace-event JSON. Calls that
+// overlapped in time go on separate rows (tids), so concurrent git
+// processes show up side by side.
+func (t *Tracer) WriteChromeTrace(w io.Writer) error {
+	calls := t.Calls()
+	slices.SortStableFunc(calls, func(a, b Call) int { return a.Start.Compare(b.Start) })
+
+	var laneEnds []time.Time // when each row's last call finished
+	events := make([]chromeEvent, 0, len(calls))
+	for _, c := range calls {
+		lane := slices.IndexFunc(laneEnds, func(end time.Time) bool { return !end.After(c.Start) })
+		if lane < 0 {
+			lane = len(laneEnds)
+			laneEnds = append(laneEnds, time.Time{})
+		}
+		laneEnds[lane] = c.Start.Add(c.Duration)
+
+		name := "git"
+		if len(c.Args) > 0 {
+			name += " " + c.Args[0]
+		}
+		events = append(events, chromeEvent{
+			Name: name,
+			Cat:  c.Kind,
+			Ph:   "X",
+			Ts:   c.Start.Sub(t.start).Microseconds(),
+			Dur:  c.Duration.Microseconds(),
+			Pid:  1,
+			Tid:  lane + 1,
+			Args: map[string]any{
+				"argv":   c.Argv,
+				"cwd":    c.Dir,
+				"exit":   c.ExitCode,
+				"stderr": c.Stderr,
+			},
+		})
+	}
+	enc := json.NewEncoder(w)
+	return enc.Encode(struct {
+		TraceEvents     []chromeEvent `json:"traceEvents"`
+		DisplayTimeUnit string        `json:"displayTimeUnit"`
+	}{events, "ms"})
+}
+
+// shellJoin quotes args for a POSIX shell, leaving plain words bare.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%^~", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
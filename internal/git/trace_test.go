@@ -0,0 +1,142 @@
+package git
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+// the tracer is process-wide, so these tests don't run in parallel and only
+// look at calls made in their own repo dir
+func callsIn(tr *Tracer, dir string) []Call {
+	var out []Call
+	for _, c := range tr.Calls() {
+		if c.Dir == dir {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func TestTracer_RecordsCalls(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	r := Repo{Dir: dir}
+	var log strings.Builder
+	tr := NewTracer(&log)
+	SetTracer(tr)
+	defer SetTracer(nil)
+
+	_, _, err := r.Run("rev-parse", "HEAD")
+	require.NoError(t, err)
+	_, _, err = r.RunWrite("checkout", "nope")
+	assert.Error(t, err, assert.AnyError)
+	require.NoError(t, r.RunLines(func(string) error { return nil }, "log", "--oneline"))
+	_, err = r.CommitInfo("HEAD")
+	require.NoError(t, err)
+	_ = r.Close()
+
+	calls := callsIn(tr, dir)
+	require.That(t, len(calls) == 4, "got %d calls: %+v", len(calls), calls)
+	kinds := []string{calls[0].Kind, calls[1].Kind, calls[2].Kind, calls[3].Kind}
+	assert.EqualArrays(t, kinds, []string{"read", "write", "lines", "batch"})
+
+	assert.EqualArrays(t, calls[0].Args, []string{"rev-parse", "HEAD"})
+	assert.That(t, calls[0].Argv[0] == "--no-pager", "argv should include the prelude: %v", calls[0].Argv)
+	assert.Equal(t, calls[0].ExitCode, 0)
+	assert.That(t, calls[0].Duration > 0, "duration %v", calls[0].Duration)
+
+	assert.That(t, calls[1].ExitCode > 0, "failed checkout exit %d", calls[1].ExitCode)
+	assert.ContainsString(t, calls[1].Stderr, "nope")
+
+	assert.ContainsString(t, log.String(), "write exit 1 in "+dir+": git --no-pager")
+	assert.ContainsString(t, log.String(), "stderr: ")
+}
+
+func TestTracer_Off(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	tr := NewTracer(nil)
+	SetTracer(tr)
+	SetTracer(nil)
+	_, _, err := Repo{Dir: dir}.Run("rev-parse", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, len(callsIn(tr, dir)), 0)
+}
+
+func fakeTracer(start time.Time, calls ...Call) *Tracer {
+	return &Tracer{start: start, calls: calls}
+}
+
+func TestTracer_WriteSummary(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	tr := fakeTracer(now,
+		Call{Args: []string{"status"}, Duration: 2 * time.Millisecond},
+		Call{Args: []string{"fetch", "origin"}, Duration: 300 * time.Millisecond},
+		Call{Args: []string{"log", "--format=%H %s"}, Duration: 40 * time.Millisecond},
+	)
+	var buf strings.Builder
+	tr.WriteSummary(&buf, 2)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.That(t, len(lines) == 4, "summary:\n%s", buf.String())
+	assert.ContainsString(t, lines[0], "3 git calls, 342.0ms in git")
+	assert.ContainsString(t, lines[2], "300.0ms  git fetch origin")
+	assert.ContainsString(t, lines[3], "40.0ms  git log '--format=%H %s'")
+}
+
+func TestTracer_WriteChromeTrace(t *testing.T) {
+	t.Parallel()
+	t0 := time.Now()
+	ms := time.Millisecond
+	// a and b overlap; c starts after a ends and reuses its row
+	tr := fakeTracer(t0,
+		Call{Kind: "read", Args: []string{"status"}, Argv: []string{"status"}, Start: t0.Add(1 * ms), Duration: 10 * ms},
+		Call{Kind: "stream", Args: []string{"fetch"}, Argv: []string{"fetch"}, Start: t0.Add(5 * ms), Duration: 20 * ms, ExitCode: 128, Stderr: "boom"},
+		Call{Kind: "read", Args: []string{"log"}, Argv: []string{"log"}, Start: t0.Add(12 * ms), Duration: 1 * ms},
+	)
+	var buf strings.Builder
+	require.NoError(t, tr.WriteChromeTrace(&buf))
+
+	var doc struct {
+		TraceEvents []struct {
+			Name string         `json:"name"`
+			Cat  string         `json:"cat"`
+			Ph   string         `json:"ph"`
+			Ts   int64          `json:"ts"`
+			Dur  int64          `json:"dur"`
+			Tid  int            `json:"tid"`
+			Args map[string]any `json:"args"`
+		} `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(buf.String()), &doc))
+	ev := doc.TraceEvents
+	require.That(t, len(ev) == 3, "events: %+v", ev)
+	assert.Equal(t, ev[0].Name, "git status")
+	assert.Equal(t, ev[0].Ph, "X")
+	assert.Equal(t, ev[0].Ts, int64(1000))
+	assert.Equal(t, ev[0].Dur, int64(10000))
+	assert.Equal(t, ev[1].Cat, "stream")
+	assert.Equal(t, ev[1].Args["exit"], any(float64(128)))
+	assert.Equal(t, ev[1].Args["stderr"], any("boom"))
+	assert.EqualArrays(t, []int{ev[0].Tid, ev[1].Tid, ev[2].Tid}, []int{1, 2, 1})
+}
+
+func TestShellJoin(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"status", "--short"}, "status --short"},
+		{[]string{"commit", "-m", "feat: it's done"}, `commit -m 'feat: it'\''s done'`},
+		{[]string{"log", ""}, "log ''"},
+		{[]string{"-C", "/tmp/a b", "refs/heads/x"}, "-C '/tmp/a b' refs/heads/x"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, shellJoin(tc.args), tc.want)
+	}
+}
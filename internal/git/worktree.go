@@ -0,0 +1,230 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Worktree is one entry of `git worktree list`. The first one Worktrees
+// returns is always the main worktree.
+type Worktree struct {
+	Path   string
+	Head   string // commit oid; empty for a bare repo
+	Branch string // short branch name; empty when detached or bare
+	Main   bool
+
+	Bare     bool
+	Detached bool
+	Locked   bool
+	Prunable bool // its directory is gone; `worktree prune` would drop it
+
+	LockReason     string
+	PrunableReason string
+}
+
+// porcelainZVersion is the first git with `worktree list -z`. Before it
+// gg falls back to newline-terminated porcelain, which only breaks on
+// paths containing a newline.
+var porcelainZVersion = [3]int{2, 36, 0}
+
+// Worktrees lists the main worktree and every linked one, from
+// `worktree list --porcelain -z`.
+func (r Repo) Worktrees() ([]Worktree, error) {
+	return r.WorktreesCtx(context.Background())
+}
+
+// WorktreesCtx is Worktrees with a context.
+func (r Repo) WorktreesCtx(ctx context.Context) ([]Worktree, error) {
+	if err := ensureMinVersion(ctx); err != nil {
+		return nil, err
+	}
+	args := []string{"worktree", "list", "--porcelain"}
+	sep := "\n"
+	if compareVersion(detectedVersion(), porcelainZVersion) >= 0 {
+		args = append(args, "-z")
+		sep = "\x00"
+	}
+	stdout, _, err := r.runRead(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktrees(stdout, sep)
+}
+
+// parseWorktrees reads porcelain output: one "key[ value]" attribute per
+// sep-terminated field, with an empty field closing each worktree.
+func parseWorktrees(out, sep string) ([]Worktree, error) {
+	var wts []Worktree
+	var cur *Worktree
+	for _, field := range strings.Split(out, sep) {
+		if field == "" {
+			cur = nil
+			continue
+		}
+		key, value, _ := strings.Cut(field, " ")
+		if key == "worktree" {
+			wts = append(wts, Worktree{Path: value, Main: len(wts) == 0})
+			cur = &wts[len(wts)-1]
+			continue
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("parsing worktree list: %q outside a worktree", field)
+		}
+		switch key {
+		case "HEAD":
+			cur.Head = value
+		case "branch":
+			cur.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			cur.Bare = true
+		case "detached":
+			cur.Detached = true
+		case "locked":
+			cur.Locked, cur.LockReason = true, value
+		case "prunable":
+			cur.Prunable, cur.PrunableReason = true, value
+		}
+		// unknown keys are attributes newer gits added; skip them
+	}
+	return wts, nil
+}
+
+// CheckedOutBranches maps each branch currently checked out in any worktree
+// (including the main worktree) to that worktree's path. Callers use the map
+// for O(1) lookups rather than running a separate subprocess per branch.
+func (r Repo) CheckedOutBranches() (map[string]string, error) {
+	return r.CheckedOutBranchesCtx(context.Background())
+}
+
+// CheckedOutBranchesCtx is CheckedOutBranches with a context.
+func (r Repo) CheckedOutBranchesCtx(ctx context.Context) (map[string]string, error) {
+	wts, err := r.WorktreesCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	for _, wt := range wts {
+		if wt.Branch != "" {
+			out[wt.Branch] = wt.Path
+		}
+	}
+	return out, nil
+}
+
+// AddWorktree checks ref out into a new linked worktree at path. A branch
+// gets checked out; anything else (a tag, an oid) leaves it detached.
+func (r Repo) AddWorktree(path, ref string) error {
+	return r.AddWorktreeCtx(context.Background(), path, ref)
+}
+
+// AddWorktreeCtx is AddWorktree with a context.
+func (r Repo) AddWorktreeCtx(ctx context.Context, path, ref string) error {
+	_, stderr, err := r.runWrite(ctx, "worktree", "add", "--quiet", path, ref)
+	if err != nil {
+		return fmt.Errorf("git worktree add %s %s: %w: %s", path, ref, err, stderr)
+	}
+	return nil
+}
+
+// AddWorktreeNewBranch creates branch off startPoint and checks it out
+// into a new linked worktree at path (`git worktree add -b`).
+func (r Repo) AddWorktreeNewBranch(path, branch, startPoint string) error {
+	return r.AddWorktreeNewBranchCtx(context.Background(), path, branch, startPoint)
+}
+
+// AddWorktreeNewBranchCtx is AddWorktreeNewBranch with a context.
+func (r Repo) AddWorktreeNewBranchCtx(ctx context.Context, path, branch, startPoint string) error {
+	args := []string{"worktree", "add", "--quiet", "-b", branch, path}
+	if startPoint != "" {
+		args = append(args, startPoint)
+	}
+	_, stderr, err := r.runWrite(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("git worktree add -b %s %s: %w: %s", branch, path, err, stderr)
+	}
+	return nil
+}
+
+// RemoveWorktree deletes the linked worktree at path. git refuses if it
+// has local changes or is locked, unless force is set -- which discards
+// them, so the caller asks the user first.
+func (r Repo) RemoveWorktree(path string, force bool) error {
+	return r.RemoveWorktreeCtx(context.Background(), path, force)
+}
+
+// RemoveWorktreeCtx is RemoveWorktree with a context.
+func (r Repo) RemoveWorktreeCtx(ctx context.Context, path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		// twice: once for local changes, once more for a lock
+		args = append(args, "--force", "--force")
+	}
+	_, stderr, err := r.runWrite(ctx, append(args, path)...)
+	if err != nil {
+		return fmt.Errorf("git worktree remove %s: %w: %s", path, err, stderr)
+	}
+	return nil
+}
+
+// MoveWorktree moves the linked worktree at path to newPath.
+func (r Repo) MoveWorktree(path, newPath string) error {
+	return r.MoveWorktreeCtx(context.Background(), path, newPath)
+}
+
+// MoveWorktreeCtx is MoveWorktree with a context.
+func (r Repo) MoveWorktreeCtx(ctx context.Context, path, newPath string) error {
+	_, stderr, err := r.runWrite(ctx, "worktree", "move", path, newPath)
+	if err != nil {
+		return fmt.Errorf("git worktree move %s %s: %w: %s", path, newPath, err, stderr)
+	}
+	return nil
+}
+
+// LockWorktree keeps the linked worktree at path from being pruned, moved
+// or removed. reason is optional and shows up in Worktrees.
+func (r Repo) LockWorktree(path, reason string) error {
+	return r.LockWorktreeCtx(context.Background(), path, reason)
+}
+
+// LockWorktreeCtx is LockWorktree with a context.
+func (r Repo) LockWorktreeCtx(ctx context.Context, path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	_, stderr, err := r.runWrite(ctx, append(args, path)...)
+	if err != nil {
+		return fmt.Errorf("git worktree lock %s: %w: %s", path, err, stderr)
+	}
+	return nil
+}
+
+// UnlockWorktree undoes LockWorktree.
+func (r Repo) UnlockWorktree(path string) error {
+	return r.UnlockWorktreeCtx(context.Background(), path)
+}
+
+// UnlockWorktreeCtx is UnlockWorktree with a context.
+func (r Repo) UnlockWorktreeCtx(ctx context.Context, path string) error {
+	_, stderr, err := r.runWrite(ctx, "worktree", "unlock", path)
+	if err != nil {
+		return fmt.Errorf("git worktree unlock %s: %w: %s", path, err, stderr)
+	}
+	return nil
+}
+
+// PruneWorktrees drops the bookkeeping for linked worktrees whose
+// directories are gone (the Prunable ones). Locked worktrees are kept.
+func (r Repo) PruneWorktrees() error {
+	return r.PruneWorktreesCtx(context.Background())
+}
+
+// PruneWorktreesCtx is PruneWorktrees with a context.
+func (r Repo) PruneWorktreesCtx(ctx context.Context) error {
+	_, stderr, err := r.runWrite(ctx, "worktree", "prune")
+	if err != nil {
+		return fmt.Errorf("git worktree prune: %w: %s", err, stderr)
+	}
+	return nil
+}
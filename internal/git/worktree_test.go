@@ -0,0 +1,152 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func TestParseWorktrees(t *testing.T) {
+	t.Parallel()
+	const h1, h2 = "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222"
+	records := [][]string{
+		{"worktree /src/repo", "HEAD " + h1, "branch refs/heads/main"},
+		{"worktree /src/wt [feature]", "HEAD " + h2, "branch refs/heads/feature", "locked on a usb stick"},
+		{"worktree /tmp/gone", "HEAD " + h1, "detached", "prunable gitdir file points to non-existent location"},
+		{"worktree /tmp/plain lock", "HEAD " + h1, "detached", "locked"},
+	}
+	want := []Worktree{
+		{Path: "/src/repo", Head: h1, Branch: "main", Main: true},
+		{Path: "/src/wt [feature]", Head: h2, Branch: "feature", Locked: true, LockReason: "on a usb stick"},
+		{Path: "/tmp/gone", Head: h1, Detached: true, Prunable: true, PrunableReason: "gitdir file points to non-existent location"},
+		{Path: "/tmp/plain lock", Head: h1, Detached: true, Locked: true},
+	}
+
+	for _, tc := range []struct {
+		name string
+		sep  string
+	}{
+		{"nul", "\x00"},
+		{"newline", "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var b strings.Builder
+			for _, rec := range records {
+				for _, field := range rec {
+					b.WriteString(field + tc.sep)
+				}
+				b.WriteString(tc.sep)
+			}
+			got, err := parseWorktrees(b.String(), tc.sep)
+			require.NoError(t, err)
+			assert.EqualArrays(t, got, want)
+		})
+	}
+
+	t.Run("bare", func(t *testing.T) {
+		t.Parallel()
+		got, err := parseWorktrees("worktree /srv/repo.git\x00bare\x00\x00", "\x00")
+		require.NoError(t, err)
+		assert.EqualArrays(t, got, []Worktree{{Path: "/srv/repo.git", Main: true, Bare: true}})
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		t.Parallel()
+		_, err := parseWorktrees("HEAD "+h1+"\x00", "\x00")
+		assert.Error(t, err, assert.AnyError)
+	})
+}
+
+// realDir resolves symlinks in a temp dir so it compares equal to the
+// paths git reports.
+func realDir(t *testing.T) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	return dir
+}
+
+func findWorktree(t *testing.T, r Repo, path string) (Worktree, bool) {
+	t.Helper()
+	wts, err := r.Worktrees()
+	require.NoError(t, err)
+	for _, wt := range wts {
+		if wt.Path == path {
+			return wt, true
+		}
+	}
+	return Worktree{}, false
+}
+
+func TestWorktrees_Lifecycle(t *testing.T) {
+	t.Parallel()
+	r := Repo{Dir: temp_repo.NewRepo(t)}
+	temp_repo.RunGit(t, r.Dir, "branch", "feature")
+	// brackets in the path used to confuse the `worktree list` parsing
+	wt := filepath.Join(realDir(t), "wt [main]")
+
+	require.NoError(t, r.AddWorktree(wt, "feature"))
+	got, ok := findWorktree(t, r, wt)
+	require.That(t, ok, "new worktree should be listed")
+	assert.Equal(t, got.Branch, "feature")
+	assert.That(t, !got.Main, "linked worktree is not the main one")
+	checked, err := r.CheckedOutBranches()
+	require.NoError(t, err)
+	assert.Equal(t, checked["feature"], wt)
+
+	require.NoError(t, r.LockWorktree(wt, "in use"))
+	got, _ = findWorktree(t, r, wt)
+	assert.That(t, got.Locked, "should be locked")
+	assert.Equal(t, got.LockReason, "in use")
+	moved := filepath.Join(realDir(t), "moved")
+	assert.Error(t, r.MoveWorktree(wt, moved), assert.AnyError, "locked worktree should not move")
+
+	require.NoError(t, r.UnlockWorktree(wt))
+	require.NoError(t, r.MoveWorktree(wt, moved))
+	_, ok = findWorktree(t, r, moved)
+	assert.That(t, ok, "worktree should be at its new path")
+
+	require.NoError(t, os.WriteFile(filepath.Join(moved, "README.md"), []byte("changed\n"), 0o644))
+	assert.Error(t, r.RemoveWorktree(moved, false), assert.AnyError, "dirty worktree needs force")
+	require.NoError(t, r.RemoveWorktree(moved, true))
+	_, ok = findWorktree(t, r, moved)
+	assert.That(t, !ok, "removed worktree should be gone")
+}
+
+func TestAddWorktreeNewBranch(t *testing.T) {
+	t.Parallel()
+	r := Repo{Dir: temp_repo.NewRepo(t)}
+	wt := filepath.Join(realDir(t), "topic")
+
+	require.NoError(t, r.AddWorktreeNewBranch(wt, "topic", "HEAD"))
+	got, ok := findWorktree(t, r, wt)
+	require.That(t, ok, "new worktree should be listed")
+	assert.Equal(t, got.Branch, "topic")
+	assert.That(t, r.BranchExists("topic"), "branch should have been created")
+
+	err := r.AddWorktreeNewBranch(filepath.Join(realDir(t), "again"), "topic", "")
+	assert.Equal(t, KindOf(err), KindRefExists)
+}
+
+func TestPruneWorktrees(t *testing.T) {
+	t.Parallel()
+	r := Repo{Dir: temp_repo.NewRepo(t)}
+	wt := filepath.Join(realDir(t), "gone")
+	require.NoError(t, r.AddWorktree(wt, "HEAD"))
+	require.NoError(t, os.RemoveAll(wt))
+
+	got, ok := findWorktree(t, r, wt)
+	require.That(t, ok, "deleted worktree is still registered")
+	assert.That(t, got.Detached, "HEAD checks out detached")
+	assert.That(t, got.Prunable, "deleted worktree should be prunable")
+
+	require.NoError(t, r.PruneWorktrees())
+	_, ok = findWorktree(t, r, wt)
+	assert.That(t, !ok, "pruned worktree should be gone")
+}
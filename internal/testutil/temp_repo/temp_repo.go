@@ -1,9 +1,11 @@
 package temp_repo
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/lczyk/assert/require"
@@ -133,3 +135,80 @@ func NewRepoWithRemote(t *testing.T) (local, remote string) {
 	RunGit(t, local, "config", "core.hooksPath", ".git/hooks")
 	return local, remote
 }
+
+// NewRepoWithBranches returns a repo with n extra commits on a linear chain
+// past the initial one, local branch bNNNNN at the i-th of them, and
+// origin/bNNNNN one commit behind it as the configured upstream -- so every
+// branch is ahead 1, except b00001 whose upstream ref is gone. Built with
+// fast-import in one process, so 5k branches take well under a second;
+// meant for benchmarks of per-ref work.
+func NewRepoWithBranches(tb testing.TB, n int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	initRepoAt(tb, dir)
+
+	var stream, config strings.Builder
+	config.WriteString("[remote \"origin\"]\n\turl = /nonexistent\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n")
+	for i := 1; i <= n; i++ {
+		name := fmt.Sprintf("b%05d", i)
+		fmt.Fprintf(&stream, "commit refs/heads/%s\nmark :%d\ncommitter Test User <test@example.com> %d +0000\n", name, i, 1700000000+i)
+		fmt.Fprintf(&stream, "data %d\n%s\n", len("chore: "+name), "chore: "+name)
+		if i == 1 {
+			stream.WriteString("from refs/heads/main\n")
+		} else {
+			fmt.Fprintf(&stream, "from :%d\n", i-1)
+		}
+		fmt.Fprintf(&stream, "M 644 inline %s.txt\ndata %d\n%s\n\n", name, len(name), name)
+		if i > 1 {
+			fmt.Fprintf(&stream, "reset refs/remotes/origin/%s\nfrom :%d\n\n", name, i-1)
+		}
+		fmt.Fprintf(&config, "[branch %q]\n\tremote = origin\n\tmerge = refs/heads/%s\n", name, name)
+	}
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stream.String())
+	out, err := cmd.CombinedOutput()
+	require.NoError(tb, err, "fast-import: ", string(out))
+	RunGit(tb, dir, "pack-refs", "--all")
+
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(tb, err, "open config")
+	_, err = f.WriteString(config.String())
+	require.NoError(tb, err, "append config")
+	require.NoError(tb, f.Close(), "close config")
+	return dir
+}
+
+// NewRepoWithSubmodule returns (super, lib): super is a fresh repo with one
+// submodule checked out at super/lib, committed, and lib is the repo it
+// was cloned from. The submodule clone has the same test identity as
+// super, so tests can commit inside it to make it drift.
+//
+// Both dirs are t.TempDir-managed. Safe for parallel tests.
+func NewRepoWithSubmodule(t *testing.T) (super, lib string) {
+	t.Helper()
+	lib = t.TempDir()
+	initRepoAt(t, lib)
+	super = t.TempDir()
+	initRepoAt(t, super)
+	// local-path submodule clones are blocked by default since git 2.38.1
+	RunGit(t, super, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "lib")
+	RunGit(t, super, "commit", "-m", "chore: add lib submodule")
+	sub := filepath.Join(super, "lib")
+	RunGit(t, sub, "config", "user.name", "Test User")
+	RunGit(t, sub, "config", "user.email", "test@example.com")
+	RunGit(t, sub, "config", "commit.gpgsign", "false")
+	return super, lib
+}
+
+// BumpSubmodule commits a new file inside the submodule at super/path and
+// records the new commit in super on the current branch, returning the
+// submodule commit's oid.
+func BumpSubmodule(t *testing.T, super, path, filename string) string {
+	t.Helper()
+	sub := filepath.Join(super, path)
+	CreateCommit(t, sub, filename, filename+"\n", "feat: add "+filename)
+	RunGit(t, super, "add", path)
+	RunGit(t, super, "commit", "-m", "chore: bump "+path)
+	return strings.TrimSpace(RunGit(t, sub, "rev-parse", "HEAD"))
+}
@@ -60,6 +60,68 @@ func SelectStream(ctx context.Context, prompt string, src *ff.SliceSource, unsel
 	return selected[0], nil
 }
 
+// SelectGraph presents pre-rendered, ANSI-coloured commit-graph rows in
+// picker order (first row nearest the prompt) and returns the chosen rows
+// as passed in. isContext marks connector rows: they keep the graph
+// readable between adjacent matches but can't be chosen. multi enables
+// Tab to pick several rows.
+func SelectGraph(prompt string, rows []string, isContext func(string) bool, multi bool) ([]string, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no options provided")
+	}
+	opt := ff.Opt{Prompt: prompt + ": ", Ansi: true, Multi: multi, Unselectable: isContext, Context: isContext}
+	idxs, err := ff.Find(context.Background(), &rows, nil, opt)
+	if err != nil {
+		if errors.Is(err, ff.ErrAbort) {
+			return nil, ErrCancelled
+		}
+		return nil, fmt.Errorf("running picker: %w", err)
+	}
+	out := make([]string, len(idxs))
+	for i, idx := range idxs {
+		out[i] = rows[idx]
+	}
+	return out, nil
+}
+
+// SelectPreview is Select with a preview pane beside the options: preview
+// gives the (ANSI-coloured) text to show for the option under the cursor.
+// It takes the whole screen, to leave the pane room.
+func SelectPreview(prompt string, options []string, preview func(string) string) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("no options provided")
+	}
+	opt := ff.Opt{Prompt: prompt + ": ", Reverse: true, Preview: preview}
+	idxs, err := ff.Find(context.Background(), &options, nil, opt)
+	if err != nil {
+		if errors.Is(err, ff.ErrAbort) {
+			return "", ErrCancelled
+		}
+		return "", fmt.Errorf("running picker: %w", err)
+	}
+	return options[idxs[0]], nil
+}
+
+// SelectLines presents ANSI-coloured rows top-down -- the lines of a diff,
+// say -- with Tab to pick several, and returns the picked rows' indices,
+// so rows with the same text stay apart. isContext marks the rows shown
+// only to read the others by: they can't be picked, and the query keeps
+// them between matches the way SelectGraph does.
+func SelectLines(prompt string, rows []string, isContext func(string) bool) ([]int, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no options provided")
+	}
+	opt := ff.Opt{Prompt: prompt + ": ", Reverse: true, Ansi: true, Multi: true, Unselectable: isContext, Context: isContext}
+	idxs, err := ff.Find(context.Background(), &rows, nil, opt)
+	if err != nil {
+		if errors.Is(err, ff.ErrAbort) {
+			return nil, ErrCancelled
+		}
+		return nil, fmt.Errorf("running picker: %w", err)
+	}
+	return idxs, nil
+}
+
 func confirmWith(selector func(string, []string, ...string) (string, error), prompt string, defaultYes bool) (bool, error) {
 	options := []string{"yes", "no"}
 	if !defaultYes {
@@ -84,6 +146,9 @@ type Selector interface {
 	Select(prompt string, options []string, initialQuery ...string) (string, error)
 	SelectStream(ctx context.Context, prompt string, src *ff.SliceSource, unselectable func(string) bool) (string, error)
 	MultiSelect(prompt string, options []string) ([]string, error)
+	SelectGraph(prompt string, rows []string, isContext func(string) bool, multi bool) ([]string, error)
+	SelectPreview(prompt string, options []string, preview func(string) string) (string, error)
+	SelectLines(prompt string, rows []string, isContext func(string) bool) ([]int, error)
 	Confirm(prompt string, defaultYes bool) (bool, error)
 }
 
@@ -111,7 +176,8 @@ func MultiSelect(prompt string, options []string) ([]string, error) {
 }
 
 // RealSelector is the production Selector. Methods delegate to ui.Select,
-// ui.SelectStream, and ui.Confirm, which drive the real fuzzyfinder UI.
+// ui.SelectStream, ui.SelectGraph, ui.SelectPreview, ui.SelectLines and
+// ui.Confirm, which drive the real fuzzyfinder UI.
 type RealSelector struct{}
 
 func (RealSelector) Select(prompt string, options []string, initialQuery ...string) (string, error) {
@@ -126,6 +192,18 @@ func (RealSelector) MultiSelect(prompt string, options []string) ([]string, erro
 	return MultiSelect(prompt, options)
 }
 
+func (RealSelector) SelectGraph(prompt string, rows []string, isContext func(string) bool, multi bool) ([]string, error) {
+	return SelectGraph(prompt, rows, isContext, multi)
+}
+
+func (RealSelector) SelectPreview(prompt string, options []string, preview func(string) string) (string, error) {
+	return SelectPreview(prompt, options, preview)
+}
+
+func (RealSelector) SelectLines(prompt string, rows []string, isContext func(string) bool) ([]int, error) {
+	return SelectLines(prompt, rows, isContext)
+}
+
 func (RealSelector) Confirm(prompt string, defaultYes bool) (bool, error) {
 	return Confirm(prompt, defaultYes)
 }
@@ -73,7 +73,7 @@ func (c *CheckoutPRCommand) Execute(args []string) error {
 
 func (c *CheckoutPRCommand) getPRRefs(remote string) ([]PRRef, error) {
 	fmt.Fprintln(c.out(), "Fetching pull request references from remote:", remote)
-	stdout, err := c.repo().LsRemote(remote)
+	stdout, err := c.repo().LsRemoteCtx(c.ctx(), remote)
 	if err != nil {
 		return nil, fmt.Errorf("listing remote refs: %w", err)
 	}
@@ -151,7 +151,7 @@ func (c *CheckoutPRCommand) checkoutPR(remote string, prNumber int, prType strin
 		}
 
 		fmt.Fprintf(c.out(), "Fetching PR #%d from %s...\n", prNumber, remote)
-		if err := c.repo().Fetch(remote, prRef); err != nil {
+		if err := c.repo().FetchCtx(c.ctx(), remote, prRef); err != nil {
 			return fmt.Errorf("fetching PR: %w", err)
 		}
 
@@ -178,7 +178,7 @@ func (c *CheckoutPRCommand) checkoutPR(remote string, prNumber int, prType strin
 	defer cleanup()
 
 	fmt.Fprintf(c.out(), "Fetching PR #%d from %s...\n", prNumber, remote)
-	if err := c.repo().Fetch(remote, prRef); err != nil {
+	if err := c.repo().FetchCtx(c.ctx(), remote, prRef); err != nil {
 		return fmt.Errorf("fetching PR: %w", err)
 	}
 
@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lczyk/assert"
 	"github.com/lczyk/assert/require"
@@ -36,6 +38,23 @@ func TestCheckoutPRCommand_Execute_ChecksOutPR(t *testing.T) {
 	assert.ContainsString(t, stub.selectCalls[1].Prompt, "pull request")
 }
 
+// a remote that never answers fails the command once the network timeout
+// from the command's context runs out, instead of hanging it.
+func TestCheckoutPRCommand_Execute_SlowRemoteTimesOut(t *testing.T) {
+	t.Parallel()
+	local, _ := temp_repo.NewRepoWithRemote(t)
+	temp_repo.RunGit(t, local, "config", "remote.origin.uploadpack", "sleep 30; git-upload-pack")
+
+	stub := &stubSelector{selectAnswers: []string{"origin"}}
+	ctx := git.WithNetworkTimeout(context.Background(), 300*time.Millisecond)
+	cmd := &CheckoutPRCommand{cmdIO: cmdIO{Out: &strings.Builder{}, Err: &strings.Builder{}, UI: stub, Repo: git.Repo{Dir: local}, Ctx: ctx}}
+
+	start := time.Now()
+	err := cmd.Execute(nil)
+	assert.Error(t, err, git.ErrNetworkTimeout)
+	assert.That(t, time.Since(start) < 10*time.Second, "gave up after %s", time.Since(start))
+}
+
 func TestParsePRRefs(t *testing.T) {
 	cases := map[string]struct {
 		input    string
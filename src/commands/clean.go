@@ -2,14 +2,13 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/lczyk/gitgum/internal/git"
-	"github.com/lczyk/gitgum/internal/strutil"
 )
 
-const gitCleanDryRunPrefix = "Would remove "
-
 // CleanCommand handles discarding working tree changes and untracked files
 type CleanCommand struct {
 	cmdIO
@@ -18,6 +17,7 @@ type CleanCommand struct {
 	Ignored   *bool `long:"ignored" description:"Remove ignored files (default: false)"`
 	All       bool  `long:"all" description:"Enable all cleanup options"`
 	Yes       bool  `short:"y" long:"yes" description:"Skip confirmation prompt"`
+	Recurse   bool  `long:"recurse-submodules" description:"Also clean inside submodules, discarding their own changes and untracked files"`
 }
 
 func (c *CleanCommand) Execute(args []string) error {
@@ -46,10 +46,34 @@ func (c *CleanCommand) Execute(args []string) error {
 		return nil
 	}
 
-	affectedFiles, err := getAffectedFiles(r, changes, untracked, ignored)
+	changed, removed, err := getAffectedFiles(r, changes, untracked, ignored)
 	if err != nil {
 		return err
 	}
+	affectedFiles := append(changed, removed...)
+
+	subs, err := r.Submodules()
+	if err != nil {
+		return fmt.Errorf("listing submodules: %w", err)
+	}
+	var prefix string // the cwd below the top level, "" or ending in "/"
+	if len(subs) > 0 {
+		if prefix, _, err = r.Run("rev-parse", "--show-prefix"); err != nil {
+			return fmt.Errorf("finding the working directory in the worktree: %w", err)
+		}
+	}
+	dirtySubs := dirtySubmodules(subs, prefix, changes, untracked)
+	if !c.Recurse && len(dirtySubs) > 0 {
+		// reset and clean don't reach into submodules (we make sure of it
+		// below), so their changes stay put -- say so rather than imply
+		// the tree ends up clean
+		fmt.Fprintf(c.out(), "Leaving submodules with their own changes alone (use --recurse-submodules to clean them too): %s\n",
+			strings.Join(submodulePaths(dirtySubs), ", "))
+		dirtySubs = nil
+	}
+	for _, sm := range dirtySubs {
+		affectedFiles = append(affectedFiles, sm.Path+"/ (inside submodule)")
+	}
 
 	if len(affectedFiles) == 0 {
 		fmt.Fprintln(c.out(), "Nothing to clean (working tree is clean)")
@@ -68,7 +92,11 @@ func (c *CleanCommand) Execute(args []string) error {
 	fmt.Fprintln(c.out())
 
 	if !c.Yes {
-		confirmed, err := c.sel().Confirm("Proceed with cleanup? This cannot be undone", false)
+		prompt := "Proceed with cleanup? Changes can be restored with gg undo; untracked files cannot"
+		if len(dirtySubs) > 0 {
+			prompt = "Proceed with cleanup? Changes can be restored with gg undo, except inside submodules; untracked files cannot"
+		}
+		confirmed, err := c.sel().Confirm(prompt, false)
 		if err != nil {
 			return err
 		}
@@ -78,63 +106,142 @@ func (c *CleanCommand) Execute(args []string) error {
 		}
 	}
 
+	j := beginJournal(r, "clean", fmt.Sprintf("clean %d files", len(affectedFiles)))
+	defer j.record(c.err())
+
 	if changes {
+		if err := j.stashChanges(); err != nil {
+			fmt.Fprintf(c.err(), "warning: changes not saved for gg undo: %v\n", err)
+		}
 		fmt.Fprintln(c.out(), "Discarding changes...")
-		if _, stderr, err := r.RunWrite("reset", "--hard"); err != nil {
+		// explicit either way when there are submodules, so a
+		// submodule.recurse=true config can't decide for the user
+		args := []string{"reset", "--hard"}
+		switch {
+		case c.Recurse:
+			args = append(args, "--recurse-submodules")
+		case len(subs) > 0:
+			args = append(args, "--no-recurse-submodules")
+		}
+		if _, stderr, err := r.RunWrite(args...); err != nil {
 			return fmt.Errorf("failed to reset changes: %w: %s", err, strings.TrimSpace(stderr))
 		}
 	}
 
 	if untracked {
 		fmt.Fprintln(c.out(), "Removing untracked files...")
-		if _, stderr, err := r.RunWrite(gitCleanArgs(false, ignored)...); err != nil {
+		if _, stderr, err := r.RunWrite(gitCleanArgs(ignored)...); err != nil {
 			return fmt.Errorf("failed to clean untracked files: %w: %s", err, strings.TrimSpace(stderr))
 		}
+		if c.Recurse {
+			// git clean never descends into submodules itself, and
+			// submodule foreach visits all of them wherever it runs, so
+			// each one below the cwd gets its own pair of calls
+			for _, sm := range subs {
+				rel, ok := strings.CutPrefix(sm.Path, prefix)
+				if !ok || !sm.Initialized() {
+					continue
+				}
+				for _, args := range [][]string{
+					append([]string{"-C", rel}, gitCleanArgs(ignored)...),
+					append([]string{"-C", rel, "submodule", "--quiet", "foreach", "--recursive", "git"}, gitCleanArgs(ignored)...),
+				} {
+					if _, stderr, err := r.RunWrite(args...); err != nil {
+						return fmt.Errorf("failed to clean untracked files in submodule %s: %w: %s", sm.Path, err, strings.TrimSpace(stderr))
+					}
+				}
+			}
+		}
+		j.entry.Removed = removed
 	}
 
 	fmt.Fprintln(c.out(), "Clean complete")
 	return nil
 }
 
-func getAffectedFiles(r git.Repo, changes, untracked, ignored bool) ([]string, error) {
-	var affectedFiles []string
-
+// getAffectedFiles lists what the cleanup will touch: each changed
+// tracked path in the worktree once (even if both staged and unstaged),
+// and separately the untracked and ignored paths git clean will remove,
+// which are only those below the working directory.
+func getAffectedFiles(r git.Backend, changes, untracked, ignored bool) (changed, removed []string, err error) {
 	if changes {
-		stdout, _, err := r.Run("diff", "--name-only")
+		st, err := r.Status()
 		if err != nil {
-			return nil, fmt.Errorf("listing modified files: %w", err)
+			return nil, nil, fmt.Errorf("listing changes: %w", err)
 		}
-		affectedFiles = append(affectedFiles, strutil.SplitLines(stdout)...)
-
-		stdout, _, err = r.Run("diff", "--cached", "--name-only")
-		if err != nil {
-			return nil, fmt.Errorf("listing staged files: %w", err)
+		for _, e := range st.Entries {
+			if !e.Tracked() {
+				continue
+			}
+			// a submodule whose own contents changed, but not the commit
+			// it's at: that's for dirtySubmodules, reset won't touch it
+			if e.IsSubmodule() && e.Sub[1] != 'C' && e.X == '.' {
+				continue
+			}
+			if e.Kind == git.EntryRenamed {
+				changed = append(changed, e.Orig+" -> "+e.Path)
+				continue
+			}
+			changed = append(changed, e.Path)
 		}
-		affectedFiles = append(affectedFiles, strutil.SplitLines(stdout)...)
+	}
+	if !untracked {
+		return changed, removed, nil
 	}
 
-	if untracked {
-		stdout, _, err := r.Run(gitCleanArgs(true, ignored)...)
-		if err != nil {
-			return nil, fmt.Errorf("listing untracked files: %w", err)
+	st, err := r.StatusWith(git.StatusOptions{Ignored: ignored, Paths: []string{"."}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing untracked files: %w", err)
+	}
+	top, _, err := r.Run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, nil, fmt.Errorf("finding the top of the worktree: %w", err)
+	}
+	for _, e := range st.Entries {
+		if e.Kind != git.EntryUntracked && !(e.Kind == git.EntryIgnored && ignored) {
+			continue
 		}
-		for _, line := range strutil.SplitLines(stdout) {
-			if trimmed, ok := strings.CutPrefix(line, gitCleanDryRunPrefix); ok {
-				affectedFiles = append(affectedFiles, trimmed)
-			}
+		// git clean -fd leaves nested repositories alone
+		if strings.HasSuffix(e.Path, "/") && isNestedRepo(top, e.Path) {
+			continue
 		}
+		removed = append(removed, e.Path)
 	}
+	return changed, removed, nil
+}
 
-	return affectedFiles, nil
+// dirtySubmodules picks the submodules with changes of their own that a
+// cleanup of the given kinds would discard. Like the superproject's, their
+// tracked changes go worktree-wide but untracked files only count below
+// prefix, the cwd relative to the top level.
+func dirtySubmodules(subs []git.Submodule, prefix string, changes, untracked bool) []git.Submodule {
+	var dirty []git.Submodule
+	for _, sm := range subs {
+		if sm.Modified && changes || sm.Untracked && untracked && strings.HasPrefix(sm.Path, prefix) {
+			dirty = append(dirty, sm)
+		}
+	}
+	return dirty
 }
 
-// gitCleanArgs builds git clean args. dry-run appends -n; ignored adds -x.
-func gitCleanArgs(dryRun, ignored bool) []string {
-	flags := "-fd"
-	if dryRun {
-		flags += "n"
+func submodulePaths(subs []git.Submodule) []string {
+	paths := make([]string, len(subs))
+	for i, sm := range subs {
+		paths[i] = sm.Path
 	}
-	args := []string{"clean", flags}
+	return paths
+}
+
+// isNestedRepo reports whether dir, relative to the top of the worktree,
+// is a repository of its own.
+func isNestedRepo(top, dir string) bool {
+	_, err := os.Stat(filepath.Join(top, dir, ".git"))
+	return err == nil
+}
+
+// gitCleanArgs builds git clean args; ignored adds -x.
+func gitCleanArgs(ignored bool) []string {
+	args := []string{"clean", "-fd"}
 	if ignored {
 		args = append(args, "-x")
 	}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -161,3 +163,108 @@ func TestCleanCommand_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAffectedFiles(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\n", "add a")
+	temp_repo.WriteFile(t, dir, ".gitignore", "*.log\n")
+	temp_repo.WriteFile(t, dir, "README.md", "staged\n")
+	temp_repo.RunGit(t, dir, "add", "README.md")
+	temp_repo.WriteFile(t, dir, "README.md", "and unstaged\n")
+	temp_repo.RunGit(t, dir, "mv", "a.txt", "b.txt")
+	temp_repo.WriteFile(t, dir, "x.log", "log\n")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0o755))
+	temp_repo.RunGit(t, filepath.Join(dir, "nested"), "init", "-q")
+
+	r := git.Repo{Dir: dir}
+	changed, removed, err := getAffectedFiles(r, true, true, false)
+	require.NoError(t, err)
+	// README.md once despite MM; nested repo skipped like git clean -fd does
+	assert.EqualArrays(t, changed, []string{"README.md", "a.txt -> b.txt"})
+	assert.EqualArrays(t, removed, []string{".gitignore"})
+
+	changed, removed, err = getAffectedFiles(r, false, true, true)
+	require.NoError(t, err)
+	assert.That(t, len(changed) == 0, "changes disabled: %v", changed)
+	assert.EqualArrays(t, removed, []string{".gitignore", "x.log"})
+}
+
+func TestCleanCommand_FromSubdir(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	temp_repo.WriteFile(t, dir, "sub/keep.txt", "tracked\n")
+	temp_repo.RunGit(t, dir, "add", "sub/keep.txt")
+	temp_repo.RunGit(t, dir, "commit", "-m", "add sub")
+	temp_repo.WriteFile(t, dir, "README.md", "modified\n")
+	temp_repo.WriteFile(t, dir, "top.txt", "untracked\n")
+	temp_repo.WriteFile(t, dir, "sub/scratch.txt", "untracked\n")
+
+	// changes go worktree-wide, like reset --hard; untracked files only
+	// below the cwd, like git clean, and the listing says just that
+	var out bytes.Buffer
+	cmd := &CleanCommand{Yes: true, cmdIO: cmdIO{Out: &out, Repo: git.Repo{Dir: filepath.Join(dir, "sub")}}}
+	require.NoError(t, cmd.Execute(nil))
+	assert.ContainsString(t, out.String(), "Files to be discarded (2):\n  README.md\n  sub/scratch.txt\n")
+	fileContent(t, dir, "README.md", "# test repo\n")
+	fileNotExists(t, dir, "sub/scratch.txt")
+	fileExists(t, dir, "top.txt")
+	fileExists(t, dir, "sub/keep.txt")
+}
+
+func TestCleanCommand_Submodules(t *testing.T) {
+	t.Parallel()
+	dirty := func(t *testing.T) string {
+		t.Helper()
+		super, _ := temp_repo.NewRepoWithSubmodule(t)
+		temp_repo.WriteFile(t, super, "README.md", "modified\n")
+		temp_repo.WriteFile(t, super, "lib/README.md", "modified\n")
+		temp_repo.WriteFile(t, super, "lib/scratch.txt", "scratch\n")
+		return super
+	}
+
+	t.Run("left alone without --recurse-submodules", func(t *testing.T) {
+		t.Parallel()
+		super := dirty(t)
+		var out bytes.Buffer
+		cmd := &CleanCommand{Yes: true, cmdIO: cmdIO{Out: &out, Repo: git.Repo{Dir: super}}}
+		require.NoError(t, cmd.Execute(nil))
+
+		assert.ContainsString(t, out.String(), "Leaving submodules with their own changes alone")
+		fileContent(t, super, "README.md", "# test repo\n")
+		fileContent(t, super, "lib/README.md", "modified\n")
+		fileExists(t, super, "lib/scratch.txt")
+	})
+
+	t.Run("cleaned with --recurse-submodules", func(t *testing.T) {
+		t.Parallel()
+		super := dirty(t)
+		cmd := &CleanCommand{Yes: true, Recurse: true, cmdIO: cmdIO{Out: io.Discard, Repo: git.Repo{Dir: super}}}
+		require.NoError(t, cmd.Execute(nil))
+
+		fileContent(t, super, "README.md", "# test repo\n")
+		fileContent(t, super, "lib/README.md", "# test repo\n")
+		fileNotExists(t, super, "lib/scratch.txt")
+	})
+}
+
+func TestCleanCommand_SubmodulesFromSubdir(t *testing.T) {
+	t.Parallel()
+	super, lib := temp_repo.NewRepoWithSubmodule(t)
+	temp_repo.RunGit(t, super, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "sub/inner")
+	temp_repo.RunGit(t, super, "commit", "-m", "chore: add sub/inner")
+	for _, sm := range []string{"lib", "sub/inner"} {
+		temp_repo.WriteFile(t, super, sm+"/README.md", "modified\n")
+		temp_repo.WriteFile(t, super, sm+"/scratch.txt", "scratch\n")
+	}
+
+	// as in the superproject: tracked changes go everywhere, untracked
+	// files only below the cwd
+	cmd := &CleanCommand{Yes: true, Recurse: true, cmdIO: cmdIO{Out: io.Discard, Repo: git.Repo{Dir: filepath.Join(super, "sub")}}}
+	require.NoError(t, cmd.Execute(nil))
+	fileContent(t, super, "lib/README.md", "# test repo\n")
+	fileExists(t, super, "lib/scratch.txt")
+	fileContent(t, super, "sub/inner/README.md", "# test repo\n")
+	fileNotExists(t, super, "sub/inner/scratch.txt")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/lczyk/gitgum/internal/git"
 	"github.com/lczyk/gitgum/internal/ui"
 )
 
@@ -90,7 +91,7 @@ func (d *DeleteCommand) Execute(args []string) error {
 		}
 
 		if _, stderr, err := d.repo().RunWriteStream("checkout", otherBranch); err != nil {
-			return fmt.Errorf("switching to branch '%s': %w: %s", otherBranch, err, strings.TrimSpace(stderr))
+			return explainGitError(fmt.Errorf("switching to branch '%s': %w: %s", otherBranch, err, strings.TrimSpace(stderr)))
 		}
 		fmt.Fprintf(d.out(), "Switched to branch '%s'.\n", otherBranch)
 	}
@@ -111,9 +112,19 @@ func (d *DeleteCommand) Execute(args []string) error {
 		needsToDeleteRemote = confirmed
 	}
 
+	j := beginJournal(d.repo(), "delete", fmt.Sprintf("delete branch '%s'", branch), "refs/heads/"+branch)
+	if remoteName != "" && remoteBranchName != "" {
+		j.entry.Upstream = remoteName + "/" + remoteBranchName
+	}
+	defer j.record(d.err())
+
 	// try safe delete first, fall back to force delete with confirmation
-	_, _, err = d.repo().RunWrite("branch", "-d", branch)
+	// only when git refused because of unmerged commits
+	_, stderr, err := d.repo().RunWrite("branch", "-d", branch)
 	if err != nil {
+		if git.KindOf(err) != git.KindNotFullyMerged {
+			return explainGitError(fmt.Errorf("deleting branch '%s': %w: %s", branch, err, strings.TrimSpace(stderr)))
+		}
 		var confirmMsg string
 		if needsToDeleteRemote {
 			confirmMsg = fmt.Sprintf("Branch '%s' is not fully merged. Do you want to force delete the local branch and the remote branch?", branch)
@@ -139,8 +150,20 @@ func (d *DeleteCommand) Execute(args []string) error {
 	}
 
 	if needsToDeleteRemote {
-		if _, stderr, err := d.repo().RunWriteStream("push", "--delete", remoteName, remoteBranchName); err != nil {
-			return fmt.Errorf("deleting remote branch: %w: %s", err, strings.TrimSpace(stderr))
+		// the remote-tracking ref goes with the remote branch; read it first
+		remoteTip, _ := d.repo().GetCommitHash("refs/remotes/" + remoteName + "/" + remoteBranchName)
+		_, stderr, err := d.repo().RunWriteStreamCtx(d.ctx(), "push", "--delete", remoteName, remoteBranchName)
+		switch {
+		case git.KindOf(err) == git.KindBadRef:
+			// someone (or the forge's auto-delete on merge) got there first
+			fmt.Fprintf(d.out(), "Remote branch '%s/%s' was already deleted.\n", remoteName, remoteBranchName)
+			return nil
+		case err != nil:
+			return explainGitError(fmt.Errorf("deleting remote branch: %w: %s", err, strings.TrimSpace(stderr)))
+		}
+		if remoteTip != "" {
+			j.entry.Summary = fmt.Sprintf("delete branch '%s' and '%s/%s'", branch, remoteName, remoteBranchName)
+			j.entry.Pushed = append(j.entry.Pushed, git.RemoteUpdate{Remote: remoteName, Name: "refs/heads/" + remoteBranchName, Old: remoteTip})
 		}
 		fmt.Fprintf(d.out(), "Deleted remote branch '%s/%s'.\n", remoteName, remoteBranchName)
 	}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -52,3 +53,39 @@ func TestDeleteCommand_DeletesPickedBranch(t *testing.T) {
 	assert.ContainsString(t, buf.String(), "Deleted local branch 'feature'.")
 	assert.Equal(t, len(stub.confirmCalls), 0)
 }
+
+// the tracked remote branch is already gone (deleted on merge by the
+// forge, say): deleting it is a no-op rather than an error.
+func TestDeleteCommand_RemoteAlreadyDeleted(t *testing.T) {
+	t.Parallel()
+	local, remote := temp_repo.NewRepoWithRemote(t)
+	temp_repo.RunGit(t, local, "checkout", "-q", "-b", "feature")
+	temp_repo.RunGit(t, local, "push", "-q", "-u", "origin", "feature")
+	temp_repo.RunGit(t, local, "checkout", "-q", "main")
+	temp_repo.RunGit(t, remote, "branch", "-D", "feature")
+
+	var buf strings.Builder
+	stub := &stubSelector{selectAnswers: []string{"feature"}, confirmAnswers: []bool{true}}
+	cmd := &DeleteCommand{cmdIO: cmdIO{Out: &buf, UI: stub, Repo: git.Repo{Dir: local}}}
+	require.NoError(t, cmd.Execute(nil))
+
+	assert.ContainsString(t, buf.String(), "Deleted local branch 'feature'.")
+	assert.ContainsString(t, buf.String(), "Remote branch 'origin/feature' was already deleted.")
+}
+
+// branch -d failing for a reason other than unmerged commits surfaces the
+// error instead of offering a force delete that would fail the same way.
+func TestDeleteCommand_OtherFailureDoesNotOfferForce(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.RunGit(t, dir, "branch", "feature")
+	temp_repo.RunGit(t, dir, "worktree", "add", "-q", filepath.Join(t.TempDir(), "wt"), "feature")
+
+	stub := &stubSelector{selectAnswers: []string{"feature"}}
+	cmd := &DeleteCommand{cmdIO: cmdIO{UI: stub, Repo: git.Repo{Dir: dir}}}
+	err := cmd.Execute(nil)
+
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "deleting branch 'feature'")
+	assert.Equal(t, len(stub.confirmCalls), 0)
+}
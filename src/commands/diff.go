@@ -7,10 +7,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/lczyk/gitgum/internal/git"
 	"github.com/lczyk/gitgum/src/litescreen"
 )
 
@@ -30,26 +32,179 @@ type DiffCommand struct {
 	cmdIO
 	Follow *float64 `long:"follow" short:"f" optional:"yes" optional-value:"2" description:"follow mode: refresh every N seconds (default 2, min 1)"`
 	Mode   string   `long:"mode" short:"m" description:"lock to a diff level: work (unstaged), index (staged), untracked, head (last commit). default: auto-cascade over work/index/untracked/head"`
+	PR     bool     `long:"pr" description:"show what a pull request from HEAD would contain: HEAD against its merge-base with the default branch"`
+
+	Split         bool `long:"split" description:"show old and new lines side by side"`
+	SplitMinWidth int  `long:"split-min-width" default:"120" description:"terminal width below which --split falls back to unified output"`
+	Wrap          bool `long:"wrap" description:"with --split, wrap long lines instead of cutting them off"`
+	// Intraline is how finely changed lines are compared with the lines
+	// they replace, to pick out what changed in them.
+	Intraline string `long:"intraline" default:"word" choice:"word" choice:"char" choice:"off" description:"highlight what changed within changed lines: word, char or off"`
+	Syntax    string `long:"syntax" default:"on" choice:"on" choice:"off" description:"colour code in hunks by its syntax, for the file types gg knows"`
+
+	refiner     *refiner
+	highlighter *syntaxHighlighter
+
+	// rangeLevel is the extra level a revision range or --pr adds, named
+	// as typed ("main..topic"), or "pr"; "" for none. rangeArgs are its
+	// revisions for git diff.
+	rangeLevel string
+	rangeArgs  []string
+	paths      []string // pathspecs narrowing every level
 }
 
 func (d *DiffCommand) Execute(args []string) error {
 	if err := d.repo().CheckInRepo(); err != nil {
 		return err
 	}
-	if len(args) > 0 {
-		return fmt.Errorf("diff takes no arguments")
-	}
 	if d.Mode != "" && d.Mode != "work" && d.Mode != "index" && d.Mode != "head" && d.Mode != "untracked" {
 		return fmt.Errorf("--mode must be one of: work, index, head, untracked")
 	}
+	if err := d.parseArgs(args); err != nil {
+		return err
+	}
+	if d.rangeLevel != "" && d.Mode != "" {
+		return fmt.Errorf("--mode can't be combined with a revision range or --pr")
+	}
 	if d.Follow != nil {
 		return d.runFollow()
 	}
 	return d.render(d.out())
 }
 
+// KeepsDoubleDash has main hand Execute the "--" go-flags would drop, so
+// parseArgs can tell paths from revisions.
+func (d *DiffCommand) KeepsDoubleDash() {}
+
+// parseArgs reads gg diff's arguments: an optional revision range, then
+// pathspecs. As in git diff, everything after a "--" is a path, and
+// anything before it a revision. Without one, the first argument is the
+// range if it names a commit (A, diffed against the worktree) or two
+// (A..B, or A...B from their merge-base), and a path otherwise.
+func (d *DiffCommand) parseArgs(args []string) error {
+	d.rangeLevel, d.rangeArgs = "", nil
+	var paths []string
+	dashed := false
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, paths, dashed = args[:i], args[i+1:], true
+	}
+	if d.PR {
+		base, err := d.prBase()
+		if err != nil {
+			return fmt.Errorf("--pr: %w", err)
+		}
+		d.rangeLevel, d.rangeArgs = "pr", []string{base + "...HEAD"}
+	} else if len(args) > 0 {
+		arg := args[0]
+		switch {
+		case d.isRevisionRange(arg):
+			d.rangeLevel, d.rangeArgs = arg, []string{arg}
+			if slices.Contains(diffModes, arg) {
+				// a branch called "head" mustn't take over the head tab
+				d.rangeLevel = arg + " (revision)"
+			}
+			args = args[1:]
+		case dashed:
+			return fmt.Errorf("%s: unknown revision", arg)
+		case strings.Contains(arg, ".."):
+			// a range that didn't resolve, unless it's a path like ../x:
+			// rev-parse takes an existing path and rejects anything else
+			if _, _, err := d.repo().Run("rev-parse", arg); err != nil {
+				return fmt.Errorf("%s: unknown revision or path not in the working tree", arg)
+			}
+		}
+	}
+	if dashed && len(args) > 0 {
+		return fmt.Errorf("%s: only a revision range goes before --", args[0])
+	}
+	d.paths = slices.Concat(args, paths)
+	return nil
+}
+
+// prBase is the branch a pull request from HEAD goes into: the default
+// branch as a remote has it, origin first, since the local one can be
+// behind it or not exist at all; the local one when no remote has it.
+func (d *DiffCommand) prBase() (string, error) {
+	r := d.repo()
+	branch, err := r.GetDefaultBranch()
+	if err != nil {
+		return "", err
+	}
+	remotes, err := r.GetRemotes()
+	if err != nil {
+		return "", err
+	}
+	if i := slices.Index(remotes, "origin"); i > 0 {
+		remotes = append([]string{"origin"}, slices.Delete(remotes, i, i+1)...)
+	}
+	for _, remote := range remotes {
+		ref := "refs/remotes/" + remote + "/" + branch
+		if _, err := r.GetCommitHash(ref); err == nil {
+			return ref, nil
+		}
+	}
+	return branch, nil
+}
+
+// isRevisionRange reports whether arg is a commit, or a range A..B or
+// A...B whose sides are commits. An empty side is HEAD, as in git.
+func (d *DiffCommand) isRevisionRange(arg string) bool {
+	sides := []string{arg}
+	for _, dots := range []string{"...", ".."} {
+		if a, b, ok := strings.Cut(arg, dots); ok {
+			sides = []string{a, b}
+			break
+		}
+	}
+	named := false
+	for _, side := range sides {
+		if side == "" {
+			continue
+		}
+		if _, err := d.repo().GetCommitHash(side + "^{commit}"); err != nil {
+			return false
+		}
+		named = true
+	}
+	return named
+}
+
+// lockedLevel is the one level to show, rather than the cascade's first
+// with anything in it: a revision range's, or --mode's. "" for none.
+func (d *DiffCommand) lockedLevel() string {
+	if d.rangeLevel != "" {
+		return d.rangeLevel
+	}
+	return d.Mode
+}
+
+// levels are the levels --follow has tabs for: the cascade's, then the
+// range's, if any.
+func (d *DiffCommand) levels() []string {
+	if d.rangeLevel == "" {
+		return diffModes
+	}
+	return append(slices.Clip(diffModes), d.rangeLevel)
+}
+
+// diffArgs is args with the pathspecs, if any, after a "--".
+func (d *DiffCommand) diffArgs(args ...string) []string {
+	if len(d.paths) == 0 {
+		return args
+	}
+	return append(append(slices.Clip(args), "--"), d.paths...)
+}
+
+// emptyLevelMessage is what --follow shows for a level with nothing in it.
+func (d *DiffCommand) emptyLevelMessage(level string) string {
+	if msg, ok := emptyModeMessages[level]; ok {
+		return msg
+	}
+	return "(no changes in " + strings.Join(d.rangeArgs, " ") + ")"
+}
+
 func (d *DiffCommand) render(w io.Writer) error {
-	if d.Mode == "untracked" {
+	if d.lockedLevel() == "untracked" {
 		return d.renderUntracked(w)
 	}
 	out, level, err := d.collectOutput()
@@ -60,9 +215,6 @@ func (d *DiffCommand) render(w io.Writer) error {
 		return nil
 	}
 	fmt.Fprintln(w, dim("--- "+level+" ---"))
-	if os.Getenv("GG_DIFF_NATIVE") == "1" {
-		return d.renderCollected(w, out)
-	}
 	fmt.Fprintln(w, out)
 	return nil
 }
@@ -81,24 +233,32 @@ func (d *DiffCommand) renderUntracked(w io.Writer) error {
 }
 
 func (d *DiffCommand) collectUntrackedEntries() ([]changeEntry, error) {
-	out, _, err := d.repo().Run("ls-files", "--others", "--exclude-standard", "-z")
+	st, err := d.repo().StatusWith(git.StatusOptions{UntrackedAll: true, Paths: d.paths})
 	if err != nil {
-		return nil, fmt.Errorf("git ls-files: %w", err)
-	}
-	if out == "" {
-		return nil, nil
+		return nil, err
 	}
-	paths := strings.Split(strings.TrimRight(out, "\x00"), "\x00")
+	return d.untrackedEntries(st), nil
+}
+
+// untrackedEntries picks the untracked files out of st (listed with
+// UntrackedAll, so one entry per file) and counts their lines.
+func (d *DiffCommand) untrackedEntries(st git.Status) []changeEntry {
 	var entries []changeEntry
-	for _, p := range paths {
-		if p == "" {
+	var root string
+	for _, e := range st.Entries {
+		if e.Kind != git.EntryUntracked {
 			continue
 		}
-		full := filepath.Join(d.repo().Dir, p)
+		if root == "" {
+			// status paths are relative to the top level, not the cwd;
+			// on error, fall back to reading them from the cwd
+			root, _ = repoRoot(d.repo())
+		}
+		full := filepath.Join(root, e.Path)
 		ns := countUntrackedLines(full, untrackedCountTimeout)
-		entries = append(entries, changeEntry{code: "??", path: p, numstat: &ns})
+		entries = append(entries, changeEntry{code: "??", path: e.Path, numstat: &ns})
 	}
-	return entries, nil
+	return entries
 }
 
 // countUntrackedLines reads path with a hard deadline. It detects binary
@@ -154,6 +314,50 @@ func doCountUntrackedLines(path string) numstat {
 }
 
 func (d *DiffCommand) collectDiff(level string) (string, error) {
+	p, out, err := d.collectLevel(level)
+	if err != nil || p == nil {
+		return out, err
+	}
+	return p.render(d.view(stdoutWidth())), nil
+}
+
+// view is how the command's flags draw a diff on a terminal width columns
+// wide: --split only once it's at least --split-min-width.
+func (d *DiffCommand) view(width int) diffView {
+	return diffView{split: d.Split && width >= d.SplitMinWidth, width: width, wrap: d.Wrap, refine: d.intraline(), highlight: d.highlight()}
+}
+
+// collectLevel fetches one level of the cascade. Tracked levels come back
+// parsed for the native renderer to draw at whatever width; the untracked
+// tree and the GG_DIFF_NATIVE=0 summaries come back as finished text.
+func (d *DiffCommand) collectLevel(level string) (*parsedDiff, string, error) {
+	if diffNative() {
+		switch level {
+		case "work":
+			p, err := d.parseDiff(true)
+			return p, "", err
+		case "index":
+			p, err := d.parseDiff(false, "--cached")
+			return p, "", err
+		case "head":
+			p, err := d.parseDiff(false, "HEAD~1..HEAD")
+			if err != nil {
+				// no HEAD~1: a single commit has nothing to diff against
+				return nil, "", nil
+			}
+			return p, "", nil
+		case d.rangeLevel:
+			p, err := d.parseDiff(false, d.rangeArgs...)
+			return p, "", err
+		}
+	}
+	out, err := d.collectSummary(level)
+	return nil, out, err
+}
+
+// collectSummary is the text form of a level: git's --compact-summary for
+// the tracked ones, the file tree for untracked.
+func (d *DiffCommand) collectSummary(level string) (string, error) {
 	colorFlag := "--color=never"
 	if colorEnabled() {
 		colorFlag = "--color=always"
@@ -167,51 +371,136 @@ func (d *DiffCommand) collectDiff(level string) (string, error) {
 		}
 		return " " + s
 	}
+	summary := func(revs ...string) (string, string, error) {
+		args := append([]string{"diff", "--compact-summary", colorFlag}, revs...)
+		return d.repo().Run(d.diffArgs(args...)...)
+	}
 	switch level {
 	case "work":
-		out, _, err := d.repo().Run("diff", "--compact-summary", colorFlag)
+		out, _, err := summary()
 		if err != nil {
 			return "", fmt.Errorf("git diff: %w", err)
 		}
-		return restore(out), nil
+		return restore(out) + d.submoduleSummary(out), nil
 	case "index":
-		out, _, err := d.repo().Run("diff", "--cached", "--compact-summary", colorFlag)
+		out, _, err := summary("--cached")
 		if err != nil {
 			return "", fmt.Errorf("git diff --cached: %w", err)
 		}
-		return restore(out), nil
+		return restore(out) + d.submoduleSummary(out, "--cached"), nil
 	case "head":
-		out, _, err := d.repo().Run("diff", "--compact-summary", colorFlag, "HEAD~1..HEAD")
+		out, _, err := summary("HEAD~1..HEAD")
 		if err != nil {
 			return "", nil
 		}
-		return restore(out), nil
+		return restore(out) + d.submoduleSummary(out, "HEAD~1..HEAD"), nil
 	case "untracked":
 		entries, err := d.collectUntrackedEntries()
 		if err != nil {
 			return "", err
 		}
-		if len(entries) == 0 {
-			return "", nil
+		return untrackedTree(entries), nil
+	case d.rangeLevel:
+		out, _, err := summary(d.rangeArgs...)
+		if err != nil {
+			return "", fmt.Errorf("git diff %s: %w", strings.Join(d.rangeArgs, " "), err)
 		}
-		var buf bytes.Buffer
-		renderTree(buildTree(entries), &buf)
-		return strings.TrimRight(buf.String(), "\n"), nil
+		return restore(out) + d.submoduleSummary(out, d.rangeArgs...), nil
 	default:
 		return "", fmt.Errorf("unknown diff level: %s", level)
 	}
 }
 
-// collectOutput runs the cascade (work -> index -> untracked -> head) unless Mode is set,
-// in which case it returns that level directly. returns (output, level, error).
+// submoduleSummary lists the submodule pointer changes in `git diff
+// args...` below its compact summary, which only shows them as a changed
+// path. "" when summary is empty or nothing moved.
+func (d *DiffCommand) submoduleSummary(summary string, args ...string) string {
+	if len(d.paths) > 0 {
+		// nothing here says which submodules the pathspecs take in
+		return ""
+	}
+	lines := d.submoduleChanges(args...)
+	if summary == "" || len(lines) == 0 {
+		return ""
+	}
+	return "\n\n " + strings.Join(lines, "\n ")
+}
+
+// submoduleChanges describes each submodule pointer change in `git diff
+// args...`. A failed lookup just leaves them out.
+func (d *DiffCommand) submoduleChanges(args ...string) []string {
+	changes, err := d.repo().SubmoduleDiff(args...)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, describeSubmoduleChange(c))
+	}
+	return lines
+}
+
+// describeSubmoduleChange formats one pointer change:
+//
+//	submodule lib: 1a2b3c4d5e6f -> 9f8e7d6c5b4a (+2 -1 commits)
+func describeSubmoduleChange(c git.SubmoduleChange) string {
+	line := "submodule " + c.Path + ": "
+	switch {
+	case c.Old == "":
+		return line + "added at " + shortOid(c.New)
+	case c.New == "":
+		return line + "removed (was " + shortOid(c.Old) + ")"
+	}
+	line += shortOid(c.Old) + " -> " + shortOid(c.New)
+	if !c.Counted {
+		return line
+	}
+	var counts []string
+	if c.Ahead > 0 {
+		counts = append(counts, paint(ansiGreen, fmt.Sprintf("+%d", c.Ahead)))
+	}
+	if c.Behind > 0 {
+		counts = append(counts, paint(ansiRed, fmt.Sprintf("-%d", c.Behind)))
+	}
+	noun := "commits"
+	if c.Ahead+c.Behind == 1 {
+		noun = "commit"
+	}
+	return line + " (" + strings.Join(counts, " ") + " " + noun + ")"
+}
+
+// untrackedTree renders untracked entries for the cascade; "" when none.
+func untrackedTree(entries []changeEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	renderTree(buildTree(entries), &buf)
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// collectOutput runs the cascade (work -> index -> untracked -> head) unless a level is locked
+// (--mode, a revision range), in which case it returns that level directly.
+// returns (output, level, error).
+// One status call up front tells which levels have anything to show, so
+// the cascade only runs the diff it's going to print.
 func (d *DiffCommand) collectOutput() (string, string, error) {
-	if d.Mode != "" {
-		out, err := d.collectDiff(d.Mode)
-		return out, d.Mode, err
+	if level := d.lockedLevel(); level != "" {
+		out, err := d.collectDiff(level)
+		return out, level, err
+	}
+	st, err := d.repo().StatusWith(git.StatusOptions{UntrackedAll: true, Paths: d.paths})
+	if err != nil {
+		return "", "", err
 	}
 	for _, level := range diffModes {
-		out, err := d.collectDiff(level)
-		if err != nil {
+		if levelClean(level, st) {
+			continue
+		}
+		var out string
+		if level == "untracked" {
+			out = untrackedTree(d.untrackedEntries(st))
+		} else if out, err = d.collectDiff(level); err != nil {
 			return "", level, err
 		}
 		if out != "" {
@@ -221,6 +510,25 @@ func (d *DiffCommand) collectOutput() (string, string, error) {
 	return "", "", nil
 }
 
+// levelClean reports whether st rules out any output at level. It only
+// ever skips: a level it lets through still falls back on its diff being
+// empty, so a status/diff disagreement can't hide changes.
+func levelClean(level string, st git.Status) bool {
+	switch level {
+	case "work":
+		return !slices.ContainsFunc(st.Entries, git.StatusEntry.Unstaged)
+	case "index":
+		return !slices.ContainsFunc(st.Entries, git.StatusEntry.Staged)
+	case "untracked":
+		return !slices.ContainsFunc(st.Entries, func(e git.StatusEntry) bool { return e.Kind == git.EntryUntracked })
+	}
+	return false
+}
+
+// splitScrollStep is how many cells h/l scroll the --split panes in
+// --follow.
+const splitScrollStep = 8
+
 var emptyModeMessages = map[string]string{
 	"work":      "(no work changes)",
 	"index":     "(no index changes)",
@@ -254,13 +562,16 @@ func (d *DiffCommand) runFollow() error {
 	tick := time.NewTicker(time.Duration(interval * float64(time.Second)))
 	defer tick.Stop()
 
+	// the cascade's levels, and a revision range's after them
+	levels := d.levels()
+
 	// primary: the mode tab cycles from. shown with <> braces.
 	// pinned: modes that stay visible across tab switches. shown bold.
 	// 1/2/3: set primary, clear all pins.
 	// tab: advance primary to next, clear all pins.
 	// shift+tab: pin current primary, advance primary to next.
 	// !/@ /#: toggle pin on that specific mode.
-	primaryMode := d.Mode
+	primaryMode := d.lockedLevel()
 	if primaryMode == "" {
 		for _, level := range diffModes {
 			out, err := d.collectDiff(level)
@@ -276,12 +587,12 @@ func (d *DiffCommand) runFollow() error {
 	pinned := map[string]bool{}
 
 	nextMode := func(from string) string {
-		for i, m := range diffModes {
+		for i, m := range levels {
 			if m == from {
-				return diffModes[(i+1)%len(diffModes)]
+				return levels[(i+1)%len(levels)]
 			}
 		}
-		return diffModes[0]
+		return levels[0]
 	}
 
 	isActive := func(m string) bool {
@@ -290,7 +601,7 @@ func (d *DiffCommand) runFollow() error {
 
 	activeCount := func() int {
 		n := 1
-		for _, m := range diffModes {
+		for _, m := range levels {
 			if m != primaryMode && pinned[m] {
 				n++
 			}
@@ -298,38 +609,69 @@ func (d *DiffCommand) runFollow() error {
 		return n
 	}
 
+	// levelOut is one active level as last fetched: parsed for the native
+	// renderer, which lays it out again at each redraw's width and scroll,
+	// or finished text.
+	type levelOut struct {
+		mode   string
+		parsed *parsedDiff
+		text   string
+	}
 	var (
+		cachedLevels []levelOut
 		cachedLines  []string
 		cachedErr    error
 		scrollOffset = 0
 		tailMode     = true
+		hscroll      = 0
+		hscrollMax   = 0 // from the last layout: where the widest line ends
 	)
 
 	refreshCache := func() {
 		cachedErr = nil
-		cachedLines = nil
-		multi := activeCount() > 1
-		first := true
-		for _, m := range diffModes {
+		cachedLevels = nil
+		for _, m := range levels {
 			if !isActive(m) {
 				continue
 			}
-			out, cErr := d.collectDiff(m)
+			p, out, cErr := d.collectLevel(m)
 			if cErr != nil {
 				cachedErr = cErr
-				cachedLines = nil
+				cachedLevels = nil
 				return
 			}
-			if !first && multi {
+			cachedLevels = append(cachedLevels, levelOut{mode: m, parsed: p, text: out})
+		}
+	}
+
+	layout := func(width int) {
+		cachedLines = nil
+		multi := len(cachedLevels) > 1
+		v := d.view(width)
+		hscrollMax = 0
+		if v.split {
+			for _, lv := range cachedLevels {
+				if lv.parsed != nil {
+					hscrollMax = max(hscrollMax, lv.parsed.maxHScroll(width))
+				}
+			}
+		}
+		hscroll = min(hscroll, hscrollMax)
+		v.hscroll = hscroll
+		for i, lv := range cachedLevels {
+			if i > 0 && multi {
 				cachedLines = append(cachedLines, "")
 			}
 			if multi {
-				cachedLines = append(cachedLines, ansiDim+"--- "+m+" ---"+ansiReset)
+				cachedLines = append(cachedLines, ansiDim+"--- "+lv.mode+" ---"+ansiReset)
+			}
+			body := lv.text
+			if lv.parsed != nil {
+				body = lv.parsed.render(v)
 			}
-			first = false
-			body := strings.Trim(out, "\n")
+			body = strings.Trim(body, "\n")
 			if body == "" {
-				cachedLines = append(cachedLines, emptyModeMessages[m])
+				cachedLines = append(cachedLines, d.emptyLevelMessage(lv.mode))
 			} else {
 				cachedLines = append(cachedLines, strings.Split(body, "\n")...)
 			}
@@ -339,13 +681,17 @@ func (d *DiffCommand) runFollow() error {
 	frame := newFollowFrame(scr)
 	redraw := func() {
 		frame.Begin()
-		frame.Header(interval, "", "j/k g/G tab q")
 		w, h := scr.Size()
+		keys := "j/k g/G tab q"
+		if d.view(w).split {
+			keys = "j/k h/l g/G tab q"
+		}
+		frame.Header(interval, "", keys)
 		dimStyle := tcell.StyleDefault.Dim(true)
 		boldDimStyle := tcell.StyleDefault.Bold(true).Dim(true)
 		frame.ExtraRow(func(y int) {
 			x := 0
-			for i, m := range diffModes {
+			for i, m := range levels {
 				if i > 0 {
 					writePlain(scr, x, y, " ", dimStyle, w, h)
 					x++
@@ -368,16 +714,17 @@ func (d *DiffCommand) runFollow() error {
 				x += len(label)
 			}
 		})
+		layout(w)
 		frame.Body(cachedLines, &scrollOffset, &tailMode, cachedErr)
 		frame.End()
 	}
 
 	// number keys 1..N select the Nth tab as primary; their shifted symbols
-	// toggle a pin on that tab. both derive from diffModes so the bindings
+	// toggle a pin on that tab. both derive from levels so the bindings
 	// stay in lockstep with the rendered tab strip.
 	shiftSymbols := []rune{'!', '@', '#', '$', '%'}
 	shiftedNum := map[rune]string{}
-	for i, m := range diffModes {
+	for i, m := range levels {
 		if i < len(shiftSymbols) {
 			shiftedNum[shiftSymbols[i]] = m
 		}
@@ -397,8 +744,8 @@ func (d *DiffCommand) runFollow() error {
 			case *tcell.EventKey:
 				_, h := scr.Size()
 				switch {
-				case ev.Rune() >= '1' && ev.Rune() <= '9' && int(ev.Rune()-'1') < len(diffModes):
-					primaryMode = diffModes[ev.Rune()-'1']
+				case ev.Rune() >= '1' && ev.Rune() <= '9' && int(ev.Rune()-'1') < len(levels):
+					primaryMode = levels[ev.Rune()-'1']
 					clear(pinned)
 					refreshCache()
 				case ev.Key() == tcell.KeyTab:
@@ -409,13 +756,19 @@ func (d *DiffCommand) runFollow() error {
 					pinned[primaryMode] = true
 					primaryMode = nextMode(primaryMode)
 					refreshCache()
+				case ev.Rune() == 'h', ev.Key() == tcell.KeyLeft:
+					// split panes scroll sideways together; unified lines
+					// are cut at the screen edge as before
+					hscroll = max(hscroll-splitScrollStep, 0)
+				case ev.Rune() == 'l', ev.Key() == tcell.KeyRight:
+					hscroll = min(hscroll+splitScrollStep, hscrollMax)
 				case shiftedNum[ev.Rune()] != "":
 					// TODO: primary-fallover UX when shift+number deselects
 					// the active primary feels clunky. revisit.
 					m := shiftedNum[ev.Rune()]
 					if m == primaryMode && activeCount() > 1 {
 						delete(pinned, m)
-						for _, candidate := range diffModes {
+						for _, candidate := range levels {
 							if pinned[candidate] {
 								primaryMode = candidate
 								delete(pinned, candidate)
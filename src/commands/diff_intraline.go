@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/lczyk/gitgum/src/diff"
+	"github.com/mattn/go-runewidth"
+)
+
+// refineCacheMax bounds how many line pairs a refiner remembers. Past it
+// the cache starts over.
+const refineCacheMax = 4096
+
+// refiner works out the intra-line emphasis for a diff's changed lines:
+// which words (or characters) of a deleted line its replacement changed.
+// It remembers the pairs it has compared, so a --follow refresh of a big
+// diff only compares the lines that are new since the last one.
+type refiner struct {
+	g    diff.Granularity
+	seen map[[2]string]refinement
+}
+
+type refinement struct {
+	before, after []diff.Span
+	ok            bool
+}
+
+func newRefiner(g diff.Granularity) *refiner {
+	return &refiner{g: g, seen: map[[2]string]refinement{}}
+}
+
+// intraline maps the --intraline flag onto a refiner, kept across calls
+// so its cache survives --follow refreshes. nil for "off".
+func (d *DiffCommand) intraline() *refiner {
+	g := diff.Words
+	switch d.Intraline {
+	case "off":
+		return nil
+	case "char":
+		g = diff.Chars
+	}
+	if d.refiner == nil || d.refiner.g != g {
+		d.refiner = newRefiner(g)
+	}
+	return d.refiner
+}
+
+// hunk finds the emphasis for each refinable line of h, keyed by the
+// line. Deleted and added lines pair up the way the split view shows
+// them (pairHunk); a pair too different to refine gets none.
+func (r *refiner) hunk(h diff.Hunk) map[*diff.Line][]diff.Span {
+	if r == nil {
+		return nil
+	}
+	spans := map[*diff.Line][]diff.Span{}
+	for _, row := range pairHunk(h) {
+		if row.left == nil || row.right == nil || row.left.Kind != diff.Del {
+			continue
+		}
+		key := [2]string{row.left.Text, row.right.Text}
+		ref, ok := r.seen[key]
+		if !ok {
+			ref.before, ref.after, ref.ok = diff.Refine(key[0], key[1], r.g)
+			if len(r.seen) >= refineCacheMax {
+				clear(r.seen)
+			}
+			r.seen[key] = ref
+		}
+		if ref.ok {
+			spans[row.left], spans[row.right] = ref.before, ref.after
+		}
+	}
+	return spans
+}
+
+// segment is a run of a line's text drawn in one style (an ansi prefix;
+// "" for none).
+type segment struct {
+	text, style string
+}
+
+// lineSegments splits a hunk line's text into styled runs. Plain, that's
+// the whole line in its colour, with the emphasised spans in reverse video
+// on top. Highlighted, each token is in its own colour over the line's
+// background, which is brighter under the emphasised spans.
+func lineSegments(l *diff.Line, m lineMarks) []segment {
+	if len(m.emphasis) == 0 && len(m.tokens) == 0 {
+		return []segment{{l.Text, lineFill(l.Kind, m.syntax)}}
+	}
+	cuts := []int{0, len(l.Text)}
+	for _, sp := range m.emphasis {
+		cuts = append(cuts, sp.Start, sp.End)
+	}
+	for _, tok := range m.tokens {
+		cuts = append(cuts, tok.Start, tok.End)
+	}
+	slices.Sort(cuts)
+	cuts = slices.Compact(cuts)
+
+	var segs []segment
+	e, t := 0, 0 // the next emphasis span and token that could cover a cut
+	for i := 0; i+1 < len(cuts); i++ {
+		from, to := cuts[i], cuts[i+1]
+		for e < len(m.emphasis) && m.emphasis[e].End <= from {
+			e++
+		}
+		for t < len(m.tokens) && m.tokens[t].End <= from {
+			t++
+		}
+		emphasised := e < len(m.emphasis) && m.emphasis[e].Start <= from
+		var style string
+		switch {
+		case m.syntax:
+			style = lineBackground(l.Kind, emphasised)
+			if t < len(m.tokens) && m.tokens[t].Start <= from {
+				style += tokenColour(m.tokens[t].Class)
+			}
+		case emphasised:
+			style = lineColour(l.Kind) + ansiReverse
+		default:
+			style = lineColour(l.Kind)
+		}
+		if n := len(segs); n > 0 && segs[n-1].style == style {
+			segs[n-1].text += l.Text[from:to]
+		} else {
+			segs = append(segs, segment{l.Text[from:to], style})
+		}
+	}
+	return segs
+}
+
+// drawSegments paints each segment in its style.
+func drawSegments(segs []segment) string {
+	var b strings.Builder
+	for _, s := range segs {
+		if s.style == "" || s.text == "" {
+			b.WriteString(s.text)
+		} else {
+			b.WriteString(paint(s.style, s.text))
+		}
+	}
+	return b.String()
+}
+
+// expandSegmentTabs is expandTabs across segments, with tab stops counted
+// from the start of the line rather than of each segment.
+func expandSegmentTabs(segs []segment) []segment {
+	out := make([]segment, 0, len(segs))
+	col := 0
+	for _, s := range segs {
+		if strings.Contains(s.text, "\t") {
+			var b strings.Builder
+			for _, r := range s.text {
+				if r == '\t' {
+					n := splitTabWidth - col%splitTabWidth
+					b.WriteString(strings.Repeat(" ", n))
+					col += n
+					continue
+				}
+				b.WriteRune(r)
+				col += runewidth.RuneWidth(r)
+			}
+			s.text = b.String()
+		} else {
+			col += runewidth.StringWidth(s.text)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// cutSegments is cutCells across segments: the cells from cell from on,
+// at most width of them, keeping each one's style, and whether any text
+// is left past them.
+func cutSegments(segs []segment, from, width int) ([]segment, bool) {
+	var out []segment
+	col, used := 0, 0
+	for i, s := range segs {
+		sw := runewidth.StringWidth(s.text)
+		if col+sw <= from {
+			col += sw
+			continue
+		}
+		part, more := cutCells(s.text, max(from-col, 0), width-used)
+		if part != "" {
+			out = append(out, segment{part, s.style})
+			used += runewidth.StringWidth(part)
+		}
+		if more {
+			return out, true
+		}
+		col += sw
+		if used >= width {
+			for _, rest := range segs[i+1:] {
+				if rest.text != "" {
+					return out, true
+				}
+			}
+			return out, false
+		}
+	}
+	return out, false
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/src/diff"
+)
+
+var refineHunk = diff.Hunk{
+	OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2,
+	Lines: []diff.Line{
+		{Kind: diff.Del, Text: "x := fooBar(1)", OldNum: 1},
+		{Kind: diff.Del, Text: "completely different", OldNum: 2},
+		{Kind: diff.Add, Text: "x := fooBaz(1)", NewNum: 1},
+		{Kind: diff.Add, Text: "nothing alike here", NewNum: 2},
+	},
+}
+
+func TestRenderFileDiff_Intraline(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+	f := diff.File{OldPath: "a.go", NewPath: "a.go", Status: diff.Modified, Hunks: []diff.Hunk{refineHunk}}
+	red, green := ansiRed, ansiGreen
+
+	cases := map[string]struct {
+		d    *DiffCommand
+		want []string
+	}{
+		"word": {&DiffCommand{Intraline: "word", Syntax: "off"}, []string{
+			paint(red, "-") + paint(red, "x := ") + paint(red+ansiReverse, "fooBar") + paint(red, "(1)"),
+			paint(red, "-") + paint(red, "completely different"),
+			paint(green, "+") + paint(green, "x := ") + paint(green+ansiReverse, "fooBaz") + paint(green, "(1)"),
+			paint(green, "+") + paint(green, "nothing alike here"),
+		}},
+		"char": {&DiffCommand{Intraline: "char", Syntax: "off"}, []string{
+			paint(red, "-") + paint(red, "x := fooBa") + paint(red+ansiReverse, "r") + paint(red, "(1)"),
+			paint(red, "-") + paint(red, "completely different"),
+			paint(green, "+") + paint(green, "x := fooBa") + paint(green+ansiReverse, "z") + paint(green, "(1)"),
+			paint(green, "+") + paint(green, "nothing alike here"),
+		}},
+		"off": {&DiffCommand{Intraline: "off", Syntax: "off"}, []string{
+			paint(red, "-") + paint(red, "x := fooBar(1)"),
+			paint(red, "-") + paint(red, "completely different"),
+			paint(green, "+") + paint(green, "x := fooBaz(1)"),
+			paint(green, "+") + paint(green, "nothing alike here"),
+		}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			renderFileDiff(&b, f, tc.d.view(80))
+			lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+			assert.EqualArrays(t, lines[2:], tc.want)
+		})
+	}
+}
+
+func TestRefiner_CachesPairs(t *testing.T) {
+	t.Parallel()
+	r := newRefiner(diff.Words)
+	first := r.hunk(refineHunk)
+	assert.Equal(t, len(r.seen), 2)
+	assert.Equal(t, len(first), 2, "only the similar pair gets emphasis")
+
+	// a refresh parses the patch afresh: new lines, same text
+	again := refineHunk
+	again.Lines = append([]diff.Line(nil), refineHunk.Lines...)
+	r.seen[[2]string{"x := fooBar(1)", "x := fooBaz(1)"}] = refinement{
+		before: []diff.Span{{Start: 0, End: 1}}, after: []diff.Span{{Start: 0, End: 1}}, ok: true,
+	}
+	second := r.hunk(again)
+	assert.EqualArrays(t, second[&again.Lines[0]], []diff.Span{{Start: 0, End: 1}}, "served from the cache")
+	assert.Equal(t, len(r.seen), 2)
+}
+
+func TestRefiner_Off(t *testing.T) {
+	t.Parallel()
+	d := &DiffCommand{Intraline: "off"}
+	assert.That(t, d.intraline() == nil, "off means no refiner")
+	assert.Equal(t, len(d.intraline().hunk(refineHunk)), 0)
+
+	d.Intraline = "char"
+	r := d.intraline()
+	assert.That(t, r != nil && r.g == diff.Chars, "char refiner")
+	assert.That(t, d.intraline() == r, "kept across calls")
+}
+
+func TestCutSegments(t *testing.T) {
+	t.Parallel()
+	segs := []segment{{"abc", "1"}, {"def", "2"}, {"gh", "3"}}
+	cases := []struct {
+		from, width int
+		want        []segment
+		more        bool
+	}{
+		{0, 10, segs, false},
+		{0, 4, []segment{{"abc", "1"}, {"d", "2"}}, true},
+		{2, 4, []segment{{"c", "1"}, {"def", "2"}}, true},
+		{3, 3, []segment{{"def", "2"}}, true},
+		{6, 3, []segment{{"gh", "3"}}, false},
+		{9, 3, nil, false},
+	}
+	for _, tc := range cases {
+		got, more := cutSegments(segs, tc.from, tc.width)
+		assert.EqualArrays(t, got, tc.want, "cutSegments(%d, %d)", tc.from, tc.width)
+		assert.Equal(t, more, tc.more, "cutSegments(%d, %d) more", tc.from, tc.width)
+	}
+}
@@ -2,13 +2,202 @@ package commands
 
 import (
 	"fmt"
-	"io"
+	"os"
+	"slices"
 	"strings"
+
+	"github.com/lczyk/gitgum/src/diff"
 )
 
-func (d *DiffCommand) renderCollected(w io.Writer, out string) error {
-	for line := range strings.SplitSeq(out, "\n") {
-		fmt.Fprintln(w, line)
+// diffNative reports whether gg diff renders patches itself. GG_DIFF_NATIVE=0
+// falls back to git's --compact-summary, which is all gg diff used to show.
+func diffNative() bool {
+	return os.Getenv("GG_DIFF_NATIVE") != "0"
+}
+
+// parsedDiff is one level's patch, parsed, ready to draw at any width.
+type parsedDiff struct {
+	files []diff.File
+	// worktree puts the tree's status codes in the Y column, as gg status
+	// does for unstaged changes; X otherwise.
+	worktree   bool
+	submodules []string // describeSubmoduleChange lines
+}
+
+// diffView is how a parsedDiff is drawn.
+type diffView struct {
+	split     bool // side by side; see renderFileSplit
+	width     int  // terminal columns, which split divides between its panes
+	wrap      bool // split: wrap long lines rather than cut them off
+	hscroll   int  // split: cells scrolled off the left of each pane
+	refine    *refiner
+	highlight *syntaxHighlighter
+}
+
+// parseDiff runs and parses `git diff args...`, narrowed to the
+// pathspecs; nil when there's no change.
+func (d *DiffCommand) parseDiff(worktree bool, args ...string) (*parsedDiff, error) {
+	patch, err := d.repo().Patch(d.diffArgs(args...)...)
+	if err != nil {
+		return nil, err
+	}
+	files, err := diff.Parse(patch)
+	if err != nil {
+		return nil, fmt.Errorf("parsing git diff: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	p := &parsedDiff{files: files, worktree: worktree}
+	if len(d.paths) == 0 {
+		p.submodules = d.submoduleChanges(args...)
+	} else if slices.ContainsFunc(files, diff.File.IsSubmodule) {
+		// the patch has the submodules the pathspecs take in
+		changes, _ := d.repo().SubmoduleDiff(args...)
+		for _, c := range changes {
+			if slices.ContainsFunc(files, func(f diff.File) bool { return f.IsSubmodule() && f.Path() == c.Path }) {
+				p.submodules = append(p.submodules, describeSubmoduleChange(c))
+			}
+		}
+	}
+	return p, nil
+}
+
+// render draws the diff: a tree of the changed files with their (+a,-d)
+// counts, any submodule pointer moves, then each file's hunks.
+func (p *parsedDiff) render(v diffView) string {
+	var b strings.Builder
+	renderTree(buildTree(diffEntries(p.files, p.worktree)), &b)
+	for _, line := range p.submodules {
+		b.WriteString(line + "\n")
+	}
+	for _, f := range p.files {
+		if f.IsSubmodule() {
+			// the summary above says more than "Subproject commit" lines
+			continue
+		}
+		b.WriteString("\n")
+		if v.split {
+			renderFileSplit(&b, f, v)
+		} else {
+			renderFileDiff(&b, f, v)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// diffEntries maps parsed files onto tree leaves, with the status letter
+// in the worktree (Y) or index (X) column. Renames get the same "R<" / "R>"
+// pair as in gg status.
+func diffEntries(files []diff.File, worktree bool) []changeEntry {
+	code := func(s diff.Status) string {
+		if worktree {
+			return " " + string(s)
+		}
+		return string(s) + " "
+	}
+	var out []changeEntry
+	for _, f := range files {
+		ns := numstat{binary: f.Binary}
+		ns.added, ns.deleted = f.Stat()
+		if f.Status == diff.Renamed {
+			out = append(out, changeEntry{code: "R<", path: f.OldPath})
+			out = append(out, changeEntry{code: "R>", path: f.NewPath, numstat: &ns})
+			continue
+		}
+		e := changeEntry{code: code(f.Status), path: f.Path(), numstat: &ns}
+		if f.IsSubmodule() {
+			e.numstat = nil
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// renderFileDiff writes one file's section: a header naming it and what
+// happened to it beyond its content, then its hunks coloured the way git
+// colours them by default.
+//
+//	a/b.go (renamed from a.go, 92% similar)
+//	@@ -1,3 +1,4 @@ func main() {
+//	 context
+//	-old
+//	+new
+//
+// With a refiner, the words a changed line's replacement changed are
+// picked out; with a highlighter, the code is coloured by its syntax.
+func renderFileDiff(b *strings.Builder, f diff.File, v diffView) {
+	renderFileHeader(b, f)
+	tokens := v.highlight.file(f)
+	for hi, h := range f.Hunks {
+		renderHunkHeader(b, h)
+		marks := v.marks(tokens, hi, h)
+		for i := range h.Lines {
+			l := &h.Lines[i]
+			b.WriteString(renderDiffLine(l, marks.line(l)) + "\n")
+			if l.NoNewline {
+				b.WriteString(dim(`\ no newline at end of file`) + "\n")
+			}
+		}
+	}
+}
+
+// renderFileHeader writes the path line, plus a marker for a binary file.
+func renderFileHeader(b *strings.Builder, f diff.File) {
+	b.WriteString(paint(ansiYellow, f.Path()))
+	if notes := fileNotes(f); len(notes) > 0 {
+		b.WriteString(" " + dim("("+strings.Join(notes, ", ")+")"))
+	}
+	b.WriteString("\n")
+	if f.Binary {
+		b.WriteString(dim("binary file differs") + "\n")
+	}
+}
+
+func renderHunkHeader(b *strings.Builder, h diff.Hunk) {
+	b.WriteString(paint(ansiCyan, h.Header()))
+	if h.Section != "" {
+		b.WriteString(" " + h.Section)
+	}
+	b.WriteString("\n")
+}
+
+// fileNotes lists what the header line says beside the path.
+func fileNotes(f diff.File) []string {
+	var notes []string
+	switch f.Status {
+	case diff.Added:
+		notes = append(notes, "new file")
+	case diff.Deleted:
+		notes = append(notes, "deleted")
+	case diff.Renamed, diff.Copied:
+		verb := "renamed"
+		if f.Status == diff.Copied {
+			verb = "copied"
+		}
+		notes = append(notes, fmt.Sprintf("%s from %s, %d%% similar", verb, f.OldPath, f.Similarity))
+	}
+	switch {
+	case f.ModeChanged():
+		notes = append(notes, "mode "+f.OldMode+" -> "+f.NewMode)
+	case f.Status == diff.Added && f.NewMode != "100644" && f.NewMode != "":
+		notes = append(notes, "mode "+f.NewMode)
+	}
+	return notes
+}
+
+// renderDiffLine draws a hunk line, with its marks: the spans the refiner
+// picked out of a changed one, and its syntax tokens.
+func renderDiffLine(l *diff.Line, m lineMarks) string {
+	return paintIf(lineSign(l.Kind, m.syntax), string(l.Kind)) + drawSegments(lineSegments(l, m))
+}
+
+func lineColour(k diff.LineKind) string {
+	switch k {
+	case diff.Add:
+		return ansiGreen
+	case diff.Del:
+		return ansiRed
 	}
-	return nil
+	return ""
 }
@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lczyk/gitgum/src/diff"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// splitTabWidth is how many cells a tab stop spans in the split view,
+// where the panes only line up if every line's width is known.
+const splitTabWidth = 4
+
+// stdoutWidth is the terminal's width, or 80 when stdout isn't one.
+func stdoutWidth() int {
+	w, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w <= 0 {
+		return 80
+	}
+	return w
+}
+
+// splitRow is one row of a split hunk: an old line beside a new one. A
+// nil side is filler, across from a line the other side added or deleted.
+type splitRow struct{ left, right *diff.Line }
+
+// pairHunk lines a hunk up for the split view: context on both sides, and
+// each run of deletions beside the run of additions that follows it, one
+// for one, with filler where one run is longer.
+func pairHunk(h diff.Hunk) []splitRow {
+	var rows []splitRow
+	lines := h.Lines
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == diff.Context {
+			rows = append(rows, splitRow{&lines[i], &lines[i]})
+			i++
+			continue
+		}
+		var dels, adds []*diff.Line
+		for ; i < len(lines) && lines[i].Kind == diff.Del; i++ {
+			dels = append(dels, &lines[i])
+		}
+		for ; i < len(lines) && lines[i].Kind == diff.Add; i++ {
+			adds = append(adds, &lines[i])
+		}
+		for j := range max(len(dels), len(adds)) {
+			var r splitRow
+			if j < len(dels) {
+				r.left = dels[j]
+			}
+			if j < len(adds) {
+				r.right = adds[j]
+			}
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// splitLayout is the shape of a split file's panes, each one
+// "<line number> <sign><text>", with " │ " between them.
+type splitLayout struct {
+	numW  int // widest line number in the file
+	textW int
+}
+
+func newSplitLayout(f diff.File, width int) splitLayout {
+	maxNum := 0
+	for _, h := range f.Hunks {
+		maxNum = max(maxNum, h.OldStart+h.OldLines, h.NewStart+h.NewLines)
+	}
+	numW := len(strconv.Itoa(maxNum))
+	return splitLayout{numW: numW, textW: max((width-3)/2-numW-2, 1)}
+}
+
+// maxHScroll is how far split panes at width can scroll sideways: until
+// the end of p's widest line reaches its pane's right edge.
+func (p *parsedDiff) maxHScroll(width int) int {
+	most := 0
+	for _, f := range p.files {
+		l := newSplitLayout(f, width)
+		for _, h := range f.Hunks {
+			for i := range h.Lines {
+				used := 0
+				for _, s := range expandSegmentTabs(lineSegments(&h.Lines[i], lineMarks{})) {
+					used += runewidth.StringWidth(s.text)
+				}
+				most = max(most, used-l.textW)
+			}
+		}
+	}
+	return most
+}
+
+// renderFileSplit writes one file's section side by side: old lines on the
+// left, new on the right, numbered, each hunk's rows paired by pairHunk.
+//
+//	@@ -4,3 +4,4 @@ func main() {
+//	4  a := 1          │ 4  a := 1
+//	5 -b := 2          │ 5 +b := 3
+//	                   │ 6 +c := 4
+//	6  return          │ 7  return
+func renderFileSplit(b *strings.Builder, f diff.File, v diffView) {
+	renderFileHeader(b, f)
+	l := newSplitLayout(f, v.width)
+	sep := dim(" │ ")
+	tokens := v.highlight.file(f)
+	for hi, h := range f.Hunks {
+		renderHunkHeader(b, h)
+		marks := v.marks(tokens, hi, h)
+		for _, r := range pairHunk(h) {
+			left := l.pane(r.left, marks.line(r.left), diff.Del, v, true)
+			right := l.pane(r.right, marks.line(r.right), diff.Add, v, false)
+			for i := range max(len(left), len(right)) {
+				b.WriteString(rowAt(left, i, l.numW+2+l.textW))
+				b.WriteString(sep)
+				b.WriteString(rowAt(right, i, 0))
+				b.WriteString("\n")
+			}
+		}
+	}
+}
+
+// rowAt is rows[i], or blank padding of width cells past the end.
+func rowAt(rows []string, i, width int) string {
+	if i < len(rows) {
+		return rows[i]
+	}
+	return strings.Repeat(" ", width)
+}
+
+// pane draws one side of a row: the line's number, its sign and its text
+// (marks drawn), cut off or wrapped to the pane, over as many
+// screen rows as wrapping takes. side is the kind this pane shows (Del on
+// the left, Add on the right). pad fills each row out to the pane's full
+// width so the next pane lines up. A filler side has no rows; rowAt blanks
+// it.
+func (l splitLayout) pane(line *diff.Line, m lineMarks, side diff.LineKind, v diffView, pad bool) []string {
+	if line == nil {
+		return nil
+	}
+	num := line.OldNum
+	if side == diff.Add {
+		num = line.NewNum
+	}
+	fill := lineFill(line.Kind, m.syntax)
+	segs := expandSegmentTabs(lineSegments(line, m))
+
+	var parts [][]segment
+	if v.wrap {
+		for from := v.hscroll; ; from += l.textW {
+			part, more := cutSegments(segs, from, l.textW)
+			parts = append(parts, part)
+			if !more {
+				break
+			}
+		}
+	} else {
+		part, more := cutSegments(segs, v.hscroll, l.textW)
+		if more {
+			part, _ = cutSegments(segs, v.hscroll, l.textW-1)
+			part = append(part, segment{"…", fill})
+		}
+		parts = append(parts, part)
+	}
+
+	rows := make([]string, 0, len(parts)+1)
+	for i, part := range parts {
+		gutter := strings.Repeat(" ", l.numW+1)
+		sign := " "
+		if i == 0 {
+			gutter = dim(padLeft(strconv.Itoa(num), l.numW)) + " "
+			sign = string(line.Kind)
+		}
+		row := gutter + paintIf(lineSign(line.Kind, m.syntax), sign) + drawSegments(part)
+		if pad {
+			used := 0
+			for _, s := range part {
+				used += runewidth.StringWidth(s.text)
+			}
+			row += paintIf(fill, strings.Repeat(" ", l.textW-used))
+		}
+		rows = append(rows, row)
+	}
+	if line.NoNewline {
+		note, _ := cutCells(`\ no newline at end of file`, 0, l.textW+1)
+		if pad {
+			note += strings.Repeat(" ", l.textW+1-runewidth.StringWidth(note))
+		}
+		rows = append(rows, strings.Repeat(" ", l.numW+1)+dim(note))
+	}
+	return rows
+}
+
+// paintIf is paint for an optional colour: s as it is when there's none.
+func paintIf(colour, s string) string {
+	if colour == "" {
+		return s
+	}
+	return paint(colour, s)
+}
+
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", max(width-len(s), 0)) + s
+}
+
+// cutCells returns the cells of s from cell from on, at most width of
+// them, and whether any of s is left past them. A wide rune that
+// straddles either edge is left out.
+func cutCells(s string, from, width int) (string, bool) {
+	var b strings.Builder
+	col := 0
+	for i, r := range s {
+		rw := runewidth.RuneWidth(r)
+		if col < from {
+			col += rw
+			continue
+		}
+		if col+rw > from+width {
+			return b.String(), i < len(s)
+		}
+		b.WriteRune(r)
+		col += rw
+	}
+	return b.String(), false
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/src/diff"
+)
+
+func TestPairHunk(t *testing.T) {
+	t.Parallel()
+	h := diff.Hunk{Lines: []diff.Line{
+		{Kind: diff.Context, Text: "a"},
+		{Kind: diff.Del, Text: "b"},
+		{Kind: diff.Del, Text: "c"},
+		{Kind: diff.Add, Text: "B"},
+		{Kind: diff.Context, Text: "d"},
+		{Kind: diff.Add, Text: "e"},
+	}}
+	var got []string
+	for _, r := range pairHunk(h) {
+		side := func(l *diff.Line) string {
+			if l == nil {
+				return "_"
+			}
+			return string(l.Kind) + l.Text
+		}
+		got = append(got, side(r.left)+"|"+side(r.right))
+	}
+	assert.EqualArrays(t, got, []string{" a| a", "-b|+B", "-c|_", " d| d", "_|+e"})
+}
+
+// splitFile is a hunk with a long changed line, for the layout tests.
+var splitFile = diff.File{OldPath: "a.go", NewPath: "a.go", Status: diff.Modified, Hunks: []diff.Hunk{{
+	OldStart: 9, OldLines: 2, NewStart: 9, NewLines: 3,
+	Lines: []diff.Line{
+		{Kind: diff.Context, Text: "\tx", OldNum: 9, NewNum: 9},
+		{Kind: diff.Del, Text: "abcdefghijklmnop", OldNum: 10},
+		{Kind: diff.Add, Text: "abcdefghijKLMNOP", NewNum: 10},
+		{Kind: diff.Add, Text: "new", NewNum: 11},
+	},
+}}}
+
+func TestRenderFileSplit(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	// width 35: panes of (35-3)/2 = 16 cells, 2 for the numbers, 2 for
+	// the gaps and signs, 12 for the text
+	cases := map[string]struct {
+		v    diffView
+		want []string
+	}{
+		"truncated": {
+			diffView{split: true, width: 35},
+			[]string{
+				" 9      x        │  9      x",
+				"10 -abcdefghijk… │ 10 +abcdefghijK…",
+				"                 │ 11 +new",
+			},
+		},
+		"wrapped": {
+			diffView{split: true, width: 35, wrap: true},
+			[]string{
+				" 9      x        │  9      x",
+				"10 -abcdefghijkl │ 10 +abcdefghijKL",
+				"    mnop         │     MNOP",
+				"                 │ 11 +new",
+			},
+		},
+		"scrolled": {
+			diffView{split: true, width: 35, hscroll: 10},
+			[]string{
+				" 9               │  9  ",
+				"10 -klmnop       │ 10 +KLMNOP",
+				"                 │ 11 +",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			renderFileSplit(&b, splitFile, tc.v)
+			want := "a.go\n@@ -9,2 +9,3 @@\n" + strings.Join(tc.want, "\n") + "\n"
+			assert.Equal(t, b.String(), want)
+		})
+	}
+}
+
+func TestParsedDiff_MaxHScroll(t *testing.T) {
+	t.Parallel()
+	p := &parsedDiff{files: []diff.File{splitFile}}
+	// 12 cells of text, 16 in the widest line
+	assert.Equal(t, p.maxHScroll(35), 4)
+	assert.Equal(t, p.maxHScroll(80), 0, "everything fits")
+}
+
+func TestDiffCommand_SplitFallsBackWhenNarrow(t *testing.T) {
+	t.Parallel()
+	d := &DiffCommand{Split: true, SplitMinWidth: 120}
+	assert.That(t, !d.view(100).split, "100 columns is below the minimum")
+	assert.That(t, d.view(120).split, "120 columns is enough")
+	assert.That(t, !(&DiffCommand{SplitMinWidth: 120}).view(200).split, "no --split, no split")
+}
+
+func TestCutCells(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		s           string
+		from, width int
+		want        string
+		more        bool
+	}{
+		{"hello", 0, 10, "hello", false},
+		{"hello", 0, 3, "hel", true},
+		{"hello", 2, 3, "llo", false},
+		{"hello", 9, 3, "", false},
+		{"日本語", 0, 3, "日", true},
+		{"日本語", 1, 4, "本", true},
+		{"日本語", 2, 4, "本語", false},
+	}
+	for _, tc := range cases {
+		got, more := cutCells(tc.s, tc.from, tc.width)
+		assert.Equal(t, got, tc.want, "cutCells(%q, %d, %d)", tc.s, tc.from, tc.width)
+		assert.Equal(t, more, tc.more, "cutCells(%q, %d, %d) more", tc.s, tc.from, tc.width)
+	}
+}
+
+func TestExpandSegmentTabs(t *testing.T) {
+	t.Parallel()
+	expand := func(texts ...string) []string {
+		var segs []segment
+		for _, s := range texts {
+			segs = append(segs, segment{text: s})
+		}
+		var out []string
+		for _, s := range expandSegmentTabs(segs) {
+			out = append(out, s.text)
+		}
+		return out
+	}
+	assert.EqualArrays(t, expand("\tx"), []string{"    x"})
+	assert.EqualArrays(t, expand("ab\tc"), []string{"ab  c"})
+	// tab stops run across segments
+	assert.EqualArrays(t, expand("ab", "\tc"), []string{"ab", "  c"})
+	assert.EqualArrays(t, expand("none"), []string{"none"})
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"hash/fnv"
+
+	"github.com/lczyk/gitgum/src/diff"
+	"github.com/lczyk/gitgum/src/syntax"
+)
+
+// highlightCacheMax bounds how many files a syntaxHighlighter remembers. Past it
+// the cache starts over.
+const highlightCacheMax = 1024
+
+// Backgrounds for the changed lines of a syntax-highlighted file, whose
+// foreground is the tokens' colours: dark red and green, and brighter ones
+// for the words intra-line emphasis picks out.
+const (
+	ansiDelBg         = "\033[48;5;52m"
+	ansiDelBgEmphasis = "\033[48;5;88m"
+	ansiAddBg         = "\033[48;5;22m"
+	ansiAddBgEmphasis = "\033[48;5;28m"
+)
+
+// syntaxHighlighter lexes the hunks of the files whose type package syntax
+// knows. It remembers each file's tokens by path and content, so a
+// --follow refresh only lexes the files that changed since the last.
+type syntaxHighlighter struct {
+	files map[string]highlighted
+}
+
+type highlighted struct {
+	sum    uint64
+	tokens [][][]syntax.Token // by hunk, then line
+}
+
+func newSyntaxHighlighter() *syntaxHighlighter {
+	return &syntaxHighlighter{files: map[string]highlighted{}}
+}
+
+// highlight maps the --syntax flag onto a highlighter, kept across calls
+// so its cache survives --follow refreshes. nil for "off", and when
+// there's no colour to draw the tokens in.
+func (d *DiffCommand) highlight() *syntaxHighlighter {
+	if d.Syntax == "off" || !colorEnabled() {
+		return nil
+	}
+	if d.highlighter == nil {
+		d.highlighter = newSyntaxHighlighter()
+	}
+	return d.highlighter
+}
+
+// file lexes f's hunks: the tokens of each hunk's lines. nil when f is
+// binary or of a type package syntax doesn't know, to be drawn as before.
+func (hl *syntaxHighlighter) file(f diff.File) [][][]syntax.Token {
+	if hl == nil || f.Binary {
+		return nil
+	}
+	lang := syntax.ForPath(f.Path())
+	if lang == nil {
+		return nil
+	}
+	sum := hunksSum(f.Hunks)
+	if c, ok := hl.files[f.Path()]; ok && c.sum == sum {
+		return c.tokens
+	}
+	tokens := make([][][]syntax.Token, len(f.Hunks))
+	for i, h := range f.Hunks {
+		tokens[i] = lexHunk(lang, h)
+	}
+	if len(hl.files) >= highlightCacheMax {
+		clear(hl.files)
+	}
+	hl.files[f.Path()] = highlighted{sum: sum, tokens: tokens}
+	return tokens
+}
+
+// lexHunk lexes a hunk's two sides apart -- context and deleted lines as
+// they were, context and added lines as they are -- so a comment or
+// string opened on one line of a side carries on to the next line of the
+// same side. Context lines get their tokens from the new side.
+func lexHunk(lang *syntax.Lang, h diff.Hunk) [][]syntax.Token {
+	out := make([][]syntax.Token, len(h.Lines))
+	var oldSt, newSt syntax.State
+	for i, l := range h.Lines {
+		switch l.Kind {
+		case diff.Del:
+			out[i], oldSt = lang.Line(l.Text, oldSt)
+		case diff.Add:
+			out[i], newSt = lang.Line(l.Text, newSt)
+		default:
+			same := oldSt == newSt
+			out[i], newSt = lang.Line(l.Text, newSt)
+			if same {
+				oldSt = newSt
+			} else {
+				_, oldSt = lang.Line(l.Text, oldSt)
+			}
+		}
+	}
+	return out
+}
+
+// hunksSum fingerprints a file's hunks, to tell whether the file changed
+// since it was last lexed.
+func hunksSum(hunks []diff.Hunk) uint64 {
+	h := fnv.New64a()
+	for _, hunk := range hunks {
+		for _, l := range hunk.Lines {
+			h.Write([]byte{byte(l.Kind)})
+			h.Write([]byte(l.Text))
+			h.Write([]byte{'\n'})
+		}
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// hunkMarks is what's drawn over a hunk's lines besides their colour: the
+// refiner's emphasis, and the highlighter's tokens. tokens is nil when the
+// file isn't highlighted.
+type hunkMarks struct {
+	emphasis map[*diff.Line][]diff.Span
+	tokens   map[*diff.Line][]syntax.Token
+}
+
+// lineMarks is hunkMarks for one line.
+type lineMarks struct {
+	emphasis []diff.Span
+	tokens   []syntax.Token
+	syntax   bool // the file is highlighted, even if this line has no tokens
+}
+
+// marks gathers the hunkMarks of hunk i of a file, given the file's tokens
+// (from syntaxHighlighter.file).
+func (v diffView) marks(tokens [][][]syntax.Token, i int, h diff.Hunk) hunkMarks {
+	m := hunkMarks{emphasis: v.refine.hunk(h)}
+	if tokens != nil {
+		m.tokens = make(map[*diff.Line][]syntax.Token, len(h.Lines))
+		for j := range h.Lines {
+			m.tokens[&h.Lines[j]] = tokens[i][j]
+		}
+	}
+	return m
+}
+
+func (m hunkMarks) line(l *diff.Line) lineMarks {
+	return lineMarks{emphasis: m.emphasis[l], tokens: m.tokens[l], syntax: m.tokens != nil}
+}
+
+// lineBackground is a changed line's background when it's highlighted;
+// emphasis the brighter one for its emphasised spans.
+func lineBackground(k diff.LineKind, emphasis bool) string {
+	switch {
+	case k == diff.Del && emphasis:
+		return ansiDelBgEmphasis
+	case k == diff.Del:
+		return ansiDelBg
+	case k == diff.Add && emphasis:
+		return ansiAddBgEmphasis
+	case k == diff.Add:
+		return ansiAddBg
+	}
+	return ""
+}
+
+// lineFill is the style of the space around a line's text -- the
+// padding of a split pane, the "…" of a cut-off line -- which carries on
+// the line's colour, or its background when it's highlighted.
+func lineFill(k diff.LineKind, highlighted bool) string {
+	if highlighted {
+		return lineBackground(k, false)
+	}
+	return lineColour(k)
+}
+
+// lineSign is the style of a line's +/- sign: its colour, over its
+// background when it's highlighted.
+func lineSign(k diff.LineKind, highlighted bool) string {
+	if highlighted {
+		return lineColour(k) + lineBackground(k, false)
+	}
+	return lineColour(k)
+}
+
+func tokenColour(c syntax.Class) string {
+	switch c {
+	case syntax.Keyword:
+		return ansiMagenta
+	case syntax.Builtin, syntax.Key:
+		return ansiBlue
+	case syntax.String:
+		return ansiYellow
+	case syntax.Number, syntax.Variable, syntax.Code:
+		return ansiCyan
+	case syntax.Comment:
+		return ansiDim
+	case syntax.Heading:
+		return ansiPink
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/src/diff"
+	"github.com/lczyk/gitgum/src/syntax"
+)
+
+var syntaxHunk = diff.Hunk{
+	OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2,
+	Lines: []diff.Line{
+		{Kind: diff.Context, Text: "x", OldNum: 1, NewNum: 1},
+		{Kind: diff.Del, Text: "return 1", OldNum: 2},
+		{Kind: diff.Add, Text: "return 2", NewNum: 2},
+	},
+}
+
+func TestRenderFileDiff_Syntax(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+	file := func(path string) diff.File {
+		return diff.File{OldPath: path, NewPath: path, Status: diff.Modified, Hunks: []diff.Hunk{syntaxHunk}}
+	}
+	red, green := ansiRed, ansiGreen
+
+	cases := map[string]struct {
+		d    *DiffCommand
+		path string
+		want []string
+	}{
+		"go": {&DiffCommand{Intraline: "off"}, "a.go", []string{
+			" x",
+			paint(red+ansiDelBg, "-") + paint(ansiDelBg+ansiMagenta, "return") + paint(ansiDelBg, " ") + paint(ansiDelBg+ansiCyan, "1"),
+			paint(green+ansiAddBg, "+") + paint(ansiAddBg+ansiMagenta, "return") + paint(ansiAddBg, " ") + paint(ansiAddBg+ansiCyan, "2"),
+		}},
+		"go with emphasis": {&DiffCommand{Intraline: "word"}, "a.go", []string{
+			" x",
+			paint(red+ansiDelBg, "-") + paint(ansiDelBg+ansiMagenta, "return") + paint(ansiDelBg, " ") + paint(ansiDelBgEmphasis+ansiCyan, "1"),
+			paint(green+ansiAddBg, "+") + paint(ansiAddBg+ansiMagenta, "return") + paint(ansiAddBg, " ") + paint(ansiAddBgEmphasis+ansiCyan, "2"),
+		}},
+		"unknown type": {&DiffCommand{Intraline: "off"}, "a.txt", []string{
+			" x",
+			paint(red, "-") + paint(red, "return 1"),
+			paint(green, "+") + paint(green, "return 2"),
+		}},
+		"off": {&DiffCommand{Intraline: "off", Syntax: "off"}, "a.go", []string{
+			" x",
+			paint(red, "-") + paint(red, "return 1"),
+			paint(green, "+") + paint(green, "return 2"),
+		}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			renderFileDiff(&b, file(tc.path), tc.d.view(80))
+			lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+			assert.EqualArrays(t, lines[2:], tc.want)
+		})
+	}
+}
+
+func TestRenderFileSplit_SyntaxPadsWithBackground(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+	f := diff.File{OldPath: "a.go", NewPath: "a.go", Status: diff.Modified, Hunks: []diff.Hunk{syntaxHunk}}
+	d := &DiffCommand{Split: true, Intraline: "off"}
+	var b strings.Builder
+	renderFileSplit(&b, f, d.view(35))
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	// the deleted line's pane is filled out to its 13 cells (one-digit
+	// numbers) in its background
+	assert.ContainsString(t, lines[3], paint(ansiDelBg, strings.Repeat(" ", 13-len("return 1"))))
+}
+
+func TestLexHunk_SidesCarryState(t *testing.T) {
+	t.Parallel()
+	// the old side opens a block comment the new side doesn't
+	h := diff.Hunk{Lines: []diff.Line{
+		{Kind: diff.Del, Text: "/* start"},
+		{Kind: diff.Add, Text: "var a"},
+		{Kind: diff.Context, Text: "end */ if"},
+	}}
+	toks := lexHunk(syntax.ForPath("a.go"), h)
+	assert.EqualArrays(t, toks[0], []syntax.Token{{Start: 0, End: 8, Class: syntax.Comment}})
+	assert.EqualArrays(t, toks[1], []syntax.Token{{Start: 0, End: 3, Class: syntax.Keyword}})
+	// context is drawn as the new side has it: no comment open
+	assert.EqualArrays(t, toks[2], []syntax.Token{{Start: 7, End: 9, Class: syntax.Keyword}})
+}
+
+func TestSyntaxHighlighter_Caches(t *testing.T) {
+	t.Parallel()
+	hl := newSyntaxHighlighter()
+	f := diff.File{OldPath: "a.go", NewPath: "a.go", Hunks: []diff.Hunk{syntaxHunk}}
+	first := hl.file(f)
+	assert.Equal(t, len(first), 1)
+	assert.Equal(t, len(hl.files), 1)
+
+	// a refresh parses the patch afresh: same text, served from the cache
+	hl.files["a.go"].tokens[0][1] = nil
+	again := f
+	again.Hunks = []diff.Hunk{syntaxHunk}
+	assert.Equal(t, len(hl.file(again)[0][1]), 0, "served from the cache")
+
+	// changed text is lexed again
+	changed := syntaxHunk
+	changed.Lines = append([]diff.Line(nil), syntaxHunk.Lines...)
+	changed.Lines[1].Text = "return 3"
+	again.Hunks = []diff.Hunk{changed}
+	assert.Equal(t, len(hl.file(again)[0][1]), 2)
+
+	assert.That(t, hl.file(diff.File{NewPath: "notes.txt"}) == nil, "unknown type")
+	assert.That(t, (*syntaxHighlighter)(nil).file(f) == nil, "nil highlighter")
+}
+
+func TestDiffCommand_SyntaxNeedsColour(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	assert.That(t, (&DiffCommand{}).highlight() == nil, "nothing to draw tokens in")
+}
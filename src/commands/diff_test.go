@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -12,35 +13,38 @@ import (
 	"github.com/lczyk/assert/require"
 	"github.com/lczyk/gitgum/internal/git"
 	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+	"github.com/lczyk/gitgum/src/diff"
 )
 
-// runBoth executes `gg diff` once via the passthrough backend and once via the
-// native backend, returning both captured outputs. byte-identity between the
-// two is the contract -- assert in caller.
-func runBoth(t *testing.T, repo git.Repo) (passthrough, native string) {
+// runBoth executes `gg diff` once via the --compact-summary passthrough and
+// once via the native renderer, returning both captured outputs.
+func runBoth(t *testing.T, repo git.Repo, args ...string) (passthrough, native string) {
 	t.Helper()
 
 	var buf bytes.Buffer
 	cmd := &DiffCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}}
 
 	t.Setenv("GG_DIFF_NATIVE", "0")
-	require.NoError(t, cmd.Execute(nil))
+	require.NoError(t, cmd.Execute(args))
 	passthrough = buf.String()
 
 	buf.Reset()
 	t.Setenv("GG_DIFF_NATIVE", "1")
-	require.NoError(t, cmd.Execute(nil))
+	require.NoError(t, cmd.Execute(args))
 	native = buf.String()
 	return
 }
 
-// assertParity is the contract: native and passthrough produce byte-identical
-// output (ANSI included).
-func assertParity(t *testing.T, repo git.Repo) string {
+// assertParity is the contract between the two: they pick the same level
+// of the cascade (or both print nothing). Returns the native output.
+func assertParity(t *testing.T, repo git.Repo, args ...string) string {
 	t.Helper()
-	pt, nt := runBoth(t, repo)
-	assert.Equal(t, pt, nt)
-	return pt
+	pt, nt := runBoth(t, repo, args...)
+	ptLevel, _, _ := strings.Cut(pt, "\n")
+	ntLevel, _, _ := strings.Cut(nt, "\n")
+	assert.Equal(t, ntLevel, ptLevel)
+	assert.Equal(t, nt == "", pt == "")
+	return nt
 }
 
 func TestDiffCommand_Parity_ModifiedFile(t *testing.T) {
@@ -216,13 +220,210 @@ func TestDiffCommand_Parity_WithColor(t *testing.T) {
 	assert.That(t, len(out) > 0, "expected non-empty output with FORCE_COLOR")
 }
 
-func TestDiffCommand_RejectsArgs(t *testing.T) {
+// newTopicRepo is main with a topic branch off it, each a commit on from
+// where they split: topic adds topic.txt, main adds main.txt.
+func newTopicRepo(t *testing.T) string {
+	t.Helper()
 	dir := temp_repo.NewRepo(t)
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "topic")
+	temp_repo.CreateCommit(t, dir, "topic.txt", "t\n", "feat: topic")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "main")
+	temp_repo.CreateCommit(t, dir, "main.txt", "m\n", "feat: main")
+	return dir
+}
+
+func TestDiffCommand_RevisionRange(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir := newTopicRepo(t)
+	cases := map[string]struct {
+		args         []string
+		pr           bool
+		header       string
+		want, absent []string
+	}{
+		"two dots": {args: []string{"main..topic"}, header: "--- main..topic ---",
+			want: []string{"[A ] topic.txt", "[D ] main.txt"}},
+		"three dots": {args: []string{"main...topic"}, header: "--- main...topic ---",
+			want: []string{"[A ] topic.txt"}, absent: []string{"main.txt"}},
+		"one rev": {args: []string{"topic"}, header: "--- topic ---",
+			want: []string{"[D ] topic.txt", "[A ] main.txt"}},
+		"open end": {args: []string{"topic.."}, header: "--- topic.. ---",
+			want: []string{"[D ] topic.txt", "[A ] main.txt"}},
+		"pathspec": {args: []string{"main..topic", "topic.txt"}, header: "--- main..topic ---",
+			want: []string{"topic.txt"}, absent: []string{"main.txt"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &DiffCommand{cmdIO: cmdIO{Out: &buf, Repo: git.Repo{Dir: dir}}}
+			require.NoError(t, cmd.Execute(tc.args))
+			out := buf.String()
+			assert.That(t, strings.HasPrefix(out, tc.header+"\n"), "header in %q", out)
+			for _, w := range tc.want {
+				assert.ContainsString(t, out, w)
+			}
+			for _, a := range tc.absent {
+				assert.That(t, !strings.Contains(out, a), "%s in %q", a, out)
+			}
+		})
+	}
+}
+
+func TestDiffCommand_Parity_RevisionRange(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir := newTopicRepo(t)
 	repo := git.Repo{Dir: dir}
+
+	out := assertParity(t, repo, "main...topic")
+	assert.ContainsString(t, out, "topic.txt")
+	pt, _ := runBoth(t, repo, "main..topic", "main.txt")
+	assert.ContainsString(t, pt, "main.txt")
+	assert.That(t, !strings.Contains(pt, "topic.txt"), "pathspec narrows the summary: %q", pt)
+}
+
+func TestDiffCommand_PR(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir := newTopicRepo(t)
+	temp_repo.RunGit(t, dir, "checkout", "-q", "topic")
+
 	var buf bytes.Buffer
-	cmd := &DiffCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}}
-	err := cmd.Execute([]string{"unexpected"})
-	assert.That(t, err != nil, "expected error for positional args")
+	cmd := &DiffCommand{cmdIO: cmdIO{Out: &buf, Repo: git.Repo{Dir: dir}}, PR: true}
+	require.NoError(t, cmd.Execute(nil))
+	out := buf.String()
+	assert.That(t, strings.HasPrefix(out, "--- pr ---\n"), "header in %q", out)
+	assert.ContainsString(t, out, "[A ] topic.txt")
+	assert.That(t, !strings.Contains(out, "main.txt"), "main's own commit isn't in the PR: %q", out)
+}
+
+func TestDiffCommand_PRAgainstRemote(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir, _ := temp_repo.NewRepoWithRemote(t)
+	// origin/main gets b, which local main never sees; the PR branches
+	// off origin/main
+	temp_repo.CreateCommit(t, dir, "b.txt", "b\n", "feat: b")
+	temp_repo.RunGit(t, dir, "push", "-q", "origin", "main")
+	temp_repo.RunGit(t, dir, "reset", "-q", "--hard", "HEAD~1")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "feature", "origin/main")
+	temp_repo.CreateCommit(t, dir, "f.txt", "f\n", "feat: f")
+
+	run := func() string {
+		var buf bytes.Buffer
+		cmd := &DiffCommand{cmdIO: cmdIO{Out: &buf, Repo: git.Repo{Dir: dir}}, PR: true}
+		require.NoError(t, cmd.Execute(nil))
+		return buf.String()
+	}
+	out := run()
+	assert.ContainsString(t, out, "[A ] f.txt")
+	assert.That(t, !strings.Contains(out, "b.txt"), "upstream's own commit isn't in the PR: %q", out)
+
+	// nor does it need a local main at all
+	temp_repo.RunGit(t, dir, "branch", "-q", "-D", "main")
+	out = run()
+	assert.ContainsString(t, out, "[A ] f.txt")
+	assert.That(t, !strings.Contains(out, "b.txt"), "no local main: %q", out)
+}
+
+func TestDiffCommand_Pathspec(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\n", "feat: a")
+	temp_repo.WriteFile(t, dir, "a.txt", "A\n")
+	temp_repo.WriteFile(t, dir, "README.md", "changed\n")
+	temp_repo.WriteFile(t, dir, "new.txt", "n\n")
+
+	run := func(mode string, args ...string) string {
+		var buf bytes.Buffer
+		cmd := &DiffCommand{cmdIO: cmdIO{Out: &buf, Repo: git.Repo{Dir: dir}}, Mode: mode}
+		require.NoError(t, cmd.Execute(args))
+		return buf.String()
+	}
+	out := run("", "a.txt")
+	assert.ContainsString(t, out, "a.txt")
+	assert.That(t, !strings.Contains(out, "README.md"), "README.md in %q", out)
+
+	// the cascade skips levels the pathspecs leave empty
+	out = run("", "new.txt")
+	assert.That(t, strings.HasPrefix(out, "--- untracked ---\n"), "header in %q", out)
+	assert.ContainsString(t, out, "new.txt")
+
+	assert.Equal(t, run("untracked", "a.txt"), "")
+}
+
+func TestDiffCommand_DoubleDash(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir := newTopicRepo(t)
+	// a file named like the branch: only the -- tells them apart
+	temp_repo.CreateCommit(t, dir, "topic", "t\n", "feat: topic file")
+	temp_repo.WriteFile(t, dir, "topic", "changed\n")
+	temp_repo.WriteFile(t, dir, "main.txt", "changed\n")
+
+	run := func(args ...string) string {
+		var buf bytes.Buffer
+		cmd := &DiffCommand{cmdIO: cmdIO{Out: &buf, Repo: git.Repo{Dir: dir}}}
+		require.NoError(t, cmd.Execute(args))
+		return buf.String()
+	}
+	out := run("--", "topic")
+	assert.That(t, strings.HasPrefix(out, "--- work ---\n"), "header in %q", out)
+	assert.ContainsString(t, out, "] topic (+1,-1)")
+	assert.That(t, !strings.Contains(out, "main.txt"), "main.txt in %q", out)
+
+	out = run("main", "--", "topic")
+	assert.That(t, strings.HasPrefix(out, "--- main ---\n"), "header in %q", out)
+	assert.ContainsString(t, out, "] topic (+1,-1)")
+	assert.That(t, !strings.Contains(out, "main.txt"), "main.txt in %q", out)
+}
+
+func TestDiffCommand_RejectsBadArgs(t *testing.T) {
+	dir := newTopicRepo(t)
+	cases := map[string]struct {
+		cmd  DiffCommand
+		args []string
+		want string
+	}{
+		"unknown range":  {args: []string{"nope..main"}, want: "unknown revision"},
+		"range and mode": {cmd: DiffCommand{Mode: "work"}, args: []string{"main..topic"}, want: "--mode"},
+		"pr and mode":    {cmd: DiffCommand{Mode: "index", PR: true}, want: "--mode"},
+		"path before --": {args: []string{"main.txt", "--"}, want: "main.txt: unknown revision"},
+		"two before --":  {args: []string{"main", "topic", "--"}, want: "only a revision range"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cmd := tc.cmd
+			cmd.cmdIO = cmdIO{Out: &bytes.Buffer{}, Repo: git.Repo{Dir: dir}}
+			err := cmd.Execute(tc.args)
+			assert.Error(t, err, assert.AnyError)
+			assert.ContainsString(t, err.Error(), tc.want)
+		})
+	}
+}
+
+func TestDiffCommand_Levels(t *testing.T) {
+	t.Parallel()
+	dir := newTopicRepo(t)
+	temp_repo.RunGit(t, dir, "branch", "head")
+	cases := map[string][]string{
+		"":            diffModes,
+		"main..topic": append(slices.Clone(diffModes), "main..topic"),
+		// a branch named like a level gets a tab of its own
+		"head": append(slices.Clone(diffModes), "head (revision)"),
+	}
+	for arg, want := range cases {
+		cmd := &DiffCommand{cmdIO: cmdIO{Repo: git.Repo{Dir: dir}}}
+		var args []string
+		if arg != "" {
+			args = []string{arg}
+		}
+		require.NoError(t, cmd.parseArgs(args))
+		assert.EqualArrays(t, cmd.levels(), want, "levels for %q", arg)
+	}
+	assert.Equal(t, len(diffModes), 4, "levels mustn't grow diffModes")
 }
 
 func TestDiffCommand_FollowRequiresTTY(t *testing.T) {
@@ -484,3 +685,141 @@ func TestCountUntrackedLines_MissingFileIsUnknown(t *testing.T) {
 	n := countUntrackedLines("/nonexistent/path/should/not/exist", 1*time.Second)
 	assert.That(t, n.unknown, "missing file -> unknown")
 }
+
+func TestCollectDiff_SubmodulePointer(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	sub := filepath.Join(super, "lib")
+	before := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+	temp_repo.CreateCommit(t, sub, "a.txt", "a\n", "feat: a")
+	after := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+
+	cmd := &DiffCommand{cmdIO: cmdIO{Repo: git.Repo{Dir: super}}}
+	out, err := cmd.collectDiff("work")
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "submodule lib: "+shortOid(before)+" -> "+shortOid(after)+" (+1 commit)")
+
+	temp_repo.RunGit(t, super, "commit", "-am", "chore: bump lib")
+	out, err = cmd.collectDiff("head")
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "submodule lib: "+shortOid(before)+" -> "+shortOid(after)+" (+1 commit)")
+}
+
+func TestCollectDiff_SubmodulePointerPathspec(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	temp_repo.CreateCommit(t, filepath.Join(super, "lib"), "a.txt", "a\n", "feat: a")
+	temp_repo.WriteFile(t, super, "README.md", "changed\n")
+
+	cmd := &DiffCommand{cmdIO: cmdIO{Repo: git.Repo{Dir: super}}}
+	require.NoError(t, cmd.parseArgs([]string{"lib"}))
+	out, err := cmd.collectDiff("work")
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "submodule lib: ")
+	assert.That(t, !strings.Contains(out, "README.md"), "README.md in %q", out)
+
+	require.NoError(t, cmd.parseArgs([]string{"README.md"}))
+	out, err = cmd.collectDiff("work")
+	require.NoError(t, err)
+	assert.That(t, !strings.Contains(out, "submodule lib"), "lib is outside the pathspec: %q", out)
+}
+
+func TestDescribeSubmoduleChange(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	const a, b = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	cases := []struct {
+		c    git.SubmoduleChange
+		want string
+	}{
+		{git.SubmoduleChange{Path: "lib", New: b}, "submodule lib: added at bbbbbbbbbbbb"},
+		{git.SubmoduleChange{Path: "lib", Old: a}, "submodule lib: removed (was aaaaaaaaaaaa)"},
+		{git.SubmoduleChange{Path: "lib", Old: a, New: b}, "submodule lib: aaaaaaaaaaaa -> bbbbbbbbbbbb"},
+		{git.SubmoduleChange{Path: "lib", Old: a, New: b, Ahead: 2, Counted: true}, "submodule lib: aaaaaaaaaaaa -> bbbbbbbbbbbb (+2 commits)"},
+		{git.SubmoduleChange{Path: "lib", Old: a, New: b, Behind: 1, Counted: true}, "submodule lib: aaaaaaaaaaaa -> bbbbbbbbbbbb (-1 commit)"},
+		{git.SubmoduleChange{Path: "lib", Old: a, New: b, Ahead: 3, Behind: 1, Counted: true}, "submodule lib: aaaaaaaaaaaa -> bbbbbbbbbbbb (+3 -1 commits)"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, describeSubmoduleChange(tc.c), tc.want)
+	}
+}
+
+func TestNativeDiff(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("GG_DIFF_NATIVE", "")
+	dir := temp_repo.NewRepo(t)
+	body := "package main\n\nfunc main() {\n\ta := 1\n\tb := 2\n\tc := 3\n\td := 4\n\tprintln(a, b, c, d)\n}\n"
+	temp_repo.CreateCommit(t, dir, "main.go", body, "chore: add main")
+	temp_repo.WriteFile(t, dir, "main.go", strings.Replace(body, "d := 4", "d := 5", 1))
+	// diff config the native renderer must not depend on
+	temp_repo.RunGit(t, dir, "config", "diff.noprefix", "true")
+	temp_repo.RunGit(t, dir, "config", "diff.context", "0")
+
+	cmd := &DiffCommand{cmdIO: cmdIO{Repo: git.Repo{Dir: dir}}}
+	out, err := cmd.collectDiff("work")
+	require.NoError(t, err)
+	assert.Equal(t, out, strings.Join([]string{
+		"[ M] main.go (+1,-1)",
+		"",
+		"main.go",
+		"@@ -4,6 +4,6 @@ func main() {",
+		" \ta := 1",
+		" \tb := 2",
+		" \tc := 3",
+		"-\td := 4",
+		"+\td := 5",
+		" \tprintln(a, b, c, d)",
+		" }",
+	}, "\n"))
+
+	temp_repo.RunGit(t, dir, "add", "main.go")
+	out, err = cmd.collectDiff("index")
+	require.NoError(t, err)
+	assert.That(t, strings.HasPrefix(out, "[M ] main.go (+1,-1)\n"), "staged code goes in the X column, got:\n", out)
+}
+
+func TestRenderFileDiff(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	cases := map[string]struct {
+		f    diff.File
+		want string
+	}{
+		"renamed": {
+			diff.File{OldPath: "a.go", NewPath: "pkg/a.go", Status: diff.Renamed, Similarity: 92},
+			"pkg/a.go (renamed from a.go, 92% similar)\n",
+		},
+		"new executable": {
+			diff.File{OldPath: "run.sh", NewPath: "run.sh", Status: diff.Added, NewMode: "100755"},
+			"run.sh (new file, mode 100755)\n",
+		},
+		"mode change": {
+			diff.File{OldPath: "run.sh", NewPath: "run.sh", Status: diff.Modified, OldMode: "100644", NewMode: "100755"},
+			"run.sh (mode 100644 -> 100755)\n",
+		},
+		"binary": {
+			diff.File{OldPath: "logo.png", NewPath: "logo.png", Status: diff.Deleted, Binary: true},
+			"logo.png (deleted)\nbinary file differs\n",
+		},
+		"no newline": {
+			diff.File{OldPath: "a.txt", NewPath: "a.txt", Status: diff.Modified, Hunks: []diff.Hunk{{
+				OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+				Lines: []diff.Line{
+					{Kind: diff.Del, Text: "a", OldNum: 1, NoNewline: true},
+					{Kind: diff.Add, Text: "b", NewNum: 1},
+				},
+			}}},
+			"a.txt\n@@ -1 +1 @@\n-a\n\\ no newline at end of file\n+b\n",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var b strings.Builder
+			renderFileDiff(&b, tc.f, diffView{})
+			assert.Equal(t, b.String(), tc.want)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// StartDryRun turns on dry-run mode for the run: every git write (and
+// release's file edits) is printed to stderr instead of run. dest is ""
+// for off, "-" or "1" to only print, or a path to also save the writes
+// as a replayable shell script. Falls back to GG_DRY_RUN when empty.
+//
+// The returned finish reports how many writes were skipped, writes the
+// script, and turns dry-run mode back off.
+func StartDryRun(dest string) (finish func() error, err error) {
+	if dest == "" {
+		dest = os.Getenv("GG_DRY_RUN")
+	}
+	if dest == "" || dest == "0" || dest == "false" {
+		return func() error { return nil }, nil
+	}
+	script := dest
+	if dest == "-" || dest == "1" || dest == "true" {
+		script = ""
+	}
+
+	dr := git.NewDryRun(os.Stderr)
+	git.SetDryRun(dr)
+	return func() error {
+		git.SetDryRun(nil)
+		if script == "" {
+			fmt.Fprintf(os.Stderr, "dry-run: skipped %d writes (--dry-run=FILE saves them as a script)\n", dr.Len())
+			return nil
+		}
+		if err := os.WriteFile(script, []byte(dr.Script()), 0o755); err != nil {
+			return fmt.Errorf("writing dry-run script: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "dry-run: skipped %d writes, replay with: sh %s\n", dr.Len(), script)
+		return nil
+	}, nil
+}
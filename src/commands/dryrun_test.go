@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+// not parallel: dry-run mode is process-wide
+func TestStartDryRun_CleanLeavesTreeAlone(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	temp_repo.WriteFile(t, dir, "README.md", "modified\n")
+	temp_repo.WriteFile(t, dir, "untracked.txt", "untracked\n")
+	script := filepath.Join(t.TempDir(), "replay.sh")
+
+	finish, err := StartDryRun(script)
+	require.NoError(t, err)
+	cmd := &CleanCommand{Yes: true, cmdIO: cmdIO{Repo: git.Repo{Dir: dir}}}
+	runErr := cmd.Execute(nil)
+	require.NoError(t, finish())
+	require.NoError(t, runErr)
+
+	fileContent(t, dir, "README.md", "modified\n")
+	fileExists(t, dir, "untracked.txt")
+
+	info, err := os.Stat(script)
+	require.NoError(t, err)
+	assert.That(t, info.Mode()&0o100 != 0, "script should be executable: %v", info.Mode())
+	body, err := os.ReadFile(script)
+	require.NoError(t, err)
+	assert.ContainsString(t, string(body), "reset --hard\n")
+	assert.ContainsString(t, string(body), "clean -fd\n")
+}
+
+func TestStartDryRun_Off(t *testing.T) {
+	t.Setenv("GG_DRY_RUN", "")
+	finish, err := StartDryRun("")
+	require.NoError(t, err)
+	require.NoError(t, finish())
+
+	dir := temp_repo.NewRepo(t)
+	temp_repo.WriteFile(t, dir, "untracked.txt", "untracked\n")
+	cmd := &CleanCommand{Yes: true, cmdIO: cmdIO{Repo: git.Repo{Dir: dir}}}
+	require.NoError(t, cmd.Execute(nil))
+	fileNotExists(t, dir, "untracked.txt")
+}
@@ -62,7 +62,7 @@ func (e *EmptyCommand) Execute(args []string) error {
 			return err
 		}
 		if confirmed {
-			if err := r.Push(); err != nil {
+			if err := r.PushCtx(e.ctx()); err != nil {
 				return fmt.Errorf("pushing: %w", err)
 			}
 			fmt.Fprintln(e.out(), "Pushed to remote.")
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// explainGitError appends a remedy to err for the git failures the user
+// has to sort out by hand. Other errors come back unchanged; kinds a
+// command can recover from (non-fast-forward, not fully merged) are
+// handled at the call site instead.
+func explainGitError(err error) error {
+	var hint string
+	switch git.KindOf(err) {
+	case git.KindLockExists:
+		hint = "another git process seems to be running in this repo; if not, remove the stale .lock file named above"
+	case git.KindRemoteUnreachable:
+		hint = "the remote could not be reached; check its url, your network and credentials"
+	case git.KindDirtyOverwrite:
+		hint = "local changes would be overwritten; commit, stash or remove them first"
+	case git.KindMergeConflict:
+		hint = "resolve the conflicts, or abort the operation that left them"
+	default:
+		return err
+	}
+	return fmt.Errorf("%w\nhint: %s", err, hint)
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"io"
 	"os"
 
@@ -17,7 +18,22 @@ type cmdIO struct {
 	Out  io.Writer
 	Err  io.Writer
 	UI   ui.Selector
-	Repo git.Repo
+	Repo git.Backend
+	Ctx  context.Context
+}
+
+// SetContext hands the command the context it runs under: main's, which
+// is cancelled on Ctrl-C and carries the network timeout.
+func (c *cmdIO) SetContext(ctx context.Context) { c.Ctx = ctx }
+
+// ctx is the command's context for calls that can hang or run long --
+// anything talking to a remote, and pickers' background producers.
+// Quick local reads use the plain Repo methods.
+func (c *cmdIO) ctx() context.Context {
+	if c.Ctx != nil {
+		return c.Ctx
+	}
+	return context.Background()
 }
 
 func (c *cmdIO) out() io.Writer {
@@ -41,8 +57,11 @@ func (c *cmdIO) sel() ui.Selector {
 	return ui.RealSelector{}
 }
 
-// repo returns the cmdIO's Repo. Zero value (Repo{Dir: ""}) targets the
-// process cwd, matching the prior free-function git.X() behaviour.
-func (c *cmdIO) repo() git.Repo {
-	return c.Repo
+// repo returns the cmdIO's Repo. Nil targets the process cwd, matching the
+// prior free-function git.X() behaviour.
+func (c *cmdIO) repo() git.Backend {
+	if c.Repo != nil {
+		return c.Repo
+	}
+	return git.CWD()
 }
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// journalOp records one write operation for gg undo. begin snapshots the
+// refs the operation may move; record snapshots them again and journals
+// whatever changed, along with anything the command added to entry.
+type journalOp struct {
+	repo   git.Backend
+	entry  git.JournalEntry
+	refs   []string
+	before map[string]string
+}
+
+func beginJournal(r git.Backend, op, summary string, refs ...string) *journalOp {
+	before, err := r.RefOids(refs...)
+	if err != nil {
+		before = nil // record will notice and skip the ref diff
+	}
+	return &journalOp{
+		repo:   r,
+		entry:  git.JournalEntry{Op: op, Summary: summary},
+		refs:   refs,
+		before: before,
+	}
+}
+
+// stashChanges saves the tracked changes an operation is about to discard
+// into the entry, so undo can bring them back.
+func (j *journalOp) stashChanges() error {
+	sha, err := j.repo.StashCreate()
+	if err != nil {
+		return err
+	}
+	j.entry.Stash = sha
+	return nil
+}
+
+// record journals the operation if it changed anything undo could act on
+// or report. The operation itself already happened, so failing to record
+// it is only a warning on w.
+func (j *journalOp) record(w io.Writer) {
+	if j.before != nil {
+		after, err := j.repo.RefOids(j.refs...)
+		if err == nil {
+			for _, name := range j.refs {
+				if j.before[name] != after[name] {
+					j.entry.Refs = append(j.entry.Refs, git.RefUpdate{Name: name, Old: j.before[name], New: after[name]})
+				}
+			}
+		}
+	}
+	e := j.entry
+	if len(e.Refs) == 0 && len(e.Pushed) == 0 && e.Stash == "" && e.Discarded == "" && len(e.Removed) == 0 {
+		return
+	}
+	if err := j.repo.AppendJournal(e); err != nil {
+		fmt.Fprintf(w, "warning: not recorded for gg undo: %v\n", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/lczyk/gitgum/internal/git"
 	"github.com/lczyk/gitgum/internal/ui"
 )
 
@@ -51,8 +52,8 @@ func (p *PushCommand) Execute(args []string) error {
 		if !confirmed {
 			return nil
 		}
-		if err := p.repo().Push(); err != nil {
-			return fmt.Errorf("failed to push: %w", err)
+		if err := p.push(remoteBranch); err != nil {
+			return err
 		}
 		fmt.Fprintf(p.out(), "Pushed to remote tracking branch '%s'.\n", remoteBranch)
 		return nil
@@ -98,7 +99,7 @@ func (p *PushCommand) Execute(args []string) error {
 
 	expectedRemoteBranchName := selectedRemote + "/" + currentBranch
 
-	remoteBranchExists, err := p.repo().RemoteBranchExists(selectedRemote, currentBranch)
+	remoteBranchExists, err := p.repo().RemoteBranchExistsCtx(p.ctx(), selectedRemote, currentBranch)
 	if err != nil {
 		return fmt.Errorf("checking remote branch: %w", err)
 	}
@@ -116,8 +117,8 @@ func (p *PushCommand) Execute(args []string) error {
 			return nil
 		}
 
-		if _, stderr, err := p.repo().RunWriteStream("push", "-u", selectedRemote, currentBranch); err != nil {
-			return fmt.Errorf("failed to push: %w: %s", err, strings.TrimSpace(stderr))
+		if err := p.push(expectedRemoteBranchName, "-u", selectedRemote, currentBranch); err != nil {
+			return err
 		}
 		fmt.Fprintf(p.out(), "Created and set tracking reference for '%s' to '%s'.\n",
 			currentBranch, expectedRemoteBranchName)
@@ -157,9 +158,43 @@ func (p *PushCommand) Execute(args []string) error {
 		return nil
 	}
 
-	if _, stderr, err := p.repo().RunWriteStream("push", selectedRemote, currentBranch); err != nil {
-		return fmt.Errorf("failed to push: %w: %s", err, strings.TrimSpace(stderr))
+	if err := p.push(expectedRemoteBranchName, selectedRemote, currentBranch); err != nil {
+		return err
 	}
 	fmt.Fprintf(p.out(), "Pushed to remote branch '%s'.\n", expectedRemoteBranchName)
 	return nil
 }
+
+// errPushRejected is returned when the remote has moved on and the user
+// declines to overwrite it.
+var errPushRejected = errors.New("push rejected: the remote has commits this branch doesn't; pull or rebase, then push again")
+
+// push runs `git push args...`. A non-fast-forward rejection is offered a
+// retry with --force-with-lease, which only overwrites target if it still
+// sits where our remote-tracking ref last saw it -- so work pushed by
+// someone else since the last fetch is never lost.
+func (p *PushCommand) push(target string, args ...string) error {
+	_, stderr, err := p.repo().RunWriteStreamCtx(p.ctx(), append([]string{"push"}, args...)...)
+	if err == nil {
+		return nil
+	}
+	if git.KindOf(err) != git.KindNonFastForward {
+		return explainGitError(fmt.Errorf("failed to push: %w: %s", err, strings.TrimSpace(stderr)))
+	}
+
+	confirmed, err := p.sel().Confirm(fmt.Sprintf("'%s' has commits that aren't on this branch. Overwrite it with --force-with-lease?", target), false)
+	if err != nil {
+		if errors.Is(err, ui.ErrCancelled) {
+			return errPushRejected
+		}
+		return fmt.Errorf("confirming force push: %w", err)
+	}
+	if !confirmed {
+		return errPushRejected
+	}
+	forceArgs := append([]string{"push", "--force-with-lease"}, args...)
+	if _, stderr, err := p.repo().RunWriteStreamCtx(p.ctx(), forceArgs...); err != nil {
+		return explainGitError(fmt.Errorf("failed to force push: %w: %s", err, strings.TrimSpace(stderr)))
+	}
+	return nil
+}
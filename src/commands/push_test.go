@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"io"
 	"os/exec"
 	"strings"
 	"testing"
@@ -125,3 +126,122 @@ func TestPushCommand_AlreadyUpToDate_SetsUpstream(t *testing.T) {
 	upstream := strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "--abbrev-ref", branch+"@{u}"))
 	assert.Equal(t, upstream, "origin/"+branch)
 }
+
+// divergeRemote moves origin's main on from a second clone, so the next
+// push from local is rejected as non-fast-forward.
+func divergeRemote(t *testing.T, local, remote string) {
+	t.Helper()
+	other := t.TempDir()
+	temp_repo.RunGit(t, other, "clone", "-q", remote, ".")
+	temp_repo.RunGit(t, other, "config", "user.name", "Other User")
+	temp_repo.RunGit(t, other, "config", "user.email", "other@example.com")
+	temp_repo.CreateCommit(t, other, "theirs.txt", "theirs", "feat: theirs")
+	temp_repo.RunGit(t, other, "push", "-q", "origin", "main")
+	temp_repo.RunGit(t, local, "fetch", "-q", "origin")
+	temp_repo.CreateCommit(t, local, "ours.txt", "ours", "feat: ours")
+}
+
+// a non-fast-forward rejection offers --force-with-lease; yes overwrites
+// the remote with the local branch.
+func TestPushCommand_NonFastForward_ForceWithLease(t *testing.T) {
+	t.Parallel()
+	local, remote := temp_repo.NewRepoWithRemote(t)
+	divergeRemote(t, local, remote)
+
+	var buf strings.Builder
+	stub := &stubSelector{confirmAnswers: []bool{true, true}}
+	cmd := &PushCommand{cmdIO: cmdIO{Out: &buf, UI: stub, Repo: git.Repo{Dir: local}}}
+	require.NoError(t, cmd.Execute(nil))
+
+	assert.Equal(t, len(stub.confirmCalls), 2)
+	assert.ContainsString(t, stub.confirmCalls[1].Prompt, "--force-with-lease")
+	assert.Equal(t, stub.confirmCalls[1].DefaultYes, false)
+	localHead := strings.TrimSpace(temp_repo.RunGit(t, local, "rev-parse", "HEAD"))
+	remoteHead := strings.TrimSpace(temp_repo.RunGit(t, remote, "rev-parse", "main"))
+	assert.Equal(t, remoteHead, localHead)
+}
+
+// declining the force push leaves the remote alone and reports the
+// rejection.
+func TestPushCommand_NonFastForward_Declined(t *testing.T) {
+	t.Parallel()
+	local, remote := temp_repo.NewRepoWithRemote(t)
+	divergeRemote(t, local, remote)
+	before := strings.TrimSpace(temp_repo.RunGit(t, remote, "rev-parse", "main"))
+
+	stub := &stubSelector{confirmAnswers: []bool{true, false}}
+	cmd := &PushCommand{cmdIO: cmdIO{UI: stub, Repo: git.Repo{Dir: local}}}
+	err := cmd.Execute(nil)
+
+	assert.Error(t, err, errPushRejected)
+	assert.Equal(t, strings.TrimSpace(temp_repo.RunGit(t, remote, "rev-parse", "main")), before)
+}
+
+// the error paths below need a remote that misbehaves on cue, which is
+// what git.Fake is for.
+
+// fakeDiverged is a Fake whose main tracks origin/main, with a commit on
+// each side the other lacks. Nothing has fetched origin's commit yet.
+func fakeDiverged(t *testing.T) *git.Fake {
+	t.Helper()
+	f := git.NewFake()
+	base := f.Refs["refs/heads/main"]
+	f.AddRemote("origin", map[string]string{"main": base})
+	f.Upstreams["main"] = "origin/main"
+	f.RemoteRefs["origin"]["refs/heads/main"] = f.NewCommit(base)
+	require.NoError(t, f.Commit("ours"))
+	return f
+}
+
+// someone pushed after our last fetch: the lease is stale, so the force
+// push is refused too and the remote keeps their commit.
+func TestPushCommand_ForceWithLease_StaleLease(t *testing.T) {
+	t.Parallel()
+	f := fakeDiverged(t)
+	theirs := f.RemoteRefs["origin"]["refs/heads/main"]
+
+	stub := &stubSelector{confirmAnswers: []bool{true, true}}
+	cmd := &PushCommand{cmdIO: cmdIO{Out: io.Discard, UI: stub, Repo: f}}
+	err := cmd.Execute(nil)
+
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "failed to force push")
+	assert.Equal(t, git.KindOf(err), git.KindNonFastForward)
+	assert.Equal(t, f.RemoteRefs["origin"]["refs/heads/main"], theirs)
+}
+
+// a push that fails for any reason other than non-fast-forward is not
+// offered a force push.
+func TestPushCommand_PushFails_NoForceOffer(t *testing.T) {
+	t.Parallel()
+	f := fakeDiverged(t)
+	f.FailNext("push", git.FakeError(git.KindRemoteUnreachable, "fatal: unable to access 'https://example.com/': Could not resolve host"))
+
+	stub := &stubSelector{confirmAnswers: []bool{true}}
+	cmd := &PushCommand{cmdIO: cmdIO{Out: io.Discard, UI: stub, Repo: f}}
+	err := cmd.Execute(nil)
+
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "Could not resolve host")
+	assert.ContainsString(t, err.Error(), "hint: the remote could not be reached")
+	assert.Equal(t, len(stub.confirmCalls), 1)
+}
+
+// an unreachable remote while checking for the branch stops before any
+// push is attempted.
+func TestPushCommand_RemoteBranchCheckFails(t *testing.T) {
+	t.Parallel()
+	f := git.NewFake()
+	f.AddRemote("origin", nil)
+	f.FailNext("ls-remote", git.FakeError(git.KindRemoteUnreachable, "fatal: Could not read from remote repository."))
+	rec := git.NewRecorder(f)
+
+	cmd := &PushCommand{cmdIO: cmdIO{Out: io.Discard, UI: &stubSelector{}, Repo: rec}}
+	err := cmd.Execute([]string{"origin"})
+
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "checking remote branch")
+	for _, c := range rec.Calls() {
+		assert.That(t, c.Method != "RunWriteStream", "unexpected push: %s", c)
+	}
+}
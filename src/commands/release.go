@@ -164,23 +164,44 @@ func (r *ReleaseCommand) Execute(args []string) error {
 		}
 	}
 
+	refs := []string{"refs/heads/" + branch}
+	for _, t := range tags {
+		refs = append(refs, "refs/tags/"+t)
+	}
+	j := beginJournal(repo, "release", "release "+strings.Join(tags, ", "), refs...)
+	defer j.record(r.err())
+
 	commitMsg := "release: " + tags[0]
 	if err := repo.Commit(commitMsg); err != nil {
-		return err
+		return explainGitError(err)
 	}
-	for _, t := range tags {
+	for i, t := range tags {
 		if err := repo.TagAnnotated(t, "release "+t); err != nil {
-			return err
+			return releaseTagError(t, tags[:i], err)
 		}
 	}
 
 	fmt.Fprintf(r.out(), "\nTagged %s. To publish:\n", strings.Join(tags, ", "))
 	fmt.Fprintf(r.out(), "  %s\n", formatPublishPushes(remote, defaultBranch, tags))
-	fmt.Fprintln(r.out(), "\nTo fully undo (drops the commit and the tag(s)):")
+	fmt.Fprintln(r.out(), "\nTo fully undo (drops the commit and the tag(s)), run gg undo, or:")
 	fmt.Fprintf(r.out(), "  git reset --hard HEAD~1 && git tag -d %s\n", strings.Join(tags, " "))
 	return nil
 }
 
+// releaseTagError reports a tag that failed after the release commit
+// landed. The commit and any tags made before it stay put, so the message
+// carries the command that drops them.
+func releaseTagError(tag string, created []string, err error) error {
+	undo := "git reset --hard HEAD~1"
+	if len(created) > 0 {
+		undo += " && git tag -d " + strings.Join(created, " ")
+	}
+	if git.KindOf(err) == git.KindRefExists {
+		return fmt.Errorf("tag %s already exists; the release commit was kept -- to drop it: %s", tag, undo)
+	}
+	return fmt.Errorf("%w\nthe release commit was kept -- to drop it: %s", explainGitError(err), undo)
+}
+
 // maxTagsPerPush is github's ceiling: a single push carrying more than this
 // many tags generates no `push` workflow events for any tag in it -- the
 // trigger silently never fires (see case.md). split tag pushes to stay under.
@@ -223,7 +244,7 @@ func regularFileExists(path string) bool {
 	return err == nil && info.Mode().IsRegular()
 }
 
-func repoRoot(r git.Repo) (string, error) {
+func repoRoot(r git.Backend) (string, error) {
 	out, _, err := r.Run("rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", fmt.Errorf("find repo root: %w", err)
@@ -242,25 +263,19 @@ type releasedState struct {
 // file for auto-bump, so a release commit can legitimately edit anything.
 // Tag-at-HEAD is the strong signal: a stray "release: ..." subject won't
 // also have its corresponding tag pointing to that commit.
-func alreadyReleased(r git.Repo) (releasedState, bool) {
-	subject, _, err := r.Run("log", "-1", "--format=%s")
+func alreadyReleased(r git.Backend) (releasedState, bool) {
+	head, err := r.CommitInfo("HEAD")
 	if err != nil {
 		return releasedState{}, false
 	}
+	subject := head[0].Subject()
 	tag, ok := strings.CutPrefix(subject, "release: ")
 	if !ok || (!strings.HasPrefix(tag, "v") && !strings.HasPrefix(tag, "r")) {
 		return releasedState{}, false
 	}
 
-	tagSHA, _, err := r.Run("rev-parse", tag+"^{commit}")
-	if err != nil {
-		return releasedState{}, false
-	}
-	headSHA, _, err := r.Run("rev-parse", "HEAD")
-	if err != nil {
-		return releasedState{}, false
-	}
-	if tagSHA != headSHA {
+	tagged, err := r.CommitInfo(tag)
+	if err != nil || tagged[0].Oid != head[0].Oid {
 		return releasedState{}, false
 	}
 	return releasedState{tag: tag, subject: subject}, true
@@ -269,7 +284,7 @@ func alreadyReleased(r git.Repo) (releasedState, bool) {
 // defaultBranchPushRemote returns the remote the default branch tracks.
 // Falls back to the sole configured remote, then "origin", so the suggested
 // push command stays useful even when the default branch has no upstream yet.
-func defaultBranchPushRemote(r git.Repo, defaultBranch string) string {
+func defaultBranchPushRemote(r git.Backend, defaultBranch string) string {
 	if name, _ := r.GetBranchTrackingRemote(defaultBranch); name != "" {
 		return name
 	}
@@ -280,7 +295,7 @@ func defaultBranchPushRemote(r git.Repo, defaultBranch string) string {
 }
 
 // latestSemverTag returns the highest vX.Y.Z tag, or "" if none exist.
-func latestSemverTag(r git.Repo) string {
+func latestSemverTag(r git.Backend) string {
 	out, _, err := r.Run("tag", "--list", "v*", "--sort=-v:refname")
 	if err != nil || out == "" {
 		return ""
@@ -295,7 +310,7 @@ func latestSemverTag(r git.Repo) string {
 
 // readVersionOrFallback reads VERSION at path, falling back to the latest
 // vX.Y.Z tag, then to "0.0.0". hasFile reports whether VERSION exists.
-func readVersionOrFallback(r git.Repo, path string) (header, prefixes []string, current string, hasFile bool, err error) {
+func readVersionOrFallback(r git.Backend, path string) (header, prefixes []string, current string, hasFile bool, err error) {
 	header, prefixes, current, err = readVersion(path)
 	if err == nil {
 		return header, prefixes, current, true, nil
@@ -367,7 +382,7 @@ func writeVersion(path string, header []string, version string) error {
 	}
 	b.WriteString(version)
 	b.WriteByte('\n')
-	return os.WriteFile(path, []byte(b.String()), 0o644)
+	return git.WriteFile(path, []byte(b.String()), 0o644)
 }
 
 type semver struct{ major, minor, patch int }
@@ -55,7 +55,7 @@ func lineMentionsVersion(line, version string) bool {
 // reference the literal current version (with digit/dot boundary) and
 // contain the word "version" (case-insensitive). The VERSION file itself
 // is skipped -- it's handled by the bumper directly.
-func scanVersionMentions(r git.Repo, root, current string) ([]versionMention, error) {
+func scanVersionMentions(r git.Backend, root, current string) ([]versionMention, error) {
 	out, _, err := r.Run("ls-files")
 	if err != nil {
 		return nil, fmt.Errorf("git ls-files: %w", err)
@@ -232,5 +232,5 @@ func rewriteLines(absPath string, lineNums []int, current, next string) error {
 	if hasTrailingNL {
 		out += "\n"
 	}
-	return os.WriteFile(absPath, []byte(out), 0o644)
+	return git.WriteFile(absPath, []byte(out), 0o644)
 }
@@ -1,12 +1,17 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/lczyk/assert"
 	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
 )
 
 func TestParseSemver(t *testing.T) {
@@ -212,3 +217,39 @@ func TestBuildRevisionTags(t *testing.T) {
 		[]string{"r3", "go/r3", "rust/r3"},
 	)
 }
+
+func TestReleaseTagError(t *testing.T) {
+	exists := &git.Error{Args: []string{"tag", "-a", "pkg/v1.2.3"}, ExitCode: 128, Kind: git.KindRefExists}
+	err := releaseTagError("pkg/v1.2.3", []string{"v1.2.3"}, fmt.Errorf("git tag pkg/v1.2.3: %w", exists))
+	assert.Equal(t, err.Error(),
+		"tag pkg/v1.2.3 already exists; the release commit was kept -- to drop it: git reset --hard HEAD~1 && git tag -d v1.2.3")
+
+	locked := &git.Error{Args: []string{"tag", "-a", "v1.2.3"}, ExitCode: 128, Kind: git.KindLockExists}
+	err = releaseTagError("v1.2.3", nil, locked)
+	assert.That(t, errors.Is(err, locked), "should wrap the git error")
+	assert.ContainsString(t, err.Error(), "hint: another git process")
+	assert.That(t, strings.HasSuffix(err.Error(), "to drop it: git reset --hard HEAD~1"), "undo without tags: %q", err.Error())
+}
+
+func TestAlreadyReleased(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	r := git.Repo{Dir: dir}
+	t.Cleanup(func() { _ = r.Close() })
+
+	_, ok := alreadyReleased(r)
+	assert.That(t, !ok, "plain commit is not a release")
+
+	temp_repo.CreateCommit(t, dir, "VERSION", "1.0.0\n", "release: v1.0.0")
+	_, ok = alreadyReleased(r)
+	assert.That(t, !ok, "release subject without its tag")
+
+	temp_repo.RunGit(t, dir, "tag", "-a", "v1.0.0", "-m", "release v1.0.0")
+	state, ok := alreadyReleased(r)
+	assert.That(t, ok, "tagged release commit at HEAD")
+	assert.Equal(t, state.tag, "v1.0.0")
+
+	temp_repo.CreateCommit(t, dir, "a.txt", "a", "release: v1.0.0")
+	_, ok = alreadyReleased(r)
+	assert.That(t, !ok, "tag points at an older commit")
+}
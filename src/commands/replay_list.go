@@ -16,7 +16,7 @@ type ReplayListCommand struct {
 }
 
 // returns commits on branchA since divergence from branchB, oldest-first
-func listCommits(repo git.Repo, branchA, branchB string) ([]string, error) {
+func listCommits(repo git.Backend, branchA, branchB string) ([]string, error) {
 	mergeBase, _, err := repo.Run("merge-base", branchA, branchB)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find merge base between '%s' and '%s': %w", branchA, branchB, err)
@@ -3,8 +3,12 @@ package commands
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/lczyk/gitgum/internal/ui"
 	ff "github.com/lczyk/gitgum/src/fuzzyfinder"
+	"github.com/lczyk/gitgum/src/litescreen/ansi"
 )
 
 // stubSelector replays canned answers for selector calls. Tests use it to
@@ -14,10 +18,14 @@ import (
 type stubSelector struct {
 	selectAnswers      []string
 	multiSelectAnswers [][]string
+	graphAnswers       [][]string
+	linesAnswers       [][]string
 	confirmAnswers     []bool
 
 	selectCalls      []selectCall
 	multiSelectCalls []selectCall
+	graphCalls       []selectCall
+	linesCalls       []selectCall
 	confirmCalls     []confirmCall
 }
 
@@ -25,6 +33,8 @@ type selectCall struct {
 	Prompt  string
 	Options []string
 	Stream  bool
+	// Preview is SelectPreview's text for the answer it gave.
+	Preview string
 }
 
 type confirmCall struct {
@@ -66,6 +76,87 @@ func (s *stubSelector) MultiSelect(prompt string, options []string) ([]string, e
 	return answer, nil
 }
 
+func (s *stubSelector) SelectGraph(prompt string, rows []string, isContext func(string) bool, multi bool) ([]string, error) {
+	s.graphCalls = append(s.graphCalls, selectCall{Prompt: prompt, Options: rows})
+	if len(s.graphAnswers) == 0 {
+		return nil, fmt.Errorf("stubSelector: unexpected SelectGraph call %q", prompt)
+	}
+	// graph answers name rows by a substring of their stripped text; the
+	// rendered glyph prefix isn't something a test should have to spell out.
+	needles := s.graphAnswers[0]
+	s.graphAnswers = s.graphAnswers[1:]
+	var picked []string
+	for _, needle := range needles {
+		i := slices.IndexFunc(rows, func(row string) bool { return strings.Contains(ansi.Strip(row), needle) })
+		if i < 0 {
+			return nil, fmt.Errorf("stubSelector: no row contains %q", needle)
+		}
+		if isContext != nil && isContext(ansi.Strip(rows[i])) {
+			return nil, fmt.Errorf("stubSelector: answer %q is a context row", needle)
+		}
+		picked = append(picked, rows[i])
+	}
+	return picked, nil
+}
+
+func (s *stubSelector) SelectPreview(prompt string, options []string, preview func(string) string) (string, error) {
+	call := selectCall{Prompt: prompt, Options: options}
+	if len(s.selectAnswers) == 0 {
+		s.selectCalls = append(s.selectCalls, call)
+		return "", fmt.Errorf("stubSelector: unexpected SelectPreview call %q", prompt)
+	}
+	// preview answers name an option by a substring, like graph answers;
+	// "" is the user backing out
+	needle := s.selectAnswers[0]
+	s.selectAnswers = s.selectAnswers[1:]
+	if needle == "" {
+		s.selectCalls = append(s.selectCalls, call)
+		return "", ui.ErrCancelled
+	}
+	i := slices.IndexFunc(options, func(o string) bool { return strings.Contains(o, needle) })
+	if i < 0 {
+		s.selectCalls = append(s.selectCalls, call)
+		return "", fmt.Errorf("stubSelector: no option contains %q", needle)
+	}
+	call.Preview = preview(options[i])
+	s.selectCalls = append(s.selectCalls, call)
+	return options[i], nil
+}
+
+func (s *stubSelector) SelectLines(prompt string, rows []string, isContext func(string) bool) ([]int, error) {
+	s.linesCalls = append(s.linesCalls, selectCall{Prompt: prompt, Options: rows})
+	if len(s.linesAnswers) == 0 {
+		return nil, fmt.Errorf("stubSelector: unexpected SelectLines call %q", prompt)
+	}
+	// each needle picks the first row containing it that isn't picked yet;
+	// no needles is the user backing out
+	needles := s.linesAnswers[0]
+	s.linesAnswers = s.linesAnswers[1:]
+	if len(needles) == 0 {
+		return nil, ui.ErrCancelled
+	}
+	var picked []int
+	for _, needle := range needles {
+		i := slices.IndexFunc(rows, func(row string) bool { return strings.Contains(ansi.Strip(row), needle) })
+		for i >= 0 && slices.Contains(picked, i) {
+			next := slices.IndexFunc(rows[i+1:], func(row string) bool { return strings.Contains(ansi.Strip(row), needle) })
+			if next < 0 {
+				i = -1
+				break
+			}
+			i += 1 + next
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("stubSelector: no row left contains %q", needle)
+		}
+		if isContext != nil && isContext(ansi.Strip(rows[i])) {
+			return nil, fmt.Errorf("stubSelector: answer %q is a context row", needle)
+		}
+		picked = append(picked, i)
+	}
+	return picked, nil
+}
+
 func (s *stubSelector) Confirm(prompt string, defaultYes bool) (bool, error) {
 	s.confirmCalls = append(s.confirmCalls, confirmCall{Prompt: prompt, DefaultYes: defaultYes})
 	if len(s.confirmAnswers) == 0 {
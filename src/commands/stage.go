@@ -0,0 +1,341 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/internal/ui"
+	"github.com/lczyk/gitgum/src/diff"
+)
+
+// StageCommand stages changes a hunk or a line at a time, in place of
+// `git add -p`: pick a file (its hunks shown beside the list), then Tab
+// the hunks and lines to take. What's picked goes to `git apply --cached`
+// as a patch. --reverse takes changes back out instead: staged ones out
+// of the index, unstaged ones out of the worktree.
+type StageCommand struct {
+	cmdIO
+	Reverse bool `short:"R" long:"reverse" description:"Unstage staged changes and discard unstaged ones, instead of staging"`
+}
+
+// stageFile is one file gg stage offers, from one side of the tree.
+type stageFile struct {
+	file      diff.File
+	staged    bool // from the index (unstage); else from the worktree
+	untracked bool // not in git yet: only staged whole, with git add
+}
+
+// whole reports whether f can only be taken as a whole: there are no
+// lines to pick from, or taking some would leave the file half added,
+// deleted or renamed.
+func (f stageFile) whole() bool {
+	return f.untracked || f.file.Binary || len(f.file.Hunks) == 0 || f.file.Status != diff.Modified
+}
+
+// action is what taking f's changes does: stage, unstage or discard.
+func (c *StageCommand) action(f stageFile) string {
+	switch {
+	case !c.Reverse:
+		return "stage"
+	case f.staged:
+		return "unstage"
+	}
+	return "discard"
+}
+
+func (c *StageCommand) Execute(args []string) error {
+	r := c.repo()
+	if err := r.CheckInRepo(); err != nil {
+		return err
+	}
+	for {
+		files, err := c.changes(args)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			if c.Reverse {
+				fmt.Fprintln(c.out(), "Nothing to unstage or discard.")
+			} else {
+				fmt.Fprintln(c.out(), "Nothing to stage.")
+			}
+			return nil
+		}
+
+		labels := make([]string, len(files))
+		byLabel := make(map[string]stageFile, len(files))
+		for i, f := range files {
+			labels[i] = c.label(f)
+			byLabel[labels[i]] = f
+		}
+		preview := func(label string) string { return stagePreview(byLabel[label]) }
+		picked, err := c.sel().SelectPreview("Select a file to "+c.promptVerb(), labels, preview)
+		if errors.Is(err, ui.ErrCancelled) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := c.take(byLabel[picked]); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *StageCommand) promptVerb() string {
+	if c.Reverse {
+		return "unstage or discard from"
+	}
+	return "stage from"
+}
+
+// changes lists what there is to take, narrowed to the pathspecs:
+// worktree changes and untracked files to stage, or staged and worktree
+// changes to take back out.
+func (c *StageCommand) changes(paths []string) ([]stageFile, error) {
+	r := c.repo()
+	var out []stageFile
+	collect := func(staged bool) error {
+		args := []string{}
+		if staged {
+			args = append(args, "--cached")
+		}
+		if len(paths) > 0 {
+			args = append(append(args, "--"), paths...)
+		}
+		patch, err := r.Patch(args...)
+		if err != nil {
+			return err
+		}
+		files, err := diff.Parse(patch)
+		if err != nil {
+			return fmt.Errorf("parsing git diff: %w", err)
+		}
+		for _, f := range files {
+			// a submodule's pointer isn't made of lines; gg status and
+			// git add cover it
+			if !f.IsSubmodule() {
+				out = append(out, stageFile{file: f, staged: staged})
+			}
+		}
+		return nil
+	}
+	if c.Reverse {
+		if err := collect(true); err != nil {
+			return nil, err
+		}
+	}
+	if err := collect(false); err != nil {
+		return nil, err
+	}
+	if !c.Reverse {
+		st, err := r.StatusWith(git.StatusOptions{UntrackedAll: true, Paths: paths})
+		if err != nil {
+			return nil, fmt.Errorf("listing untracked files: %w", err)
+		}
+		for _, e := range st.Entries {
+			if e.Kind == git.EntryUntracked {
+				out = append(out, stageFile{file: diff.File{OldPath: e.Path, NewPath: e.Path, Status: diff.Added}, untracked: true})
+			}
+		}
+	}
+	return out, nil
+}
+
+// label is f's row in the file picker: what taking it does (with
+// --reverse), what happened to the file, its path and its size.
+func (c *StageCommand) label(f stageFile) string {
+	what := "modified"
+	switch {
+	case f.untracked:
+		what = "untracked"
+	case f.file.Status == diff.Added:
+		what = "added"
+	case f.file.Status == diff.Deleted:
+		what = "deleted"
+	case f.file.Status == diff.Renamed:
+		what = "renamed"
+	case f.file.Status == diff.Copied:
+		what = "copied"
+	}
+	name := f.file.Path()
+	if f.file.Status == diff.Renamed || f.file.Status == diff.Copied {
+		name = f.file.OldPath + " -> " + f.file.NewPath
+	}
+	label := fmt.Sprintf("%-9s  %s", what, name)
+	if c.Reverse {
+		label = fmt.Sprintf("%-7s  %s", c.action(f), label)
+	}
+	switch {
+	case f.file.Binary:
+		label += "  (binary)"
+	case !f.untracked:
+		added, deleted := f.file.Stat()
+		label += fmt.Sprintf("  (+%d,-%d)", added, deleted)
+	}
+	return label
+}
+
+// stagePreview draws f's hunks for the file picker's preview pane, the
+// way gg diff draws them.
+func stagePreview(f stageFile) string {
+	if f.untracked {
+		return paint(ansiYellow, f.file.Path()) + "\n" + dim("untracked file: staged whole")
+	}
+	var b strings.Builder
+	renderFileDiff(&b, f.file, (&DiffCommand{}).view(0))
+	if f.whole() && !f.file.Binary {
+		b.WriteString(dim("taken whole, not line by line") + "\n")
+	}
+	return b.String()
+}
+
+// take picks the changes to take from f and applies them.
+func (c *StageCommand) take(f stageFile) error {
+	if f.whole() {
+		return c.takeWhole(f)
+	}
+	keep, n, err := c.pickLines(f)
+	if errors.Is(err, ui.ErrCancelled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	part := f.file.Select(keep, c.Reverse)
+	if len(part.Hunks) == 0 {
+		return nil
+	}
+	what := fmt.Sprintf("%d %s of %s", n, plural(n, "line"), f.file.Path())
+	return c.apply(f, diff.Format(part), what)
+}
+
+// takeWhole takes all of f's changes. Staging goes through git add, which
+// also covers untracked and binary files; taking changes back out applies
+// the file's patch backwards, with binary contents in it.
+func (c *StageCommand) takeWhole(f stageFile) error {
+	r := c.repo()
+	if !c.Reverse {
+		if err := r.Add(topPath(f.file.Path())); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out(), "Staged %s.\n", f.file.Path())
+		return nil
+	}
+	args := []string{"--binary"}
+	if f.staged {
+		args = append(args, "--cached")
+	}
+	// both paths, so a rename's patch still has both sides of it
+	args = append(args, "--", topPath(f.file.OldPath))
+	if f.file.NewPath != f.file.OldPath {
+		args = append(args, topPath(f.file.NewPath))
+	}
+	patch, err := r.Patch(args...)
+	if err != nil {
+		return err
+	}
+	if patch == "" {
+		return nil
+	}
+	return c.apply(f, patch, f.file.Path())
+}
+
+// pickLines asks which of f's changed lines to take: Tab on a hunk's
+// header takes the whole hunk, on a line just the line. Context lines are
+// shown but can't be picked. n counts the changed lines picked.
+func (c *StageCommand) pickLines(f stageFile) (keep func(hunk, line int) bool, n int, err error) {
+	type ref struct{ hunk, line int } // line -1 for the header
+	var rows []string
+	var refs []ref
+	v := (&DiffCommand{}).view(0)
+	tokens := v.highlight.file(f.file)
+	for hi, h := range f.file.Hunks {
+		var b strings.Builder
+		renderHunkHeader(&b, h)
+		rows = append(rows, strings.TrimSuffix(b.String(), "\n"))
+		refs = append(refs, ref{hi, -1})
+		marks := v.marks(tokens, hi, h)
+		for li := range h.Lines {
+			l := &h.Lines[li]
+			segs := expandSegmentTabs(lineSegments(l, marks.line(l)))
+			rows = append(rows, paintIf(lineSign(l.Kind, marks.line(l).syntax), string(l.Kind))+drawSegments(segs))
+			refs = append(refs, ref{hi, li})
+		}
+	}
+	isContext := func(row string) bool { return strings.HasPrefix(row, " ") }
+	prompt := fmt.Sprintf("Tab the hunks or lines of %s to %s", f.file.Path(), c.action(f))
+	idxs, err := c.sel().SelectLines(prompt, rows, isContext)
+	if err != nil {
+		return nil, 0, err
+	}
+	hunks := map[int]bool{}
+	lines := map[ref]bool{}
+	for _, i := range idxs {
+		if refs[i].line < 0 {
+			hunks[refs[i].hunk] = true
+		} else {
+			lines[refs[i]] = true
+		}
+	}
+	keep = func(hunk, line int) bool { return hunks[hunk] || lines[ref{hunk, line}] }
+	for hi, h := range f.file.Hunks {
+		for li, l := range h.Lines {
+			if l.Kind != diff.Context && keep(hi, li) {
+				n++
+			}
+		}
+	}
+	return keep, n, nil
+}
+
+// apply applies patch, a change taken from f, the way --reverse says:
+// staged, unstaged, or discarded from the worktree -- which asks first,
+// and journals the patch so gg undo can put it back.
+func (c *StageCommand) apply(f stageFile, patch, what string) error {
+	r := c.repo()
+	switch c.action(f) {
+	case "stage":
+		if err := r.ApplyPatch(patch, true, false); err != nil {
+			return fmt.Errorf("staging %s: %w", what, err)
+		}
+		fmt.Fprintf(c.out(), "Staged %s.\n", what)
+	case "unstage":
+		if err := r.ApplyPatch(patch, true, true); err != nil {
+			return fmt.Errorf("unstaging %s: %w", what, err)
+		}
+		fmt.Fprintf(c.out(), "Unstaged %s.\n", what)
+	default:
+		confirmed, err := c.sel().Confirm(fmt.Sprintf("Discard %s? It can be restored with gg undo", what), false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(c.out(), "Discard cancelled.")
+			return nil
+		}
+		j := beginJournal(r, "stage", "discard "+what)
+		defer j.record(c.err())
+		if err := r.ApplyPatch(patch, false, true); err != nil {
+			return fmt.Errorf("discarding %s: %w", what, err)
+		}
+		j.entry.Discarded = patch
+		fmt.Fprintf(c.out(), "Discarded %s.\n", what)
+	}
+	return nil
+}
+
+// topPath is a pathspec for path as a diff names it: from the top of the
+// worktree, wherever gg runs, and with no glob in it.
+func topPath(path string) string {
+	return ":(top,literal)" + path
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+func runStage(t *testing.T, dir string, reverse bool, stub *stubSelector) (string, error) {
+	t.Helper()
+	var buf strings.Builder
+	cmd := &StageCommand{Reverse: reverse, cmdIO: cmdIO{Out: &buf, Err: &buf, UI: stub, Repo: git.Repo{Dir: dir}}}
+	err := cmd.Execute(nil)
+	return buf.String(), err
+}
+
+func TestStageCommand_StagesPickedLines(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "one\ntwo\nthree\n", "feat: a")
+	temp_repo.WriteFile(t, dir, "a.txt", "one\n2\nthree\nfour\n")
+
+	stub := &stubSelector{selectAnswers: []string{"a.txt", ""}, linesAnswers: [][]string{{"+2"}}}
+	out, err := runStage(t, dir, false, stub)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "Staged 1 line of a.txt.")
+	assert.ContainsString(t, stub.selectCalls[0].Preview, "@@ -1,3 +1,4 @@")
+	assert.ContainsString(t, stub.selectCalls[0].Options[0], "modified   a.txt  (+2,-1)")
+	assert.Equal(t, stub.linesCalls[0].Prompt, "Tab the hunks or lines of a.txt to stage")
+
+	// the deleted line it replaces stays, as it wasn't picked
+	assert.Equal(t, temp_repo.RunGit(t, dir, "show", ":a.txt"), "one\ntwo\n2\nthree\n")
+	fileContent(t, dir, "a.txt", "one\n2\nthree\nfour\n")
+}
+
+func TestStageCommand_FromSubdirectory(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "top.txt", "one\ntwo\n", "feat: top")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	temp_repo.CreateCommit(t, dir, "sub/s.txt", "s\n", "feat: sub")
+	temp_repo.WriteFile(t, dir, "top.txt", "one\n2\n")
+
+	// the patch names top.txt from the top, outside the cwd
+	stub := &stubSelector{selectAnswers: []string{"top.txt", ""}, linesAnswers: [][]string{{"@@"}}}
+	out, err := runStage(t, filepath.Join(dir, "sub"), false, stub)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "Staged 2 lines of top.txt.")
+	assert.Equal(t, temp_repo.RunGit(t, dir, "show", ":top.txt"), "one\n2\n")
+}
+
+func TestStageCommand_StagesWholeHunk(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", strings.Repeat("x\n", 10), "feat: a")
+	temp_repo.WriteFile(t, dir, "a.txt", "first\n"+strings.Repeat("x\n", 10)+"last\n")
+
+	stub := &stubSelector{selectAnswers: []string{"a.txt", ""}, linesAnswers: [][]string{{"@@ -8,3 +9,4 @@"}}}
+	out, err := runStage(t, dir, false, stub)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "Staged 1 line of a.txt.")
+	assert.Equal(t, temp_repo.RunGit(t, dir, "show", ":a.txt"), strings.Repeat("x\n", 10)+"last\n")
+}
+
+func TestStageCommand_UntrackedAndDone(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.WriteFile(t, dir, "new.txt", "new\n")
+
+	stub := &stubSelector{selectAnswers: []string{"new.txt"}}
+	out, err := runStage(t, dir, false, stub)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "Staged new.txt.")
+	assert.ContainsString(t, out, "Nothing to stage.")
+	assert.ContainsString(t, stub.selectCalls[0].Preview, "untracked file")
+	assert.Equal(t, temp_repo.RunGit(t, dir, "status", "--porcelain"), "A  new.txt\n")
+}
+
+func TestStageCommand_ReverseUnstagesDiscardsAndUndoes(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "one\ntwo\nthree\n", "feat: a")
+	temp_repo.WriteFile(t, dir, "a.txt", "one\n2\nthree\n")
+	temp_repo.RunGit(t, dir, "add", "a.txt")
+	temp_repo.WriteFile(t, dir, "a.txt", "one\n2\n3\n")
+
+	stub := &stubSelector{
+		selectAnswers:  []string{"unstage", "discard", ""},
+		linesAnswers:   [][]string{{"@@"}, {"-three", "+3"}},
+		confirmAnswers: []bool{true},
+	}
+	out, err := runStage(t, dir, true, stub)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "Unstaged 2 lines of a.txt.")
+	assert.ContainsString(t, out, "Discarded 2 lines of a.txt.")
+	assert.Equal(t, stub.confirmCalls[0].DefaultYes, false)
+
+	assert.Equal(t, temp_repo.RunGit(t, dir, "show", ":a.txt"), "one\ntwo\nthree\n")
+	fileContent(t, dir, "a.txt", "one\n2\nthree\n")
+
+	out, err = undoLatest(t, dir)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "re-apply the discarded changes")
+	fileContent(t, dir, "a.txt", "one\n2\n3\n")
+}
+
+func TestStageCommand_Fake(t *testing.T) {
+	t.Parallel()
+	patch := strings.Join([]string{
+		"diff --git a/a.txt b/a.txt",
+		"--- a/a.txt",
+		"+++ b/a.txt",
+		"@@ -1 +1 @@",
+		"-a",
+		"+b",
+		"",
+	}, "\n")
+
+	t.Run("apply fails", func(t *testing.T) {
+		t.Parallel()
+		f := git.NewFake()
+		f.Patches[""] = patch
+		f.FailNext("apply", errors.New("patch does not apply"))
+		stub := &stubSelector{selectAnswers: []string{"a.txt"}, linesAnswers: [][]string{{"+b"}}}
+		cmd := &StageCommand{cmdIO: cmdIO{Out: &strings.Builder{}, UI: stub, Repo: f}}
+		err := cmd.Execute(nil)
+		assert.That(t, err != nil && strings.Contains(err.Error(), "staging 1 line of a.txt: patch does not apply"), "got ", err)
+	})
+
+	t.Run("discard declined", func(t *testing.T) {
+		t.Parallel()
+		f := git.NewFake()
+		f.Patches[""] = patch
+		stub := &stubSelector{selectAnswers: []string{"discard", ""}, linesAnswers: [][]string{{"@@"}}, confirmAnswers: []bool{false}}
+		var buf strings.Builder
+		cmd := &StageCommand{Reverse: true, cmdIO: cmdIO{Out: &buf, UI: stub, Repo: f}}
+		require.NoError(t, cmd.Execute(nil))
+		assert.ContainsString(t, buf.String(), "Discard cancelled.")
+		assert.Equal(t, len(f.Applied), 0)
+	})
+
+	t.Run("line picker cancelled", func(t *testing.T) {
+		t.Parallel()
+		f := git.NewFake()
+		f.Patches[""] = patch
+		stub := &stubSelector{selectAnswers: []string{"a.txt", ""}, linesAnswers: [][]string{nil}}
+		cmd := &StageCommand{cmdIO: cmdIO{Out: &strings.Builder{}, UI: stub, Repo: f}}
+		require.NoError(t, cmd.Execute(nil))
+		assert.Equal(t, len(f.Applied), 0)
+		assert.Equal(t, len(stub.selectCalls), 2, "back to the file list")
+	})
+}
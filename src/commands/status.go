@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/lczyk/gitgum/internal/git"
 	"github.com/lczyk/gitgum/src/litescreen"
 	"golang.org/x/term"
 )
@@ -30,8 +31,9 @@ func (s *StatusCommand) Execute(args []string) error {
 	return s.runFollow()
 }
 
-// renderFull writes the standard four-section status: branches, remotes,
-// changes, status. Used by the non-follow path.
+// renderFull writes the standard status sections: branches, remotes,
+// changes, submodules (when there are any), status. Used by the
+// non-follow path.
 func statusHeader(label string) string {
 	prefix := "- " + label + " "
 	w, _, err := term.GetSize(int(os.Stdout.Fd()))
@@ -72,44 +74,87 @@ func (s *StatusCommand) renderFull(out io.Writer) error {
 	return s.renderBody(out)
 }
 
-// renderBody writes only the CHANGES + STATUS sections. Shared between the
-// non-follow render and the follow-loop redraw. Runs `git status` only --
-// no fetch, no remote ops.
+// renderBody writes only the CHANGES + SUBMODULES + STATUS sections.
+// Shared between the non-follow render and the follow-loop redraw. Local
+// reads only -- no fetch, no remote ops.
 func (s *StatusCommand) renderBody(out io.Writer) error {
 	printHeader := func(label string) {
 		fmt.Fprintln(out, paint(ansiDim, statusHeader(label)))
 	}
 
-	stdout, _, err := s.repo().Run("status", "--short", "--branch")
+	st, err := s.repo().Status()
 	if err != nil {
 		return fmt.Errorf("getting status: %w", err)
 	}
-	lines := strings.Split(stdout, "\n")
 
-	changeLines := lines[1:]
-	hasChanges := false
-	for _, l := range changeLines {
-		if l != "" {
-			hasChanges = true
-			break
-		}
-	}
-	if hasChanges {
+	if len(st.Entries) > 0 {
 		printHeader("CHANGES")
 		if s.Flat {
-			fmt.Fprintln(out, strings.Join(changeLines, "\n"))
+			for _, e := range st.Entries {
+				fmt.Fprintln(out, shortStatusLine(e))
+			}
 		} else {
-			entries := parseChangeLines(changeLines)
+			entries := changeEntries(st.Entries)
 			annotateNumstats(s.repo(), entries)
 			renderTree(buildTree(entries), out)
 		}
 	}
 
+	subs, err := s.repo().Submodules()
+	if err != nil {
+		return fmt.Errorf("getting submodules: %w", err)
+	}
+	if len(subs) > 0 {
+		printHeader("SUBMODULES")
+		fmt.Fprintln(out, renderSubmodules(subs))
+	}
+
 	printHeader("STATUS")
-	fmt.Fprintln(out, lines[0])
+	fmt.Fprintln(out, shortBranchLine(st.Branch))
+	if st.Stash > 0 {
+		fmt.Fprintln(out, dim(fmt.Sprintf("stashes: %d", st.Stash)))
+	}
 	return nil
 }
 
+// shortStatusLine formats an entry the way `git status --short` does.
+func shortStatusLine(e git.StatusEntry) string {
+	if e.Kind == git.EntryRenamed {
+		return e.Code() + " " + e.Orig + " -> " + e.Path
+	}
+	return e.Code() + " " + e.Path
+}
+
+// shortBranchLine formats the branch header the way `git status --short
+// --branch` does: "## main...origin/main [ahead 1, behind 2]".
+func shortBranchLine(b git.BranchStatus) string {
+	switch {
+	case b.Head == "":
+		return "## HEAD (no branch)"
+	case b.Oid == "":
+		return "## No commits yet on " + b.Head
+	}
+	line := "## " + b.Head
+	if b.Upstream == "" {
+		return line
+	}
+	line += "..." + b.Upstream
+	var track []string
+	if b.Gone {
+		track = append(track, "gone")
+	}
+	if b.Ahead > 0 {
+		track = append(track, fmt.Sprintf("ahead %d", b.Ahead))
+	}
+	if b.Behind > 0 {
+		track = append(track, fmt.Sprintf("behind %d", b.Behind))
+	}
+	if len(track) > 0 {
+		line += " [" + strings.Join(track, ", ") + "]"
+	}
+	return line
+}
+
 func (s *StatusCommand) runFollow() error {
 	if !stdoutIsTTY() {
 		return errors.New("--follow requires a tty")
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// renderSubmodules formats the SUBMODULES section, one row per submodule:
+//
+//	  lib         1a2b3c4d5e6f
+//	+ vendor/x    1a2b3c4d5e6f -> 9f8e7d6c5b4a  (modified, untracked)
+//	- docs/theme  1a2b3c4d5e6f  (not initialized)
+//
+// The markers are `git submodule status`'s: '+' when the checked-out commit
+// has drifted from the recorded one, '-' when the submodule isn't cloned.
+func renderSubmodules(subs []git.Submodule) string {
+	width := 0
+	for _, s := range subs {
+		width = max(width, len(s.Path))
+	}
+	var out []string
+	for _, s := range subs {
+		marker, oids := " ", shortOid(s.Recorded)
+		switch {
+		case !s.Initialized():
+			marker = "-"
+		case s.Drifted():
+			marker = paint(ansiYellow, "+")
+			oids += " -> " + paint(ansiYellow, shortOid(s.CheckedOut))
+		}
+		var notes []string
+		if !s.Initialized() {
+			notes = append(notes, "not initialized")
+		}
+		if s.Modified {
+			notes = append(notes, paint(ansiRed, "modified"))
+		}
+		if s.Untracked {
+			notes = append(notes, paint(ansiRed, "untracked"))
+		}
+		line := fmt.Sprintf("%s %-*s  %s", marker, width, s.Path, oids)
+		if len(notes) > 0 {
+			line += "  (" + strings.Join(notes, ", ") + ")"
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
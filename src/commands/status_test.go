@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -138,3 +139,77 @@ func TestParseRemotes(t *testing.T) {
 		})
 	}
 }
+
+func TestShortBranchLine(t *testing.T) {
+	cases := map[string]struct {
+		in       git.BranchStatus
+		expected string
+	}{
+		"no upstream": {in: git.BranchStatus{Oid: "abc", Head: "main"}, expected: "## main"},
+		"in sync":     {in: git.BranchStatus{Oid: "abc", Head: "main", Upstream: "origin/main"}, expected: "## main...origin/main"},
+		"diverged": {
+			in:       git.BranchStatus{Oid: "abc", Head: "main", Upstream: "origin/main", Ahead: 1, Behind: 2},
+			expected: "## main...origin/main [ahead 1, behind 2]",
+		},
+		"gone":     {in: git.BranchStatus{Oid: "abc", Head: "x", Upstream: "origin/x", Gone: true}, expected: "## x...origin/x [gone]"},
+		"detached": {in: git.BranchStatus{Oid: "abc"}, expected: "## HEAD (no branch)"},
+		"unborn":   {in: git.BranchStatus{Head: "main"}, expected: "## No commits yet on main"},
+	}
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, shortBranchLine(tt.in), tt.expected)
+		})
+	}
+}
+
+func TestStatusCommand_Flat(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.WriteFile(t, dir, "README.md", "stash me\n")
+	temp_repo.RunGit(t, dir, "stash")
+	temp_repo.RunGit(t, dir, "mv", "README.md", "NEW.md")
+	temp_repo.WriteFile(t, dir, "untracked.txt", "hello\n")
+
+	var buf strings.Builder
+	cmd := &StatusCommand{cmdIO: cmdIO{Out: &buf, Repo: git.Repo{Dir: dir}}, Flat: true}
+	require.NoError(t, cmd.renderBody(&buf))
+
+	output := buf.String()
+	assert.ContainsString(t, output, "R  README.md -> NEW.md\n")
+	assert.ContainsString(t, output, "?? untracked.txt\n")
+	assert.ContainsString(t, output, "## main\n")
+	assert.ContainsString(t, output, "stashes: 1")
+}
+
+func TestStatusCommand_Submodules(t *testing.T) {
+	t.Parallel()
+	super, _ := temp_repo.NewRepoWithSubmodule(t)
+	sub := filepath.Join(super, "lib")
+	temp_repo.CreateCommit(t, sub, "new.txt", "new\n", "feat: new")
+	temp_repo.WriteFile(t, sub, "scratch.txt", "scratch\n")
+
+	var buf strings.Builder
+	cmd := &StatusCommand{cmdIO: cmdIO{Out: &buf, Repo: git.Repo{Dir: super}}, Flat: true}
+	require.NoError(t, cmd.renderBody(&buf))
+
+	output := buf.String()
+	assert.ContainsString(t, output, "SUBMODULES")
+	assert.ContainsString(t, output, "+ lib  ")
+	assert.ContainsString(t, output, "(untracked)")
+}
+
+func TestRenderSubmodules(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	const a, b = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	got := renderSubmodules([]git.Submodule{
+		{Path: "lib", Recorded: a, CheckedOut: a},
+		{Path: "vendor/x", Recorded: a, CheckedOut: b, Modified: true, Untracked: true},
+		{Path: "theme", Recorded: a},
+	})
+	assert.Equal(t, got, strings.Join([]string{
+		"  lib       aaaaaaaaaaaa",
+		"+ vendor/x  aaaaaaaaaaaa -> bbbbbbbbbbbb  (modified, untracked)",
+		"- theme     aaaaaaaaaaaa  (not initialized)",
+	}, "\n"))
+}
@@ -26,7 +26,7 @@ type numstat struct {
 // matching `git diff --numstat HEAD --no-renames` line. Untracked files,
 // binary diffs, rename markers, and empty repos (no HEAD) get nil -- the
 // renderer simply omits the count for those.
-func annotateNumstats(repo git.Repo, entries []changeEntry) {
+func annotateNumstats(repo git.Backend, entries []changeEntry) {
 	out, _, err := repo.Run("diff", "--numstat", "--no-renames", "HEAD")
 	if err != nil {
 		return
@@ -70,21 +70,18 @@ type treeNode struct {
 	entry    *changeEntry
 }
 
-func parseChangeLines(lines []string) []changeEntry {
+// changeEntries maps status entries onto tree leaves. A rename becomes two
+// leaves, "R<" at its origin and "R>" at its new path, so both ends show up
+// in the tree. A copy's origin is unchanged, so only its new path is listed.
+func changeEntries(entries []git.StatusEntry) []changeEntry {
 	var out []changeEntry
-	for _, line := range lines {
-		if len(line) < 4 {
+	for _, e := range entries {
+		if e.Kind == git.EntryRenamed && e.RenameOp == 'R' {
+			out = append(out, changeEntry{code: "R<", path: e.Orig})
+			out = append(out, changeEntry{code: "R>", path: e.Path})
 			continue
 		}
-		code := line[:2]
-		rest := line[3:]
-		if (code[0] == 'R' || code[1] == 'R') && strings.Contains(rest, " -> ") {
-			parts := strings.SplitN(rest, " -> ", 2)
-			out = append(out, changeEntry{code: "R<", path: parts[0]})
-			out = append(out, changeEntry{code: "R>", path: parts[1]})
-			continue
-		}
-		out = append(out, changeEntry{code: code, path: rest})
+		out = append(out, changeEntry{code: e.Code(), path: e.Path})
 	}
 	return out
 }
@@ -117,6 +114,7 @@ const (
 	ansiReset   = "\033[0m"
 	ansiDim     = "\033[2m"
 	ansiItalic  = "\033[3m"
+	ansiReverse = "\033[7m"
 	ansiRed     = "\033[31m"
 	ansiGreen   = "\033[32m"
 	ansiYellow  = "\033[33m"
@@ -6,41 +6,46 @@ import (
 	"testing"
 
 	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/internal/git"
 )
 
 var ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 
 func stripAnsi(s string) string { return ansiRe.ReplaceAllString(s, "") }
 
-func TestParseChangeLines(t *testing.T) {
+func TestChangeEntries(t *testing.T) {
 	cases := map[string]struct {
-		input    []string
+		input    []git.StatusEntry
 		expected []changeEntry
 	}{
-		"empty": {input: []string{}, expected: nil},
+		"empty": {input: nil, expected: nil},
 		"single modified": {
-			input:    []string{" M go.mod"},
+			input:    []git.StatusEntry{{Kind: git.EntryOrdinary, X: '.', Y: 'M', Path: "go.mod"}},
 			expected: []changeEntry{{code: " M", path: "go.mod"}},
 		},
 		"untracked": {
-			input:    []string{"?? delete_me"},
+			input:    []git.StatusEntry{{Kind: git.EntryUntracked, X: '?', Y: '?', Path: "delete_me"}},
 			expected: []changeEntry{{code: "??", path: "delete_me"}},
 		},
 		"rename splits into two": {
-			input: []string{"R  old/path.go -> new/path.go"},
+			input: []git.StatusEntry{{Kind: git.EntryRenamed, X: 'R', Y: '.', RenameOp: 'R', Path: "new/path.go", Orig: "old/path.go"}},
 			expected: []changeEntry{
 				{code: "R<", path: "old/path.go"},
 				{code: "R>", path: "new/path.go"},
 			},
 		},
-		"skips short lines": {
-			input:    []string{"", "x"},
-			expected: nil,
+		"copy keeps only the new path": {
+			input:    []git.StatusEntry{{Kind: git.EntryRenamed, X: 'C', Y: '.', RenameOp: 'C', Path: "b.go", Orig: "a.go"}},
+			expected: []changeEntry{{code: "C ", path: "b.go"}},
+		},
+		"unmerged": {
+			input:    []git.StatusEntry{{Kind: git.EntryUnmerged, X: 'U', Y: 'U', Path: "both.go"}},
+			expected: []changeEntry{{code: "UU", path: "both.go"}},
 		},
 	}
 	for name, tt := range cases {
 		t.Run(name, func(t *testing.T) {
-			got := parseChangeLines(tt.input)
+			got := changeEntries(tt.input)
 			assert.EqualArrays(t, got, tt.expected)
 		})
 	}
@@ -113,7 +118,7 @@ func TestRenderTree_Nested(t *testing.T) {
 }
 
 func TestRenderTree_RenameTwoLeaves(t *testing.T) {
-	entries := parseChangeLines([]string{"R  a/old.go -> b/new.go"})
+	entries := changeEntries([]git.StatusEntry{{Kind: git.EntryRenamed, X: 'R', Y: '.', RenameOp: 'R', Path: "b/new.go", Orig: "a/old.go"}})
 	var buf strings.Builder
 	renderTree(buildTree(entries), &buf)
 	got := stripAnsi(buf.String())
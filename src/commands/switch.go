@@ -12,13 +12,17 @@ import (
 
 type SwitchCommand struct {
 	cmdIO
+
+	// shell opens an interactive shell in a directory, for going to
+	// another worktree; nil runs the user's own (runShell).
+	shell func(dir string) error
 }
 
 // resolveCurrentBranchContext figures out the current branch, its tracking
 // remote, and a human-readable status line for display. In detached HEAD,
 // returns currentBranch="" so downstream branch filters don't match the literal
 // string "HEAD" (which can't be a real branch but is what rev-parse returns).
-func resolveCurrentBranchContext(r git.Repo) (currentBranch, trackingRemote, statusLine string, err error) {
+func resolveCurrentBranchContext(r git.Backend) (currentBranch, trackingRemote, statusLine string, err error) {
 	currentBranch, err = r.GetCurrentBranch()
 	if err != nil {
 		return "", "", "", fmt.Errorf("getting current branch: %w", err)
@@ -44,7 +48,7 @@ func resolveCurrentBranchContext(r git.Repo) (currentBranch, trackingRemote, sta
 
 func (s *SwitchCommand) checkoutBranch(branch string) error {
 	if err := s.repo().Checkout(branch); err != nil {
-		return fmt.Errorf("could not switch to branch '%s': %w", branch, err)
+		return explainGitError(fmt.Errorf("could not switch to branch '%s': %w", branch, err))
 	}
 	return nil
 }
@@ -76,12 +80,12 @@ func (s *SwitchCommand) Execute(args []string) error {
 		return fmt.Errorf("getting remotes: %w", err)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(s.ctx())
 	defer cancel()
 
 	src := streamBranches(ctx, r, s.err(), currentBranch, trackingRemote, remotes)
 
-	selected, err := s.sel().SelectStream(ctx, "Select a branch to switch to", src, isCheckedOutElsewhere)
+	selected, err := s.sel().SelectStream(ctx, "Select a branch to switch to", src, nil)
 	cancel()
 	if err != nil {
 		fmt.Fprintln(s.err(), "No branch selected. Aborting switch.")
@@ -100,7 +104,21 @@ func (s *SwitchCommand) Execute(args []string) error {
 	return nil
 }
 
+// applySelection switches to the picked branch, then offers to update any
+// submodule whose recorded commit the switch changed.
 func (s *SwitchCommand) applySelection(selected string) error {
+	// best effort: without the before picture there's just no offer
+	before, _ := s.repo().Submodules()
+	if err := s.switchTo(selected); err != nil {
+		return err
+	}
+	return s.offerSubmoduleUpdate(before)
+}
+
+func (s *SwitchCommand) switchTo(selected string) error {
+	if isCheckedOutElsewhere(selected) {
+		return s.goToWorktree(selected)
+	}
 	parts := strings.SplitN(selected, ": ", 2)
 	if len(parts) != 2 {
 		return fmt.Errorf("invalid selection: %s", selected)
@@ -124,3 +142,44 @@ func (s *SwitchCommand) applySelection(selected string) error {
 		return fmt.Errorf("unknown branch type: %s", typ)
 	}
 }
+
+// offerSubmoduleUpdate asks to run `git submodule update` for initialized
+// submodules the switch left behind: their recorded commit changed and
+// what's checked out no longer matches it. Submodules that had drifted
+// on purpose before the switch, with the record unchanged, are left be.
+func (s *SwitchCommand) offerSubmoduleUpdate(before []git.Submodule) error {
+	after, err := s.repo().Submodules()
+	if err != nil {
+		fmt.Fprintf(s.err(), "warning: could not check submodules: %v\n", err)
+		return nil
+	}
+	recorded := make(map[string]string, len(before))
+	for _, sm := range before {
+		recorded[sm.Path] = sm.Recorded
+	}
+	var stale []git.Submodule
+	for _, sm := range after {
+		if sm.Drifted() && recorded[sm.Path] != sm.Recorded {
+			stale = append(stale, sm)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(s.out(), "Submodules now recorded at a different commit than checked out:")
+	fmt.Fprintln(s.out(), renderSubmodules(stale))
+	confirmed, err := s.sel().Confirm("Run 'git submodule update' for them?", true)
+	if err != nil && !errors.Is(err, ui.ErrCancelled) {
+		return fmt.Errorf("confirming submodule update: %w", err)
+	}
+	if !confirmed {
+		fmt.Fprintln(s.err(), "Not updating submodules; run 'git submodule update' when ready.")
+		return nil
+	}
+	if err := s.repo().SubmoduleUpdateCtx(s.ctx(), submodulePaths(stale)...); err != nil {
+		return explainGitError(fmt.Errorf("updating submodules: %w", err))
+	}
+	fmt.Fprintf(s.out(), "Updated %d submodule(s).\n", len(stale))
+	return nil
+}
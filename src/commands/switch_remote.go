@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 
+	"github.com/lczyk/gitgum/internal/git"
 	"github.com/lczyk/gitgum/internal/ui"
 )
 
@@ -11,8 +12,12 @@ import (
 // Otherwise offers to create a tracking branch.
 func (s *SwitchCommand) handleRemoteSelection(remote, branch string) error {
 	fmt.Fprintf(s.out(), "Fetching '%s/%s'...\n", remote, branch)
-	if err := s.repo().Fetch(remote, branch); err != nil {
-		return fmt.Errorf("fetching '%s/%s': %w", remote, branch, err)
+	if err := s.repo().FetchCtx(s.ctx(), remote, branch); err != nil {
+		// offline: carry on from the last fetch if there was one
+		if _, herr := s.repo().GetCommitHash("refs/remotes/" + remote + "/" + branch); herr != nil || git.KindOf(err) != git.KindRemoteUnreachable {
+			return explainGitError(fmt.Errorf("fetching '%s/%s': %w", remote, branch, err))
+		}
+		fmt.Fprintf(s.err(), "Could not reach '%s'; using '%s/%s' as of the last fetch.\n", remote, remote, branch)
 	}
 
 	if !s.repo().BranchExists(branch) {
@@ -87,9 +92,14 @@ func (s *SwitchCommand) alignWithRemote(remote, branch string) error {
 		fmt.Fprintf(s.out(), "Switched to branch '%s' (diverged from '%s/%s').\n", branch, remote, branch)
 		return nil
 	}
+	j := beginJournal(s.repo(), "switch", fmt.Sprintf("reset '%s' to '%s'", branch, remoteRef), "refs/heads/"+branch)
+	if err := j.stashChanges(); err != nil {
+		fmt.Fprintf(s.err(), "warning: local changes not saved for gg undo: %v\n", err)
+	}
 	if err := s.repo().ResetHard(remoteRef); err != nil {
-		return fmt.Errorf("resetting local branch: %w", err)
+		return explainGitError(fmt.Errorf("resetting local branch: %w", err))
 	}
+	j.record(s.err())
 	fmt.Fprintf(s.out(), "Switched to branch '%s', reset to remote branch '%s/%s'.\n", branch, remote, branch)
 	return nil
 }
@@ -6,7 +6,6 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/lczyk/gitgum/internal/git"
@@ -15,20 +14,19 @@ import (
 
 const streamDelay = 3 * time.Millisecond
 
-// checkedOutMarker tags a branch already checked out in another worktree. The
-// switch flow always ends in `git checkout <name>` / reset, which git rejects
-// for a branch checked out elsewhere, so these entries are shown but made
-// unselectable (see switch.go). The emitter and the unselectable predicate both
-// key on this string -- keep them in sync via this const.
+// checkedOutMarker tags a branch already checked out in another worktree.
+// git won't check a branch out twice, so picking one of these goes to its
+// worktree instead (see goToWorktree). The emitter and isCheckedOutElsewhere
+// both key on this string -- keep them in sync via this const.
 const checkedOutMarker = " (checked out in "
 
-// checkedOutSuffix renders the display suffix naming the blocking worktree.
+// checkedOutSuffix renders the display suffix naming the worktree.
 func checkedOutSuffix(worktreePath string) string {
 	return checkedOutMarker + filepath.Base(worktreePath) + " worktree)"
 }
 
-// isCheckedOutElsewhere is the picker's Unselectable predicate: an entry
-// carrying the checked-out marker can't be switched to (see checkedOutMarker).
+// isCheckedOutElsewhere reports whether a picker entry carries the
+// checked-out marker, and so stands for another worktree's branch.
 func isCheckedOutElsewhere(item string) bool {
 	return strings.Contains(item, checkedOutMarker)
 }
@@ -38,26 +36,17 @@ type branchEntry struct {
 	dedupKey string
 }
 
-// streamBranches collects local and remote branches concurrently, deduplicating
-// and writing into a SliceSource the picker consumes. Caller cancels ctx
-// when the consumer (fuzzyfinder) is done; cancellation also stops producers.
-// errOut receives non-fatal diagnostic messages from the producers.
+// streamBranches lists local and remote branches from one for-each-ref,
+// deduplicating and writing them into a SliceSource the picker consumes.
+// Caller cancels ctx when the consumer (fuzzyfinder) is done; cancellation
+// also stops the producer. errOut receives non-fatal diagnostic messages.
 //
-// The returned SliceSource supports both Add (used by the producers below)
+// The returned SliceSource supports both Add (used by the producer below)
 // and RemoveFunc (left available for future hooks that drop branches as the
 // user deletes them).
-func streamBranches(ctx context.Context, r git.Repo, errOut io.Writer, currentBranch, trackingRemote string, remotes []string) *ff.SliceSource {
+func streamBranches(ctx context.Context, r git.Backend, errOut io.Writer, currentBranch, trackingRemote string, remotes []string) *ff.SliceSource {
 	src := ff.NewSliceSource()
 	seen := make(map[string]struct{})
-	var seenMu sync.Mutex
-
-	// Fetch checkout state once so producers can do map lookups instead of
-	// N subprocess calls.
-	checkedOut, err := r.CheckedOutBranches()
-	if err != nil {
-		fmt.Fprintf(errOut, "error getting worktrees: %v\n", err)
-		checkedOut = map[string]string{}
-	}
 
 	queue := make(chan branchEntry, 1000)
 	go func() {
@@ -65,13 +54,10 @@ func streamBranches(ctx context.Context, r git.Repo, errOut io.Writer, currentBr
 			select {
 			case entry := <-queue:
 				time.Sleep(streamDelay)
-				seenMu.Lock()
 				if _, ok := seen[entry.dedupKey]; ok {
-					seenMu.Unlock()
 					continue
 				}
 				seen[entry.dedupKey] = struct{}{}
-				seenMu.Unlock()
 				src.Add(entry.display)
 			case <-ctx.Done():
 				return
@@ -79,10 +65,28 @@ func streamBranches(ctx context.Context, r git.Repo, errOut io.Writer, currentBr
 		}
 	}()
 
-	go streamLocalBranches(ctx, r, errOut, queue, currentBranch, checkedOut)
-	for _, remote := range remotes {
-		go streamRemoteBranches(ctx, r, errOut, queue, remote, currentBranch, trackingRemote, checkedOut)
-	}
+	go func() {
+		// one for-each-ref answers upstreams and worktrees for every
+		// branch, instead of a subprocess per branch
+		refs, err := r.RefsWithUpstreamsCtx(ctx)
+		if ctx.Err() != nil {
+			return // picker closed before the refs arrived
+		}
+		if err != nil {
+			fmt.Fprintf(errOut, "error getting branches: %v\n", err)
+			return
+		}
+		checkedOut := make(map[string]string)
+		for _, ref := range refs {
+			if !ref.IsRemote() && ref.Worktree != "" {
+				checkedOut[ref.Short()] = ref.Worktree
+			}
+		}
+		// locals first: a tracking local's dedupKey hides the remote entry
+		// it tracks
+		emitLocalBranches(ctx, refs, queue, currentBranch, checkedOut)
+		emitRemoteBranches(ctx, refs, queue, remotes, currentBranch, trackingRemote, checkedOut)
+	}()
 
 	// Known limitation: branches deleted in another shell while the picker
 	// is open stay in the list until the user closes and reopens it. The
@@ -93,23 +97,17 @@ func streamBranches(ctx context.Context, r git.Repo, errOut io.Writer, currentBr
 	return src
 }
 
-func streamLocalBranches(ctx context.Context, r git.Repo, errOut io.Writer, queue chan<- branchEntry, currentBranch string, checkedOut map[string]string) {
-	locals, err := r.GetLocalBranches()
-	if err != nil {
-		fmt.Fprintf(errOut, "error getting local branches: %v\n", err)
-		return
-	}
-
-	for _, branch := range locals {
-		if branch == currentBranch {
+func emitLocalBranches(ctx context.Context, refs []git.Ref, queue chan<- branchEntry, currentBranch string, checkedOut map[string]string) {
+	for _, ref := range refs {
+		if ref.IsRemote() {
 			continue
 		}
-		tr, err := r.GetBranchTrackingRemote(branch)
-		if err != nil {
-			tr = ""
+		branch := ref.Short()
+		if branch == currentBranch {
+			continue
 		}
 		var entry branchEntry
-		if tr != "" {
+		if tr := ref.Remote; tr != "" && tr != "." {
 			entry = branchEntry{
 				display:  "local/remote: " + branch,
 				dedupKey: "remote:" + tr + "/" + branch,
@@ -120,8 +118,9 @@ func streamLocalBranches(ctx context.Context, r git.Repo, errOut io.Writer, queu
 				dedupKey: "local:" + branch,
 			}
 		}
-		// checked out in another worktree -> show but mark unselectable;
-		// `git checkout <branch>` would fail. dedupKey stays clean.
+		// checked out in another worktree -> marked; `git checkout
+		// <branch>` would fail, so picking it goes there. dedupKey stays
+		// clean.
 		if wt, ok := checkedOut[branch]; ok {
 			entry.display += checkedOutSuffix(wt)
 		}
@@ -133,14 +132,15 @@ func streamLocalBranches(ctx context.Context, r git.Repo, errOut io.Writer, queu
 	}
 }
 
-func streamRemoteBranches(ctx context.Context, r git.Repo, errOut io.Writer, queue chan<- branchEntry, remote, currentBranch, trackingRemote string, checkedOut map[string]string) {
-	branches, err := r.GetRemoteBranches(remote)
-	if err != nil {
-		fmt.Fprintf(errOut, "error getting remote branches for '%s': %v\n", remote, err)
-		return
-	}
-
-	for _, branch := range branches {
+func emitRemoteBranches(ctx context.Context, refs []git.Ref, queue chan<- branchEntry, remotes []string, currentBranch, trackingRemote string, checkedOut map[string]string) {
+	for _, ref := range refs {
+		if !ref.IsRemote() {
+			continue
+		}
+		remote, branch := splitRemoteRef(ref.Short(), remotes)
+		if remote == "" {
+			continue // a remote-tracking ref for a remote that's been removed
+		}
 		if remote == trackingRemote && branch == currentBranch {
 			continue
 		}
@@ -150,7 +150,7 @@ func streamRemoteBranches(ctx context.Context, r git.Repo, errOut io.Writer, que
 		}
 		// selecting a remote branch ends in `git checkout <branch>` on the
 		// local landing name; if that's checked out elsewhere it'd fail, so
-		// show but mark unselectable. skip the current branch (own ref).
+		// mark it like the local one. skip the current branch (own ref).
 		if wt, ok := checkedOut[branch]; ok && branch != currentBranch {
 			entry.display += checkedOutSuffix(wt)
 		}
@@ -161,3 +161,17 @@ func streamRemoteBranches(ctx context.Context, r git.Repo, errOut io.Writer, que
 		}
 	}
 }
+
+// splitRemoteRef splits "origin/feat/x" into its remote and branch. Remote
+// names can hold slashes too, so the longest known remote prefix wins.
+func splitRemoteRef(short string, remotes []string) (remote, branch string) {
+	for _, r := range remotes {
+		if strings.HasPrefix(short, r+"/") && len(r) > len(remote) {
+			remote = r
+		}
+	}
+	if remote == "" {
+		return "", ""
+	}
+	return remote, short[len(remote)+1:]
+}
@@ -3,6 +3,10 @@ package commands
 import (
 	"bytes"
 	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -102,7 +106,7 @@ func TestSwitchCommand_Execute_PicksLocalBranch(t *testing.T) {
 
 // Same-name branch on a different remote must appear even when the current
 // branch is checked out. Regression: checkedOut["main"] was true (current
-// branch is checked out), but the checkedOut filter in streamRemoteBranches
+// branch is checked out), but the checkedOut filter in emitRemoteBranches
 // dropped origin/main when local main tracked other/main — the filter didn't
 // distinguish "checked out in another worktree" from "checked out here".
 func TestStreamBranches_SameNameOnOtherRemote(t *testing.T) {
@@ -151,9 +155,9 @@ func TestStreamBranches_SameNameOnOtherRemote(t *testing.T) {
 }
 
 // A branch checked out in another worktree must still appear in the picker,
-// tagged with the worktree marker and reported unselectable -- previously it
-// was silently dropped, which looked like a missing branch.
-func TestStreamBranches_CheckedOutElsewhereIsMarkedUnselectable(t *testing.T) {
+// tagged with the worktree marker -- previously it was silently dropped,
+// which looked like a missing branch.
+func TestStreamBranches_CheckedOutElsewhereIsMarked(t *testing.T) {
 	t.Parallel()
 
 	dir := temp_repo.NewRepo(t)
@@ -183,7 +187,72 @@ func TestStreamBranches_CheckedOutElsewhereIsMarkedUnselectable(t *testing.T) {
 
 	require.That(t, feature != "", "feature branch should appear")
 	assert.ContainsString(t, feature, "(checked out in")
-	assert.That(t, isCheckedOutElsewhere(feature), "marked entry should be unselectable")
+	assert.That(t, isCheckedOutElsewhere(feature), "marked entry should go to its worktree")
+}
+
+func TestApplySelection_CheckedOutElsewhere(t *testing.T) {
+	t.Parallel()
+	setup := func(t *testing.T) (dir, wt string) {
+		t.Helper()
+		dir = temp_repo.NewRepo(t)
+		temp_repo.RunGit(t, dir, "branch", "feature")
+		wt = filepath.Join(t.TempDir(), "feature-wt")
+		temp_repo.RunGit(t, dir, "worktree", "add", "-q", wt, "feature")
+		return dir, wt
+	}
+	picked := "local: feature" + checkedOutSuffix("feature-wt")
+
+	t.Run("opens a shell there", func(t *testing.T) {
+		t.Parallel()
+		dir, wt := setup(t)
+		var buf strings.Builder
+		var opened string
+		s := &SwitchCommand{
+			cmdIO: cmdIO{Out: &buf, UI: &stubSelector{confirmAnswers: []bool{true}}, Repo: git.Repo{Dir: dir}},
+			shell: func(dir string) error { opened = dir; return nil },
+		}
+		require.NoError(t, s.applySelection(picked))
+		assert.Equal(t, opened, wt)
+		assert.ContainsString(t, buf.String(), "'feature' is checked out in the worktree at "+wt)
+		assert.Equal(t, currentBranchIn(t, dir), "main", "nothing switched here")
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		t.Parallel()
+		dir, wt := setup(t)
+		var buf strings.Builder
+		s := &SwitchCommand{
+			cmdIO: cmdIO{Out: &buf, UI: &stubSelector{confirmAnswers: []bool{false}}, Repo: git.Repo{Dir: dir}},
+			shell: func(string) error { t.Error("no shell when declined"); return nil },
+		}
+		require.NoError(t, s.applySelection(picked))
+		assert.ContainsString(t, buf.String(), "To work on it: cd "+wt)
+	})
+
+	t.Run("gone worktree is pruned", func(t *testing.T) {
+		t.Parallel()
+		dir, wt := setup(t)
+		require.NoError(t, os.RemoveAll(wt))
+		var buf strings.Builder
+		s := &SwitchCommand{cmdIO: cmdIO{Out: &buf, UI: &stubSelector{confirmAnswers: []bool{true}}, Repo: git.Repo{Dir: dir}}}
+		require.NoError(t, s.applySelection(picked))
+		assert.ContainsString(t, buf.String(), "which is gone")
+		assert.ContainsString(t, buf.String(), "Switched to branch 'feature'.")
+		assert.Equal(t, currentBranchIn(t, dir), "feature")
+	})
+
+	t.Run("remote entry", func(t *testing.T) {
+		t.Parallel()
+		f := git.NewFake()
+		f.Trees = []git.Worktree{{Path: "/wt/main", Branch: "main", Main: true}, {Path: "/wt/feat", Branch: "feat"}}
+		var opened string
+		s := &SwitchCommand{
+			cmdIO: cmdIO{Out: &strings.Builder{}, UI: &stubSelector{confirmAnswers: []bool{true}}, Repo: f},
+			shell: func(dir string) error { opened = dir; return nil },
+		}
+		require.NoError(t, s.applySelection("remote: origin/feat"+checkedOutSuffix("/wt/feat")))
+		assert.Equal(t, opened, "/wt/feat")
+	})
 }
 
 // Regression: in detached HEAD, rev-parse --abbrev-ref returns "HEAD" and
@@ -201,3 +270,186 @@ func TestResolveCurrentBranchContext_DetachedHEAD(t *testing.T) {
 	assert.Equal(t, trackingRemote, "")
 	assert.ContainsString(t, statusLine, "detached HEAD")
 }
+
+// fakeWithFeature is a Fake with origin/feature fetched one commit past
+// main, and no local feature branch.
+func fakeWithFeature(t *testing.T) *git.Fake {
+	t.Helper()
+	f := git.NewFake()
+	main := f.Refs["refs/heads/main"]
+	f.AddRemote("origin", map[string]string{"main": main, "feature": f.NewCommit(main)})
+	return f
+}
+
+func TestHandleRemoteSelection_Offline(t *testing.T) {
+	t.Parallel()
+	unreachable := git.FakeError(git.KindRemoteUnreachable, "fatal: Could not read from remote repository.", "fetch", "origin")
+
+	t.Run("falls back to the last fetch", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithFeature(t)
+		f.FailNext("fetch", unreachable)
+
+		var out, errOut bytes.Buffer
+		s := &SwitchCommand{cmdIO: cmdIO{Out: &out, Err: &errOut, UI: &stubSelector{confirmAnswers: []bool{true}}, Repo: f}}
+		require.NoError(t, s.handleRemoteSelection("origin", "feature"))
+
+		assert.ContainsString(t, errOut.String(), "Could not reach 'origin'")
+		assert.Equal(t, f.Head, "feature")
+		assert.Equal(t, f.Refs["refs/heads/feature"], f.Refs["refs/remotes/origin/feature"])
+		assert.Equal(t, f.Upstreams["feature"], "origin/feature")
+	})
+
+	t.Run("never fetched", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithFeature(t)
+		f.FailNext("fetch", unreachable)
+
+		s := &SwitchCommand{cmdIO: cmdIO{Out: io.Discard, Err: io.Discard, UI: &stubSelector{}, Repo: f}}
+		err := s.handleRemoteSelection("origin", "other")
+
+		assert.Error(t, err, assert.AnyError)
+		assert.ContainsString(t, err.Error(), "hint: the remote could not be reached")
+		assert.Equal(t, f.Head, "main")
+	})
+
+	t.Run("other fetch errors don't fall back", func(t *testing.T) {
+		t.Parallel()
+		f := fakeWithFeature(t)
+		f.FailNext("fetch", git.FakeError(git.KindLockExists, "fatal: Unable to create '.git/shallow.lock': File exists."))
+
+		s := &SwitchCommand{cmdIO: cmdIO{Out: io.Discard, Err: io.Discard, UI: &stubSelector{}, Repo: f}}
+		err := s.handleRemoteSelection("origin", "feature")
+
+		assert.Error(t, err, assert.AnyError)
+		assert.ContainsString(t, err.Error(), "another git process")
+	})
+}
+
+// reset --hard onto the remote can still fail on the worktree; the local
+// branch is left where it was.
+func TestHandleRemoteSelection_ResetFails(t *testing.T) {
+	t.Parallel()
+	f := fakeWithFeature(t)
+	f.Refs["refs/heads/feature"] = f.Refs["refs/heads/main"]
+	f.Upstreams["feature"] = "origin/feature"
+	f.FailNext("reset", git.FakeError(git.KindDirtyOverwrite, "error: Your local changes to the following files would be overwritten by merge:"))
+
+	s := &SwitchCommand{cmdIO: cmdIO{Out: io.Discard, Err: io.Discard, UI: &stubSelector{confirmAnswers: []bool{true}}, Repo: f}}
+	err := s.handleRemoteSelection("origin", "feature")
+
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "resetting local branch")
+	assert.ContainsString(t, err.Error(), "hint: local changes would be overwritten")
+	assert.Equal(t, f.Refs["refs/heads/feature"], f.Refs["refs/heads/main"])
+	journal, err := f.Journal()
+	require.NoError(t, err)
+	assert.Equal(t, len(journal), 0)
+}
+
+func TestHandleRemoteSelection_CreateBranchFails(t *testing.T) {
+	t.Parallel()
+	f := fakeWithFeature(t)
+	f.FailNext("checkout", git.FakeError(git.KindDirtyOverwrite, "error: Your local changes to the following files would be overwritten by checkout:"))
+
+	s := &SwitchCommand{cmdIO: cmdIO{Out: io.Discard, Err: io.Discard, UI: &stubSelector{confirmAnswers: []bool{true}}, Repo: f}}
+	err := s.handleRemoteSelection("origin", "feature")
+
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "creating tracking branch")
+	assert.That(t, !f.BranchExists("feature"), "feature should not have been created")
+	assert.Equal(t, f.Head, "main")
+}
+
+// newSubmoduleBranches returns a superproject on main whose branch "bump"
+// records lib one commit further on, and that commit's oid.
+func newSubmoduleBranches(t *testing.T) (super, bumped string) {
+	t.Helper()
+	super, _ = temp_repo.NewRepoWithSubmodule(t)
+	temp_repo.RunGit(t, super, "checkout", "-q", "-b", "bump")
+	bumped = temp_repo.BumpSubmodule(t, super, "lib", "a.txt")
+	temp_repo.RunGit(t, super, "checkout", "-q", "main")
+	// checkout leaves lib at bump's commit; put it back where main has it
+	temp_repo.RunGit(t, super, "submodule", "update", "-q")
+	return super, bumped
+}
+
+func TestApplySelection_OffersSubmoduleUpdate(t *testing.T) {
+	t.Parallel()
+	super, bumped := newSubmoduleBranches(t)
+	sub := filepath.Join(super, "lib")
+
+	var out bytes.Buffer
+	stub := &stubSelector{confirmAnswers: []bool{true}}
+	s := &SwitchCommand{cmdIO: cmdIO{Out: &out, Err: io.Discard, UI: stub, Repo: git.Repo{Dir: super}}}
+	require.NoError(t, s.applySelection("local: bump"))
+
+	assert.Equal(t, len(stub.confirmCalls), 1)
+	assert.ContainsString(t, stub.confirmCalls[0].Prompt, "submodule update")
+	assert.ContainsString(t, out.String(), "+ lib")
+	assert.Equal(t, strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD")), bumped)
+}
+
+func TestApplySelection_SubmoduleUpdateDeclined(t *testing.T) {
+	t.Parallel()
+	super, bumped := newSubmoduleBranches(t)
+	sub := filepath.Join(super, "lib")
+	before := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+
+	var errOut bytes.Buffer
+	stub := &stubSelector{confirmAnswers: []bool{false}}
+	s := &SwitchCommand{cmdIO: cmdIO{Out: io.Discard, Err: &errOut, UI: stub, Repo: git.Repo{Dir: super}}}
+	require.NoError(t, s.applySelection("local: bump"))
+
+	assert.ContainsString(t, errOut.String(), "Not updating submodules")
+	got := strings.TrimSpace(temp_repo.RunGit(t, sub, "rev-parse", "HEAD"))
+	assert.Equal(t, got, before)
+	assert.That(t, got != bumped, "lib should not have moved")
+}
+
+// a submodule checked out elsewhere on purpose is left be when the switch
+// doesn't change what's recorded for it.
+func TestApplySelection_KeepsDeliberateSubmoduleDrift(t *testing.T) {
+	t.Parallel()
+	f := git.NewFake()
+	f.Refs["refs/heads/other"] = f.Refs["refs/heads/main"]
+	f.Subs = []git.Submodule{{Name: "lib", Path: "lib", Recorded: "aaaa", CheckedOut: "bbbb"}}
+	rec := git.NewRecorder(f)
+
+	s := &SwitchCommand{cmdIO: cmdIO{Out: io.Discard, Err: io.Discard, UI: &stubSelector{}, Repo: rec}}
+	require.NoError(t, s.applySelection("local: other"))
+
+	assert.Equal(t, f.Head, "other")
+	assert.Equal(t, f.Subs[0].CheckedOut, "bbbb")
+	assert.That(t, !slices.Contains(rec.Methods(), "SubmoduleUpdate"), "no update expected")
+}
+
+func TestApplySelection_SubmoduleUpdateFails(t *testing.T) {
+	t.Parallel()
+	f := git.NewFake()
+	f.Refs["refs/heads/other"] = f.Refs["refs/heads/main"]
+	f.Subs = []git.Submodule{{Name: "lib", Path: "lib", Recorded: "aaaa", CheckedOut: "aaaa"}}
+	f.FailNext("submodule", git.FakeError(git.KindDirtyOverwrite, "error: Your local changes to the following files would be overwritten by checkout:"))
+	// the switch moves what's recorded; the Fake doesn't model that itself
+	s := &SwitchCommand{cmdIO: cmdIO{Out: io.Discard, Err: io.Discard, UI: &stubSelector{confirmAnswers: []bool{true}}, Repo: &bumpOnCheckout{Fake: f, recorded: "cccc"}}}
+
+	err := s.applySelection("local: other")
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "updating submodules")
+	assert.ContainsString(t, err.Error(), "hint: local changes would be overwritten")
+}
+
+// bumpOnCheckout is a Fake whose checkouts also move lib's recorded
+// commit, as switching to a branch that bumped it would.
+type bumpOnCheckout struct {
+	*git.Fake
+	recorded string
+}
+
+func (b *bumpOnCheckout) Checkout(branch string) error {
+	if err := b.Fake.Checkout(branch); err != nil {
+		return err
+	}
+	b.Subs[0].Recorded = b.recorded
+	return nil
+}
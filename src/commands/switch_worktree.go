@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// goToWorktree handles a picked branch that's checked out in another
+// worktree, which git won't check out here as well: it offers a shell in
+// that worktree, or, when the worktree's directory is gone, to prune it
+// and switch here after all.
+func (s *SwitchCommand) goToWorktree(selected string) error {
+	entry, _, _ := strings.Cut(selected, checkedOutMarker)
+	_, name, _ := strings.Cut(entry, ": ")
+	branch := name
+	if strings.HasPrefix(entry, "remote: ") {
+		_, branch, _ = strings.Cut(name, "/")
+	}
+
+	r := s.repo()
+	wts, err := r.Worktrees()
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+	i := slices.IndexFunc(wts, func(wt git.Worktree) bool { return wt.Branch == branch })
+	if i < 0 {
+		// freed since the picker listed it
+		return s.switchTo(entry)
+	}
+	wt := wts[i]
+
+	if wt.Prunable {
+		fmt.Fprintf(s.out(), "'%s' is checked out in %s, which is gone.\n", branch, wt.Path)
+		if wt.Locked {
+			fmt.Fprintf(s.out(), "That worktree is locked; run 'git worktree unlock %s' to let it be pruned.\n", wt.Path)
+			return nil
+		}
+		confirmed, err := s.sel().Confirm(fmt.Sprintf("Prune it and switch to '%s' here?", branch), true)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(s.out(), "Not switching.")
+			return nil
+		}
+		if err := r.PruneWorktrees(); err != nil {
+			return err
+		}
+		return s.switchTo(entry)
+	}
+
+	fmt.Fprintf(s.out(), "'%s' is checked out in the worktree at %s.\n", branch, wt.Path)
+	confirmed, err := s.sel().Confirm("Open a shell there?", true)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintf(s.out(), "To work on it: cd %s\n", wt.Path)
+		return nil
+	}
+	shell := s.shell
+	if shell == nil {
+		shell = runShell
+	}
+	if err := shell(wt.Path); err != nil {
+		return fmt.Errorf("shell in %s: %w", wt.Path, err)
+	}
+	fmt.Fprintf(s.out(), "Back from %s.\n", wt.Path)
+	return nil
+}
+
+// runShell runs the user's $SHELL (sh without one) in dir, on gg's own
+// terminal, until it exits.
+func runShell(dir string) error {
+	sh := os.Getenv("SHELL")
+	if sh == "" {
+		sh = "/bin/sh"
+	}
+	cmd := exec.Command(sh)
+	cmd.Dir = dir
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	// its exit status is the last command typed's, not a failure
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// WithNetworkTimeout applies the per-call network timeout to ctx. timeout
+// is a Go duration ("30s", "2m"), "0" for none, or "" to fall back to
+// GG_NETWORK_TIMEOUT and then git.DefaultNetworkTimeout.
+func WithNetworkTimeout(ctx context.Context, timeout string) (context.Context, error) {
+	if timeout == "" {
+		timeout = os.Getenv("GG_NETWORK_TIMEOUT")
+	}
+	if timeout == "" {
+		return ctx, nil
+	}
+	if timeout == "0" {
+		return git.WithNetworkTimeout(ctx, 0), nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d < 0 {
+		return nil, fmt.Errorf("invalid network timeout %q: want a duration like 30s or 2m, or 0 for none", timeout)
+	}
+	return git.WithNetworkTimeout(ctx, d), nil
+}
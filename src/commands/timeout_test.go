@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// not parallel: t.Setenv
+func TestWithNetworkTimeout(t *testing.T) {
+	tests := []struct {
+		flag, env string
+		want      time.Duration
+		wantErr   bool
+	}{
+		{"", "", git.DefaultNetworkTimeout, false},
+		{"30s", "", 30 * time.Second, false},
+		{"0", "", 0, false},
+		{"", "5m", 5 * time.Minute, false},
+		{"10s", "5m", 10 * time.Second, false},
+		{"soon", "", 0, true},
+		{"-1s", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("GG_NETWORK_TIMEOUT", tt.env)
+		ctx, err := WithNetworkTimeout(context.Background(), tt.flag)
+		if tt.wantErr {
+			assert.Error(t, err, assert.AnyError, tt.flag)
+			continue
+		}
+		require.NoError(t, err, tt.flag)
+		assert.Equal(t, git.NetworkTimeout(ctx), tt.want)
+	}
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lczyk/gitgum/internal/git"
+)
+
+// traceSummaryCalls is how many of the slowest calls the end-of-run
+// summary lists.
+const traceSummaryCalls = 10
+
+// StartTrace installs a git call tracer for the run. dest is where each
+// call is logged as it finishes: "" for nowhere, "-" or "1" for stderr,
+// anything else a file path. jsonPath, if set, gets the calls as Chrome
+// trace-event JSON at the end. Either falls back to GG_TRACE /
+// GG_TRACE_JSON when empty.
+//
+// The returned finish writes the slowest-calls summary after the log and
+// the JSON file, then uninstalls the tracer. With tracing off, it's a
+// no-op.
+func StartTrace(dest, jsonPath string) (finish func() error, err error) {
+	if dest == "" {
+		dest = os.Getenv("GG_TRACE")
+	}
+	if jsonPath == "" {
+		jsonPath = os.Getenv("GG_TRACE_JSON")
+	}
+	if dest == "0" || dest == "false" {
+		dest = ""
+	}
+	if dest == "" && jsonPath == "" {
+		return func() error { return nil }, nil
+	}
+
+	var log io.Writer
+	var logFile *os.File
+	switch dest {
+	case "":
+	case "-", "1", "true":
+		log = os.Stderr
+	default:
+		logFile, err = os.Create(dest)
+		if err != nil {
+			return nil, fmt.Errorf("opening trace file: %w", err)
+		}
+		log = logFile
+	}
+
+	tracer := git.NewTracer(log)
+	git.SetTracer(tracer)
+	return func() error {
+		git.SetTracer(nil)
+		if log != nil {
+			tracer.WriteSummary(log, traceSummaryCalls)
+		}
+		if logFile != nil {
+			if err := logFile.Close(); err != nil {
+				return fmt.Errorf("writing trace file: %w", err)
+			}
+		}
+		if jsonPath != "" {
+			return writeChromeTrace(tracer, jsonPath)
+		}
+		return nil
+	}, nil
+}
+
+func writeChromeTrace(tracer *git.Tracer, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("opening trace json: %w", err)
+	}
+	if err := tracer.WriteChromeTrace(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing trace json: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing trace json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+// not parallel: the tracer is process-wide
+func TestStartTrace_FileAndJSON(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	out := t.TempDir()
+	logPath := filepath.Join(out, "trace.log")
+	jsonPath := filepath.Join(out, "trace.json")
+
+	finish, err := StartTrace(logPath, jsonPath)
+	require.NoError(t, err)
+	_, _, err = git.Repo{Dir: dir}.Run("rev-parse", "HEAD")
+	require.NoError(t, err)
+	require.NoError(t, finish())
+
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.ContainsString(t, string(log), "rev-parse HEAD")
+	assert.ContainsString(t, string(log), "slowest:")
+
+	raw, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	assert.That(t, doc["traceEvents"] != nil, "no traceEvents in %s", raw)
+
+	// finish uninstalled the tracer
+	_, _, err = git.Repo{Dir: dir}.Run("rev-parse", "--show-toplevel")
+	require.NoError(t, err)
+	log, err = os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.That(t, !strings.Contains(string(log), "--show-toplevel"), "call traced after finish:\n%s", log)
+}
+
+func TestStartTrace_Env(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "trace.log")
+	t.Setenv("GG_TRACE", logPath)
+	finish, err := StartTrace("", "")
+	require.NoError(t, err)
+	require.NoError(t, finish())
+	_, err = os.Stat(logPath)
+	require.NoError(t, err, "GG_TRACE path should be used")
+
+	t.Setenv("GG_TRACE", "0")
+	finish, err = StartTrace("", "")
+	require.NoError(t, err)
+	require.NoError(t, finish())
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"regexp"
 	"slices"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/src/graph"
 	"github.com/lczyk/gitgum/src/litescreen"
 	"github.com/lczyk/gitgum/src/litescreen/ansi"
 )
@@ -23,6 +25,40 @@ type TreeCommand struct {
 	Since   string   `long:"since" default:"2w" description:"limit history. shorthand: '2w', '10d', '1h' (units: s/m/h/d/w/y). ISO date: '2024-01-01'. bare integer: tree depth (last N commits). empty: show all."`
 	Reverse bool     `long:"reverse" short:"r" description:"newest-first output (useful in follow mode)"`
 	Follow  *float64 `long:"follow" short:"f" optional:"yes" optional-value:"2" description:"follow mode: refresh every N seconds (default 2, min 1)"`
+	// Order picks the native engine's row ordering. "git" matches what
+	// `git log --graph` prints (--graph implies --topo-order).
+	Order string `long:"order" default:"engine" choice:"engine" choice:"git" choice:"date" choice:"topo" choice:"first-parent" description:"commit ordering: engine (keeps merged branches next to their merge), git/topo (git --topo-order, as git log --graph prints), date (git --date-order), first-parent (keep mainline runs contiguous)"`
+	// LaneColors pins graph lane colours per branch; other lanes pick from
+	// the palette by a hash of their branch name.
+	LaneColors string `long:"lane-colors" description:"per-branch lane colours, e.g. 'main=green,feat/x=bold-blue'. names: red green yellow blue magenta cyan purple pink, each with a bold- variant"`
+
+	// Pick opens the graph in the fuzzyfinder and prints the chosen
+	// commits' hashes instead of the graph.
+	Pick bool `long:"pick" short:"p" description:"pick commits from the graph interactively (Tab for several); prints their full hashes"`
+
+	// Collapse folds straight runs of plain commits into one row.
+	Collapse int `long:"collapse" optional:"yes" optional-value:"3" default:"0" description:"fold runs of N or more linear, undecorated commits into one '⋮ N commits' row (default 3; Enter expands them in --follow)"`
+	// Format swaps the terminal rendering for an export.
+	Format string `long:"format" default:"text" choice:"text" choice:"svg" choice:"dot" choice:"json" description:"output format: text (terminal graph), svg (drawn graph, lane colours), dot (Graphviz DAG), json (rows, glyphs and node positions)"`
+
+	// Branches, Remotes and Exclude narrow the refs the graph starts from
+	// (default: all of them). FirstParent and AncestryPath filter the
+	// history below; edges leaving the filtered set end on an elided
+	// marker (graph.Prune).
+	Branches     string `long:"branches" description:"only branches matching these comma-separated names or globs, e.g. 'feat/*,main'"`
+	Remotes      bool   `long:"remotes" description:"only remote-tracking branches (with --branches: those as well)"`
+	Exclude      string `long:"exclude" description:"leave out branches matching these comma-separated globs, e.g. 'dependabot/*' (matches remote branches under any remote too)"`
+	FirstParent  bool   `long:"first-parent" description:"follow only the first parent of merges; merged-in branches show as elided markers"`
+	AncestryPath string `long:"ancestry-path" value-name:"A..B" description:"only commits on a path from A to B (descendants of A that are ancestors of B)"`
+
+	// Highlight lights up what a ref can reach and dims the rest.
+	Highlight string `long:"highlight" value-name:"REF" description:"highlight the commits and lanes reachable from REF (e.g. HEAD) and dim everything else; merge bases with the default branch are marked ◆"`
+	// Columns adds per-commit metadata ahead of the graph.
+	Columns string `long:"columns" description:"metadata columns before the graph, comma-separated: author (initials), name, age, sig (signature status), stat (diffstat)"`
+
+	laneColors map[int64]string // parsed LaneColors, keyed by laneKey
+	columns    []string         // parsed Columns
+	revs       []string         // revision arguments for git log, see revArgs
 }
 
 var (
@@ -39,6 +75,21 @@ var sinceDurations = map[string]time.Duration{
 	"y": 365 * 24 * time.Hour,
 }
 
+// order maps the --order flag onto a graph.Order. Unknown values (only
+// reachable when the struct is built by hand, go-flags enforces the
+// choices) fall back to the engine default.
+func (t *TreeCommand) order() graph.Order {
+	switch t.Order {
+	case "date":
+		return graph.OrderDate
+	case "git", "topo":
+		return graph.OrderTopo
+	case "first-parent":
+		return graph.OrderFirstParent
+	}
+	return graph.OrderEngine
+}
+
 // parseSinceArg interprets the --since value. Returns (duration, sinceArg,
 // maxCount, err). For shorthands (2w, 10d, etc.) duration is non-zero and
 // sinceArg is empty -- the caller resolves the duration relative to the newest
@@ -66,12 +117,13 @@ func parseSinceArg(s string) (time.Duration, string, int, error) {
 
 // resolveSinceArg turns a parsed since into the final --since string for git.
 // For relative durations, it subtracts from the newest commit's timestamp
-// (across all branches) rather than from now.
-func resolveSinceArg(r git.Repo, dur time.Duration, sinceArg string) (string, error) {
+// (across the selected refs, revs) rather than from now.
+func resolveSinceArg(r git.Backend, revs []string, dur time.Duration, sinceArg string) (string, error) {
 	if dur == 0 {
 		return sinceArg, nil
 	}
-	stdout, _, err := r.Run("log", "--all", "-1", "--format=%ct", "--date-order")
+	args := append([]string{"log", "-1", "--format=%ct", "--date-order"}, revs...)
+	stdout, _, err := r.Run(args...)
 	if err != nil {
 		return "", fmt.Errorf("finding newest commit: %w", err)
 	}
@@ -102,10 +154,31 @@ func (t *TreeCommand) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
-	sinceArg, err = resolveSinceArg(t.repo(), dur, sinceArg)
+	if t.laneColors, err = parseLaneColors(t.LaneColors); err != nil {
+		return err
+	}
+	if t.columns, err = parseColumns(t.Columns); err != nil {
+		return err
+	}
+	if t.revs, err = t.revArgs(); err != nil {
+		return err
+	}
+	sinceArg, err = resolveSinceArg(t.repo(), t.revs, dur, sinceArg)
 	if err != nil {
 		return err
 	}
+	if t.Format != "" && t.Format != "text" {
+		if t.Follow != nil || t.Pick || len(t.columns) > 0 || t.Highlight != "" {
+			return fmt.Errorf("--format=%s can't be combined with --follow, --pick, --columns or --highlight", t.Format)
+		}
+		return t.renderExport(t.out(), sinceArg, maxCount)
+	}
+	if t.Pick {
+		if t.Follow != nil {
+			return errors.New("--pick can't be combined with --follow")
+		}
+		return t.runPick(sinceArg, maxCount)
+	}
 	if t.Follow == nil {
 		return t.renderOnce(t.out(), sinceArg, maxCount)
 	}
@@ -116,11 +189,14 @@ func (t *TreeCommand) renderOnce(w io.Writer, sinceArg string, maxCount int) err
 	if os.Getenv("GG_TREE_NATIVE") != "0" {
 		return t.renderNative(w, sinceArg, maxCount)
 	}
+	if len(t.columns) > 0 || t.Highlight != "" {
+		return errors.New("--columns and --highlight need the native renderer (GG_TREE_NATIVE=0 is set)")
+	}
 	colorFlag := "--color=never"
 	if colorEnabled() {
 		colorFlag = "--color=always"
 	}
-	gitArgs := []string{"log", "--graph", "--oneline", "--all", "--decorate", colorFlag}
+	gitArgs := append([]string{"log", "--graph", "--oneline", "--decorate", colorFlag}, t.revs...)
 	if sinceArg != "" {
 		gitArgs = append(gitArgs, "--since", sinceArg)
 	}
@@ -175,35 +251,44 @@ func (t *TreeCommand) runFollow(sinceArg string, maxCount int) error {
 	var (
 		cachedRefs   string
 		cachedLines  []string
+		cachedFolds  [][]string // parallels cachedLines, see streamNative
 		cachedAt     time.Time
 		cachedErr    error
 		forceRender  = true
+		rendering    bool
 		scrollOffset = 0
 		tailMode     = true
+		expanded     = map[string]bool{} // commits of folds opened with Enter
 	)
 
+	// renders run off the ui goroutine and post what they have so far, so a
+	// big history fills in segment by segment instead of blanking the
+	// screen until git log finishes.
+	progress := make(chan treeProgress)
 	refreshCache := func() {
+		if rendering {
+			return // previous render still streaming in; don't stack another
+		}
 		refs, refsErr := t.snapshotRefs()
 		stale := time.Since(cachedAt) > fullRefreshEvery
 		if forceRender || stale || refsErr != nil || refs != cachedRefs {
-			var treeBuf bytes.Buffer
-			cachedErr = t.renderOnce(&treeBuf, sinceArg, maxCount)
-			body := strings.Trim(treeBuf.String(), "\n")
-			if body == "" {
-				cachedLines = nil
-			} else {
-				cachedLines = strings.Split(body, "\n")
-			}
+			rendering = true
 			cachedRefs = refs
 			cachedAt = time.Now()
 			forceRender = false
+			// the render goroutine gets its own copy; Enter keeps adding
+			go t.renderProgressive(sinceArg, maxCount, t.layoutOptions(maps.Clone(expanded)), progress, quit)
 		}
 	}
 
+	keyHints := "j/k g/G scroll, q exit"
+	if t.Collapse > 0 {
+		keyHints = "j/k g/G scroll, enter expand, q exit"
+	}
 	frame := newFollowFrame(scr)
 	redraw := func() {
 		frame.Begin()
-		frame.Header(interval, "interval", "j/k g/G scroll, q exit")
+		frame.Header(interval, "interval", keyHints)
 		frame.Body(cachedLines, &scrollOffset, &tailMode, cachedErr)
 		frame.End()
 	}
@@ -215,12 +300,35 @@ func (t *TreeCommand) runFollow(sinceArg string, maxCount int) error {
 		case <-tick.C:
 			refreshCache()
 			redraw()
+		case p := <-progress:
+			// keep the previous frame up until the new render has
+			// something to show
+			if len(p.lines) > 0 || p.done {
+				cachedLines, cachedFolds = p.lines, p.folds
+			}
+			if p.done {
+				cachedErr = p.err
+				rendering = false
+				if forceRender {
+					refreshCache() // an Enter landed mid-render
+				}
+			}
+			redraw()
 		case ev := <-events:
 			switch ev := ev.(type) {
 			case *tcell.EventResize:
 				redraw()
 			case *tcell.EventKey:
 				_, h := scr.Size()
+				if ev.Key() == tcell.KeyEnter {
+					// no cursor in follow mode: Enter opens every fold on
+					// screen (the body sits below the one-line header)
+					if expandVisibleFolds(cachedFolds, scrollOffset, h-1, expanded) {
+						forceRender = true
+						refreshCache()
+					}
+					continue
+				}
 				if !handleFollowKey(ev, &scrollOffset, &tailMode, h) {
 					return nil
 				}
@@ -230,6 +338,107 @@ func (t *TreeCommand) runFollow(sinceArg string, maxCount int) error {
 	}
 }
 
+// expandVisibleFolds marks the commits of every fold row in the visible
+// window [offset, offset+visible) as expanded. Reports whether there was
+// anything to open.
+func expandVisibleFolds(folds [][]string, offset, visible int, expanded map[string]bool) bool {
+	lo := max(offset, 0)
+	hi := min(offset+visible, len(folds))
+	opened := false
+	for i := lo; i < hi; i++ {
+		for _, id := range folds[i] {
+			expanded[id] = true
+			opened = true
+		}
+	}
+	return opened
+}
+
+// treeProgress is one update from renderProgressive: the lines rendered
+// so far, in display order. done marks the last update of a render.
+type treeProgress struct {
+	lines []string
+	folds [][]string // parallels lines
+	err   error
+	done  bool
+}
+
+// progressEvery throttles partial updates from renderProgressive. Every
+// update flattens the lines so far, so posting per segment would go
+// quadratic on six-figure histories.
+const progressEvery = 100 * time.Millisecond
+
+// renderProgressive renders the tree for --follow, posting partial results
+// to out as segments land and a final done update at the end. Segments
+// arrive newest history first, so oldest-first output grows upwards --
+// tail mode keeps the newest commits in view while older ones fill in.
+// Gives up as soon as quit closes.
+func (t *TreeCommand) renderProgressive(sinceArg string, maxCount int, opts graph.LayoutOptions, out chan<- treeProgress, quit <-chan struct{}) {
+	send := func(p treeProgress) bool {
+		select {
+		case out <- p:
+			return true
+		case <-quit:
+			return false
+		}
+	}
+	if os.Getenv("GG_TREE_NATIVE") == "0" {
+		var treeBuf bytes.Buffer
+		err := t.renderOnce(&treeBuf, sinceArg, maxCount)
+		lines := splitBody(treeBuf.String())
+		send(treeProgress{lines: lines, folds: make([][]string, len(lines)), err: err, done: true})
+		return
+	}
+
+	type segment struct {
+		lines []string
+		folds [][]string
+	}
+	var segs []segment
+	flatten := func() ([]string, [][]string) {
+		var lines []string
+		var folds [][]string
+		for i := range segs {
+			if !t.Reverse {
+				i = len(segs) - 1 - i
+			}
+			lines = append(lines, segs[i].lines...)
+			folds = append(folds, segs[i].folds...)
+		}
+		return lines, folds
+	}
+	last := time.Now()
+	err := t.streamNative(sinceArg, maxCount, opts, func(lines []string, folds [][]string) bool {
+		segs = append(segs, segment{lines, folds})
+		select {
+		case <-quit:
+			return false
+		default:
+		}
+		if time.Since(last) < progressEvery {
+			return true
+		}
+		last = time.Now()
+		lines, folds = flatten()
+		return send(treeProgress{lines: lines, folds: folds})
+	})
+	if errors.Is(err, errTreeStopped) {
+		return
+	}
+	lines, folds := flatten()
+	send(treeProgress{lines: lines, folds: folds, err: err, done: true})
+}
+
+// splitBody splits rendered output into lines, dropping the surrounding
+// blank lines. An empty body is nil.
+func splitBody(s string) []string {
+	body := strings.Trim(s, "\n")
+	if body == "" {
+		return nil
+	}
+	return strings.Split(body, "\n")
+}
+
 // handleFollowKey applies a key event to the follow-mode scroll state.
 // Returns false when the key requests exit. screenH is the current screen
 // height; used for page-size math.
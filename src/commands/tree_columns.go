@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lczyk/gitgum/src/graph"
+	"github.com/lczyk/gitgum/src/litescreen/ansi"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// treeColumnWidths lists the --columns fields and their widths. Widths are
+// fixed rather than fitted to the data so rows line up across stream
+// segments.
+var treeColumnWidths = map[string]int{
+	"author": 3,  // initials
+	"name":   16, // author name, cut with "…"
+	"age":    4,  // "3d", "11mo"
+	"sig":    1,  // signature status glyph
+	"stat":   11, // "+1234 -567"
+}
+
+// commitMeta is the per-commit data behind --columns, beyond what the
+// graph itself needs.
+type commitMeta struct {
+	author         string
+	epoch          int64
+	sig            string // git's %G? code; empty when sig isn't shown
+	added, deleted int
+	stat           bool // a --shortstat line came through (merges get none)
+}
+
+// parseColumns parses a --columns value ("author,age,sig") into the
+// column names, in order.
+func parseColumns(s string) ([]string, error) {
+	cols := splitList(s)
+	for _, c := range cols {
+		if _, ok := treeColumnWidths[c]; !ok {
+			return nil, fmt.Errorf("--columns: unknown column %q (one of: author, name, age, sig, stat)", c)
+		}
+	}
+	return cols, nil
+}
+
+// parseCommitMeta reads the --columns fields nativeLogArgs appends to a
+// log line: "...\x00<author>\x00<sig>".
+func parseCommitMeta(line string, epoch int64) *commitMeta {
+	seg := strings.SplitN(line, "\x00", 5)
+	m := &commitMeta{epoch: epoch}
+	if len(seg) > 3 {
+		m.author = seg[3]
+	}
+	if len(seg) > 4 {
+		m.sig = strings.TrimSpace(seg[4])
+	}
+	return m
+}
+
+// parseShortstat reads a --shortstat line (" 2 files changed, 5
+// insertions(+), 1 deletion(-)") into m. ok is false for anything else.
+func parseShortstat(line string, m *commitMeta) bool {
+	if !strings.Contains(line, "changed") {
+		return false
+	}
+	for _, part := range strings.Split(line, ",") {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			continue
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(fields[1], "insertion"):
+			m.added = n
+		case strings.HasPrefix(fields[1], "deletion"):
+			m.deleted = n
+		}
+	}
+	m.stat = true
+	return true
+}
+
+// addColumns prefixes each rendered row with its --columns cells. Rows
+// without a commit of their own -- connectors, folds, elided markers --
+// get blanks so the graph stays in line. Metadata is dropped from metas
+// once its row is out.
+func (t *TreeCommand) addColumns(lr graph.LayoutResult, lines []string, metas map[string]*commitMeta, useColor bool) {
+	now := time.Now()
+	for i, row := range lr.Rows {
+		var m *commitMeta
+		if c := row.Commit; c != nil {
+			if row.Folded == nil {
+				m = metas[c.ID]
+			}
+			delete(metas, c.ID)
+			for _, n := range row.Folded {
+				delete(metas, n.ID)
+			}
+		}
+		var b strings.Builder
+		for _, col := range t.columns {
+			plain, styled := "", ""
+			if m != nil {
+				plain, styled = columnCell(col, m, now, useColor)
+			}
+			pad := strings.Repeat(" ", max(treeColumnWidths[col]-runewidth.StringWidth(plain), 0))
+			if col == "age" || col == "stat" {
+				b.WriteString(pad + styled)
+			} else {
+				b.WriteString(styled + pad)
+			}
+			b.WriteByte(' ')
+		}
+		lines[i] = b.String() + lines[i]
+	}
+}
+
+// columnCell is one column's text for m, fitted to the column's width:
+// plain for measuring, and styled for printing (the same text, coloured
+// when useColor is on).
+func columnCell(col string, m *commitMeta, now time.Time, useColor bool) (plain, styled string) {
+	paint := func(text, color string) (string, string) {
+		if !useColor || text == "" {
+			return text, text
+		}
+		return text, color + text + ansiReset
+	}
+	switch col {
+	case "author":
+		return paint(initials(m.author), ansiBoldBlue)
+	case "name":
+		return paint(runewidth.Truncate(m.author, treeColumnWidths[col], "…"), ansiBoldBlue)
+	case "age":
+		return paint(formatAge(now.Sub(time.Unix(m.epoch, 0))), ansiGreen)
+	case "sig":
+		switch m.sig {
+		case "G":
+			return paint("✓", ansiGreen)
+		case "U":
+			return paint("✓", ansiYellow) // good, but the key isn't trusted
+		case "B", "R":
+			return paint("✗", ansiRed) // bad or revoked
+		case "X", "Y":
+			return paint("!", ansiYellow) // expired
+		case "E":
+			return paint("?", ansiDim) // can't check, key missing
+		}
+	case "stat":
+		if !m.stat {
+			return "", ""
+		}
+		addPlain, add := paint("+"+compactCount(m.added), ansiGreen)
+		delPlain, del := paint("-"+compactCount(m.deleted), ansiRed)
+		return addPlain + " " + delPlain, add + " " + del
+	}
+	return "", ""
+}
+
+// initials turns an author name into up to three capitals: "Jane Q.
+// Public" -> "JQP". A one-word name gives its first two letters.
+func initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '.' || r == '-' || r == '_'
+	})
+	var out []rune
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		out = []rune(words[0])[:min(2, len([]rune(words[0])))]
+	default:
+		for _, w := range words[:min(3, len(words))] {
+			out = append(out, []rune(w)[0])
+		}
+	}
+	return strings.ToUpper(string(out))
+}
+
+// formatAge is a short relative age: "now", "45m", "3h", "2d", "3w",
+// "11mo", "2y".
+func formatAge(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return strconv.Itoa(int(d/time.Minute)) + "m"
+	case d < day:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	case d < 14*day:
+		return strconv.Itoa(int(d/day)) + "d"
+	case d < 60*day:
+		return strconv.Itoa(int(d/(7*day))) + "w"
+	case d < 365*day:
+		return strconv.Itoa(int(d/(30*day))) + "mo"
+	}
+	return strconv.Itoa(int(d/(365*day))) + "y"
+}
+
+// compactCount keeps diffstat counts to four characters: 12345 -> "12k".
+func compactCount(n int) string {
+	if n < 10000 {
+		return strconv.Itoa(n)
+	}
+	if n < 1000000 {
+		return strconv.Itoa(n/1000) + "k"
+	}
+	return strconv.Itoa(n/1000000) + "M"
+}
+
+// fitToTerminal cuts lines down to the terminal's width when stdout is
+// one, so column-heavy rows end in "…" instead of wrapping.
+func fitToTerminal(lines []string) {
+	if !stdoutIsTTY() {
+		return
+	}
+	w, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w <= 0 {
+		return
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, w, "…")
+	}
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lczyk/gitgum/src/graph"
+)
+
+// cssColors maps the ansi codes the tree palette and --lane-colors use onto
+// SVG paint values, so an exported graph keeps the terminal's lane colours.
+// Bold variants get the brighter shade, as most terminals draw them.
+var cssColors = map[string]string{
+	ansiRed:         "#c91b00",
+	ansiGreen:       "#00a600",
+	ansiYellow:      "#c7a100",
+	ansiBlue:        "#2a4fd6",
+	ansiMagenta:     "#b22cb2",
+	ansiCyan:        "#00a6b2",
+	ansiPurple:      "#875faf",
+	ansiPink:        "#ff5faf",
+	ansiBoldRed:     "#ff4438",
+	ansiBoldGreen:   "#1fc91f",
+	ansiBoldYellow:  "#e5bf00",
+	ansiBoldBlue:    "#5c7cff",
+	ansiBoldMagenta: "#e046e0",
+	ansiBoldCyan:    "#10d1dd",
+	ansiBoldPurple:  "#a67fd1",
+	ansiBoldPink:    "#ff7fc1",
+}
+
+// renderExport lays out the graph and writes it as --format asks (dot,
+// json or svg) instead of terminal text. Labels are uncoloured; colour,
+// where the format has any, comes from the lanes.
+func (t *TreeCommand) renderExport(w io.Writer, sinceArg string, maxCount int) error {
+	var nodes []graph.Node
+	err := t.readNative(sinceArg, maxCount, false, func(n graph.Node, _ *commitMeta) error {
+		nodes = append(nodes, n)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	lr := graph.Layout(nodes, graph.LayoutOptions{Order: t.order()})
+
+	switch t.Format {
+	case "dot":
+		err = graph.WriteDOT(w, lr)
+	case "json":
+		err = graph.WriteJSON(w, lr)
+	case "svg":
+		palette := make([]string, len(lanePalette))
+		for i, code := range lanePalette {
+			palette[i] = cssColors[code]
+		}
+		st := graph.Style{LinePrefix: cssColors[ansiRed], Palette: palette}
+		if len(t.laneColors) > 0 {
+			st.LaneColor = func(key int64) string { return cssColors[t.laneColors[key]] }
+		}
+		err = graph.WriteSVG(w, lr, st)
+	default:
+		return fmt.Errorf("--format=%q: unknown format", t.Format)
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", t.Format, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// revArgs builds the revision selection every tree render hands to git:
+// --all by default, narrowed by --branches / --remotes / --exclude, or the
+// --ancestry-path range, plus --first-parent.
+//
+// git scopes --exclude to the next --all / --branches / --remotes and
+// matches it against the name under that namespace, so each exclude is
+// repeated before every selector in the form that selector sees: a
+// 'dependabot/*' has to read 'refs/heads/dependabot/*' for --all and
+// '*/dependabot/*' for remotes, which carry the remote's name.
+func (t *TreeCommand) revArgs() ([]string, error) {
+	branches := splitList(t.Branches)
+	excludes := splitList(t.Exclude)
+	var revs []string
+	if t.FirstParent {
+		revs = append(revs, "--first-parent")
+	}
+
+	if t.AncestryPath != "" {
+		if len(branches) > 0 || t.Remotes || len(excludes) > 0 {
+			return nil, errors.New("--ancestry-path can't be combined with --branches, --remotes or --exclude")
+		}
+		from, to, ok := strings.Cut(t.AncestryPath, "..")
+		if !ok || from == "" || to == "" || strings.HasPrefix(to, ".") {
+			return nil, fmt.Errorf("--ancestry-path=%q: expected a range A..B", t.AncestryPath)
+		}
+		return append(revs, "--ancestry-path", from+".."+to), nil
+	}
+
+	exclude := func(forms ...string) {
+		for _, g := range excludes {
+			for _, form := range forms {
+				revs = append(revs, "--exclude="+strings.ReplaceAll(form, "%s", g))
+			}
+		}
+	}
+	if len(branches) == 0 && !t.Remotes {
+		exclude("refs/heads/%s", "refs/remotes/%s", "refs/remotes/*/%s")
+		return append(revs, "--all"), nil
+	}
+	for _, b := range branches {
+		if !hasGlobMeta(b) {
+			// git reads a plain --branches=main as main/*, so exact names
+			// go in as refs; --exclude doesn't apply to an explicit ref
+			ref, err := t.branchRef(b)
+			if err != nil {
+				return nil, err
+			}
+			revs = append(revs, ref)
+			continue
+		}
+		exclude("%s")
+		revs = append(revs, "--branches="+b)
+	}
+	if t.Remotes {
+		exclude("%s", "*/%s")
+		revs = append(revs, "--remotes")
+	}
+	return revs, nil
+}
+
+// branchRef resolves a plain --branches name to its full ref: a local
+// branch, or failing that a remote-tracking one ("origin/main").
+func (t *TreeCommand) branchRef(name string) (string, error) {
+	for _, ref := range []string{"refs/heads/" + name, "refs/remotes/" + name} {
+		if _, _, err := t.repo().Run("show-ref", "--verify", "--quiet", ref); err == nil {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("--branches: no branch named %q", name)
+}
+
+// hasGlobMeta reports whether s holds a glob character git would expand.
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// splitList splits a comma-separated flag value, dropping blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// pruneKeep builds the graph.Prune predicate for --first-parent and
+// --ancestry-path: which parent edges stay in the view. nil means every
+// edge does.
+//
+// The ancestry set comes from rev-list without --since, so a parent that's
+// on the path but older than the window still dangles off the bottom like
+// any other cut-off history instead of turning into a marker.
+func (t *TreeCommand) pruneKeep() (func(parent string, i int) bool, error) {
+	if !t.FirstParent && t.AncestryPath == "" {
+		return nil, nil
+	}
+	var onPath map[string]bool
+	if t.AncestryPath != "" {
+		onPath = map[string]bool{}
+		err := t.repo().RunLines(func(line string) error {
+			onPath[strings.TrimSpace(line)] = true
+			return nil
+		}, append([]string{"rev-list"}, t.revs...)...)
+		if err != nil {
+			return nil, fmt.Errorf("git rev-list: %w", err)
+		}
+	}
+	return func(parent string, i int) bool {
+		if t.FirstParent && i > 0 {
+			return false
+		}
+		return onPath == nil || onPath[parent]
+	}, nil
+}
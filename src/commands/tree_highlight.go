@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lczyk/gitgum/src/graph"
+	"github.com/lczyk/gitgum/src/litescreen/ansi"
+)
+
+// mergeBaseMark stands in for `*` on a merge base of the --highlight ref
+// and the default branch.
+const mergeBaseMark = "◆"
+
+// highlighter implements --highlight. Reachability is worked out as the
+// nodes stream in: git log lists children before parents, so a commit is
+// reachable from the ref exactly when it's the ref or some reachable
+// commit named it as a parent by the time it shows up.
+type highlighter struct {
+	useColor bool
+	want     map[string]bool // reachable, not seen yet
+	lit      map[string]bool // reachable and seen, until its row is drawn
+	bases    map[string]bool // merge bases with the default branch
+}
+
+// newHighlighter resolves --highlight and its merge bases with the
+// default branch. nil without --highlight. A repo with no resolvable
+// default branch just gets no merge-base marks.
+func (t *TreeCommand) newHighlighter(useColor bool) (*highlighter, error) {
+	if t.Highlight == "" {
+		return nil, nil
+	}
+	tip, err := t.repo().GetCommitHash(t.Highlight + "^{commit}")
+	if err != nil {
+		// rev-parse's own complaint is mostly usage noise
+		return nil, fmt.Errorf("--highlight=%s: no such commit", t.Highlight)
+	}
+	h := &highlighter{
+		useColor: useColor,
+		want:     map[string]bool{tip: true},
+		lit:      map[string]bool{},
+		bases:    map[string]bool{},
+	}
+	if def, err := t.repo().GetDefaultBranch(); err == nil {
+		stdout, _, err := t.repo().Run("merge-base", "--all", tip, def)
+		if err == nil {
+			for _, base := range strings.Fields(stdout) {
+				// the ref sitting on the default branch is its own merge
+				// base; marking it says nothing
+				if base != tip {
+					h.bases[base] = true
+				}
+			}
+		}
+	}
+	return h, nil
+}
+
+// see records n as it streams in, newest first, and dims the label of a
+// commit the ref can't reach.
+func (h *highlighter) see(n *graph.Node) {
+	if !h.want[n.ID] {
+		if h.useColor {
+			n.Label = ansiDim + ansi.Strip(n.Label) + ansiReset
+		}
+		return
+	}
+	delete(h.want, n.ID)
+	h.lit[n.ID] = true
+	for _, p := range n.Parents {
+		h.want[p] = true
+	}
+}
+
+// style is the graph.Style.Node hook. Without colour there's no dimming
+// to see, so unreachable commits get a hollow marker instead.
+func (h *highlighter) style(n *graph.Node) graph.NodeStyle {
+	ns := graph.NodeStyle{Dim: !h.lit[n.ID]}
+	switch {
+	case h.bases[n.ID]:
+		ns.Mark = mergeBaseMark
+	case ns.Dim && !h.useColor:
+		ns.Mark = "o"
+	}
+	return ns
+}
+
+// drawn forgets the commits of rendered rows.
+func (h *highlighter) drawn(lr graph.LayoutResult) {
+	for _, row := range lr.Rows {
+		if row.Commit != nil {
+			delete(h.lit, row.Commit.ID)
+		}
+		for _, n := range row.Folded {
+			delete(h.lit, n.ID)
+		}
+	}
+}
+
+// apply wires h into st.
+func (h *highlighter) apply(st *graph.Style) {
+	st.Node = h.style
+	if h.useColor {
+		st.DimPrefix = ansiDim
+	}
+}
@@ -1,18 +1,216 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/lczyk/gitgum/src/graph"
+	"github.com/lczyk/gitgum/src/litescreen/ansi"
 )
 
 func (t *TreeCommand) renderNative(w io.Writer, sinceArg string, maxCount int) error {
-	r := t.repo()
+	// segments come newest-first. newest-first output can print each one as
+	// it lands; oldest-first has to hold the rendered lines (not the nodes
+	// or layout) until git is done.
+	var held [][]string
+	err := t.streamNative(sinceArg, maxCount, t.layoutOptions(nil), func(lines []string, _ [][]string) bool {
+		if len(t.columns) > 0 {
+			fitToTerminal(lines)
+		}
+		if t.Reverse {
+			for _, line := range lines {
+				fmt.Fprintln(w, line)
+			}
+			return true
+		}
+		held = append(held, lines)
+		return true
+	})
+	for i := len(held) - 1; i >= 0; i-- {
+		for _, line := range held[i] {
+			fmt.Fprintln(w, line)
+		}
+	}
+	return err
+}
+
+// streamNative reads `git log` line by line into a graph.Stream and hands
+// each finished segment to emit: its rendered lines, and alongside them
+// the hashes each fold row stands for (nil for other rows). Segments
+// arrive newest history first; lines within one are already in display
+// order (flipped for --reverse), so the caller only decides where each
+// segment goes. When emit returns false, git is stopped and streamNative
+// returns errTreeStopped without laying out the rest.
+func (t *TreeCommand) streamNative(sinceArg string, maxCount int, opts graph.LayoutOptions, emit func(lines []string, folds [][]string) bool) error {
+	useColor := colorEnabled()
+	st := t.nativeStyle(useColor)
+	hl, err := t.newHighlighter(useColor)
+	if err != nil {
+		return err
+	}
+	if hl != nil {
+		hl.apply(&st)
+	}
+	metas := map[string]*commitMeta{}
+	stopped := false
+	stream := graph.NewStream(opts, func(lr graph.LayoutResult) {
+		lines := graph.Render(lr, st)
+		if hl != nil {
+			hl.drawn(lr)
+		}
+		if len(t.columns) > 0 {
+			t.addColumns(lr, lines, metas, useColor)
+		}
+		folds := make([][]string, len(lr.Rows))
+		for i, row := range lr.Rows {
+			for _, n := range row.Folded {
+				folds[i] = append(folds[i], n.ID)
+			}
+		}
+		if t.Reverse {
+			slices.Reverse(lines)
+			slices.Reverse(folds)
+			for i, line := range lines {
+				lines[i] = swapGraphSlashes(line)
+			}
+		}
+		if !emit(lines, folds) {
+			stopped = true
+		}
+	})
+	push := func(n graph.Node, m *commitMeta) error {
+		if stopped {
+			return errTreeStopped
+		}
+		if m != nil {
+			metas[n.ID] = m
+		}
+		if hl != nil {
+			hl.see(&n)
+		}
+		stream.Push(n)
+		return nil
+	}
+	if err := t.readNative(sinceArg, maxCount, useColor, push); err != nil {
+		if errors.Is(err, errTreeStopped) {
+			return errTreeStopped
+		}
+		return err
+	}
+	stream.Flush()
+	if stopped {
+		return errTreeStopped
+	}
+	return nil
+}
+
+// errTreeStopped is what streamNative returns once its caller stopped
+// taking segments.
+var errTreeStopped = errors.New("tree render stopped")
+
+// readNative runs the native `git log` and hands each parsed node to fn,
+// newest first, along with its --columns metadata (nil without --columns).
+// Under --first-parent / --ancestry-path, parent edges that leave the view
+// are cut with graph.Prune and their elided markers follow the child
+// straight away, with no metadata.
+//
+// --shortstat puts a commit's stat on a line of its own after it, so each
+// node is held back until the next one (or the end) shows up. An error from
+// fn stops git and comes back wrapped.
+func (t *TreeCommand) readNative(sinceArg string, maxCount int, useColor bool, fn func(graph.Node, *commitMeta) error) error {
+	keep, err := t.pruneKeep()
+	if err != nil {
+		return err
+	}
+	var (
+		held     *graph.Node
+		heldMeta *commitMeta
+	)
+	release := func() error {
+		if held == nil {
+			return nil
+		}
+		n := *held
+		held = nil
+		if keep == nil {
+			return fn(n, heldMeta)
+		}
+		n, markers := graph.Prune(n, keep)
+		if err := fn(n, heldMeta); err != nil {
+			return err
+		}
+		for _, m := range markers {
+			m.Label = elidedLabel(m.Label, useColor)
+			if err := fn(m, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err = t.repo().RunLines(func(line string) error {
+		n, ok := parseNativeLine(line, useColor)
+		if !ok {
+			if heldMeta != nil {
+				parseShortstat(line, heldMeta)
+			}
+			return nil
+		}
+		if err := release(); err != nil {
+			return err
+		}
+		held, heldMeta = &n, nil
+		if len(t.columns) > 0 {
+			heldMeta = parseCommitMeta(line, n.Epoch)
+		}
+		return nil
+	}, t.nativeLogArgs(sinceArg, maxCount)...)
+	if err != nil {
+		return fmt.Errorf("git log: %w", err)
+	}
+	return release()
+}
+
+// elidedLabel labels the marker for a parent outside the view: its short
+// hash, dimmed when colour is on.
+func elidedLabel(id string, useColor bool) string {
+	label := id[:min(len(id), 7)] + " elided"
+	if useColor {
+		return ansiDim + label + ansiReset
+	}
+	return label
+}
+
+// layoutOptions builds the engine options for the terminal graph. With
+// --collapse, decorated commits (branch and tag tips) always stay visible,
+// as do the commits in expanded -- folds the user opened in --follow.
+func (t *TreeCommand) layoutOptions(expanded map[string]bool) graph.LayoutOptions {
+	opts := graph.LayoutOptions{Order: t.order(), Collapse: t.Collapse}
+	if t.Collapse > 0 {
+		opts.Keep = func(n *graph.Node) bool {
+			return expanded[n.ID] || isDecorated(n.Label)
+		}
+	}
+	return opts
+}
+
+// isDecorated reports whether a native label carries a %d ref decoration:
+// "<hash> (<refs>) <subject>", possibly with ansi colour baked in. A
+// subject that itself opens with "(" reads as decorated too; the only cost
+// is that commit never folds.
+func isDecorated(label string) bool {
+	_, rest, _ := strings.Cut(ansi.Strip(label), " ")
+	return strings.HasPrefix(rest, "(")
+}
 
+// nativeLogArgs builds the `git log` invocation the native renderer parses
+// (see parseNativeLine) over the revisions picked by revArgs.
+func (t *TreeCommand) nativeLogArgs(sinceArg string, maxCount int) []string {
 	// Build git log args: plumbing format with null-delimited segments.
 	colorFlag := "--color=never"
 	if colorEnabled() {
@@ -22,111 +220,151 @@ func (t *TreeCommand) renderNative(w io.Writer, sinceArg string, maxCount int) e
 	// committer date, and the layout engine's row ordering must match it or an
 	// open (never-merged) side branch whose author date predates the trunk tip
 	// gets sorted to the bottom, detached from its fork point.
-	gitArgs := []string{"log", "--all", "--format=%H %P%x00%h%d %s%x00%ct", "--date-order", colorFlag}
+	format := "%H %P%x00%h%d %s%x00%ct"
+	if len(t.columns) > 0 {
+		// author and signature for --columns (see parseCommitMeta). %G?
+		// runs gpg over every commit, so it's only asked for when shown.
+		format += "%x00%an%x00"
+		if slices.Contains(t.columns, "sig") {
+			format += "%G?"
+		}
+	}
+	gitArgs := []string{"log", "--format=" + format, "--date-order", colorFlag}
+	if slices.Contains(t.columns, "stat") {
+		gitArgs = append(gitArgs, "--shortstat")
+	}
+	gitArgs = append(gitArgs, t.revs...)
 	if sinceArg != "" {
 		gitArgs = append(gitArgs, "--since", sinceArg)
 	}
 	if maxCount > 0 {
 		gitArgs = append(gitArgs, fmt.Sprintf("-%d", maxCount))
 	}
+	return gitArgs
+}
 
-	stdout, _, runErr := r.Run(gitArgs...)
-	if runErr != nil {
-		return fmt.Errorf("git log: %w", runErr)
+// nativeStyle is the graph.Style for the native renderer: git's red for
+// uncoloured lines, lane palette plus any --lane-colors pins.
+func (t *TreeCommand) nativeStyle(useColor bool) graph.Style {
+	if !useColor {
+		return graph.Style{}
 	}
-	if strings.TrimSpace(stdout) == "" {
-		return nil
+	st := graph.Style{LinePrefix: ansiRed, LineSuffix: ansiReset, Palette: lanePalette}
+	if len(t.laneColors) > 0 {
+		st.LaneColor = func(key int64) string { return t.laneColors[key] }
 	}
+	return st
+}
 
-	useColor := colorEnabled()
-	nodes, err := parseNativeCommits(stdout, useColor)
-	if err != nil {
-		return fmt.Errorf("parsing git log output: %w", err)
+// parseNativeLine parses one line of the null-delimited git log output and
+// pre-formats the Label with ANSI escapes when color is on. Format:
+// "<hash> <parents>\x00<hash> <decorations> <subject>\x00<epoch>"
+// ok is false for blank or malformed lines, which are skipped.
+//
+// Branch-name hints are hashed into int64 lane ids (laneKey) so repeated
+// names share a lane, and a branch keeps its palette colour across runs.
+func parseNativeLine(line string, useColor bool) (graph.Node, bool) {
+	seg := strings.SplitN(line, "\x00", 4) // a 4th holds --columns fields
+	if len(seg) < 2 {
+		return graph.Node{}, false
 	}
-	if len(nodes) == 0 {
-		return nil
+	topo := strings.Fields(seg[0])
+	if len(topo) == 0 {
+		return graph.Node{}, false
 	}
-
-	lr := graph.Layout(nodes)
-
-	st := graph.Style{}
+	id := topo[0]
+	var parents []string
+	if len(topo) > 1 {
+		parents = topo[1:]
+	}
+	// Strip trailing whitespace -- empty %s leaves a trailing space
+	// after the hash that the old per-segment render dropped.
+	rawLabel := strings.TrimRight(seg[1], " ")
+	var epoch int64
+	if len(seg) > 2 {
+		epoch, _ = strconv.ParseInt(strings.TrimSpace(seg[2]), 10, 64)
+	}
+	var lane int64
+	if name := extractLaneName(rawLabel); name != "" {
+		lane = laneKey(name)
+	}
+	label := rawLabel
 	if useColor {
-		st = graph.Style{LinePrefix: ansiRed, LineSuffix: ansiReset}
+		label = colorLabel(rawLabel)
 	}
+	return graph.Node{
+		ID:      id,
+		Label:   label,
+		Parents: parents,
+		Epoch:   epoch,
+		Lane:    lane,
+	}, true
+}
 
-	lines := graph.Render(lr, st)
-	if t.Reverse {
-		slices.Reverse(lines)
-		for i, line := range lines {
-			lines[i] = swapGraphSlashes(line)
-		}
-	}
-	for _, line := range lines {
-		fmt.Fprintln(w, line)
+// laneKey hashes a branch name into a graph.Node.Lane value (fnv-1a). Zero
+// means "no lane" to the engine, so the one-in-2^64 zero hash is nudged.
+func laneKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	if k := int64(h.Sum64()); k != 0 {
+		return k
 	}
-	return nil
+	return 1
 }
 
-// parseNativeCommits parses null-delimited git log output and pre-formats
-// each Label with ANSI escapes when color is on. Each commit is one line:
-// "<hash> <parents>\x00<hash> <decorations> <subject>\x00<epoch>"
-//
-// Branch-name hints are interned into int64 lane ids via a per-call map
-// so repeated names share a lane.
-func parseNativeCommits(raw string, useColor bool) ([]graph.Node, error) {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
+// lanePalette follows git's default color.graph cycle: the six basic
+// colours, then their bold variants.
+var lanePalette = []string{
+	ansiRed, ansiGreen, ansiYellow, ansiBlue, ansiMagenta, ansiCyan,
+	ansiBoldRed, ansiBoldGreen, ansiBoldYellow, ansiBoldBlue, ansiBoldMagenta, ansiBoldCyan,
+}
+
+// laneColorNames are the colour names accepted by --lane-colors.
+var laneColorNames = map[string]string{
+	"red":          ansiRed,
+	"green":        ansiGreen,
+	"yellow":       ansiYellow,
+	"blue":         ansiBlue,
+	"magenta":      ansiMagenta,
+	"cyan":         ansiCyan,
+	"purple":       ansiPurple,
+	"pink":         ansiPink,
+	"bold-red":     ansiBoldRed,
+	"bold-green":   ansiBoldGreen,
+	"bold-yellow":  ansiBoldYellow,
+	"bold-blue":    ansiBoldBlue,
+	"bold-magenta": ansiBoldMagenta,
+	"bold-cyan":    ansiBoldCyan,
+	"bold-purple":  ansiBoldPurple,
+	"bold-pink":    ansiBoldPink,
+}
+
+// parseLaneColors parses a --lane-colors value ("main=green,feat/x=blue")
+// into lane-key -> ansi prefix overrides. Keys go through laneKey so they
+// line up with the Lane values parseNativeLine assigns.
+func parseLaneColors(s string) (map[int64]string, error) {
+	if strings.TrimSpace(s) == "" {
 		return nil, nil
 	}
-	lines := strings.Split(raw, "\n")
-	nodes := make([]graph.Node, 0, len(lines))
-	laneIds := map[string]int64{}
-	for _, line := range lines {
-		if line == "" {
+	out := map[int64]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
 			continue
 		}
-		seg := strings.SplitN(line, "\x00", 3)
-		if len(seg) < 2 {
-			continue
-		}
-		topo := strings.Fields(seg[0])
-		if len(topo) == 0 {
-			continue
+		name, color, ok := strings.Cut(pair, "=")
+		name, color = strings.TrimSpace(name), strings.TrimSpace(color)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("--lane-colors: %q: expected <branch>=<color>", pair)
 		}
-		id := topo[0]
-		var parents []string
-		if len(topo) > 1 {
-			parents = topo[1:]
+		code, ok := laneColorNames[color]
+		if !ok {
+			names := slices.Sorted(maps.Keys(laneColorNames))
+			return nil, fmt.Errorf("--lane-colors: %q: unknown color %q (one of: %s)", pair, color, strings.Join(names, ", "))
 		}
-		// Strip trailing whitespace -- empty %s leaves a trailing space
-		// after the hash that the old per-segment render dropped.
-		rawLabel := strings.TrimRight(seg[1], " ")
-		var epoch int64
-		if len(seg) > 2 {
-			epoch, _ = strconv.ParseInt(strings.TrimSpace(seg[2]), 10, 64)
-		}
-		var lane int64
-		if name := extractLaneName(rawLabel); name != "" {
-			lid, ok := laneIds[name]
-			if !ok {
-				lid = int64(len(laneIds) + 1)
-				laneIds[name] = lid
-			}
-			lane = lid
-		}
-		label := rawLabel
-		if useColor {
-			label = colorLabel(rawLabel)
-		}
-		nodes = append(nodes, graph.Node{
-			ID:      id,
-			Label:   label,
-			Parents: parents,
-			Epoch:   epoch,
-			Lane:    lane,
-		})
-	}
-	return nodes, nil
+		out[laneKey(name)] = code
+	}
+	return out, nil
 }
 
 // extractLaneName parses the first branch name from git's %d decoration
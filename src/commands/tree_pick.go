@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lczyk/gitgum/src/graph"
+	"github.com/lczyk/gitgum/src/litescreen/ansi"
+)
+
+// runPick lays out the graph and opens it in the picker instead of
+// printing it, then prints the full hash of each chosen commit, one per
+// line -- so `git rebase -i $(gg tree --pick)` and friends work.
+//
+// Unlike the plain render this isn't streamed: the picker wants every row
+// up front, and Row.Commit is how a picked display row maps back to its
+// commit.
+func (t *TreeCommand) runPick(sinceArg string, maxCount int) error {
+	// the picker draws on the tty even when stdout is captured by $(...),
+	// so only an explicit opt-out turns colour off here
+	useColor := os.Getenv("NO_COLOR") == ""
+
+	var nodes []graph.Node
+	hl, err := t.newHighlighter(useColor)
+	if err != nil {
+		return err
+	}
+	metas := map[string]*commitMeta{}
+	err = t.readNative(sinceArg, maxCount, useColor, func(n graph.Node, m *commitMeta) error {
+		if hl != nil {
+			hl.see(&n)
+		}
+		nodes = append(nodes, n)
+		if m != nil {
+			metas[n.ID] = m
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return errors.New("no commits to pick from (try a wider --since)")
+	}
+
+	lr := graph.Layout(nodes, graph.LayoutOptions{Order: t.order()})
+	st := t.nativeStyle(useColor)
+	if hl != nil {
+		hl.apply(&st)
+	}
+	lines := graph.Render(lr, st)
+	if len(t.columns) > 0 {
+		t.addColumns(lr, lines, metas, useColor)
+	}
+
+	// the picker lists bottom-up from the prompt, so rows go in newest
+	// first: the graph keeps its oldest-at-top shape and the cursor starts
+	// on the newest commit. Commit rows are keyed by their stripped text
+	// (what the picker's predicates see); the hash in the label keeps them
+	// unique. Everything else -- connectors, elided markers -- is context.
+	rows := make([]string, 0, len(lines))
+	hashes := make(map[string]string, len(nodes))
+	for i := len(lines) - 1; i >= 0; i-- {
+		rows = append(rows, lines[i])
+		if c := lr.Rows[i].Commit; c != nil && !c.Elided {
+			hashes[ansi.Strip(lines[i])] = c.ID
+		}
+	}
+	isContext := func(row string) bool {
+		_, ok := hashes[row]
+		return !ok
+	}
+
+	picked, err := t.sel().SelectGraph("commit", rows, isContext, true)
+	if err != nil {
+		return err
+	}
+	for _, row := range picked {
+		fmt.Fprintln(t.out(), hashes[ansi.Strip(row)])
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -13,6 +15,8 @@ import (
 	"github.com/lczyk/assert/require"
 	"github.com/lczyk/gitgum/internal/git"
 	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+	"github.com/lczyk/gitgum/src/graph"
+	"github.com/lczyk/gitgum/src/litescreen/ansi"
 )
 
 func TestParseSinceArg(t *testing.T) {
@@ -326,3 +330,500 @@ func TestTreeCommand_NoColor(t *testing.T) {
 	require.NoError(t, err)
 	assert.That(t, !strings.Contains(buf.String(), "\x1b"), "should not contain ansi escapes when NO_COLOR is set")
 }
+
+func TestParseLaneColors(t *testing.T) {
+	cases := map[string]struct {
+		in      string
+		want    map[int64]string
+		wantErr bool
+	}{
+		"empty":      {"", nil, false},
+		"single":     {"main=green", map[int64]string{laneKey("main"): ansiGreen}, false},
+		"multi":      {"main=green, feat/x=bold-blue", map[int64]string{laneKey("main"): ansiGreen, laneKey("feat/x"): ansiBoldBlue}, false},
+		"trailing":   {"main=red,", map[int64]string{laneKey("main"): ansiRed}, false},
+		"no equals":  {"main", nil, true},
+		"no name":    {"=red", nil, true},
+		"bad colour": {"main=chartreuse", nil, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseLaneColors(tc.in)
+			if tc.wantErr {
+				assert.That(t, err != nil, "expected error for %q", tc.in)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualMaps(t, got, tc.want)
+		})
+	}
+}
+
+func TestTreeCommand_LaneColors(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+	t.Setenv("GG_TREE_NATIVE", "")
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\n", "chore: Add A")
+	temp_repo.RunGit(t, dir, "checkout", "-b", "feature")
+	temp_repo.CreateCommit(t, dir, "b.txt", "b\n", "chore: Add B on feature")
+	temp_repo.RunGit(t, dir, "checkout", "main")
+	temp_repo.CreateCommit(t, dir, "c.txt", "c\n", "chore: Add C on main")
+	repo := git.Repo{Dir: dir}
+
+	var buf bytes.Buffer
+	cmd := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, Since: "", LaneColors: "feature=bold-pink"}
+	err := cmd.Execute(nil)
+	require.NoError(t, err)
+	// which glyph the feature lane gets depends on same-second commit
+	// ordering, so only pin that its colour shows up somewhere
+	assert.ContainsString(t, buf.String(), ansiBoldPink, "feature lane drawn in its pinned colour")
+
+	bad := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, LaneColors: "feature=nope"}
+	assert.That(t, bad.Execute(nil) != nil, "unknown colour should error")
+}
+
+func TestTreeCommand_StreamStopsWhenEmitDeclines(t *testing.T) {
+	// 1000 commits in a line: several segments' worth
+	dir := temp_repo.NewRepoWithBranches(t, 1000)
+	cmd := &TreeCommand{cmdIO: cmdIO{Repo: git.Repo{Dir: dir}}, revs: []string{"b01000"}}
+
+	var all int
+	err := cmd.streamNative("", 0, cmd.layoutOptions(nil), func([]string, [][]string) bool {
+		all++
+		return true
+	})
+	require.NoError(t, err)
+	require.That(t, all > 1, "want several segments, got %d", all)
+
+	calls := 0
+	err = cmd.streamNative("", 0, cmd.layoutOptions(nil), func([]string, [][]string) bool {
+		calls++
+		return false
+	})
+	assert.That(t, errors.Is(err, errTreeStopped), "got %v", err)
+	assert.Equal(t, calls, 1)
+}
+
+func TestTreeCommand_OrderFlag(t *testing.T) {
+	cases := map[string]graph.Order{
+		"":             graph.OrderEngine,
+		"engine":       graph.OrderEngine,
+		"git":          graph.OrderTopo,
+		"date":         graph.OrderDate,
+		"topo":         graph.OrderTopo,
+		"first-parent": graph.OrderFirstParent,
+	}
+	for in, want := range cases {
+		t.Run(in, func(t *testing.T) {
+			cmd := &TreeCommand{Order: in}
+			assert.Equal(t, cmd.order(), want)
+		})
+	}
+}
+
+func TestTreeCommand_Pick(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\n", "chore: Add A")
+	temp_repo.RunGit(t, dir, "checkout", "-b", "feature")
+	temp_repo.CreateCommit(t, dir, "b.txt", "b\n", "chore: Add B on feature")
+	temp_repo.RunGit(t, dir, "checkout", "main")
+	temp_repo.CreateCommit(t, dir, "c.txt", "c\n", "chore: Add C on main")
+	temp_repo.RunGit(t, dir, "merge", "--no-ff", "-m", "chore: Merge feature", "feature")
+	repo := git.Repo{Dir: dir}
+	hash := func(ref string) string {
+		out, err := repo.GetCommitHash(ref)
+		require.NoError(t, err)
+		return out
+	}
+
+	t.Run("prints the picked commits' full hashes", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		stub := &stubSelector{graphAnswers: [][]string{{"Add B on feature", "Add A"}}}
+		cmd := &TreeCommand{cmdIO: cmdIO{Out: &buf, UI: stub, Repo: repo}, Pick: true}
+		require.NoError(t, cmd.Execute(nil))
+		assert.Equal(t, buf.String(), hash("feature")+"\n"+hash("main~2")+"\n")
+	})
+
+	t.Run("rows keep the graph, newest first", func(t *testing.T) {
+		t.Parallel()
+		stub := &stubSelector{graphAnswers: [][]string{{"Merge feature"}}}
+		cmd := &TreeCommand{cmdIO: cmdIO{Out: io.Discard, UI: stub, Repo: repo}, Pick: true}
+		require.NoError(t, cmd.Execute(nil))
+
+		require.Equal(t, len(stub.graphCalls), 1)
+		rows := stub.graphCalls[0].Options
+		assert.ContainsString(t, ansi.Strip(rows[0]), "chore: Merge feature")
+		assert.ContainsString(t, ansi.Strip(rows[len(rows)-1]), "chore: init")
+		// the merge's fork/join connectors come through as their own rows
+		connectors := 0
+		for _, row := range rows {
+			if !strings.Contains(ansi.Strip(row), "chore:") {
+				connectors++
+			}
+		}
+		assert.That(t, connectors > 0, "want connector rows between commits, got ", rows)
+	})
+
+	t.Run("connector rows can't be picked", func(t *testing.T) {
+		t.Parallel()
+		stub := &stubSelector{graphAnswers: [][]string{{"|/"}}}
+		cmd := &TreeCommand{cmdIO: cmdIO{Out: io.Discard, UI: stub, Repo: repo}, Pick: true}
+		err := cmd.Execute(nil)
+		require.That(t, err != nil, "picking a connector row should fail")
+		assert.ContainsString(t, err.Error(), "context row")
+	})
+
+	t.Run("rejects --follow", func(t *testing.T) {
+		t.Parallel()
+		follow := 2.0
+		cmd := &TreeCommand{cmdIO: cmdIO{Out: io.Discard, Repo: repo}, Pick: true, Follow: &follow}
+		err := cmd.Execute(nil)
+		assert.That(t, err != nil, "--pick with --follow should fail")
+	})
+}
+
+func TestTreeCommand_Format(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\n", "chore: Add A")
+	temp_repo.RunGit(t, dir, "checkout", "-b", "feature")
+	temp_repo.CreateCommit(t, dir, "b.txt", "b\n", "chore: Add B & more")
+	temp_repo.RunGit(t, dir, "checkout", "main")
+	temp_repo.RunGit(t, dir, "merge", "--no-ff", "-m", "chore: Merge feature", "feature")
+	repo := git.Repo{Dir: dir}
+	tip, err := repo.GetCommitHash("main")
+	require.NoError(t, err)
+
+	cases := map[string][]string{
+		"dot":  {"digraph history {", `"` + tip + `" -> "`, "chore: Add B & more"},
+		"json": {`"columns": 2`, `"id": "` + tip + `"`, `"glyphs": "* "`},
+		"svg":  {"<svg ", "<circle ", "chore: Add B &amp; more", `stroke="#`},
+	}
+	for format, wants := range cases {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			cmd := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, Format: format}
+			require.NoError(t, cmd.Execute(nil))
+			out := buf.String()
+			for _, want := range wants {
+				assert.ContainsString(t, out, want)
+			}
+			assert.That(t, !strings.Contains(out, "\x1b"), "exports carry no ansi escapes")
+		})
+	}
+
+	t.Run("rejects --pick", func(t *testing.T) {
+		t.Parallel()
+		cmd := &TreeCommand{cmdIO: cmdIO{Out: io.Discard, Repo: repo}, Format: "svg", Pick: true}
+		assert.That(t, cmd.Execute(nil) != nil, "--format=svg with --pick should fail")
+	})
+}
+
+func TestTreeCommand_Collapse(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		temp_repo.CreateCommit(t, dir, name+".txt", name+"\n", "chore: Add "+name)
+	}
+	temp_repo.RunGit(t, dir, "tag", "v1")
+	for _, name := range []string{"e", "f", "g", "h"} {
+		temp_repo.CreateCommit(t, dir, name+".txt", name+"\n", "chore: Add "+name)
+	}
+	repo := git.Repo{Dir: dir}
+
+	var buf bytes.Buffer
+	cmd := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, Collapse: 3}
+	require.NoError(t, cmd.Execute(nil))
+	out := buf.String()
+
+	// init has no parent and the tagged / branch tips are decorated, so
+	// they stay; the straight runs between them fold.
+	assert.ContainsString(t, out, "chore: init")
+	assert.ContainsString(t, out, "⋮ 3 commits") // a..c
+	assert.ContainsString(t, out, "chore: Add d")
+	assert.ContainsString(t, out, "⋮ 3 commits") // e..g
+	assert.ContainsString(t, out, "chore: Add h")
+	assert.That(t, !strings.Contains(out, "chore: Add b"), "b should be folded away, got\n", out)
+}
+
+func TestIsDecorated(t *testing.T) {
+	cases := map[string]bool{
+		"abc1234 (HEAD -> main) subject":                  true,
+		"abc1234 (tag: v1) subject":                       true,
+		"abc1234 subject":                                 false,
+		"abc1234":                                         false,
+		colorLabel("abc1234 (origin/main) subject"):       true,
+		colorLabel("abc1234 plain subject with (parens)"): false,
+	}
+	for label, want := range cases {
+		assert.Equal(t, isDecorated(label), want, "label %q", label)
+	}
+}
+
+func TestExpandVisibleFolds(t *testing.T) {
+	folds := [][]string{{"a1", "a2"}, nil, {"b1"}, nil, {"c1", "c2"}}
+	expanded := map[string]bool{}
+
+	// window [1, 4) holds only the b fold
+	assert.That(t, expandVisibleFolds(folds, 1, 3, expanded), "b fold is on screen")
+	assert.EqualMaps(t, expanded, map[string]bool{"b1": true})
+
+	// a window with no folds in it opens nothing
+	assert.That(t, !expandVisibleFolds(folds, 3, 1, expanded), "no fold on screen")
+
+	// windows hanging off either end are clamped
+	assert.That(t, expandVisibleFolds(folds, -2, 100, expanded), "everything on screen")
+	assert.Equal(t, len(expanded), 5)
+}
+
+func TestTreeCommand_RevArgs(t *testing.T) {
+	cases := map[string]struct {
+		cmd     TreeCommand
+		want    []string
+		wantErr bool
+	}{
+		"default": {TreeCommand{}, []string{"--all"}, false},
+		"exclude": {
+			TreeCommand{Exclude: "bot/*"},
+			[]string{"--exclude=refs/heads/bot/*", "--exclude=refs/remotes/bot/*", "--exclude=refs/remotes/*/bot/*", "--all"},
+			false,
+		},
+		"branch globs": {
+			TreeCommand{Branches: "feat/*, fix/*", Exclude: "feat/wip*"},
+			[]string{"--exclude=feat/wip*", "--branches=feat/*", "--exclude=feat/wip*", "--branches=fix/*"},
+			false,
+		},
+		"remotes": {
+			TreeCommand{Remotes: true, Exclude: "bot/*", FirstParent: true},
+			[]string{"--first-parent", "--exclude=bot/*", "--exclude=*/bot/*", "--remotes"},
+			false,
+		},
+		"ancestry":         {TreeCommand{AncestryPath: "v1..main"}, []string{"--ancestry-path", "v1..main"}, false},
+		"ancestry no dots": {TreeCommand{AncestryPath: "v1"}, nil, true},
+		"ancestry three":   {TreeCommand{AncestryPath: "v1...main"}, nil, true},
+		"ancestry mixed":   {TreeCommand{AncestryPath: "v1..main", Remotes: true}, nil, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.cmd.revArgs()
+			if tc.wantErr {
+				assert.That(t, err != nil, "expected an error, got", got)
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualArrays(t, got, tc.want)
+		})
+	}
+}
+
+func TestTreeCommand_RefFilters(t *testing.T) {
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "m1.txt", "m1\n", "chore: m1")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "feat/a")
+	temp_repo.CreateCommit(t, dir, "f1.txt", "f1\n", "feat: f1")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-")
+	temp_repo.CreateCommit(t, dir, "m2.txt", "m2\n", "chore: m2")
+	temp_repo.RunGit(t, dir, "merge", "-q", "--no-ff", "feat/a", "-m", "merge feat/a")
+	base := strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "HEAD~2")) // m1
+	temp_repo.CreateCommit(t, dir, "m3.txt", "m3\n", "chore: m3")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "dependabot/x", "HEAD~2")
+	temp_repo.CreateCommit(t, dir, "bot.txt", "bot\n", "chore: bump deps")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-")
+	repo := git.Repo{Dir: dir}
+
+	render := func(t *testing.T, cmd TreeCommand) string {
+		t.Helper()
+		var buf bytes.Buffer
+		cmd.cmdIO = cmdIO{Out: &buf, Repo: repo}
+		cmd.Since = ""
+		require.NoError(t, cmd.Execute(nil))
+		return buf.String()
+	}
+
+	t.Run("exclude", func(t *testing.T) {
+		out := render(t, TreeCommand{Exclude: "dependabot/*"})
+		assert.ContainsString(t, out, "feat: f1")
+		assert.That(t, !strings.Contains(out, "bump deps"), "dependabot branch should be left out, got\n", out)
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		out := render(t, TreeCommand{Branches: "dependabot/*"})
+		assert.ContainsString(t, out, "bump deps")
+		assert.That(t, !strings.Contains(out, "chore: m3"), "main's tip should be left out, got\n", out)
+	})
+
+	t.Run("first parent", func(t *testing.T) {
+		out := render(t, TreeCommand{Branches: "main", FirstParent: true})
+		assert.ContainsString(t, out, "merge feat/a")
+		assert.That(t, !strings.Contains(out, "feat: f1"), "side branch should be elided, got\n", out)
+		// the merge's second parent is still there, as a marker
+		assert.ContainsString(t, out, "~ ")
+		assert.ContainsString(t, out, " elided")
+	})
+
+	t.Run("ancestry path", func(t *testing.T) {
+		out := render(t, TreeCommand{AncestryPath: base + "..main"})
+		assert.ContainsString(t, out, "merge feat/a")
+		assert.ContainsString(t, out, "feat: f1")
+		assert.ContainsString(t, out, "chore: m2")
+		// m1 is the range's base: both lines off it end on an elided marker
+		assert.That(t, !strings.Contains(out, "chore: m1"), "base should be elided, got\n", out)
+		assert.That(t, !strings.Contains(out, "bump deps"), "dependabot isn't on the path, got\n", out)
+		assert.ContainsString(t, out, base[:7]+" elided")
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, Branches: "nope"}
+		err := cmd.Execute(nil)
+		assert.That(t, err != nil && strings.Contains(err.Error(), `"nope"`), "expected unknown branch error, got", err)
+	})
+}
+
+func TestTreeCommand_Columns(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\nb\nc\n", "chore: Add A")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "feature")
+	temp_repo.CreateCommit(t, dir, "f.txt", "f\n", "feat: F")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-")
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\n", "chore: Trim A")
+	temp_repo.RunGit(t, dir, "merge", "-q", "--no-ff", "feature", "-m", "merge feature")
+	repo := git.Repo{Dir: dir}
+
+	var buf bytes.Buffer
+	cmd := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, Since: "", Columns: "author,age,stat"}
+	require.NoError(t, cmd.Execute(nil))
+
+	// every row has the same prefix width, commit or not, so the graph
+	// column lines up
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	prefix := len("TU  ") + len("now ") + len("+1234 -5678 ")
+	rows := map[string]string{}
+	for _, line := range lines {
+		require.That(t, len(line) > prefix, "short line", line)
+		_, label, _ := strings.Cut(line[prefix:], " ")
+		rows[strings.TrimLeft(label, " |/\\*")] = line[:prefix]
+	}
+	find := func(subject string) string {
+		for label, cells := range rows {
+			if strings.HasSuffix(label, subject) {
+				return cells
+			}
+		}
+		t.Fatalf("no row for %q in\n%s", subject, buf.String())
+		return ""
+	}
+	assert.That(t, strings.HasPrefix(find("chore: Trim A"), "TU   now"), "author and age cells, got %q", find("chore: Trim A"))
+	assert.ContainsString(t, find("chore: Trim A"), "+0 -2")
+	assert.ContainsString(t, find("chore: Add A"), "+3 -0")
+	// merges have no diffstat of their own
+	assert.That(t, !strings.Contains(find("merge feature"), "+"), "merge stat should be blank, got %q", find("merge feature"))
+
+	bad := &TreeCommand{cmdIO: cmdIO{Out: io.Discard, Repo: repo}, Columns: "author,shoesize"}
+	assert.That(t, bad.Execute(nil) != nil, "unknown column should fail")
+}
+
+func TestFormatAge(t *testing.T) {
+	day := 24 * time.Hour
+	cases := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "now"},
+		{45 * time.Minute, "45m"},
+		{3 * time.Hour, "3h"},
+		{2 * day, "2d"},
+		{20 * day, "2w"},
+		{90 * day, "3mo"},
+		{800 * day, "2y"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, formatAge(tc.d), tc.want)
+		assert.That(t, len(tc.want) <= treeColumnWidths["age"], "%q overflows the age column", tc.want)
+	}
+}
+
+func TestInitials(t *testing.T) {
+	cases := map[string]string{
+		"Jane Q. Public": "JQP",
+		"jane doe":       "JD",
+		"lczyk":          "LC",
+		"a":              "A",
+		"Ana-Maria Ruiz": "AMR",
+		"":               "",
+	}
+	for in, want := range cases {
+		assert.Equal(t, initials(in), want, "initials(%q)", in)
+	}
+}
+
+func TestParseShortstat(t *testing.T) {
+	var m commitMeta
+	assert.That(t, parseShortstat(" 2 files changed, 5 insertions(+), 1 deletion(-)", &m), "should parse")
+	assert.Equal(t, m.added, 5)
+	assert.Equal(t, m.deleted, 1)
+
+	m = commitMeta{}
+	assert.That(t, parseShortstat(" 1 file changed, 12 deletions(-)", &m), "should parse")
+	assert.Equal(t, m.added, 0)
+	assert.Equal(t, m.deleted, 12)
+
+	assert.That(t, !parseShortstat("", &m), "blank line isn't a stat")
+	assert.Equal(t, compactCount(12345), "12k")
+}
+
+func TestTreeCommand_Highlight(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("FORCE_COLOR", "")
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "a.txt", "a\n", "chore: fork point")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "feature")
+	temp_repo.CreateCommit(t, dir, "f.txt", "f\n", "feat: on feature")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "main")
+	temp_repo.CreateCommit(t, dir, "b.txt", "b\n", "chore: on main")
+	repo := git.Repo{Dir: dir}
+
+	render := func(t *testing.T, ref string) map[string]string {
+		t.Helper()
+		var buf bytes.Buffer
+		cmd := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, Since: "", Highlight: ref}
+		require.NoError(t, cmd.Execute(nil))
+		// subject -> the commit's marker. Everything ahead of the subject
+		// is graph and a hex hash, so the marker is the only one of these.
+		marks := map[string]string{}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			for _, subject := range []string{"chore: init", "chore: fork point", "feat: on feature", "chore: on main"} {
+				head, ok := strings.CutSuffix(line, subject)
+				if !ok {
+					continue
+				}
+				for _, mark := range []string{"*", "o", mergeBaseMark} {
+					if strings.Contains(head, mark) {
+						marks[subject] = mark
+					}
+				}
+			}
+		}
+		return marks
+	}
+
+	marks := render(t, "feature")
+	assert.Equal(t, marks["feat: on feature"], "*")
+	assert.Equal(t, marks["chore: on main"], "o")
+	assert.Equal(t, marks["chore: fork point"], mergeBaseMark)
+	assert.Equal(t, marks["chore: init"], "*")
+
+	// the default branch itself: nothing dimmed, no merge base to mark
+	marks = render(t, "HEAD")
+	assert.Equal(t, marks["chore: on main"], "*")
+	assert.Equal(t, marks["chore: fork point"], "*")
+	assert.Equal(t, marks["feat: on feature"], "o")
+
+	var buf bytes.Buffer
+	bad := &TreeCommand{cmdIO: cmdIO{Out: &buf, Repo: repo}, Highlight: "no-such-ref"}
+	assert.That(t, bad.Execute(nil) != nil, "unknown ref should fail")
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/internal/ui"
+)
+
+// undoListMax caps how far back the undo picker reaches.
+const undoListMax = 30
+
+type UndoCommand struct {
+	cmdIO
+}
+
+func (u *UndoCommand) Execute(args []string) error {
+	r := u.repo()
+	if err := r.CheckInRepo(); err != nil {
+		return err
+	}
+	entries, err := r.Journal()
+	if err != nil {
+		return err
+	}
+	candidates := undoable(entries)
+	if len(candidates) == 0 {
+		fmt.Fprintln(u.out(), "Nothing to undo.")
+		return nil
+	}
+
+	labels := make([]string, len(candidates))
+	byLabel := make(map[string]git.JournalEntry, len(candidates))
+	for i, e := range candidates {
+		labels[i] = undoLabel(e)
+		byLabel[labels[i]] = e
+	}
+	picked, err := u.sel().Select("Select an operation to undo", labels)
+	if err != nil {
+		if errors.Is(err, ui.ErrCancelled) {
+			fmt.Fprintln(u.out(), "Aborting undo.")
+			return nil
+		}
+		return err
+	}
+	e := byLabel[picked]
+
+	if err := u.check(e); err != nil {
+		return err
+	}
+	fmt.Fprintf(u.out(), "Undoing %q:\n", e.Summary)
+	for _, step := range undoPlan(e) {
+		fmt.Fprintf(u.out(), "  %s\n", step)
+	}
+	for _, p := range e.Removed {
+		fmt.Fprintf(u.out(), "  (not restorable, was untracked: %s)\n", p)
+	}
+	confirmed, err := u.sel().Confirm("Proceed?", true)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Fprintln(u.out(), "Aborting undo.")
+		return nil
+	}
+	if err := u.undo(e); err != nil {
+		return err
+	}
+	fmt.Fprintf(u.out(), "Undid %q.\n", e.Summary)
+	return nil
+}
+
+// undoable returns the entries gg undo offers, newest first: everything
+// but undos themselves and what they already reversed.
+func undoable(entries []git.JournalEntry) []git.JournalEntry {
+	undone := map[string]bool{}
+	for _, e := range entries {
+		if e.Undoes != "" {
+			undone[e.Undoes] = true
+		}
+	}
+	var out []git.JournalEntry
+	for _, e := range slices.Backward(entries) {
+		if e.Op == "undo" || undone[e.ID] {
+			continue
+		}
+		out = append(out, e)
+		if len(out) == undoListMax {
+			break
+		}
+	}
+	return out
+}
+
+func undoLabel(e git.JournalEntry) string {
+	return fmt.Sprintf("%s  %-7s  %s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Op, e.Summary)
+}
+
+// check refuses an undo whose refs have moved since the operation, or
+// whose saved state is gone.
+func (u *UndoCommand) check(e git.JournalEntry) error {
+	r := u.repo()
+	if len(e.Refs) == 0 && len(e.Pushed) == 0 && e.Stash == "" && e.Discarded == "" {
+		return fmt.Errorf("can't undo %q: untracked files were never in git, so there's nothing to restore", e.Summary)
+	}
+	cur, err := r.RefOids(refNames(e.Refs)...)
+	if err != nil {
+		return err
+	}
+	head, _ := currentHeadRef(r)
+	for _, ref := range e.Refs {
+		if cur[ref.Name] != ref.New {
+			return fmt.Errorf("can't undo %q: %s has moved since (now %s, was %s)",
+				e.Summary, shortRefName(ref.Name), describeOid(cur[ref.Name]), describeOid(ref.New))
+		}
+		if ref.Name == head && ref.Old == "" {
+			return fmt.Errorf("can't undo %q: it created %s, which is checked out", e.Summary, shortRefName(ref.Name))
+		}
+	}
+	if e.Stash != "" {
+		if _, _, err := r.Run("cat-file", "-e", e.Stash+"^{commit}"); err != nil {
+			return fmt.Errorf("can't undo %q: the saved changes (%s) have been garbage collected", e.Summary, shortOid(e.Stash))
+		}
+	}
+	return nil
+}
+
+// undoPlan describes, in order, what undo will do.
+func undoPlan(e git.JournalEntry) []string {
+	var plan []string
+	for _, p := range e.Pushed {
+		if p.Old == "" {
+			plan = append(plan, fmt.Sprintf("delete %s on '%s'", shortRefName(p.Name), p.Remote))
+		} else {
+			plan = append(plan, fmt.Sprintf("push %s back to %s on '%s'", shortOid(p.Old), shortRefName(p.Name), p.Remote))
+		}
+	}
+	for _, ref := range e.Refs {
+		switch {
+		case ref.Old == "":
+			plan = append(plan, "delete "+shortRefName(ref.Name))
+		case ref.New == "":
+			plan = append(plan, fmt.Sprintf("restore %s at %s", shortRefName(ref.Name), shortOid(ref.Old)))
+		default:
+			plan = append(plan, fmt.Sprintf("move %s back to %s", shortRefName(ref.Name), shortOid(ref.Old)))
+		}
+	}
+	if e.Upstream != "" && slices.ContainsFunc(e.Refs, func(ref git.RefUpdate) bool { return ref.New == "" }) {
+		plan = append(plan, "track "+e.Upstream)
+	}
+	if e.Stash != "" || e.Discarded != "" {
+		plan = append(plan, "re-apply the discarded changes")
+	}
+	return plan
+}
+
+func (u *UndoCommand) undo(e git.JournalEntry) error {
+	r := u.repo()
+	j := beginJournal(r, "undo", "undo "+e.Summary, refNames(e.Refs)...)
+	j.entry.Undoes = e.ID
+	defer j.record(u.err())
+
+	for _, p := range e.Pushed {
+		// the lease makes the push fail if the remote ref isn't where the
+		// operation left it -- e.g. someone re-created a deleted branch
+		src := p.Old + ":" + p.Name
+		if p.Old == "" {
+			src = ":" + p.Name
+		}
+		if _, stderr, err := r.RunWriteStreamCtx(u.ctx(), "push", "--force-with-lease="+p.Name+":"+p.New, p.Remote, src); err != nil {
+			return explainGitError(fmt.Errorf("restoring %s on '%s': %w: %s", shortRefName(p.Name), p.Remote, err, strings.TrimSpace(stderr)))
+		}
+		j.entry.Pushed = append(j.entry.Pushed, git.RemoteUpdate{Remote: p.Remote, Name: p.Name, Old: p.New, New: p.Old})
+	}
+
+	head, _ := currentHeadRef(r)
+	for _, ref := range e.Refs {
+		var args []string
+		switch {
+		case ref.Name == head:
+			// moves the checked-out branch and its files, keeping any local
+			// changes that don't touch what's being reset
+			args = []string{"reset", "--keep", ref.Old}
+		case ref.Old == "":
+			args = []string{"update-ref", "-d", ref.Name, ref.New}
+		default:
+			// old value "" makes update-ref refuse if the ref exists
+			args = []string{"update-ref", ref.Name, ref.Old, ref.New}
+		}
+		if _, stderr, err := r.RunWrite(args...); err != nil {
+			return explainGitError(fmt.Errorf("restoring %s: %w: %s", shortRefName(ref.Name), err, strings.TrimSpace(stderr)))
+		}
+	}
+
+	if e.Upstream != "" {
+		for _, ref := range e.Refs {
+			branch, ok := strings.CutPrefix(ref.Name, "refs/heads/")
+			if !ok || ref.New != "" {
+				continue
+			}
+			if _, stderr, err := r.RunWrite("branch", "--set-upstream-to="+e.Upstream, branch); err != nil {
+				fmt.Fprintf(u.err(), "warning: couldn't set '%s' to track '%s': %s\n", branch, e.Upstream, strings.TrimSpace(stderr))
+			}
+		}
+	}
+
+	if e.Stash != "" {
+		if _, stderr, err := r.RunWrite("stash", "apply", "--index", e.Stash); err != nil {
+			return explainGitError(fmt.Errorf("re-applying discarded changes: %w: %s\nthey're kept in commit %s: git stash apply %s",
+				err, strings.TrimSpace(stderr), e.Stash, e.Stash))
+		}
+	}
+	if e.Discarded != "" {
+		if err := r.ApplyPatch(e.Discarded, false, false); err != nil {
+			return explainGitError(fmt.Errorf("re-applying discarded changes: %w", err))
+		}
+	}
+	return nil
+}
+
+func refNames(refs []git.RefUpdate) []string {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref.Name
+	}
+	return names
+}
+
+// currentHeadRef is the full name of the checked-out branch, "" when
+// detached.
+func currentHeadRef(r git.Backend) (string, error) {
+	stdout, _, err := r.Run("symbolic-ref", "-q", "HEAD")
+	return stdout, err
+}
+
+func shortRefName(name string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/remotes/"} {
+		if s, ok := strings.CutPrefix(name, prefix); ok {
+			return s
+		}
+	}
+	return name
+}
+
+func shortOid(oid string) string {
+	if len(oid) > 12 {
+		return oid[:12]
+	}
+	return oid
+}
+
+func describeOid(oid string) string {
+	if oid == "" {
+		return "gone"
+	}
+	return shortOid(oid)
+}
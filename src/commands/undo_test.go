@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/internal/git"
+	"github.com/lczyk/gitgum/internal/testutil/temp_repo"
+)
+
+// undoLatest runs gg undo picking the newest journal entry.
+func undoLatest(t *testing.T, dir string) (string, error) {
+	t.Helper()
+	r := git.Repo{Dir: dir}
+	entries, err := r.Journal()
+	require.NoError(t, err)
+	cands := undoable(entries)
+	require.That(t, len(cands) > 0, "nothing in the journal to undo")
+
+	var buf strings.Builder
+	stub := &stubSelector{selectAnswers: []string{undoLabel(cands[0])}, confirmAnswers: []bool{true}}
+	cmd := &UndoCommand{cmdIO: cmdIO{Out: &buf, Err: &buf, UI: stub, Repo: r}}
+	err = cmd.Execute(nil)
+	return buf.String(), err
+}
+
+func TestUndoCommand_NothingToUndo(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+
+	var buf strings.Builder
+	cmd := &UndoCommand{cmdIO: cmdIO{Out: &buf, UI: &stubSelector{}, Repo: git.Repo{Dir: dir}}}
+	require.NoError(t, cmd.Execute(nil))
+	assert.ContainsString(t, buf.String(), "Nothing to undo.")
+}
+
+func TestUndoCommand_RestoresDeletedBranch(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.RunGit(t, dir, "checkout", "-q", "-b", "feature")
+	temp_repo.CreateCommit(t, dir, "feature.txt", "feature\n", "feat: feature")
+	temp_repo.RunGit(t, dir, "checkout", "-q", "main")
+	tip := strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "feature"))
+
+	stub := &stubSelector{selectAnswers: []string{"feature"}, confirmAnswers: []bool{true}}
+	del := &DeleteCommand{cmdIO: cmdIO{Out: &strings.Builder{}, UI: stub, Repo: git.Repo{Dir: dir}}}
+	require.NoError(t, del.Execute(nil))
+	assert.Equal(t, temp_repo.RunGit(t, dir, "branch", "--list", "feature"), "")
+
+	out, err := undoLatest(t, dir)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "restore feature at "+tip[:12])
+	assert.Equal(t, strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "feature")), tip)
+
+	// the undo itself isn't offered again, and neither is what it undid
+	var buf strings.Builder
+	cmd := &UndoCommand{cmdIO: cmdIO{Out: &buf, UI: &stubSelector{}, Repo: git.Repo{Dir: dir}}}
+	require.NoError(t, cmd.Execute(nil))
+	assert.ContainsString(t, buf.String(), "Nothing to undo.")
+}
+
+func TestUndoCommand_RestoresCleanedChanges(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	temp_repo.CreateCommit(t, dir, "staged.txt", "one\n", "feat: staged")
+	temp_repo.WriteFile(t, dir, "README.md", "modified\n")
+	temp_repo.WriteFile(t, dir, "staged.txt", "two\n")
+	temp_repo.RunGit(t, dir, "add", "staged.txt")
+	temp_repo.WriteFile(t, dir, "untracked.txt", "untracked\n")
+
+	clean := &CleanCommand{Yes: true, cmdIO: cmdIO{Out: &strings.Builder{}, Repo: git.Repo{Dir: dir}}}
+	require.NoError(t, clean.Execute(nil))
+	fileContent(t, dir, "README.md", "# test repo\n")
+
+	out, err := undoLatest(t, dir)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "re-apply the discarded changes")
+	assert.ContainsString(t, out, "not restorable, was untracked: untracked.txt")
+	fileContent(t, dir, "README.md", "modified\n")
+	// staged changes come back staged
+	assert.Equal(t, temp_repo.RunGit(t, dir, "diff", "--cached", "--name-only"), "staged.txt\n")
+	fileNotExists(t, dir, "untracked.txt")
+}
+
+// a release-shaped entry: a commit on the checked-out branch plus a tag.
+func TestUndoCommand_DropsCommitAndTag(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	r := git.Repo{Dir: dir}
+	before := strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "HEAD"))
+
+	j := beginJournal(r, "release", "release v0.1.0", "refs/heads/main", "refs/tags/v0.1.0")
+	temp_repo.CreateCommit(t, dir, "VERSION", "0.1.0\n", "chore: release v0.1.0")
+	temp_repo.RunGit(t, dir, "tag", "-a", "v0.1.0", "-m", "v0.1.0")
+	j.record(&strings.Builder{})
+
+	out, err := undoLatest(t, dir)
+	require.NoError(t, err)
+	assert.ContainsString(t, out, "delete v0.1.0")
+	assert.ContainsString(t, out, "move main back to "+before[:12])
+	assert.Equal(t, strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "HEAD")), before)
+	assert.Equal(t, temp_repo.RunGit(t, dir, "tag", "--list"), "")
+	fileNotExists(t, dir, "VERSION")
+}
+
+func TestUndoCommand_RefusesWhenRefMoved(t *testing.T) {
+	t.Parallel()
+	dir := temp_repo.NewRepo(t)
+	r := git.Repo{Dir: dir}
+
+	j := beginJournal(r, "release", "release v0.1.0", "refs/heads/main")
+	temp_repo.CreateCommit(t, dir, "VERSION", "0.1.0\n", "chore: release v0.1.0")
+	j.record(&strings.Builder{})
+	temp_repo.CreateCommit(t, dir, "later.txt", "later\n", "feat: later")
+	head := strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "HEAD"))
+
+	_, err := undoLatest(t, dir)
+	assert.Error(t, err, assert.AnyError)
+	assert.ContainsString(t, err.Error(), "main has moved since")
+	assert.Equal(t, strings.TrimSpace(temp_repo.RunGit(t, dir, "rev-parse", "HEAD")), head)
+}
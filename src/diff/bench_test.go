@@ -0,0 +1,27 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/gitgum/src/diff"
+)
+
+// BenchmarkRefine is a typical changed line, one identifier renamed.
+func BenchmarkRefine(b *testing.B) {
+	before := "\tif err := r.runWrite(ctx, \"worktree\", \"add\", \"--quiet\", path, ref); err != nil {"
+	after := strings.Replace(before, "runWrite", "runWriteStreaming", 1)
+	for b.Loop() {
+		diff.Refine(before, after, diff.Words)
+	}
+}
+
+// BenchmarkRefine_Unrelated is the worst case the limits allow: long
+// lines with nothing in common, which Refine gives up on.
+func BenchmarkRefine_Unrelated(b *testing.B) {
+	before := strings.Repeat("alpha beta ", 40)
+	after := strings.Repeat("gamma delta ", 40)
+	for b.Loop() {
+		diff.Refine(before, after, diff.Words)
+	}
+}
@@ -0,0 +1,427 @@
+// Package diff parses git's unified diff output (`git diff -p`) into a
+// typed model: one File per `diff --git` header, with its extended header
+// (renames, copies, mode changes, binary markers) and its hunks. It knows
+// the patch format but nothing about running git or drawing the result.
+//
+// Typical usage:
+//
+//	files, err := diff.Parse(patch)
+//	for _, f := range files {
+//		added, deleted := f.Stat()
+//		...
+//	}
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Status is what a patch does to a file, using git's --name-status letters.
+type Status byte
+
+const (
+	Modified Status = 'M'
+	Added    Status = 'A'
+	Deleted  Status = 'D'
+	Renamed  Status = 'R'
+	Copied   Status = 'C'
+)
+
+// File is one file's section of a patch.
+type File struct {
+	// OldPath and NewPath are the paths either side, without the a/ and
+	// b/ prefixes. An added file has OldPath == NewPath, as does a
+	// deleted one; only renames and copies differ.
+	OldPath, NewPath string
+	Status           Status
+	// OldMode and NewMode are octal modes such as "100644". They differ
+	// only on a mode change; either is "" when the patch doesn't say.
+	OldMode, NewMode string
+	Similarity       int  // percentage, for renames and copies
+	Binary           bool // "Binary files ... differ"; no hunks
+	Hunks            []Hunk
+}
+
+// Path is the file's path after the patch, or before it for a deletion.
+func (f File) Path() string {
+	if f.Status == Deleted {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// ModeChanged reports whether the patch changes the file's mode.
+func (f File) ModeChanged() bool {
+	return f.OldMode != "" && f.NewMode != "" && f.OldMode != f.NewMode
+}
+
+// IsSubmodule reports whether either side is a gitlink.
+func (f File) IsSubmodule() bool {
+	return f.OldMode == "160000" || f.NewMode == "160000"
+}
+
+// Stat counts the lines the patch adds and deletes, as `--numstat` would.
+func (f File) Stat() (added, deleted int) {
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case Add:
+				added++
+			case Del:
+				deleted++
+			}
+		}
+	}
+	return added, deleted
+}
+
+// Hunk is one `@@` block.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	// Section is the function context git prints after the closing @@,
+	// from the file's diff driver or its default funcname rule.
+	Section string
+	Lines   []Line
+}
+
+// Header formats the hunk's range line without the section, as git does:
+// "@@ -1,3 +1,4 @@", with a count of 1 left implicit.
+func (h Hunk) Header() string {
+	return "@@ -" + hunkRange(h.OldStart, h.OldLines) + " +" + hunkRange(h.NewStart, h.NewLines) + " @@"
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return strconv.Itoa(start)
+	}
+	return strconv.Itoa(start) + "," + strconv.Itoa(lines)
+}
+
+// LineKind is a hunk line's prefix character.
+type LineKind byte
+
+const (
+	Context LineKind = ' '
+	Add     LineKind = '+'
+	Del     LineKind = '-'
+)
+
+// Line is one line of a hunk, without its prefix or newline.
+type Line struct {
+	Kind LineKind
+	Text string
+	// OldNum and NewNum are 1-based line numbers in the old and new file;
+	// 0 on the side the line isn't on.
+	OldNum, NewNum int
+	// NoNewline marks the last line of a file that doesn't end in a
+	// newline ("\ No newline at end of file" followed it).
+	NoNewline bool
+}
+
+// Parse reads a patch as `git diff -p` prints it, with the default a/ and
+// b/ prefixes. Anything before the first `diff --git` line is skipped, as
+// are lines git adds that the model has no room for (index, etc.).
+// Combined diffs (`diff --cc`, from merges) aren't supported.
+func Parse(patch string) ([]File, error) {
+	p := parser{lines: strings.Split(patch, "\n")}
+	// a trailing newline leaves an empty last element that isn't a line
+	if n := len(p.lines); n > 0 && p.lines[n-1] == "" {
+		p.lines = p.lines[:n-1]
+	}
+	var files []File
+	for p.i < len(p.lines) {
+		line := p.lines[p.i]
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			f, err := p.file()
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		case strings.HasPrefix(line, "diff --cc "), strings.HasPrefix(line, "diff --combined "):
+			return nil, fmt.Errorf("line %d: combined diffs aren't supported", p.i+1)
+		default:
+			p.i++
+		}
+	}
+	return files, nil
+}
+
+type parser struct {
+	lines []string
+	i     int
+}
+
+// file parses one file's section, starting at its `diff --git` line.
+func (p *parser) file() (File, error) {
+	start := p.i
+	oldPath, newPath, err := splitGitHeader(strings.TrimPrefix(p.lines[p.i], "diff --git "))
+	if err != nil {
+		return File{}, fmt.Errorf("line %d: %w", start+1, err)
+	}
+	f := File{OldPath: oldPath, NewPath: newPath, Status: Modified}
+	p.i++
+
+	// extended header, up to the first hunk or the next file
+	for ; p.i < len(p.lines); p.i++ {
+		line := p.lines[p.i]
+		key, value := headerField(line)
+		switch key {
+		case "old mode":
+			f.OldMode = value
+		case "new mode":
+			f.NewMode = value
+		case "deleted file mode":
+			f.Status, f.OldMode = Deleted, value
+		case "new file mode":
+			f.Status, f.NewMode = Added, value
+		case "similarity index":
+			f.Similarity, _ = strconv.Atoi(strings.TrimSuffix(value, "%"))
+		case "dissimilarity index":
+			// only with -B, which gg doesn't pass
+		case "rename from", "copy from":
+			if f.OldPath, err = unquote(value); err != nil {
+				return File{}, fmt.Errorf("line %d: %w", p.i+1, err)
+			}
+			f.Status = Renamed
+			if key == "copy from" {
+				f.Status = Copied
+			}
+		case "rename to", "copy to":
+			if f.NewPath, err = unquote(value); err != nil {
+				return File{}, fmt.Errorf("line %d: %w", p.i+1, err)
+			}
+		case "index":
+			// "index <old>..<new>[ <mode>]"; the mode is there when it
+			// didn't change
+			if _, mode, ok := strings.Cut(value, " "); ok {
+				f.OldMode, f.NewMode = mode, mode
+			}
+		case "---", "+++":
+			if value != "/dev/null" {
+				path, err := unquote(strings.TrimSuffix(value, "\t"))
+				if err != nil {
+					return File{}, fmt.Errorf("line %d: %w", p.i+1, err)
+				}
+				if key == "---" {
+					f.OldPath = strings.TrimPrefix(path, "a/")
+				} else {
+					f.NewPath = strings.TrimPrefix(path, "b/")
+				}
+			}
+		case "Binary files", "GIT binary patch":
+			f.Binary = true
+		default:
+			if !strings.HasPrefix(line, "@@ ") {
+				// the next file, or the end of the patch
+				return finish(f), nil
+			}
+			for p.i < len(p.lines) && strings.HasPrefix(p.lines[p.i], "@@ ") {
+				h, err := p.hunk()
+				if err != nil {
+					return File{}, err
+				}
+				f.Hunks = append(f.Hunks, h)
+			}
+			return finish(f), nil
+		}
+	}
+	return finish(f), nil
+}
+
+// finish settles the paths of an added or deleted file, whose /dev/null
+// side the header lines leave at the `diff --git` guess.
+func finish(f File) File {
+	switch f.Status {
+	case Added:
+		f.OldPath = f.NewPath
+	case Deleted:
+		f.NewPath = f.OldPath
+	}
+	return f
+}
+
+// headerField splits an extended header line into its keyword and value.
+func headerField(line string) (key, value string) {
+	for _, k := range []string{
+		"old mode", "new mode", "deleted file mode", "new file mode",
+		"similarity index", "dissimilarity index",
+		"rename from", "rename to", "copy from", "copy to",
+		"index", "---", "+++",
+	} {
+		if v, ok := strings.CutPrefix(line, k+" "); ok {
+			return k, v
+		}
+	}
+	if strings.HasPrefix(line, "Binary files ") {
+		return "Binary files", ""
+	}
+	if line == "GIT binary patch" {
+		return line, ""
+	}
+	return "", line
+}
+
+// hunk parses one hunk, starting at its @@ line. The counts in the header
+// say where it ends, so a deleted line that reads "-- foo" can't be taken
+// for anything else.
+func (p *parser) hunk() (Hunk, error) {
+	header := p.lines[p.i]
+	h, err := parseHunkHeader(header)
+	if err != nil {
+		return Hunk{}, fmt.Errorf("line %d: %w", p.i+1, err)
+	}
+	p.i++
+	oldNum, newNum := h.OldStart, h.NewStart
+	oldLeft, newLeft := h.OldLines, h.NewLines
+	for p.i < len(p.lines) {
+		line := p.lines[p.i]
+		if strings.HasPrefix(line, `\`) {
+			// "\ No newline at end of file", for the line before it
+			if n := len(h.Lines); n > 0 {
+				h.Lines[n-1].NoNewline = true
+			}
+			p.i++
+			continue
+		}
+		if oldLeft <= 0 && newLeft <= 0 {
+			break
+		}
+		if line == "" {
+			// a blank context line, with diff.suppressBlankEmpty
+			line = " "
+		}
+		l := Line{Kind: LineKind(line[0]), Text: line[1:]}
+		switch l.Kind {
+		case Context:
+			l.OldNum, l.NewNum = oldNum, newNum
+			oldNum, newNum = oldNum+1, newNum+1
+			oldLeft, newLeft = oldLeft-1, newLeft-1
+		case Del:
+			l.OldNum = oldNum
+			oldNum++
+			oldLeft--
+		case Add:
+			l.NewNum = newNum
+			newNum++
+			newLeft--
+		default:
+			return Hunk{}, fmt.Errorf("line %d: unexpected %q in hunk %s", p.i+1, line, h.Header())
+		}
+		if oldLeft < 0 || newLeft < 0 {
+			return Hunk{}, fmt.Errorf("line %d: hunk %s is longer than its header says", p.i+1, h.Header())
+		}
+		h.Lines = append(h.Lines, l)
+		p.i++
+	}
+	if oldLeft > 0 || newLeft > 0 {
+		return Hunk{}, fmt.Errorf("hunk %s is cut short", h.Header())
+	}
+	return h, nil
+}
+
+// parseHunkHeader reads "@@ -a[,b] +c[,d] @@[ section]".
+func parseHunkHeader(line string) (Hunk, error) {
+	rest, ok := strings.CutPrefix(line, "@@ -")
+	if !ok {
+		return Hunk{}, fmt.Errorf("malformed hunk header %q", line)
+	}
+	ranges, section, ok := strings.Cut(rest, " @@")
+	if !ok {
+		return Hunk{}, fmt.Errorf("malformed hunk header %q", line)
+	}
+	oldRange, newRange, ok := strings.Cut(ranges, " +")
+	if !ok {
+		return Hunk{}, fmt.Errorf("malformed hunk header %q", line)
+	}
+	var h Hunk
+	var err error
+	if h.OldStart, h.OldLines, err = parseRange(oldRange); err != nil {
+		return Hunk{}, fmt.Errorf("malformed hunk header %q: %w", line, err)
+	}
+	if h.NewStart, h.NewLines, err = parseRange(newRange); err != nil {
+		return Hunk{}, fmt.Errorf("malformed hunk header %q: %w", line, err)
+	}
+	h.Section = strings.TrimPrefix(section, " ")
+	return h, nil
+}
+
+// parseRange reads "start[,lines]"; lines defaults to 1.
+func parseRange(s string) (start, lines int, err error) {
+	startStr, linesStr, ok := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return start, 1, nil
+	}
+	lines, err = strconv.Atoi(linesStr)
+	return start, lines, err
+}
+
+// splitGitHeader splits the "a/<old> b/<new>" of a `diff --git` line.
+// Unquoted paths may contain spaces, which makes the split ambiguous in
+// general; it's exact whenever both paths are the same (everything but
+// renames and copies, whose real paths come from later header lines).
+func splitGitHeader(s string) (oldPath, newPath string, err error) {
+	if strings.HasPrefix(s, `"`) {
+		end := closingQuote(s)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted path in %q", s)
+		}
+		if oldPath, err = unquote(s[:end+1]); err != nil {
+			return "", "", err
+		}
+		if newPath, err = unquote(strings.TrimPrefix(s[end+1:], " ")); err != nil {
+			return "", "", err
+		}
+		return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(newPath, "b/"), nil
+	}
+	if i := strings.Index(s, ` "`); i >= 0 {
+		// only the new path needed quoting
+		if newPath, err = unquote(s[i+1:]); err != nil {
+			return "", "", err
+		}
+		return strings.TrimPrefix(s[:i], "a/"), strings.TrimPrefix(newPath, "b/"), nil
+	}
+	// "a/P b/P": the same path twice splits down the middle
+	if half := len(s) / 2; len(s)%2 == 1 && s[half] == ' ' &&
+		strings.HasPrefix(s, "a/") && s[half+1:half+3] == "b/" && s[2:half] == s[half+3:] {
+		return s[2:half], s[half+3:], nil
+	}
+	i := strings.Index(s, " b/")
+	if i < 0 || !strings.HasPrefix(s, "a/") {
+		return "", "", fmt.Errorf("malformed diff header %q", s)
+	}
+	return s[2:i], s[i+3:], nil
+}
+
+// closingQuote is the index of the quote closing the one s starts with,
+// or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote undoes git's C-style quoting of paths with special characters
+// (core.quotePath); other paths come back as they are.
+func unquote(s string) (string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return s, nil
+	}
+	u, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("bad quoted path %s: %w", s, err)
+	}
+	return u, nil
+}
@@ -0,0 +1,163 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/src/diff"
+)
+
+func patch(lines ...string) string { return strings.Join(lines, "\n") + "\n" }
+
+func TestParse_Modified(t *testing.T) {
+	t.Parallel()
+	files, err := diff.Parse(patch(
+		"diff --git a/main.go b/main.go",
+		"index 1111111..2222222 100644",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,4 +1,5 @@ package main",
+		" import \"fmt\"",
+		"-func a() {}",
+		"+func a() { fmt.Println() }",
+		"+func b() {}",
+		"",
+		" // end",
+		"@@ -10 +11 @@ func main() {",
+		"-\treturn",
+		"+\treturn nil",
+	))
+	require.NoError(t, err)
+	require.That(t, len(files) == 1, "want one file, got ", files)
+	f := files[0]
+	assert.Equal(t, f.Path(), "main.go")
+	assert.Equal(t, f.Status, diff.Modified)
+	assert.Equal(t, f.OldMode, "100644")
+	assert.That(t, !f.ModeChanged(), "mode unchanged")
+	require.That(t, len(f.Hunks) == 2, "want two hunks, got ", f.Hunks)
+
+	h := f.Hunks[0]
+	assert.Equal(t, h.Header(), "@@ -1,4 +1,5 @@")
+	assert.Equal(t, h.Section, "package main")
+	assert.EqualArrays(t, h.Lines, []diff.Line{
+		{Kind: diff.Context, Text: `import "fmt"`, OldNum: 1, NewNum: 1},
+		{Kind: diff.Del, Text: "func a() {}", OldNum: 2},
+		{Kind: diff.Add, Text: "func a() { fmt.Println() }", NewNum: 2},
+		{Kind: diff.Add, Text: "func b() {}", NewNum: 3},
+		{Kind: diff.Context, Text: "", OldNum: 3, NewNum: 4},
+		{Kind: diff.Context, Text: "// end", OldNum: 4, NewNum: 5},
+	})
+	assert.Equal(t, f.Hunks[1].Header(), "@@ -10 +11 @@")
+	assert.Equal(t, f.Hunks[1].Section, "func main() {")
+
+	added, deleted := f.Stat()
+	assert.Equal(t, added, 3)
+	assert.Equal(t, deleted, 2)
+}
+
+func TestParse_HeaderVariants(t *testing.T) {
+	t.Parallel()
+	files, err := diff.Parse(patch(
+		"diff --git a/new file.txt b/new file.txt",
+		"new file mode 100644",
+		"index 0000000..3333333",
+		"--- /dev/null",
+		"+++ b/new file.txt\t",
+		"@@ -0,0 +1 @@",
+		"+hello",
+		"\\ No newline at end of file",
+		"diff --git a/gone.txt b/gone.txt",
+		"deleted file mode 100644",
+		"index 3333333..0000000",
+		"--- a/gone.txt",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"--- not a header",
+		"diff --git a/run.sh b/run.sh",
+		"old mode 100644",
+		"new mode 100755",
+		"diff --git a/old.go b/pkg/new.go",
+		"similarity index 92%",
+		"rename from old.go",
+		"rename to pkg/new.go",
+		"index 4444444..5555555 100644",
+		"--- a/old.go",
+		"+++ b/pkg/new.go",
+		"@@ -1 +1 @@",
+		"-package old",
+		"+package new",
+		"diff --git a/logo.png b/logo.png",
+		"index 6666666..7777777 100644",
+		"Binary files a/logo.png and b/logo.png differ",
+		`diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"`,
+		"new file mode 100644",
+		"index 0000000..8888888",
+		"diff --git a/lib b/lib",
+		"index 9999999..aaaaaaa 160000",
+		"--- a/lib",
+		"+++ b/lib",
+		"@@ -1 +1 @@",
+		"-Subproject commit 9999999",
+		"+Subproject commit aaaaaaa",
+	))
+	require.NoError(t, err)
+	require.That(t, len(files) == 7, "want seven files, got ", files)
+
+	added := files[0]
+	assert.Equal(t, added.Status, diff.Added)
+	assert.Equal(t, added.OldPath, "new file.txt")
+	assert.Equal(t, added.NewPath, "new file.txt")
+	assert.EqualArrays(t, added.Hunks[0].Lines, []diff.Line{{Kind: diff.Add, Text: "hello", NewNum: 1, NoNewline: true}})
+
+	deleted := files[1]
+	assert.Equal(t, deleted.Status, diff.Deleted)
+	assert.Equal(t, deleted.Path(), "gone.txt")
+	// the count in the header, not the "---" prefix, says it's a line
+	assert.EqualArrays(t, deleted.Hunks[0].Lines, []diff.Line{{Kind: diff.Del, Text: "-- not a header", OldNum: 1}})
+
+	mode := files[2]
+	assert.That(t, mode.ModeChanged(), "100644 -> 100755")
+	assert.Equal(t, mode.NewMode, "100755")
+	assert.Equal(t, len(mode.Hunks), 0)
+
+	renamed := files[3]
+	assert.Equal(t, renamed.Status, diff.Renamed)
+	assert.Equal(t, renamed.OldPath, "old.go")
+	assert.Equal(t, renamed.NewPath, "pkg/new.go")
+	assert.Equal(t, renamed.Similarity, 92)
+
+	assert.That(t, files[4].Binary, "logo.png is binary")
+	assert.Equal(t, len(files[4].Hunks), 0)
+
+	assert.Equal(t, files[5].Path(), "café.txt")
+	assert.Equal(t, files[5].Status, diff.Added)
+
+	assert.That(t, files[6].IsSubmodule(), "lib is a gitlink")
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"combined":         patch("diff --cc file.txt", "index 1,2..3"),
+		"bad hunk header":  patch("diff --git a/x b/x", "--- a/x", "+++ b/x", "@@ -1 +1", "-a", "+b"),
+		"short hunk":       patch("diff --git a/x b/x", "--- a/x", "+++ b/x", "@@ -1,2 +1,2 @@", "-a", "+b"),
+		"stray hunk line":  patch("diff --git a/x b/x", "--- a/x", "+++ b/x", "@@ -1,2 +1,2 @@", "-a", "*b"),
+		"malformed header": patch("diff --git nonsense"),
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := diff.Parse(in)
+			assert.Error(t, err, assert.AnyError)
+		})
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	t.Parallel()
+	files, err := diff.Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, len(files), 0)
+}
@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Granularity is the unit Refine compares a pair of lines in.
+type Granularity int
+
+const (
+	// Words compares identifier-like runs (letters, digits, _), runs of
+	// whitespace, and every other character on its own, so "foo.Bar(x)"
+	// is foo . Bar ( x ).
+	Words Granularity = iota
+	// Chars compares character by character.
+	Chars
+)
+
+// Span is the byte range [Start, End) of a line.
+type Span struct{ Start, End int }
+
+// Limits on Refine, which runs on every changed pair of a diff and so
+// must stay cheap: lines with more tokens than maxRefineTokens, or pairs
+// needing more than maxRefineEdits token edits, aren't refined.
+const (
+	maxRefineTokens = 512
+	maxRefineEdits  = 128
+	// maxChangedFraction is how much of a pair (by bytes) may change
+	// before emphasis stops pointing anything out: past it the lines are
+	// just different.
+	maxChangedFraction = 0.6
+)
+
+// Refine finds which parts of before and after differ, by a Myers diff
+// over their tokens. The spans come back merged, in order. ok is false when
+// the pair is too long or too different to be worth refining, and the
+// lines should be shown as changed as a whole.
+func Refine(before, after string, g Granularity) (oldSpans, newSpans []Span, ok bool) {
+	a, b := Tokenize(before, g), Tokenize(after, g)
+	if len(a) > maxRefineTokens || len(b) > maxRefineTokens {
+		return nil, nil, false
+	}
+	total := len(before) + len(after)
+	if float64(total-2*sharedBytes(a, b)) > maxChangedFraction*float64(total) {
+		// even matching every shared token couldn't bring it under
+		return nil, nil, false
+	}
+	inA, inB, ok := commonTokens(a, b)
+	if !ok {
+		return nil, nil, false
+	}
+	oldSpans, oldChanged := changedSpans(a, inA)
+	newSpans, newChanged := changedSpans(b, inB)
+	if float64(oldChanged+newChanged) > maxChangedFraction*float64(total) {
+		return nil, nil, false
+	}
+	return oldSpans, newSpans, true
+}
+
+// sharedBytes is the size of the tokens a and b have in common, counted
+// as a multiset: an upper bound on what any common subsequence can match.
+func sharedBytes(a, b []string) int {
+	counts := make(map[string]int, len(a))
+	for _, tok := range a {
+		counts[tok]++
+	}
+	shared := 0
+	for _, tok := range b {
+		if counts[tok] > 0 {
+			counts[tok]--
+			shared += len(tok)
+		}
+	}
+	return shared
+}
+
+// Tokenize splits s into the tokens Refine compares. Joined, they are s.
+func Tokenize(s string, g Granularity) []string {
+	var toks []string
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		end := i + size
+		if g == Words {
+			if class := tokenClass(r); class != 0 {
+				for end < len(s) {
+					next, n := utf8.DecodeRuneInString(s[end:])
+					if tokenClass(next) != class {
+						break
+					}
+					end += n
+				}
+			}
+		}
+		toks = append(toks, s[i:end])
+		i = end
+	}
+	return toks
+}
+
+// tokenClass groups the runes Words keeps together: 1 for word
+// characters, 2 for whitespace, 0 for anything that stands alone.
+func tokenClass(r rune) int {
+	switch {
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	case unicode.IsSpace(r):
+		return 2
+	}
+	return 0
+}
+
+// changedSpans merges the runs of tokens not in the common subsequence
+// into byte spans, and counts their bytes.
+func changedSpans(toks []string, common []bool) (spans []Span, changed int) {
+	pos := 0
+	for i, tok := range toks {
+		if !common[i] {
+			if n := len(spans); n > 0 && spans[n-1].End == pos {
+				spans[n-1].End += len(tok)
+			} else {
+				spans = append(spans, Span{pos, pos + len(tok)})
+			}
+			changed += len(tok)
+		}
+		pos += len(tok)
+	}
+	return spans, changed
+}
+
+// commonTokens marks the tokens of a and b in a longest common
+// subsequence, found with Myers' O(ND) algorithm. ok is false when it
+// takes more than maxRefineEdits edits.
+func commonTokens(a, b []string) (inA, inB []bool, ok bool) {
+	n, m := len(a), len(b)
+	inA, inB = make([]bool, n), make([]bool, m)
+	limit := min(n+m, maxRefineEdits)
+	off := limit + 1
+	// v[off+k] is the furthest x reached on diagonal k = x - y
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1] // down: an insertion from b
+			} else {
+				x = v[off+k-1] + 1 // right: a deletion from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				backtrack(trace, off, n, m, inA, inB)
+				return inA, inB, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// backtrack walks the snapshots commonTokens took back from (n, m),
+// marking each diagonal (matched) step.
+func backtrack(trace [][]int, off, x, y int, inA, inB []bool) {
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			inA[x], inB[y] = true, true
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		inA[x], inB[y] = true, true
+	}
+}
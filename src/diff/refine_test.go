@@ -0,0 +1,80 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/src/diff"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+	assert.EqualArrays(t, diff.Tokenize("foo.Bar(x_1,  y)", diff.Words),
+		[]string{"foo", ".", "Bar", "(", "x_1", ",", "  ", "y", ")"})
+	assert.EqualArrays(t, diff.Tokenize("héé!", diff.Chars), []string{"h", "é", "é", "!"})
+	assert.Equal(t, len(diff.Tokenize("", diff.Words)), 0)
+}
+
+// mark brackets the spans of s, to compare refinements at a glance.
+func mark(s string, spans []diff.Span) string {
+	var b strings.Builder
+	pos := 0
+	for _, sp := range spans {
+		b.WriteString(s[pos:sp.Start] + "[" + s[sp.Start:sp.End] + "]")
+		pos = sp.End
+	}
+	return b.String() + s[pos:]
+}
+
+func TestRefine(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name          string
+		before, after string
+		g             diff.Granularity
+		wantOld       string
+		wantNew       string
+	}{
+		{"one identifier", "\treturn fooBar(x, y)", "\treturn fooBaz(x, y)", diff.Words,
+			"\treturn [fooBar](x, y)", "\treturn [fooBaz](x, y)"},
+		{"chars", "\treturn fooBar(x, y)", "\treturn fooBaz(x, y)", diff.Chars,
+			"\treturn fooBa[r](x, y)", "\treturn fooBa[z](x, y)"},
+		{"insertion", "f(a, c)", "f(a, b, c)", diff.Words,
+			"f(a, c)", "f(a, [b, ]c)"},
+		{"adjacent tokens merge", "x := 1", "x = 2", diff.Words,
+			"x [:]= [1]", "x = [2]"},
+		{"identical", "same", "same", diff.Words, "same", "same"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			oldSpans, newSpans, ok := diff.Refine(tc.before, tc.after, tc.g)
+			assert.That(t, ok, "should refine")
+			assert.Equal(t, mark(tc.before, oldSpans), tc.wantOld)
+			assert.Equal(t, mark(tc.after, newSpans), tc.wantNew)
+		})
+	}
+}
+
+func TestRefine_GivesUp(t *testing.T) {
+	t.Parallel()
+	_, _, ok := diff.Refine("completely different", "nothing alike here", diff.Words)
+	assert.That(t, !ok, "unrelated lines aren't worth refining")
+
+	long := strings.Repeat("a ", 1000)
+	_, _, ok = diff.Refine(long, long+"b", diff.Words)
+	assert.That(t, !ok, "too many tokens")
+
+	var before, after strings.Builder
+	for i := range 200 {
+		before.WriteString("x ")
+		if i%2 == 0 {
+			after.WriteString("y ")
+		} else {
+			after.WriteString("x ")
+		}
+	}
+	_, _, ok = diff.Refine(before.String(), after.String(), diff.Words)
+	assert.That(t, !ok, "too many edits")
+}
@@ -0,0 +1,234 @@
+package diff
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Select narrows f to the changed lines keep picks out (by hunk and line
+// index), the way `git add -p` edits a hunk, so the result applies to the
+// same preimage as f. Hunks left with no change are dropped.
+//
+// Forward, f's old side is the preimage: a deleted line that isn't kept
+// stays as context and an added one is dropped. With reverse -- for a
+// patch applied with `git apply -R` -- the new side is the preimage, so
+// it's the other way round. Either way the preimage side of each hunk
+// keeps its range, and the other is recounted.
+func (f File) Select(keep func(hunk, line int) bool, reverse bool) File {
+	out := f
+	out.Hunks = nil
+	delta := 0 // how far the recounted side has moved, from the hunks before
+	for hi, h := range f.Hunks {
+		var lines []Line
+		changed := false
+		for li := 0; li < len(h.Lines); {
+			if h.Lines[li].Kind == Context {
+				lines = append(lines, h.Lines[li])
+				li++
+				continue
+			}
+			end := li
+			for end < len(h.Lines) && h.Lines[end].Kind != Context {
+				end++
+			}
+			first := li
+			block, ok := selectBlock(h.Lines[li:end], func(j int) bool { return keep(hi, first+j) }, reverse)
+			lines = append(lines, block...)
+			changed = changed || ok
+			li = end
+		}
+		if !changed {
+			continue
+		}
+		nh := Hunk{Section: h.Section, Lines: lines}
+		oldLines, newLines := 0, 0
+		for _, l := range lines {
+			if l.Kind != Add {
+				oldLines++
+			}
+			if l.Kind != Del {
+				newLines++
+			}
+		}
+		if reverse {
+			nh.NewStart, nh.NewLines = h.NewStart, h.NewLines
+			nh.OldStart, nh.OldLines = shiftRange(h.NewStart, h.NewLines, oldLines, -delta)
+			delta += newLines - oldLines
+		} else {
+			nh.OldStart, nh.OldLines = h.OldStart, h.OldLines
+			nh.NewStart, nh.NewLines = shiftRange(h.OldStart, h.OldLines, newLines, delta)
+			delta += newLines - oldLines
+		}
+		nh.number()
+		out.Hunks = append(out.Hunks, nh)
+	}
+	return out
+}
+
+// selectBlock is Select for one run of changed lines. The preimage side's
+// lines keep their order, as changes or as context; the kept lines of the
+// other side go in beside the lines they replace: forward, the added
+// lines after the last deletion kept, and reversed, the deleted lines
+// before the first addition kept.
+func selectBlock(block []Line, keep func(int) bool, reverse bool) (out []Line, changed bool) {
+	pre := Del
+	if reverse {
+		pre = Add
+	}
+	var moved []Line
+	at := -1
+	for i, l := range block {
+		switch {
+		case l.Kind != pre:
+			if keep(i) {
+				moved = append(moved, l)
+			}
+		case keep(i):
+			if !reverse || at < 0 {
+				at = len(out)
+			}
+			out = append(out, l)
+			if !reverse {
+				at = len(out)
+			}
+		default:
+			l.Kind = Context
+			out = append(out, l)
+		}
+	}
+	if at < 0 {
+		// no line of the preimage side kept: deletions go first, as git
+		// puts them, and additions last
+		at = 0
+		if !reverse {
+			at = len(out)
+		}
+	}
+	changed = len(moved) > 0 || slices.ContainsFunc(out, func(l Line) bool { return l.Kind != Context })
+	return slices.Insert(out, at, moved...), changed
+}
+
+// shiftRange places a range of lines lines, delta lines on from the range
+// (start, count) on the other side. An empty range starts at the line
+// before it, as git writes them.
+func shiftRange(start, count, lines, delta int) (int, int) {
+	first := start
+	if count == 0 {
+		first++
+	}
+	first += delta
+	if lines == 0 {
+		first--
+	}
+	return first, lines
+}
+
+// number sets the line numbers of h's lines from its header.
+func (h *Hunk) number() {
+	oldNum, newNum := h.OldStart, h.NewStart
+	for i := range h.Lines {
+		l := &h.Lines[i]
+		l.OldNum, l.NewNum = 0, 0
+		if l.Kind != Add {
+			l.OldNum = oldNum
+			oldNum++
+		}
+		if l.Kind != Del {
+			l.NewNum = newNum
+			newNum++
+		}
+	}
+}
+
+// Format writes files back out as a patch `git apply` takes: the header
+// lines Parse reads, and the hunks. A binary file's contents aren't in
+// the model, so it comes out as the "Binary files differ" marker, which
+// git apply refuses.
+func Format(files ...File) string {
+	var b strings.Builder
+	for _, f := range files {
+		oldPath, newPath := quote("a/"+f.OldPath), quote("b/"+f.NewPath)
+		b.WriteString("diff --git " + oldPath + " " + newPath + "\n")
+		switch f.Status {
+		case Added:
+			b.WriteString("new file mode " + f.NewMode + "\n")
+		case Deleted:
+			b.WriteString("deleted file mode " + f.OldMode + "\n")
+		default:
+			if f.ModeChanged() {
+				b.WriteString("old mode " + f.OldMode + "\n")
+				b.WriteString("new mode " + f.NewMode + "\n")
+			}
+		}
+		if f.Status == Renamed || f.Status == Copied {
+			verb := "rename"
+			if f.Status == Copied {
+				verb = "copy"
+			}
+			b.WriteString("similarity index " + strconv.Itoa(f.Similarity) + "%\n")
+			b.WriteString(verb + " from " + quote(f.OldPath) + "\n")
+			b.WriteString(verb + " to " + quote(f.NewPath) + "\n")
+		}
+		if f.Binary {
+			b.WriteString("Binary files " + oldPath + " and " + newPath + " differ\n")
+			continue
+		}
+		if len(f.Hunks) == 0 {
+			continue
+		}
+		if f.Status == Added {
+			oldPath = "/dev/null"
+		}
+		if f.Status == Deleted {
+			newPath = "/dev/null"
+		}
+		b.WriteString("--- " + oldPath + "\n")
+		b.WriteString("+++ " + newPath + "\n")
+		for _, h := range f.Hunks {
+			b.WriteString(h.Header())
+			if h.Section != "" {
+				b.WriteString(" " + h.Section)
+			}
+			b.WriteByte('\n')
+			for _, l := range h.Lines {
+				b.WriteByte(byte(l.Kind))
+				b.WriteString(l.Text)
+				b.WriteByte('\n')
+				if l.NoNewline {
+					b.WriteString("\\ No newline at end of file\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// quote C-quotes a path the way git does when it holds a quote, a
+// backslash or a control character; other paths come back as they are.
+func quote(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { return r < 0x20 || r == 0x7f || r == '"' || r == '\\' }) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				b.WriteString(`\` + strconv.FormatInt(int64(c)|0o1000, 8)[1:])
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
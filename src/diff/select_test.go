@@ -0,0 +1,192 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/src/diff"
+)
+
+var selectPatch = patch(
+	"diff --git a/a.txt b/a.txt",
+	"--- a/a.txt",
+	"+++ b/a.txt",
+	"@@ -1,3 +1,4 @@ top",
+	" one",
+	"-two",
+	"+2",
+	"+2.5",
+	" three",
+	"@@ -10,2 +11,2 @@",
+	" ten",
+	"-eleven",
+	"+11",
+)
+
+func TestFile_Select(t *testing.T) {
+	t.Parallel()
+	files, err := diff.Parse(selectPatch)
+	require.NoError(t, err)
+	f := files[0]
+
+	cases := []struct {
+		name    string
+		keep    [][2]int // hunk, line
+		reverse bool
+		want    string
+	}{
+		{"all", [][2]int{{0, 1}, {0, 2}, {0, 3}, {1, 1}, {1, 2}}, false, selectPatch},
+		{"nothing", nil, false, ""},
+		{"one added line", [][2]int{{0, 3}}, false, patch(
+			"diff --git a/a.txt b/a.txt",
+			"--- a/a.txt",
+			"+++ b/a.txt",
+			"@@ -1,3 +1,4 @@ top",
+			" one",
+			" two",
+			"+2.5",
+			" three",
+		)},
+		{"later hunk shifts", [][2]int{{0, 1}, {1, 2}}, false, patch(
+			"diff --git a/a.txt b/a.txt",
+			"--- a/a.txt",
+			"+++ b/a.txt",
+			"@@ -1,3 +1,2 @@ top",
+			" one",
+			"-two",
+			" three",
+			"@@ -10,2 +9,3 @@",
+			" ten",
+			" eleven",
+			"+11",
+		)},
+		{"reverse keeps the new side", [][2]int{{0, 2}, {1, 1}}, true, patch(
+			"diff --git a/a.txt b/a.txt",
+			"--- a/a.txt",
+			"+++ b/a.txt",
+			"@@ -1,3 +1,4 @@ top",
+			" one",
+			"+2",
+			" 2.5",
+			" three",
+			"@@ -10,3 +11,2 @@",
+			" ten",
+			"-eleven",
+			" 11",
+		)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			keep := func(h, l int) bool {
+				for _, k := range tc.keep {
+					if k == [2]int{h, l} {
+						return true
+					}
+				}
+				return false
+			}
+			got := f.Select(keep, tc.reverse)
+			if tc.want == "" {
+				assert.Equal(t, len(got.Hunks), 0)
+				return
+			}
+			assert.Equal(t, diff.Format(got), tc.want)
+		})
+	}
+}
+
+func TestFile_SelectKeepsReplacementsInPlace(t *testing.T) {
+	t.Parallel()
+	files, err := diff.Parse(patch(
+		"diff --git a/a.txt b/a.txt",
+		"--- a/a.txt",
+		"+++ b/a.txt",
+		"@@ -1,2 +1,2 @@",
+		"-a",
+		"-b",
+		"+A",
+		"+B",
+	))
+	require.NoError(t, err)
+	f := files[0]
+	lines := func(got diff.File) []string {
+		var out []string
+		for _, l := range got.Hunks[0].Lines {
+			out = append(out, string(l.Kind)+l.Text)
+		}
+		return out
+	}
+	// a for A: A lands where a was, ahead of b
+	fwd := f.Select(func(h, l int) bool { return l == 0 || l == 2 }, false)
+	assert.EqualArrays(t, lines(fwd), []string{"-a", "+A", " b"})
+	// B back to b, in the worktree: b lands where B is, after A
+	rev := f.Select(func(h, l int) bool { return l == 1 || l == 3 }, true)
+	assert.EqualArrays(t, lines(rev), []string{" A", "-b", "+B"})
+}
+
+func TestFile_SelectNumbersLines(t *testing.T) {
+	t.Parallel()
+	files, err := diff.Parse(selectPatch)
+	require.NoError(t, err)
+	got := files[0].Select(func(h, l int) bool { return h == 1 }, false)
+	require.That(t, len(got.Hunks) == 1, "one hunk, got ", got.Hunks)
+	assert.EqualArrays(t, got.Hunks[0].Lines, []diff.Line{
+		{Kind: diff.Context, Text: "ten", OldNum: 10, NewNum: 10},
+		{Kind: diff.Del, Text: "eleven", OldNum: 11},
+		{Kind: diff.Add, Text: "11", NewNum: 11},
+	})
+}
+
+func TestFormat_RoundTrips(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"added": patch(
+			"diff --git a/new.sh b/new.sh",
+			"new file mode 100755",
+			"--- /dev/null",
+			"+++ b/new.sh",
+			"@@ -0,0 +1 @@",
+			"+echo hi",
+			`\ No newline at end of file`,
+		),
+		"deleted": patch(
+			"diff --git a/old.txt b/old.txt",
+			"deleted file mode 100644",
+			"--- a/old.txt",
+			"+++ /dev/null",
+			"@@ -1 +0,0 @@",
+			"-bye",
+		),
+		"renamed with mode": patch(
+			"diff --git a/x b/y",
+			"old mode 100644",
+			"new mode 100755",
+			"similarity index 90%",
+			"rename from x",
+			"rename to y",
+			"--- a/x",
+			"+++ b/y",
+			"@@ -1 +1 @@",
+			"-a",
+			"+b",
+		),
+		"quoted": patch(
+			`diff --git "a/tab\there" "b/tab\there"`,
+			`--- "a/tab\there"`,
+			`+++ "b/tab\there"`,
+			"@@ -1 +1 @@",
+			"-a",
+			"+b",
+		),
+	}
+	for name, p := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			files, err := diff.Parse(p)
+			require.NoError(t, err)
+			assert.Equal(t, diff.Format(files...), p)
+		})
+	}
+}
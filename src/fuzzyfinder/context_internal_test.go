@@ -0,0 +1,42 @@
+package fuzzyfinder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+)
+
+// Opt.Context rows are kept only between two matching neighbours, and
+// never match the query on their own.
+func TestFilter_ContextItems(t *testing.T) {
+	t.Parallel()
+	items := []string{
+		"* a1 fix parser", // 0
+		"|",               // 1
+		"* b2 fix lexer",  // 2
+		"|\\",             // 3
+		"| * c3 docs",     // 4
+		"|/",              // 5
+		"* d4 fix tests",  // 6
+		"|",               // 7
+	}
+	isContext := func(s string) bool { return !strings.Contains(s, "*") }
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{0, 1, 2, 3, 4, 5, 6, 7}}, // empty query shows everything
+		{"fix", []int{0, 1, 2, 6}},          // a1-b2 adjacent; c3 breaks the run to d4
+		{"docs", []int{4}},                  // lone match, no neighbours to join
+		{"/", nil},                          // context rows don't match on their own
+	}
+	for _, tc := range cases {
+		f, m := NewWithMockedTerminal()
+		require.NoError(t, f.initFinder(items, Opt{Context: isContext, Query: tc.query}))
+		m.Fini()
+		assert.EqualArrays(t, f.state.matched, tc.want, "query %q", tc.query)
+	}
+}
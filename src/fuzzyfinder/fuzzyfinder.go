@@ -109,6 +109,12 @@ type finder struct {
 	drawWordsBuf []rune
 	drawWords    []string
 
+	// previews caches Opt.Preview per item string, parsed into lines of
+	// styled runes. It has its own lock: _draw runs under a read lock in
+	// tests.
+	previewMu sync.Mutex
+	previews  map[string][][]ansi.StyledRune
+
 	// filterDone is a non-blocking signal sent by the bg event-loop
 	// goroutine after a full filter+draw cycle. only used by tests to
 	// sync on event processing without a fixed sleep. buffered 1 so
@@ -149,6 +155,7 @@ func (f *finder) initFinder(items []string, opt Opt) error {
 
 	f.opt = &opt
 	f.state = state{}
+	f.previews = nil
 
 	if f.multi {
 		f.state.selection = map[int]int{}
@@ -214,7 +221,7 @@ func (f *finder) updateItems(items []string) {
 	if len(f.state.input) == 0 {
 		f.resetMatchedIdentity(len(f.state.items))
 	} else {
-		f.state.matched = matching.FindAllLower(strings.ToLower(string(f.state.input)), f.state.itemsLower)
+		f.state.matched = f.withContextLocked(matching.FindAllLower(strings.ToLower(string(f.state.input)), f.state.itemsLower))
 	}
 
 	// Re-key selection: drop entries whose item is gone; keep selection order
@@ -356,6 +363,10 @@ func (f *finder) _draw() {
 	f.term.Clear()
 
 	maxWidth := width
+	previewing := f.opt.Preview != nil && width >= minPreviewWidth
+	if previewing {
+		maxWidth = width / 2
+	}
 
 	// Layout: rows are addressed as offsets from the prompt outward into the
 	// item area. step is +1 in reverse mode (prompt at top, items grow down)
@@ -372,7 +383,7 @@ func (f *finder) _draw() {
 	offset := 1
 	var w int
 	if len(f.opt.Header) > 0 {
-		for _, r := range runewidth.Truncate(f.opt.Header, maxWidth-2, "..") {
+		for _, r := range runewidth.Truncate(f.opt.Header, width-2, "..") {
 			style := tcell.StyleDefault.Foreground(tcell.ColorGreen).Background(tcell.ColorDefault)
 			f.term.SetContent(2+w, rowAt(offset), r, nil, style)
 			w += runewidth.RuneWidth(r)
@@ -575,6 +586,72 @@ func (f *finder) _draw() {
 			}
 		}
 	}
+
+	if previewing {
+		// the pane spans the item area, read top-down whichever way the
+		// items grow
+		top := min(rowAt(firstItemOffset), rowAt(firstItemOffset+itemAreaHeight))
+		var lines [][]ansi.StyledRune
+		if f.state.y < len(f.state.matched) {
+			lines = f.preview(f.state.items[f.state.matched[f.state.y]])
+		}
+		f.drawPreview(lines, maxWidth, top, itemAreaHeight+1, width)
+	}
+}
+
+// minPreviewWidth is the narrowest terminal Opt.Preview splits.
+const minPreviewWidth = 40
+
+// previewTabWidth is the tab stop preview text is expanded to.
+const previewTabWidth = 8
+
+// preview returns Opt.Preview's text for item as styled lines.
+func (f *finder) preview(item string) [][]ansi.StyledRune {
+	f.previewMu.Lock()
+	defer f.previewMu.Unlock()
+	if lines, ok := f.previews[item]; ok {
+		return lines
+	}
+	if f.previews == nil {
+		f.previews = map[string][][]ansi.StyledRune{}
+	}
+	var lines [][]ansi.StyledRune
+	for _, line := range strings.Split(strings.TrimRight(f.opt.Preview(item), "\n"), "\n") {
+		lines = append(lines, ansi.Parse(line, tcell.StyleDefault))
+	}
+	f.previews[item] = lines
+	return lines
+}
+
+// drawPreview draws the preview pane: a separator in column left, then
+// lines from row top, height rows of them, up to column right.
+func (f *finder) drawPreview(lines [][]ansi.StyledRune, left, top, height, right int) {
+	sepStyle := tcell.StyleDefault.Foreground(tcell.ColorDarkGray).Background(tcell.ColorDefault)
+	for i := 0; i < height; i++ {
+		f.term.SetContent(left, top+i, '│', nil, sepStyle)
+		if i >= len(lines) {
+			continue
+		}
+		start := left + 2
+		col := start
+		for _, sr := range lines[i] {
+			r, n := sr.R, 1
+			if r == '\t' {
+				r, n = ' ', previewTabWidth-(col-start)%previewTabWidth
+			}
+			rw := runewidth.RuneWidth(r)
+			if rw == 0 {
+				continue
+			}
+			if col+rw*n > right {
+				break
+			}
+			for ; n > 0; n-- {
+				f.term.SetContent(col, top+i, r, nil, sr.Style)
+				col += rw
+			}
+		}
+	}
 }
 
 func (f *finder) draw(d time.Duration) {
@@ -885,7 +962,7 @@ func (f *finder) filter() {
 
 	f.stateMu.Lock()
 	defer f.stateMu.Unlock()
-	f.state.matched = matchedItems
+	f.state.matched = f.withContextLocked(matchedItems)
 	if len(f.state.matched) == 0 {
 		f.state.cursorY = 0
 		f.state.y = 0
@@ -1169,6 +1246,37 @@ func (f *finder) unselectableLocked(idx int) bool {
 	return f.opt.Unselectable(f.state.items[idx])
 }
 
+// withContextLocked applies Opt.Context to a query's matches: context items
+// that matched on their own are dropped, and ones sandwiched between two
+// matching non-context items are put back, in item order.
+func (f *finder) withContextLocked(matched []int) []int {
+	if f.opt == nil || f.opt.Context == nil {
+		return matched
+	}
+	hit := make([]bool, len(f.state.items))
+	for _, m := range matched {
+		hit[m] = true
+	}
+	out := make([]int, 0, len(matched))
+	var pending []int // context items since the last non-context item
+	prevHit := false
+	for i, item := range f.state.items {
+		if f.opt.Context(item) {
+			pending = append(pending, i)
+			continue
+		}
+		if hit[i] {
+			if prevHit {
+				out = append(out, pending...)
+			}
+			out = append(out, i)
+		}
+		prevHit = hit[i]
+		pending = pending[:0]
+	}
+	return out
+}
+
 // confirmSelection resolves what Enter should return. Three outcomes:
 //   - (nil, ErrAbort): nothing to confirm (no matches)
 //   - (nil, nil): the cursored item is unselectable; caller keeps the picker open
@@ -47,6 +47,22 @@ type Opt struct {
 	// NOTE: the predicate keys on the item string, so duplicate item strings
 	// share a selectability. Fine unless you need two same-text items to differ.
 	Unselectable func(item string) bool
+	// Context, when non-nil, marks items (ANSI-stripped when Opt.Ansi) that
+	// carry no text of their own -- the connector rows of a commit graph, say.
+	// The query never matches them directly; while it's non-empty a context
+	// item is kept only when the nearest non-context items above and below it
+	// both match, so runs of adjacent matches stay visually joined. Items are
+	// never reordered, so this only makes sense for sources whose order means
+	// something. Usually paired with Unselectable.
+	Context func(item string) bool
+	// Preview, when non-nil, returns the text to show for the item under
+	// the cursor (ANSI-stripped when Opt.Ansi) in a pane to the right of
+	// the items, which then get the left half of the width. The text may
+	// carry ANSI SGR escapes; lines past the pane's height or width are
+	// cut. It's called once per item string and remembered, so it may be
+	// slow-ish, but not so slow the cursor lags. The pane is left out on
+	// terminals too narrow to split.
+	Preview func(item string) string
 }
 
 func (o Opt) withDefaults() Opt {
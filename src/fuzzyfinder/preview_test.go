@@ -0,0 +1,74 @@
+package fuzzyfinder_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lczyk/assert"
+	ff "github.com/lczyk/gitgum/src/fuzzyfinder"
+)
+
+// screenRow is the text of row y of the mocked terminal, trailing blanks
+// trimmed.
+func screenRow(term *ff.TerminalMock, y int) string {
+	cells, width, _ := term.GetContents()
+	var b strings.Builder
+	for _, c := range cells[y*width : (y+1)*width] {
+		if len(c.Runes) == 0 {
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteString(string(c.Runes))
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func TestFind_preview(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	calls := map[string]int{}
+	preview := func(item string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[item]++
+		return "of " + item + "\n\x1b[31mred\x1b[m\tafter tab\n"
+	}
+	esc := key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone})
+
+	t.Run("shows the cursor's item", func(t *testing.T) {
+		t.Parallel()
+		f, term := ff.NewWithMockedTerminal()
+		// 60x10, bottom-up: the pane starts at the top row, right of half
+		term.SetEvents(append(runes("ban"), esc)...)
+		items := []string{"apple", "banana"}
+		_, err := f.Find(context.Background(), &items, nil, ff.Opt{Preview: preview})
+		assert.ErrorIs(t, err, ff.ErrAbort)
+
+		assert.Equal(t, screenRow(term, 0)[30:], "│ of banana")
+		assert.Equal(t, screenRow(term, 1)[30:], "│ red     after tab")
+		assert.Equal(t, screenRow(term, 2)[30:], "│")
+		_, _, style, _ := term.GetContent(32, 1)
+		fg, _, _ := style.Decompose()
+		assert.Equal(t, fg, tcell.ColorMaroon, "the preview's own colours")
+		mu.Lock()
+		assert.Equal(t, calls["banana"], 1, "remembered across redraws")
+		mu.Unlock()
+	})
+
+	t.Run("left out when narrow", func(t *testing.T) {
+		t.Parallel()
+		f, term := ff.NewWithMockedTerminal()
+		term.SetSize(30, 10)
+		term.SetEvents(esc)
+		items := []string{"cherry"}
+		_, err := f.Find(context.Background(), &items, nil, ff.Opt{Preview: preview})
+		assert.ErrorIs(t, err, ff.ErrAbort)
+		assert.Equal(t, screenRow(term, 0), "")
+		mu.Lock()
+		assert.Equal(t, calls["cherry"], 0)
+		mu.Unlock()
+	})
+}
@@ -0,0 +1,86 @@
+package graph
+
+import "strconv"
+
+// collapseRuns implements LayoutOptions.Collapse. It returns a copy of
+// nodes with every foldable run swapped for one stand-in node, plus the
+// runs keyed by stand-in ID so Layout can mark the rows afterwards.
+//
+// A run is a chain of linear commits: exactly one parent, exactly one
+// child, not kept. Consecutive members are linked by that single edge, so
+// the stand-in just takes the newest member's ID (its child already points
+// at it) and the oldest member's parents. Stream's pinned head is never
+// folded -- the segment has to line up on it.
+func collapseRuns(nodes []Node, opts LayoutOptions) ([]Node, map[string][]*Node) {
+	idx := make(map[string]int, len(nodes))
+	childCount := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		idx[n.ID] = i
+		for _, pid := range n.Parents {
+			childCount[pid]++
+		}
+	}
+	linear := make([]bool, len(nodes))
+	for i := range nodes {
+		n := &nodes[i]
+		linear[i] = len(n.Parents) == 1 && childCount[n.ID] == 1 && n.ID != opts.head &&
+			(opts.Keep == nil || !opts.Keep(n))
+	}
+	// parentOf is the in-set index of i's parent, when that parent can
+	// extend the run (-1 otherwise)
+	parentOf := func(i int) int {
+		if p, ok := idx[nodes[i].Parents[0]]; ok && linear[p] {
+			return p
+		}
+		return -1
+	}
+	continues := make([]bool, len(nodes)) // i is some linear member's parent
+	for i := range nodes {
+		if linear[i] {
+			if p := parentOf(i); p >= 0 {
+				continues[p] = true
+			}
+		}
+	}
+
+	folded := make([]bool, len(nodes))
+	standIns := map[int]Node{}
+	runs := map[string][]*Node{}
+	for i := range nodes {
+		if !linear[i] || continues[i] {
+			continue // not the newest member of a run
+		}
+		run := []*Node{&nodes[i]}
+		for p := parentOf(i); p >= 0; p = parentOf(p) {
+			run = append(run, &nodes[p])
+		}
+		if len(run) < opts.Collapse || len(run) < 2 {
+			continue
+		}
+		for _, m := range run {
+			folded[idx[m.ID]] = true
+		}
+		newest, oldest := run[0], run[len(run)-1]
+		standIns[i] = Node{
+			ID:      newest.ID,
+			Label:   strconv.Itoa(len(run)) + " commits",
+			Parents: oldest.Parents,
+			Epoch:   newest.Epoch,
+			Lane:    newest.Lane,
+		}
+		runs[newest.ID] = run
+	}
+	if len(runs) == 0 {
+		return nodes, nil
+	}
+
+	out := make([]Node, 0, len(nodes))
+	for i, n := range nodes {
+		if s, ok := standIns[i]; ok {
+			out = append(out, s)
+		} else if !folded[i] {
+			out = append(out, n)
+		}
+	}
+	return out, runs
+}
@@ -1,18 +1,29 @@
 package graph
 
-import "sort"
+import (
+	"cmp"
+	"slices"
+	"sort"
+)
 
 // Layout takes a slice of Nodes and returns a layout result describing how
 // each commit maps to a (row, col) pair in the rendered output, plus any
-// stagger rows for fork / merge / catch-up edges.
-func Layout(nodes []Node) LayoutResult {
+// stagger rows for fork / merge / catch-up edges. Pass the zero
+// LayoutOptions for the engine defaults.
+func Layout(nodes []Node, opts LayoutOptions) LayoutResult {
 	if len(nodes) == 0 {
 		return LayoutResult{}
 	}
+	var runs map[string][]*Node
+	if opts.Collapse > 0 {
+		nodes, runs = collapseRuns(nodes, opts)
+	}
 
 	st := &layoutState{
 		idx:   make(map[string]*nodeState, len(nodes)),
 		nodes: make([]*nodeState, 0, len(nodes)),
+		order: opts.Order,
+		head:  opts.head,
 	}
 
 	// Count children per parent ahead of time so we can pre-size each
@@ -112,7 +123,27 @@ func Layout(nodes []Node) LayoutResult {
 	// Phase 5: row generation.
 	rows := st.generateRows(order)
 
-	return LayoutResult{Rows: rows, Columns: st.numCols}
+	// fold rows and elided markers are laid out as plain commits; swap
+	// their marker (and hang the run off a fold row)
+	for i := range rows {
+		c := rows[i].Commit
+		if c == nil {
+			continue
+		}
+		g := GlyphStar
+		switch {
+		case runs[c.ID] != nil:
+			rows[i].Folded = runs[c.ID]
+			g = GlyphFold
+		case c.Elided:
+			g = GlyphElided
+		}
+		if col := st.idx[c.ID].col; g != GlyphStar && col >= 0 && rows[i].Glyphs[col] == GlyphStar {
+			rows[i].Glyphs[col] = g
+		}
+	}
+
+	return LayoutResult{Rows: rows, Columns: st.numCols, LaneKeys: st.laneKeys}
 }
 
 // insertionSortChildren stable-sorts a parent's children slice in place.
@@ -159,37 +190,50 @@ type nodeState struct {
 	// detectCrossings; avoids one map[string]int per crossing-having
 	// commit.
 	routings []int
+	laneID   int // id of the lane span holding this commit (see lane.id)
 }
 
 type layoutState struct {
 	idx            map[string]*nodeState
 	nodes          []*nodeState
+	order          Order
+	head           string // see LayoutOptions.head
 	numCols        int
 	lanes          [][]lane           // lanes per col, sorted by introRow
-	previews       map[*nodeState]int // commit -> preview col for `|\|` stagger when 2nd parent shares col
+	previews       map[*nodeState]int // commit -> lane id of the dead same-col parent lane drawn as the `|\|` tail
 	glyphArena     []Glyph            // flat backing for per-row Glyph slices
+	laneArena      []int              // flat backing for per-row Lanes slices, parallel to glyphArena
 	arenaOff       int
-	routingSlab    []int // backing for nodeState.routings slices
+	laneKeys       []int64 // lane id -> Node.Lane of the newest commit carrying one; [0] unused
+	routingSlab    []int   // backing for nodeState.routings slices
 	routingSlabOff int
 }
 
-// nextRowGlyphs hands out a numCols-wide sub-slice of the pre-allocated
-// glyph arena. All slots come back zero-initialized (= GlyphSpace) since
-// the arena was zero-initialized on allocation.
-func (st *layoutState) nextRowGlyphs() []Glyph {
-	g := st.glyphArena[st.arenaOff : st.arenaOff+st.numCols : st.arenaOff+st.numCols]
-	st.arenaOff += st.numCols
-	return g
+// nextRowGlyphs hands out numCols-wide sub-slices of the pre-allocated
+// glyph and lane arenas. All slots come back zero-initialized (=
+// GlyphSpace / no lane) since the arenas were zero-initialized on
+// allocation.
+func (st *layoutState) nextRowGlyphs() ([]Glyph, []int) {
+	lo, hi := st.arenaOff, st.arenaOff+st.numCols
+	st.arenaOff = hi
+	return st.glyphArena[lo:hi:hi], st.laneArena[lo:hi:hi]
 }
 
 // lane is one continuous span of a column's life. A col can host multiple
 // disjoint lanes when sequential side branches reuse the same col.
 type lane struct {
+	id       int // 1-based, unique per span; indexes layoutState.laneKeys
 	col      int
 	introRow int // integer row where lane becomes active (= parent.row + 1, or commit row if no parent / lane already in use earlier)
 	endRow   int // last integer row of lane activity (last commit row in lane)
 	introCol int // col of introducing parent (-1 if no parent / first lane in col 0)
 	consumer *nodeState
+	// holdRow is the last row the lane is drawn alive for, >= endRow. It
+	// runs past endRow when the merge consuming the lane sits further up
+	// than the lane's last commit -- orders other than OrderEngine can
+	// slot unrelated commits in between, and the lane has to stay visible
+	// as a `|` across them until the merge's term stagger picks it up.
+	holdRow int
 }
 
 // ------ phase 1: topological sort --------------------------------------------------------------------------
@@ -213,77 +257,164 @@ func (st *layoutState) sort() {
 			ready = append(ready, ns)
 		}
 	}
+	// sortReady puts the newest ready node first (date-ordered queue).
+	sortReady := func() {
+		sort.Slice(ready, func(i, j int) bool {
+			a, b := ready[i], ready[j]
+			if st.head != "" && (a.ID == st.head || b.ID == st.head) {
+				return a.ID == st.head
+			}
+			if a.Epoch != b.Epoch {
+				return a.Epoch > b.Epoch
+			}
+			if a.Label != b.Label {
+				return a.Label < b.Label
+			}
+			return a.ID < b.ID
+		})
+	}
 
 	placed := make(map[string]bool, n)
 	row := n - 1 // assign rows newest-first
-
-	// walk places ns and recurses into non-first-parent ancestors immediately,
-	// so second-parent branches appear right after the merge. first-parent
-	// continuations go through the ready queue keyed by date.
-	var walk func(ns *nodeState)
-	walk = func(ns *nodeState) {
-		if placed[ns.ID] {
-			return
-		}
+	place := func(ns *nodeState) {
 		placed[ns.ID] = true
 		ns.row = row
 		row--
+	}
 
-		// Pre-decrement first parent's indeg before descending into non-first
-		// parents. When a non-first parent chain reaches a node that's also
-		// shared with our first parent (e.g. m7 = outer's first-parent AND
-		// inner's second-parent), the descendant's decrement will see the
-		// already-reduced count and hit 0, walking the shared chain depth-first
-		// from the descendant. Without this pre-decrement, the shared chain
-		// gets stranded until our trailing first-parent block runs, by which
-		// time the descendant has already walked its own first-parent chain --
-		// flipping their relative ordering.
-		var fp *nodeState
-		if len(ns.Parents) > 0 {
-			if p := st.idx[ns.Parents[0]]; p != nil {
-				indeg[p.ID]--
-				fp = p
+	switch st.order {
+	case OrderDate:
+		// git's --date-order: one priority queue keyed by date, a node
+		// becomes ready once every child is out. No branch grouping at all.
+		for len(ready) > 0 {
+			sortReady()
+			ns := ready[0]
+			ready = ready[1:]
+			place(ns)
+			for _, pid := range ns.Parents {
+				if p := st.idx[pid]; p != nil {
+					indeg[p.ID]--
+					if indeg[p.ID] == 0 && !placed[p.ID] {
+						ready = append(ready, p)
+					}
+				}
 			}
 		}
 
-		// Non-first parents: process depth-first, immediately. This places
-		// side-branch commits in rows above the merge commit in newest-first
-		// order (= just below merge in oldest-first output).
-		for i := 1; i < len(ns.Parents); i++ {
-			pid := ns.Parents[i]
-			p := st.idx[pid]
-			if p == nil {
-				continue
-			}
-			indeg[p.ID]--
-			if indeg[p.ID] == 0 && !placed[p.ID] {
-				walk(p)
+	case OrderTopo:
+		// git's --topo-order: same readiness rule but the queue is a LIFO
+		// stack. Parents are pushed in order, so the last (non-first)
+		// parent pops first and a merged side branch lands right after its
+		// merge. Tips seed the stack newest-on-top.
+		sortReady()
+		slices.Reverse(ready)
+		for len(ready) > 0 {
+			ns := ready[len(ready)-1]
+			ready = ready[:len(ready)-1]
+			place(ns)
+			for _, pid := range ns.Parents {
+				if p := st.idx[pid]; p != nil {
+					indeg[p.ID]--
+					if indeg[p.ID] == 0 && !placed[p.ID] {
+						ready = append(ready, p)
+					}
+				}
 			}
 		}
 
-		// First parent: walk if ready and not already placed by a shared-chain
-		// descent above. Falls through to ready queue when not yet reachable
-		// (other children still pending).
-		if fp != nil && !placed[fp.ID] && indeg[fp.ID] == 0 {
-			walk(fp)
+	case OrderFirstParent:
+		// strict first-parent: follow each first-parent chain as far as
+		// it's ready before looking anywhere else. Non-first parents wait
+		// in the date-ordered queue, so side branches land below (older
+		// than) the mainline run that merged them. Elided markers have
+		// nothing to wait for and go straight in.
+		for len(ready) > 0 {
+			sortReady()
+			ns := ready[0]
+			ready = ready[1:]
+			for ns != nil && !placed[ns.ID] {
+				place(ns)
+				var next *nodeState
+				for i, pid := range ns.Parents {
+					p := st.idx[pid]
+					if p == nil {
+						continue
+					}
+					indeg[p.ID]--
+					if indeg[p.ID] != 0 || placed[p.ID] {
+						continue
+					}
+					switch {
+					case i == 0:
+						next = p
+					case p.Elided:
+						// a stub, not a side branch: keep it under its merge
+						place(p)
+					default:
+						ready = append(ready, p)
+					}
+				}
+				ns = next
+			}
 		}
-	}
 
-	for len(ready) > 0 {
-		// Pick newest ready node (date-ordered queue).
-		sort.Slice(ready, func(i, j int) bool {
-			a, b := ready[i], ready[j]
-			if a.Epoch != b.Epoch {
-				return a.Epoch > b.Epoch
+	default:
+		// walk places ns and recurses into non-first-parent ancestors immediately,
+		// so second-parent branches appear right after the merge. first-parent
+		// continuations go through the ready queue keyed by date.
+		var walk func(ns *nodeState)
+		walk = func(ns *nodeState) {
+			if placed[ns.ID] {
+				return
 			}
-			if a.Label != b.Label {
-				return a.Label < b.Label
+			place(ns)
+
+			// Pre-decrement first parent's indeg before descending into non-first
+			// parents. When a non-first parent chain reaches a node that's also
+			// shared with our first parent (e.g. m7 = outer's first-parent AND
+			// inner's second-parent), the descendant's decrement will see the
+			// already-reduced count and hit 0, walking the shared chain depth-first
+			// from the descendant. Without this pre-decrement, the shared chain
+			// gets stranded until our trailing first-parent block runs, by which
+			// time the descendant has already walked its own first-parent chain --
+			// flipping their relative ordering.
+			var fp *nodeState
+			if len(ns.Parents) > 0 {
+				if p := st.idx[ns.Parents[0]]; p != nil {
+					indeg[p.ID]--
+					fp = p
+				}
 			}
-			return a.ID < b.ID
-		})
-		ns := ready[0]
-		ready = ready[1:]
-		walk(ns)
+
+			// Non-first parents: process depth-first, immediately. This places
+			// side-branch commits in rows above the merge commit in newest-first
+			// order (= just below merge in oldest-first output).
+			for i := 1; i < len(ns.Parents); i++ {
+				pid := ns.Parents[i]
+				p := st.idx[pid]
+				if p == nil {
+					continue
+				}
+				indeg[p.ID]--
+				if indeg[p.ID] == 0 && !placed[p.ID] {
+					walk(p)
+				}
+			}
+
+			// First parent: walk if ready and not already placed by a shared-chain
+			// descent above. Falls through to ready queue when not yet reachable
+			// (other children still pending).
+			if fp != nil && !placed[fp.ID] && indeg[fp.ID] == 0 {
+				walk(fp)
+			}
+		}
+
+		for len(ready) > 0 {
+			sortReady()
+			ns := ready[0]
+			ready = ready[1:]
+			walk(ns)
+		}
 	}
 
 	// Assign rows to any remaining unplaced nodes (valid DAG should never hit this).
@@ -385,6 +516,18 @@ func (st *layoutState) compactColumns() {
 				}
 			}
 		}
+		// merge edges hold the parent's col open up to the merge (see
+		// lane.holdRow), so nothing else may be packed into that span.
+		// OrderEngine places a merged branch right below its merge, which
+		// leaves that span empty except for octopus siblings that are
+		// meant to share a col -- skip it there.
+		for i := 1; st.order != OrderEngine && i < len(ns.Parents); i++ {
+			if p := st.idx[ns.Parents[i]]; p != nil && p.col != ns.col {
+				for r := p.row + 1; r < ns.row; r++ {
+					activity[p.col][r] = true
+				}
+			}
+		}
 	}
 	mergedTo := make([]int, st.numCols)
 	for i := range mergedTo {
@@ -456,6 +599,9 @@ func (st *layoutState) compactColumns() {
 // the same col is still active.
 func (st *layoutState) buildLanes(order []*nodeState) {
 	st.lanes = make([][]lane, st.numCols)
+	// Slot 0 is the "no lane" sentinel so zero-valued Row.Lanes entries
+	// read as unowned.
+	st.laneKeys = make([]int64, 1, len(order)+1)
 
 	// Map node -> index of lane that contains it.
 	nodeLane := make(map[string]int, len(order))
@@ -481,7 +627,15 @@ func (st *layoutState) buildLanes(order []*nodeState) {
 			}
 		}
 		if extend && openIdx[c] >= 0 {
-			st.lanes[c][openIdx[c]].endRow = ns.row
+			l := &st.lanes[c][openIdx[c]]
+			l.endRow = ns.row
+			l.holdRow = ns.row
+			ns.laneID = l.id
+			// order is oldest-first so the last write is the newest
+			// commit -- the branch tip, which is what names the lane.
+			if ns.Lane != 0 {
+				st.laneKeys[l.id] = ns.Lane
+			}
 			nodeLane[ns.ID] = openIdx[c]
 			continue
 		}
@@ -512,7 +666,9 @@ func (st *layoutState) buildLanes(order []*nodeState) {
 				introRow = ns.row
 			}
 		}
-		l := lane{col: c, introRow: introRow, endRow: ns.row, introCol: introCol}
+		l := lane{id: len(st.laneKeys), col: c, introRow: introRow, endRow: ns.row, holdRow: ns.row, introCol: introCol}
+		st.laneKeys = append(st.laneKeys, ns.Lane)
+		ns.laneID = l.id
 		st.lanes[c] = append(st.lanes[c], l)
 		openIdx[c] = len(st.lanes[c]) - 1
 		nodeLane[ns.ID] = openIdx[c]
@@ -532,7 +688,9 @@ func (st *layoutState) buildLanes(order []*nodeState) {
 				continue
 			}
 			if p.col != ns.col {
-				st.lanes[p.col][li].consumer = ns
+				l := &st.lanes[p.col][li]
+				l.consumer = ns
+				l.holdRow = max(l.holdRow, ns.row-1)
 			}
 		}
 	}
@@ -689,7 +847,7 @@ func (st *layoutState) detectMergePreviews(order []*nodeState) {
 			if pLane == nil || pLane.endRow >= ns.row {
 				continue
 			}
-			st.previews[ns] = 1 // marker; value unused
+			st.previews[ns] = p.laneID
 			break
 		}
 	}
@@ -749,14 +907,16 @@ func (st *layoutState) generateRows(order []*nodeState) []Row {
 	}
 	if totalRows > 0 && st.numCols > 0 {
 		st.glyphArena = make([]Glyph, totalRows*st.numCols)
+		st.laneArena = make([]int, totalRows*st.numCols)
 	}
 
-	// Active: row is in some lane's [introRow, endRow] span in col c.
-	// Pre-compute a [row][col] bitmap once instead of scanning lanes on
-	// every call. forkRows/termRows hit active() O(numCols) times per
+	// laneAt: id of the lane whose [introRow, endRow] span covers row in
+	// col c, 0 if none (so laneAt != 0 doubles as "col is active").
+	// Pre-compute a [row][col] grid once instead of scanning lanes on
+	// every call. forkRows/termRows hit laneAt() O(numCols) times per
 	// stagger row, which is the dominant cost for large histories with
 	// many merges. Trades O(rows * numCols) memory for O(1) lookup.
-	activeBits := make([]bool, (lastRow+1)*len(st.lanes))
+	laneBits := make([]int, (lastRow+1)*len(st.lanes))
 	stride := len(st.lanes)
 	for c := 0; c < len(st.lanes); c++ {
 		for _, l := range st.lanes[c] {
@@ -764,20 +924,20 @@ func (st *layoutState) generateRows(order []*nodeState) []Row {
 			if lo < 0 {
 				lo = 0
 			}
-			hi := l.endRow
+			hi := max(l.endRow, l.holdRow)
 			if hi > lastRow {
 				hi = lastRow
 			}
 			for r := lo; r <= hi; r++ {
-				activeBits[r*stride+c] = true
+				laneBits[r*stride+c] = l.id
 			}
 		}
 	}
-	active := func(row int, c int) bool {
+	laneAt := func(row int, c int) int {
 		if c < 0 || c >= stride || row < 0 || row > lastRow {
-			return false
+			return 0
 		}
-		return activeBits[row*stride+c]
+		return laneBits[row*stride+c]
 	}
 
 	// Index commits by row. Each topo-placed node gets a unique row, so
@@ -840,16 +1000,17 @@ func (st *layoutState) generateRows(order []*nodeState) []Row {
 		// AND introCol >= 0 (i.e., forks from another col, not a root).
 		for _, l := range introsAt[rowNum] {
 			var start int
-			rows, start = st.forkRows(rows, l, rowNum, active)
+			rows, start = st.forkRows(rows, l, rowNum, laneAt)
 			// Merge-preview decoration: if the commit owning this fork
 			// has a same-col 2nd-parent in a different (dead) lane,
 			// append a trailing `|` to the stagger row immediately above
 			// the commit -- this is git's `|\|` shape.
 			if len(rows) > start {
 				if ns := commitsAt[l.endRow]; ns != nil && ns.col == l.col {
-					if _, ok := st.previews[ns]; ok {
+					if pl, ok := st.previews[ns]; ok {
 						last := &rows[len(rows)-1]
 						last.Tail = append(last.Tail, GlyphPipe, GlyphSpace)
+						last.TailLanes = append(last.TailLanes, pl, 0)
 					}
 				}
 			}
@@ -894,21 +1055,23 @@ func (st *layoutState) generateRows(order []*nodeState) []Row {
 					seen[key] = true
 				}
 				if hasRC {
-					rows = st.crossingStagger(rows, p, ns, rc, rowNum, active)
+					rows = st.crossingStagger(rows, p, ns, rc, rowNum, laneAt)
 				} else {
-					rows = st.termRows(rows, p, ns, rowNum, active)
+					rows = st.termRows(rows, p, ns, rowNum, laneAt)
 				}
 			}
 		}
 
 		// Commit rows.
 		if ns := commitsAt[rowNum]; ns != nil {
-			glyphs := st.nextRowGlyphs()
+			glyphs, lanes := st.nextRowGlyphs()
 			for c := 0; c < st.numCols; c++ {
 				if c == ns.col {
 					glyphs[c] = GlyphStar
-				} else if active(rowNum, c) {
+					lanes[c] = ns.laneID
+				} else if id := laneAt(rowNum, c); id != 0 {
 					glyphs[c] = GlyphPipe
+					lanes[c] = id
 				} else {
 					glyphs[c] = GlyphSpace
 				}
@@ -927,7 +1090,7 @@ func (st *layoutState) generateRows(order []*nodeState) []Row {
 					extras++
 				}
 			}
-			rows = append(rows, Row{Commit: ns.Node, Glyphs: glyphs, Extras: extras})
+			rows = append(rows, Row{Commit: ns.Node, Glyphs: glyphs, Lanes: lanes, Extras: extras})
 		}
 	}
 
@@ -941,7 +1104,7 @@ func (st *layoutState) generateRows(order []*nodeState) []Row {
 // before the diagonal physically arrives -- this is git's `|\|` then
 // `| |\` pattern. Returns the start index of appended rows so the
 // caller can mutate (e.g. preview tail decoration).
-func (st *layoutState) forkRows(dst []Row, l lane, rowNum int, active func(int, int) bool) ([]Row, int) {
+func (st *layoutState) forkRows(dst []Row, l lane, rowNum int, laneAt func(int, int) int) ([]Row, int) {
 	dir := 1
 	glyph := GlyphBackslash
 	if l.introCol > l.col {
@@ -955,43 +1118,60 @@ func (st *layoutState) forkRows(dst []Row, l lane, rowNum int, active func(int,
 	start := len(dst)
 	for i := 0; i < d; i++ {
 		stepCol := l.introCol + dir*(i+1)
-		glyphs := st.nextRowGlyphs()
+		glyphs, lanes := st.nextRowGlyphs()
 		for c := 0; c < st.numCols; c++ {
 			switch {
 			case c == stepCol:
 				glyphs[c] = glyph
+				lanes[c] = l.id
 			case c == l.introCol:
 				glyphs[c] = GlyphPipe
+				lanes[c] = liveLane(laneAt, rowNum, c)
 			case c == l.col:
 				glyphs[c] = GlyphPipe
-			case active(rowNum, c) || active(rowNum-1, c):
-				glyphs[c] = GlyphPipe
+				lanes[c] = l.id
 			default:
-				glyphs[c] = GlyphSpace
+				if id := liveLane(laneAt, rowNum, c); id != 0 {
+					glyphs[c] = GlyphPipe
+					lanes[c] = id
+				} else {
+					glyphs[c] = GlyphSpace
+				}
 			}
 		}
-		dst = append(dst, Row{Glyphs: glyphs})
+		dst = append(dst, Row{Glyphs: glyphs, Lanes: lanes})
 	}
 	return dst, start
 }
 
+// liveLane is the lane drawn as a pass-through pipe in col c on a stagger
+// row placed at rowNum: whatever lane covers rowNum, else the one still
+// hanging on from the row above.
+func liveLane(laneAt func(int, int) int, rowNum, c int) int {
+	if id := laneAt(rowNum, c); id != 0 {
+		return id
+	}
+	return laneAt(rowNum-1, c)
+}
+
 // crossingStagger renders a catch-up termination via a routing col: a
 // fork from p.col out to routingCol, then a term from routingCol back to
 // ns.col. Used when the source col stays alive past p so the natural
 // `|/` would collide with the source col's vertical lane.
-func (st *layoutState) crossingStagger(dst []Row, p, ns *nodeState, routingCol, rowNum int, active func(int, int) bool) []Row {
-	forkLane := lane{col: routingCol, introCol: p.col, introRow: rowNum, endRow: rowNum}
-	dst, _ = st.forkRows(dst, forkLane, rowNum, active)
-	fakeP := &nodeState{col: routingCol}
-	dst = st.termRows(dst, fakeP, ns, rowNum, active)
+func (st *layoutState) crossingStagger(dst []Row, p, ns *nodeState, routingCol, rowNum int, laneAt func(int, int) int) []Row {
+	forkLane := lane{id: p.laneID, col: routingCol, introCol: p.col, introRow: rowNum, endRow: rowNum}
+	dst, _ = st.forkRows(dst, forkLane, rowNum, laneAt)
+	fakeP := &nodeState{col: routingCol, laneID: p.laneID}
+	dst = st.termRows(dst, fakeP, ns, rowNum, laneAt)
 	return dst
 }
 
 // termRows renders the stagger row(s) terminating an edge from parent p
 // into commit ns when they sit on different cols. The diagonal glyph
 // sits at the parent's col on step 0 and steps toward ns.col on later
-// steps; this matches git's `|/` (or `|\`) at the dying col.
-func (st *layoutState) termRows(dst []Row, p, ns *nodeState, rowNum int, active func(int, int) bool) []Row {
+// steps; this matches git's `|/` (or `|\`) at the dying col. The
+// diagonal keeps p's lane colour all the way into ns.
+func (st *layoutState) termRows(dst []Row, p, ns *nodeState, rowNum int, laneAt func(int, int) int) []Row {
 	if p.col == ns.col {
 		return dst
 	}
@@ -1007,20 +1187,28 @@ func (st *layoutState) termRows(dst []Row, p, ns *nodeState, rowNum int, active
 	}
 	for i := 0; i < d; i++ {
 		stepCol := p.col + dir*i
-		glyphs := st.nextRowGlyphs()
+		glyphs, lanes := st.nextRowGlyphs()
 		for c := 0; c < st.numCols; c++ {
 			switch {
 			case c == stepCol:
 				glyphs[c] = glyph
-			case c == p.col || c == ns.col:
+				lanes[c] = p.laneID
+			case c == p.col:
 				glyphs[c] = GlyphPipe
-			case active(rowNum, c) || active(rowNum-1, c):
+				lanes[c] = cmp.Or(liveLane(laneAt, rowNum, c), p.laneID)
+			case c == ns.col:
 				glyphs[c] = GlyphPipe
+				lanes[c] = cmp.Or(liveLane(laneAt, rowNum, c), ns.laneID)
 			default:
-				glyphs[c] = GlyphSpace
+				if id := liveLane(laneAt, rowNum, c); id != 0 {
+					glyphs[c] = GlyphPipe
+					lanes[c] = id
+				} else {
+					glyphs[c] = GlyphSpace
+				}
 			}
 		}
-		dst = append(dst, Row{Glyphs: glyphs})
+		dst = append(dst, Row{Glyphs: glyphs, Lanes: lanes})
 	}
 	return dst
 }
@@ -0,0 +1,272 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Exporters for a finished layout, for when the graph has to leave the
+// terminal (design docs, incident reports). Labels are written as given --
+// pass uncoloured ones; the exporters don't strip ANSI escapes.
+
+// WriteDOT writes the commit DAG behind lr as a Graphviz digraph. It's the
+// raw DAG -- one node per commit, one edge per parent -- and ignores the
+// engine's columns; Graphviz does its own placement. Edges point child ->
+// parent with rankdir=BT, so the oldest commits end up on top like Render's
+// output. Parents outside the layout (cut off by a --since, say) are left
+// out rather than drawn as empty nodes; elided markers (Prune) are dashed.
+func WriteDOT(w io.Writer, lr LayoutResult) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph history {\n")
+	bw.WriteString("\trankdir=BT;\n")
+	bw.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	present := make(map[string]bool, len(lr.Rows))
+	for _, row := range lr.Rows {
+		if row.Commit != nil {
+			present[row.Commit.ID] = true
+		}
+	}
+	for _, row := range lr.Rows {
+		if row.Commit == nil {
+			continue
+		}
+		style := ""
+		if row.Commit.Elided {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(bw, "\t%s [label=%s%s];\n", strconv.Quote(row.Commit.ID), strconv.Quote(row.Commit.Label), style)
+	}
+	for _, row := range lr.Rows {
+		if row.Commit == nil {
+			continue
+		}
+		for _, pid := range row.Commit.Parents {
+			if present[pid] {
+				fmt.Fprintf(bw, "\t%s -> %s;\n", strconv.Quote(row.Commit.ID), strconv.Quote(pid))
+			}
+		}
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// jsonLayout is the WriteJSON document. Field names are part of the
+// output format -- rename with care.
+type jsonLayout struct {
+	Columns  int       `json:"columns"`
+	LaneKeys []int64   `json:"lane_keys"`
+	Rows     []jsonRow `json:"rows"`
+}
+
+type jsonRow struct {
+	Commit *jsonNode `json:"commit"` // null on connector rows
+	Col    int       `json:"col"`    // the commit's column, -1 on connector rows
+	Glyphs string    `json:"glyphs"` // one character per column
+	Lanes  []int     `json:"lanes"`
+	Tail   string    `json:"tail,omitempty"`
+	Text   string    `json:"text"` // the row as Render prints it, unstyled
+}
+
+type jsonNode struct {
+	ID      string   `json:"id"`
+	Label   string   `json:"label"`
+	Parents []string `json:"parents"`
+	Epoch   int64    `json:"epoch"`
+	Lane    int64    `json:"lane,omitempty"`
+}
+
+// WriteJSON writes lr as one JSON document: the column count, lane keys,
+// and every row oldest-first with its glyphs, lane ids, commit (if any)
+// and the commit's column. Together row index and col give each node's
+// position in the drawn graph.
+func WriteJSON(w io.Writer, lr LayoutResult) error {
+	lines := Render(lr, Style{})
+	doc := jsonLayout{
+		Columns:  lr.Columns,
+		LaneKeys: lr.LaneKeys,
+		Rows:     make([]jsonRow, len(lr.Rows)),
+	}
+	if doc.LaneKeys == nil {
+		doc.LaneKeys = []int64{}
+	}
+	for i, row := range lr.Rows {
+		jr := jsonRow{
+			Col:    -1,
+			Glyphs: glyphString(row.Glyphs),
+			Lanes:  row.Lanes,
+			Tail:   glyphString(row.Tail),
+			Text:   lines[i],
+		}
+		if jr.Lanes == nil {
+			jr.Lanes = make([]int, len(row.Glyphs))
+		}
+		if c := row.Commit; c != nil {
+			parents := c.Parents
+			if parents == nil {
+				parents = []string{}
+			}
+			jr.Commit = &jsonNode{ID: c.ID, Label: c.Label, Parents: parents, Epoch: c.Epoch, Lane: c.Lane}
+			for col, g := range row.Glyphs {
+				if g == GlyphStar || g == GlyphFold || g == GlyphElided {
+					jr.Col = col
+					break
+				}
+			}
+		}
+		doc.Rows[i] = jr
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encoding layout: %w", err)
+	}
+	return nil
+}
+
+func glyphString(gs []Glyph) string {
+	var b strings.Builder
+	for _, g := range gs {
+		b.WriteString(g.String())
+	}
+	return b.String()
+}
+
+// svg geometry, in px. A slot is half a column, same as Render's packing.
+const (
+	svgSlotW  = 8
+	svgRowH   = 20
+	svgPad    = 10
+	svgCharW  = 7.2 // advance of 12px monospace, near enough for sizing
+	svgRadius = 4
+)
+
+// WriteSVG draws lr as a self-contained SVG: the same packed columns and
+// rows Render prints, with lines for `|`, `/` and `\`, dots for commits and
+// the label to the right; fold rows (LayoutOptions.Collapse) are dashed and
+// elided markers (Prune) are a dashed stub.
+// Colours come from st exactly as for Render, except the strings are SVG
+// paint values ("#d33", "teal") rather than ANSI prefixes: Palette /
+// LaneColor per lane, LinePrefix for lanes without one, StarPrefix for the
+// dots (lane colour when unset). Suffixes are ignored. A zero Style draws
+// everything in grey.
+func WriteSVG(w io.Writer, lr LayoutResult, st Style) error {
+	lanePaint := st.lanePrefixes(lr.LaneKeys)
+	paint := func(lane int) string {
+		if lanePaint != nil && lane > 0 && lane < len(lanePaint) && lanePaint[lane] != "" {
+			return lanePaint[lane]
+		}
+		if st.LinePrefix != "" {
+			return st.LinePrefix
+		}
+		return "#888"
+	}
+
+	// pack every row once up front: drawing a row needs its neighbours'
+	// slots to know which way the dots connect.
+	packed := make([][]slot, len(lr.Rows))
+	labelX := make([]int, len(lr.Rows))
+	width := 0
+	for i, row := range lr.Rows {
+		var buf []slot
+		packed[i] = packRow(&buf, row, lr.Columns)
+		labelX[i] = svgPad + (len(packed[i])+row.Extras*2+1)*svgSlotW
+		right := svgPad + len(packed[i])*svgSlotW
+		if row.Commit != nil {
+			right = labelX[i] + int(float64(len([]rune(row.Commit.Label)))*svgCharW)
+		}
+		width = max(width, right)
+	}
+
+	slotX := func(s int) int { return svgPad + s*svgSlotW + svgSlotW/2 }
+	rowY := func(r int) int { return svgPad + r*svgRowH }
+	// ends reports where a glyph touches the top and bottom edges of its
+	// row, as slot indexes (-1: doesn't).
+	ends := func(s slot, i int) (top, bottom int) {
+		switch s.g {
+		case GlyphPipe, GlyphStar, GlyphFold, GlyphElided:
+			return i, i
+		case GlyphSlash:
+			return i + 1, i - 1
+		case GlyphBackslash:
+			return i - 1, i + 1
+		}
+		return -1, -1
+	}
+	touches := func(r, at int, bottomEdge bool) bool {
+		if r < 0 || r >= len(packed) {
+			return false
+		}
+		for i, s := range packed[r] {
+			top, bottom := ends(s, i)
+			if (bottomEdge && bottom == at) || (!bottomEdge && top == at) {
+				return true
+			}
+		}
+		return false
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"monospace\" font-size=\"12\">\n",
+		width+svgPad, 2*svgPad+len(lr.Rows)*svgRowH)
+	bw.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"white\"/>\n")
+
+	// lines first so the dots sit on top of them
+	bw.WriteString("<g stroke-width=\"2\" stroke-linecap=\"round\" fill=\"none\">\n")
+	for r, slots := range packed {
+		y0, y1 := rowY(r), rowY(r+1)
+		for i, s := range slots {
+			switch s.g {
+			case GlyphPipe:
+				fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\"/>\n", slotX(i), y0, slotX(i), y1, html.EscapeString(paint(s.lane)))
+			case GlyphFold:
+				fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\" stroke-dasharray=\"2 3\"/>\n", slotX(i), y0, slotX(i), y1, html.EscapeString(paint(s.lane)))
+			case GlyphElided:
+				// a dashed stub down from the child, stopping mid-row
+				fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\" stroke-dasharray=\"2 3\"/>\n", slotX(i), y0, slotX(i), (y0+y1)/2, html.EscapeString(paint(s.lane)))
+			case GlyphSlash, GlyphBackslash:
+				top, bottom := ends(s, i)
+				fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\"/>\n", slotX(top), y0, slotX(bottom), y1, html.EscapeString(paint(s.lane)))
+			case GlyphStar:
+				// a dot only continues up or down where the neighbouring
+				// row actually meets it -- tips and roots stop short
+				mid := (y0 + y1) / 2
+				if touches(r-1, i, true) {
+					fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\"/>\n", slotX(i), y0, slotX(i), mid, html.EscapeString(paint(s.lane)))
+				}
+				if touches(r+1, i, false) {
+					fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\"/>\n", slotX(i), mid, slotX(i), y1, html.EscapeString(paint(s.lane)))
+				}
+			}
+		}
+	}
+	bw.WriteString("</g>\n")
+
+	for r, slots := range packed {
+		row := lr.Rows[r]
+		if row.Commit == nil {
+			continue
+		}
+		// fold rows keep their dashed line and label but get no dot
+		mid := (rowY(r) + rowY(r+1)) / 2
+		for i, s := range slots {
+			if s.g != GlyphStar {
+				continue
+			}
+			fill := st.StarPrefix
+			if fill == "" {
+				fill = paint(s.lane)
+			}
+			fmt.Fprintf(bw, "<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"%s\"><title>%s</title></circle>\n",
+				slotX(i), mid, svgRadius, html.EscapeString(fill), html.EscapeString(row.Commit.ID))
+		}
+		fmt.Fprintf(bw, "<text x=\"%d\" y=\"%d\" dominant-baseline=\"middle\">%s</text>\n", labelX[r], mid, html.EscapeString(row.Commit.Label))
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
@@ -0,0 +1,128 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/assert/require"
+	"github.com/lczyk/gitgum/src/graph"
+)
+
+// exportNodes is a fork + merge with a parent outside the set (as a
+// --since cutoff leaves behind) and a label that needs escaping.
+func exportNodes() []graph.Node {
+	return []graph.Node{
+		{ID: "base", Label: "base", Epoch: 1, Parents: []string{"gone"}},
+		{ID: "side", Label: `side "quoted" <b>`, Epoch: 2, Parents: []string{"base"}, Lane: 3},
+		{ID: "main", Label: "main", Epoch: 3, Parents: []string{"base"}, Lane: 4},
+		{ID: "merge", Label: "merge", Epoch: 4, Parents: []string{"main", "side"}, Lane: 4},
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteDOT(&buf, graph.Layout(exportNodes(), graph.LayoutOptions{})))
+
+	expected := `digraph history {
+	rankdir=BT;
+	node [shape=box, fontname="monospace"];
+	"base" [label="base"];
+	"main" [label="main"];
+	"side" [label="side \"quoted\" <b>"];
+	"merge" [label="merge"];
+	"main" -> "base";
+	"side" -> "base";
+	"merge" -> "main";
+	"merge" -> "side";
+}
+`
+	assert.Equal(t, buf.String(), expected)
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+	nodes := exportNodes()
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteJSON(&buf, lr))
+
+	var doc struct {
+		Columns  int     `json:"columns"`
+		LaneKeys []int64 `json:"lane_keys"`
+		Rows     []struct {
+			Commit *struct {
+				ID      string   `json:"id"`
+				Parents []string `json:"parents"`
+			} `json:"commit"`
+			Col    int    `json:"col"`
+			Glyphs string `json:"glyphs"`
+			Lanes  []int  `json:"lanes"`
+			Text   string `json:"text"`
+		} `json:"rows"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	lines := graph.Render(lr, graph.Style{})
+	assert.Equal(t, doc.Columns, lr.Columns)
+	assert.Equal(t, len(doc.LaneKeys), len(lr.LaneKeys))
+	require.Equal(t, len(doc.Rows), len(lr.Rows))
+	commits := 0
+	for i, row := range doc.Rows {
+		assert.Equal(t, row.Text, lines[i])
+		assert.Equal(t, len(row.Glyphs), lr.Columns)
+		assert.Equal(t, len(row.Lanes), lr.Columns)
+		if row.Commit == nil {
+			assert.Equal(t, row.Col, -1)
+			continue
+		}
+		commits++
+		assert.Equal(t, row.Commit.ID, lr.Rows[i].Commit.ID)
+		assert.Equal(t, row.Glyphs[row.Col], byte('*'))
+	}
+	assert.Equal(t, commits, len(nodes))
+}
+
+func TestWriteSVG(t *testing.T) {
+	t.Parallel()
+	nodes := exportNodes()
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
+	st := graph.Style{Palette: []string{"#a00", "#0a0", "#00a", "#aa0"}}
+	var buf bytes.Buffer
+	require.NoError(t, graph.WriteSVG(&buf, lr, st))
+	out := buf.String()
+
+	// well-formed, one dot per commit, labels escaped into text nodes
+	dec := xml.NewDecoder(strings.NewReader(out))
+	circles := 0
+	var texts []string
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if se, ok := tok.(xml.StartElement); ok {
+			switch se.Name.Local {
+			case "circle":
+				circles++
+			case "text":
+				var s string
+				require.NoError(t, dec.DecodeElement(&s, &se))
+				texts = append(texts, s)
+			}
+		}
+	}
+	assert.Equal(t, circles, len(nodes))
+	assert.ContainsString(t, strings.Join(texts, "\n"), `side "quoted" <b>`)
+
+	// keyed lanes pick their palette colour like Render: main 4%4, side 3%4
+	assert.ContainsString(t, out, `stroke="#a00"`)
+	assert.ContainsString(t, out, `stroke="#aa0"`)
+}
@@ -5,8 +5,8 @@
 //
 // Typical usage:
 //
-//	lr := graph.Layout(nodes)
-//	lines := graph.Render(lr, graph.Style{}) // or pass a populated Style
+//	lr := graph.Layout(nodes, graph.LayoutOptions{}) // or pick an Order
+//	lines := graph.Render(lr, graph.Style{})         // or pass a populated Style
 package graph
 
 // Node is a vertex in the commit DAG. Parents is a forward edge list
@@ -33,6 +33,52 @@ type Node struct {
 	Parents []string // parent IDs (empty for roots)
 	Epoch   int64    // sort key (commonly unix epoch seconds; any monotonic int works). Optional -- when all Epochs are equal (incl. zero), nodes tiebreak by ID for deterministic layout.
 	Lane    int64
+	// Elided marks a stand-in for a parent cut away by Prune. It's drawn
+	// with GlyphElided instead of GlyphStar.
+	Elided bool
+}
+
+// Order selects how Layout places commits into rows. Every mode produces a
+// valid topological order (children above parents in newest-first terms);
+// they differ in how parallel lines of history interleave.
+type Order int
+
+const (
+	// OrderEngine is the default: depth-first into non-first parents right
+	// after a merge, first-parent continuations through a date-ordered
+	// queue. Keeps merged side branches next to their merge.
+	OrderEngine Order = iota
+	// OrderDate mirrors `git log --date-order`: a single date-priority
+	// queue, so concurrent branches interleave by Epoch.
+	OrderDate
+	// OrderTopo mirrors `git log --topo-order`: a LIFO of ready commits,
+	// which shows a merged branch's commits right after the merge and
+	// never intermixes two lines of history.
+	OrderTopo
+	// OrderFirstParent keeps each first-parent chain contiguous for as long
+	// as it's ready; side branches are placed after the mainline run that
+	// merged them.
+	OrderFirstParent
+)
+
+// LayoutOptions tunes Layout. The zero value is the engine default.
+type LayoutOptions struct {
+	Order Order
+
+	// Collapse, when > 0, folds every run of at least Collapse linear
+	// commits -- one parent, one child, not kept by Keep -- into a single
+	// fold row: GlyphFold in the run's lane and an "N commits" label. The
+	// lanes around the run are laid out as if it were one commit. Row.Folded
+	// lists what a fold row stands for.
+	Collapse int
+	// Keep reports commits that must stay visible even inside a foldable
+	// run -- ref tips, or runs a user has expanded. nil keeps none.
+	Keep func(n *Node) bool
+
+	// head, when set, is placed in the newest row ahead of any other ready
+	// node so it lands on col 0. Stream uses it to line a segment's top up
+	// with the previous segment's bottom.
+	head string
 }
 
 // Glyph is a single graph-drawing character in one column of one row.
@@ -44,11 +90,13 @@ const (
 	GlyphStar                   // "*"
 	GlyphSlash                  // "/"
 	GlyphBackslash              // "\"
+	GlyphFold                   // "⋮", a collapsed run (LayoutOptions.Collapse)
+	GlyphElided                 // "~", a parent left out of the view (Prune)
 )
 
-// String returns the single-character ASCII representation of g. Panics
-// on values outside the iota range -- those represent internal corruption
-// rather than user error.
+// String returns the single-character representation of g -- ASCII for
+// all but GlyphFold. Panics on values outside the iota range -- those
+// represent internal corruption rather than user error.
 func (g Glyph) String() string {
 	switch g {
 	case GlyphSpace:
@@ -61,6 +109,10 @@ func (g Glyph) String() string {
 		return "/"
 	case GlyphBackslash:
 		return "\\"
+	case GlyphFold:
+		return "⋮"
+	case GlyphElided:
+		return "~"
 	}
 	panic("graph: unknown Glyph value")
 }
@@ -69,10 +121,39 @@ func (g Glyph) String() string {
 // LineSuffix wrap the line glyphs (`|`, `/`, `\`). StarPrefix / StarSuffix
 // wrap commit markers (`*`). Spaces are written unwrapped.
 //
+// Palette switches line glyphs to per-lane colouring: every glyph is
+// wrapped in the prefix picked for the lane it belongs to, closed with
+// LineSuffix. A lane keeps its colour from fork to merge. Lanes with a
+// non-zero key (see LayoutResult.LaneKeys) index the palette by key, so
+// callers that hash branch names get colours that stay put across runs;
+// anonymous lanes cycle through the palette in order of appearance.
+// LaneColor, when set, is asked first for keyed lanes and may return ""
+// to defer to the palette. Glyphs with no owning lane fall back to
+// LinePrefix.
+//
+// Node, when set, styles commits one at a time (see NodeStyle). A dimmed
+// commit takes the edges to its parents with it: those glyphs are wrapped
+// in DimPrefix instead of their lane colour, closed with LineSuffix.
+//
 // The zero Style produces plain ASCII output with no escapes.
 type Style struct {
 	LinePrefix, LineSuffix string
 	StarPrefix, StarSuffix string
+	Palette                []string
+	LaneColor              func(key int64) string
+	Node                   func(n *Node) NodeStyle
+	DimPrefix              string
+}
+
+// NodeStyle is what Style.Node returns for one commit. The zero value
+// draws the commit as usual.
+type NodeStyle struct {
+	// Dim draws the commit's marker and the edges to its parents with
+	// Style.DimPrefix. The label is the caller's to dim.
+	Dim bool
+	// Mark, when set, is drawn in place of the commit's `*` -- a merge
+	// base, say. It should be one cell wide.
+	Mark string
 }
 
 // Row is one output line. Commit is nil on stagger / continuation rows
@@ -81,7 +162,14 @@ type Style struct {
 // Node through it.
 type Row struct {
 	Commit *Node
+	// Folded is set on fold rows (LayoutOptions.Collapse): the commits the
+	// row stands for, newest first, pointing into the input slice. Commit is
+	// then a stand-in owned by the layout, carrying the newest commit's ID.
+	Folded []*Node
 	Glyphs []Glyph // len == LayoutResult.Columns
+	// Lanes parallels Glyphs: the id of the lane each glyph is drawn for,
+	// 0 for spaces. Ids index LayoutResult.LaneKeys.
+	Lanes []int
 	// Extras is the number of `*-to-label` alignment-padding slots a merge
 	// commit needs. Computed by Layout from how many non-first parents end
 	// up in cols different from the commit's own col -- matches git's
@@ -92,12 +180,18 @@ type Row struct {
 	// slots, before any extras/label. Used for transient single-row
 	// decorations (e.g. the trailing `|` that turns a fork stagger `|\`
 	// into git's `|\|` shape) without widening LayoutResult.Columns.
-	Tail []Glyph
+	Tail      []Glyph
+	TailLanes []int // parallels Tail, same meaning as Lanes
 }
 
 // LayoutResult is the computed output of Layout. Rows is in oldest-first
 // display order. Columns is the maximum lane index used.
+//
+// LaneKeys maps a lane id (Row.Lanes) to the Node.Lane of the newest
+// commit in that lane, or 0 when none of its commits carry one. Index 0
+// is the "no lane" sentinel.
 type LayoutResult struct {
-	Rows    []Row
-	Columns int
+	Rows     []Row
+	Columns  int
+	LaneKeys []int64
 }
@@ -182,14 +182,14 @@ func octopusFan(k int) []graph.Node {
 func benchLayout(b *testing.B, nodes []graph.Node) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		_ = graph.Layout(nodes)
+		_ = graph.Layout(nodes, graph.LayoutOptions{})
 	}
 }
 
 func benchLayoutAndRender(b *testing.B, nodes []graph.Node) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		lr := graph.Layout(nodes)
+		lr := graph.Layout(nodes, graph.LayoutOptions{})
 		_ = graph.Render(lr, graph.Style{})
 	}
 }
@@ -215,7 +215,7 @@ func BenchmarkRender_Parallel100x10(b *testing.B) {
 // loop so regressions in the per-row formatter / slot packer surface
 // independently of layout work.
 func benchRenderOnly(b *testing.B, nodes []graph.Node, st graph.Style) {
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -273,3 +273,21 @@ func BenchmarkRender_SharedDual1000(b *testing.B) {
 	benchLayoutAndRender(b, sharedParentDualMerge(1000))
 }
 func BenchmarkRender_Octopus100(b *testing.B) { benchLayoutAndRender(b, octopusFan(100)) }
+
+// benchStream feeds nodes newest-first through a Stream, the way gg tree
+// reads git log. Compare against BenchmarkLayout_* for the segmenting cost.
+func benchStream(b *testing.B, nodes []graph.Node) {
+	feed := newestFirst(nodes, graph.LayoutOptions{Order: graph.OrderDate})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := graph.NewStream(graph.LayoutOptions{Order: graph.OrderDate}, func(graph.LayoutResult) {})
+		for _, n := range feed {
+			s.Push(n)
+		}
+		s.Flush()
+	}
+}
+
+func BenchmarkStream_Linear10000(b *testing.B) { benchStream(b, linearChain(10000)) }
+func BenchmarkStream_Merges1000(b *testing.B)  { benchStream(b, mergeSeries(1000, 5)) }
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/lczyk/assert"
@@ -11,7 +12,7 @@ import (
 
 func TestRender_Empty(t *testing.T) {
 	t.Parallel()
-	lr := Layout(nil)
+	lr := Layout(nil, LayoutOptions{})
 	assert.Equal(t, len(lr.Rows), 0)
 	assert.Equal(t, lr.Columns, 0)
 	lines := Render(lr, Style{})
@@ -26,7 +27,7 @@ func TestRender_TopologicalCorrection(t *testing.T) {
 		{ID: "child", Label: "c child", Parents: []string{"parent"}, Epoch: 0},
 	}
 
-	lr := Layout(nodes)
+	lr := Layout(nodes, LayoutOptions{})
 
 	// Verify internal row assignment: parent must be at lower row than child.
 	pState := findNode(lr, "parent")
@@ -66,3 +67,36 @@ func rowIndex(lr LayoutResult, id string) int {
 	}
 	return -1
 }
+
+func TestStream_BacksOffAfterFailedCut(t *testing.T) {
+	t.Parallel()
+	// y0 and its orphan root y1 come either side of the mainline's tip,
+	// so y takes col 0 and the mainline never gets it back: no cut can
+	// line up, and each try lays out the whole buffer
+	nodes := []Node{
+		{ID: "y0", Label: "y0", Epoch: 3000, Parents: []string{"y1"}},
+		{ID: "c0", Label: "c0", Epoch: 2999, Parents: []string{"c1"}},
+		{ID: "y1", Label: "y1", Epoch: 2998},
+	}
+	for i := 1; i < 2000; i++ {
+		n := Node{ID: fmt.Sprintf("c%d", i), Label: "c", Epoch: int64(2000 - i)}
+		if i < 1999 {
+			n.Parents = []string{fmt.Sprintf("c%d", i+1)}
+		}
+		nodes = append(nodes, n)
+	}
+	segs := 0
+	s := NewStream(LayoutOptions{Order: OrderDate}, func(LayoutResult) { segs++ })
+	failed := 0
+	for _, n := range nodes {
+		retry := s.retry
+		s.Push(n)
+		if s.retry != retry {
+			failed++
+		}
+	}
+	s.Flush()
+	assert.Equal(t, segs, 1)
+	// tried at 256, 512, 1024 and 2048 commits, not at every one past 256
+	assert.That(t, failed > 0 && failed <= 4, "failed cuts: %d", failed)
+}
@@ -19,7 +19,7 @@ func TestRender_Linear(t *testing.T) {
 		{ID: "c", Label: "c third commit", Parents: []string{"b"}, Epoch: 200},
 	}
 
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, graph.Style{})
 
 	// Oldest first: a, then b, then c. No branching, so all in column 0.
@@ -55,7 +55,7 @@ func TestRender_Fork(t *testing.T) {
 		{ID: "c", Label: "c branch2", Parents: []string{"a"}, Epoch: 101},
 	}
 
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, graph.Style{})
 
 	// Two columns: main branch (a→b) in col 0, branch2 (c) in col 1.
@@ -82,7 +82,7 @@ func TestRender_Merge(t *testing.T) {
 		{ID: "d", Label: "d merge", Parents: []string{"b", "c"}, Epoch: 200},
 	}
 
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, graph.Style{})
 
 	// Should have 2 columns at the merge point.
@@ -110,7 +110,7 @@ func TestRender_Style(t *testing.T) {
 		LinePrefix: "<L>", LineSuffix: "</L>",
 		StarPrefix: "<S>", StarSuffix: "</S>",
 	}
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, st)
 	assert.That(t, len(lines) >= 3, "at least 3 rows produced")
 	// First row: "* base" -> star wrapped, then ' base'.
@@ -120,13 +120,108 @@ func TestRender_Style(t *testing.T) {
 	assert.ContainsString(t, stagger, "<L>", "stagger row uses LinePrefix")
 }
 
+func TestRender_LanePalette(t *testing.T) {
+	t.Parallel()
+	// Side lane carries its colour from the fork stagger, through its
+	// commits, into the merge stagger; mainline keeps its own throughout.
+	nodes := []graph.Node{
+		{ID: "base", Label: "base", Epoch: 1},
+		{ID: "side1", Label: "side1", Epoch: 2, Parents: []string{"base"}},
+		{ID: "side2", Label: "side2", Epoch: 3, Parents: []string{"side1"}, Lane: 3},
+		{ID: "main1", Label: "main1", Epoch: 4, Parents: []string{"base"}, Lane: 4},
+		{ID: "merge", Label: "merge", Epoch: 5, Parents: []string{"main1", "side2"}, Lane: 4},
+	}
+	st := graph.Style{
+		LineSuffix: "</>",
+		Palette:    []string{"<a>", "<b>", "<c>", "<d>"},
+	}
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
+	lines := graph.Render(lr, st)
+
+	// keyed lanes index the palette by key: main 4%4 -> <a>, side 3%4 -> <d>
+	expected := []string{
+		"* base",
+		"<a>|</><d>\\</>",
+		"* <d>|</> main1",
+		"<a>|</> * side1",
+		"<a>|</> * side2",
+		"<a>|</><d>/</>",
+		"*   merge",
+	}
+	assert.Equal(t, len(lines), len(expected))
+	for i := range expected {
+		assert.Equal(t, strings.TrimRight(lines[i], " "), expected[i])
+	}
+}
+
+func TestRender_LaneColorOverride(t *testing.T) {
+	t.Parallel()
+	nodes := []graph.Node{
+		{ID: "base", Label: "base", Epoch: 1, Lane: 1},
+		{ID: "side", Label: "side", Epoch: 2, Parents: []string{"base"}, Lane: 2},
+		{ID: "main", Label: "main", Epoch: 3, Parents: []string{"base", "side"}, Lane: 1},
+	}
+	st := graph.Style{
+		LinePrefix: "<L>", LineSuffix: "</>",
+		Palette: []string{"<a>"},
+		LaneColor: func(key int64) string {
+			if key == 2 {
+				return "<side>"
+			}
+			return "" // defer to palette
+		},
+	}
+	lines := graph.Render(graph.Layout(nodes, graph.LayoutOptions{}), st)
+	joined := strings.Join(lines, "\n")
+	assert.ContainsString(t, joined, "<side>", "override applied to the side lane")
+	assert.ContainsString(t, joined, "<a>|", "mainline falls back to the palette")
+	assert.That(t, !strings.Contains(joined, "<L>"), "LinePrefix unused when every glyph has a lane")
+}
+
+func TestRender_NodeStyle(t *testing.T) {
+	t.Parallel()
+	// side1 and everything under it are lit; main1 and the merge are
+	// dimmed, and so are the edges from them to their parents -- including
+	// the merge's edge into the lit side branch.
+	nodes := []graph.Node{
+		{ID: "base", Label: "base", Epoch: 1},
+		{ID: "side1", Label: "side1", Epoch: 2, Parents: []string{"base"}},
+		{ID: "main1", Label: "main1", Epoch: 3, Parents: []string{"base"}},
+		{ID: "merge", Label: "merge", Epoch: 4, Parents: []string{"main1", "side1"}},
+	}
+	lit := map[string]bool{"base": true, "side1": true}
+	st := graph.Style{
+		LinePrefix: "<L>", LineSuffix: "</>", DimPrefix: "<dim>",
+		Node: func(n *graph.Node) graph.NodeStyle {
+			ns := graph.NodeStyle{Dim: !lit[n.ID]}
+			if n.ID == "base" {
+				ns.Mark = "o"
+			}
+			return ns
+		},
+	}
+	lines := graph.Render(graph.Layout(nodes, graph.LayoutOptions{Order: graph.OrderDate}), st)
+	expected := []string{
+		"o base",
+		"<dim>|</><L>\\</>",
+		"<dim>|</> * side1",
+		"<dim>*</> <dim>|</> main1",
+		"<dim>|</><dim>/</>",
+		"<dim>*</>   merge",
+	}
+	assert.Equal(t, len(lines), len(expected))
+	for i := range expected {
+		assert.Equal(t, strings.TrimRight(lines[i], " "), expected[i])
+	}
+}
+
 func TestRender_SingleNode(t *testing.T) {
 	t.Parallel()
 	nodes := []graph.Node{
 		{ID: "root", Label: "root initial", Parents: nil, Epoch: 1},
 	}
 
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, graph.Style{})
 
 	assert.Equal(t, len(lines), 1)
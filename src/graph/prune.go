@@ -0,0 +1,42 @@
+package graph
+
+import "strconv"
+
+// Prune cuts the parent edges of n that keep rejects -- parents outside a
+// filtered view, such as the side branches of a first-parent history -- and
+// points each one at an elided marker instead. It returns the pruned node
+// and the markers, which go into the layout right after it.
+//
+// A marker is a parentless Node with Elided set, n's Epoch, no Lane and
+// the cut parent's ID as Label (callers usually relabel it). It's drawn as
+// GlyphElided one row under its child, so the edge ends on an explicit stub
+// instead of a lane dangling off towards a commit that isn't there. Each
+// cut edge gets its own marker, with an ID built from n.ID and the edge's
+// index, so markers are unique as long as the real IDs are.
+//
+// keep is asked once per parent, with the parent's index in n.Parents. n
+// comes back unchanged (and no markers) when every edge is kept.
+func Prune(n Node, keep func(parent string, i int) bool) (Node, []Node) {
+	var markers []Node
+	var parents []string
+	for i, pid := range n.Parents {
+		if keep(pid, i) {
+			if parents != nil {
+				parents = append(parents, pid)
+			}
+			continue
+		}
+		if parents == nil {
+			// first cut: copy, the caller's Parents slice stays as it was
+			parents = append(make([]string, 0, len(n.Parents)), n.Parents[:i]...)
+		}
+		m := Node{ID: n.ID + "~" + strconv.Itoa(i), Label: pid, Epoch: n.Epoch, Elided: true}
+		parents = append(parents, m.ID)
+		markers = append(markers, m)
+	}
+	if markers == nil {
+		return n, nil
+	}
+	n.Parents = parents
+	return n, markers
+}
@@ -0,0 +1,80 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/src/graph"
+)
+
+// firstParentView is a mainline with one merge, as `git log --first-parent`
+// hands it over: the merged side branch (f1) never shows up, only M's edge
+// to it does.
+func firstParentView() []graph.Node {
+	in := []graph.Node{
+		{ID: "root", Label: "root", Epoch: 0},
+		{ID: "m1", Label: "m1", Epoch: 1, Parents: []string{"root"}},
+		{ID: "m2", Label: "m2", Epoch: 3, Parents: []string{"m1"}},
+		{ID: "M", Label: "M", Epoch: 4, Parents: []string{"m2", "f1"}},
+		{ID: "m3", Label: "m3", Epoch: 5, Parents: []string{"M"}},
+	}
+	var out []graph.Node
+	for _, n := range in {
+		n, markers := graph.Prune(n, func(_ string, i int) bool { return i == 0 })
+		out = append(out, n)
+		for _, m := range markers {
+			m.Label = "elided " + m.Label
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+	parents := []string{"a", "b", "c"}
+	n := graph.Node{ID: "M", Parents: parents, Epoch: 7}
+
+	same, markers := graph.Prune(n, func(string, int) bool { return true })
+	assert.Equal(t, len(markers), 0)
+	assert.EqualArrays(t, same.Parents, parents)
+
+	pruned, markers := graph.Prune(n, func(p string, _ int) bool { return p != "b" })
+	assert.EqualArrays(t, pruned.Parents, []string{"a", "M~1", "c"})
+	assert.Equal(t, len(markers), 1)
+	m := markers[0]
+	assert.Equal(t, m.ID, "M~1")
+	assert.Equal(t, m.Label, "b")
+	assert.Equal(t, m.Epoch, int64(7))
+	assert.That(t, m.Elided && len(m.Parents) == 0, "marker should be a parentless elided node")
+	// the caller's slice is left alone
+	assert.EqualArrays(t, parents, []string{"a", "b", "c"})
+}
+
+func TestScenario_PruneFirstParent(t *testing.T) {
+	t.Parallel()
+	// the cut edge ends on a stub right under its merge, whatever the order
+	expected := `* root
+* m1
+* m2
+| ~ elided f1
+|/
+*   M
+* m3`
+	for _, order := range []graph.Order{graph.OrderEngine, graph.OrderDate, graph.OrderTopo, graph.OrderFirstParent} {
+		assertGraphWith(t, firstParentView(), graph.LayoutOptions{Order: order}, expected)
+	}
+}
+
+func TestPrune_ElidedGlyph(t *testing.T) {
+	t.Parallel()
+	lr := graph.Layout(firstParentView(), graph.LayoutOptions{})
+	var elided int
+	for _, row := range lr.Rows {
+		if row.Commit != nil && row.Commit.Elided {
+			elided++
+			assert.Equal(t, row.Glyphs[1], graph.GlyphElided)
+		}
+	}
+	assert.Equal(t, elided, 1)
+}
@@ -16,31 +16,44 @@ func Render(lr LayoutResult, st Style) []string {
 		return nil
 	}
 	// Reused across rows; renderRowInto truncates to zero before refilling.
-	slots := make([]Glyph, 0, 2*lr.Columns+4)
+	slots := make([]slot, 0, 2*lr.Columns+4)
+	// Per-lane-id prefixes, resolved once up front so the row loop is a
+	// plain index. nil when the style isn't lane-coloured.
+	lanePrefixes := st.lanePrefixes(lr.LaneKeys)
 	// Rough estimate: 2 chars/col + per-row label budget. Over-allocate
 	// modestly so the buffer rarely grows -- a few growslice events are
 	// much cheaper than one alloc per row.
 	estBytes := 0
+	styleOverhead := len(st.LinePrefix) + len(st.LineSuffix) + len(st.StarPrefix) + len(st.StarSuffix)
+	for _, p := range st.Palette {
+		styleOverhead = max(styleOverhead, 2*(len(p)+len(st.LineSuffix)))
+	}
 	for _, row := range lr.Rows {
 		estBytes += 2 * lr.Columns
 		if row.Commit != nil {
 			estBytes += len(row.Commit.Label) + row.Extras*2 + 1
 		}
-		if styleOverhead := len(st.LinePrefix) + len(st.LineSuffix) + len(st.StarPrefix) + len(st.StarSuffix); styleOverhead > 0 {
+		if styleOverhead > 0 {
 			estBytes += styleOverhead * 4
 		}
 	}
 	buf := make([]byte, 0, estBytes)
-	offsets := make([]int, len(lr.Rows)+1)
-	for i, row := range lr.Rows {
-		offsets[i] = len(buf)
-		buf = renderRowInto(buf, &slots, row, lr.Columns, st)
+	// rows go in newest first, so a Style.Node pass sees each commit
+	// before the edges to its parents
+	var hl *dimmer
+	if st.Node != nil {
+		hl = &dimmer{dim: make([]bool, len(lr.LaneKeys)), seen: make([]bool, len(lr.LaneKeys))}
+	}
+	offsets := make([]int, 2*len(lr.Rows))
+	for i := len(lr.Rows) - 1; i >= 0; i-- {
+		offsets[2*i] = len(buf)
+		buf = renderRowInto(buf, &slots, lr.Rows[i], lr.Columns, st, lanePrefixes, hl)
+		offsets[2*i+1] = len(buf)
 	}
-	offsets[len(lr.Rows)] = len(buf)
 
 	lines := make([]string, len(lr.Rows))
 	for i := range lines {
-		start, end := offsets[i], offsets[i+1]
+		start, end := offsets[2*i], offsets[2*i+1]
 		if start == end {
 			continue // leave as ""
 		}
@@ -52,7 +65,84 @@ func Render(lr LayoutResult, st Style) []string {
 	return lines
 }
 
-func renderRowInto(buf []byte, slotsBuf *[]Glyph, row Row, numCols int, st Style) []byte {
+// lanePrefixes resolves the line prefix for every lane id in keys per the
+// Palette / LaneColor rules documented on Style. Returns nil when neither
+// is set, which keeps the plain LinePrefix path.
+func (st Style) lanePrefixes(keys []int64) []string {
+	if len(st.Palette) == 0 && st.LaneColor == nil {
+		return nil
+	}
+	out := make([]string, len(keys))
+	n := uint64(len(st.Palette))
+	for id := 1; id < len(keys); id++ {
+		key := keys[id]
+		if key != 0 && st.LaneColor != nil {
+			if p := st.LaneColor(key); p != "" {
+				out[id] = p
+				continue
+			}
+		}
+		switch {
+		case n == 0:
+			out[id] = st.LinePrefix
+		case key != 0:
+			out[id] = st.Palette[uint64(key)%n]
+		default:
+			out[id] = st.Palette[uint64(id-1)%n]
+		}
+	}
+	return out
+}
+
+// slot is one output cell of the packed row: a glyph plus the lane it's
+// drawn for (0 = none), so diagonals that slide into a neighbouring slot
+// keep their own lane colour. dim is set by a Style.Node pass.
+type slot struct {
+	g    Glyph
+	lane int
+	dim  bool
+}
+
+// dimmer carries Style.Node dimming up the graph. Rows are fed newest
+// first; a lane's glyphs take the state of the last commit seen in it,
+// which is the child end of the edges they draw. A lane that shows up
+// before any of its commits is the side of a merge, and takes the state
+// of the commit below it -- the merge.
+type dimmer struct {
+	dim, seen []bool // by lane id
+	last      bool   // the latest commit row's Dim
+}
+
+func (d *dimmer) row(row Row, slots []slot, ns NodeStyle) {
+	if row.Commit != nil {
+		d.last = ns.Dim
+		for _, s := range slots {
+			if s.g == GlyphStar || s.g == GlyphFold || s.g == GlyphElided {
+				if s.lane > 0 && s.lane < len(d.dim) {
+					d.dim[s.lane], d.seen[s.lane] = ns.Dim, true
+				}
+				break
+			}
+		}
+	}
+	for i, s := range slots {
+		switch {
+		case s.g == GlyphSpace:
+		case s.lane <= 0 || s.lane >= len(d.dim):
+			slots[i].dim = d.last
+		default:
+			if !d.seen[s.lane] {
+				d.dim[s.lane], d.seen[s.lane] = d.last, true
+			}
+			slots[i].dim = d.dim[s.lane]
+		}
+	}
+}
+
+// packRow lays row's glyphs out into output slots (two per col) and
+// returns the ones that are drawn, Tail included: nil for an all-space
+// stagger row. slotsBuf is reused across rows.
+func packRow(slotsBuf *[]slot, row Row, numCols int) []slot {
 	// Build slot grid by packing left-to-right. Diagonals (`/`, `\`) slide
 	// into the previous col's trailing-space slot, and the next col's
 	// primary slides up too -- this is git's compressed `|\|` cross-routing
@@ -60,20 +150,23 @@ func renderRowInto(buf []byte, slotsBuf *[]Glyph, row Row, numCols int, st Style
 	slots := (*slotsBuf)[:0]
 	for c := 0; c < numCols; c++ {
 		g := row.Glyphs[c]
-		if (g == GlyphSlash || g == GlyphBackslash) && len(slots) > 0 && slots[len(slots)-1] == GlyphSpace {
-			slots[len(slots)-1] = g
+		var id int
+		if row.Lanes != nil {
+			id = row.Lanes[c]
+		}
+		if (g == GlyphSlash || g == GlyphBackslash) && len(slots) > 0 && slots[len(slots)-1].g == GlyphSpace {
+			slots[len(slots)-1] = slot{g: g, lane: id}
 			continue
 		}
 		if g == GlyphSpace {
-			slots = append(slots, GlyphSpace, GlyphSpace)
+			slots = append(slots, slot{}, slot{})
 		} else {
-			slots = append(slots, g, GlyphSpace)
+			slots = append(slots, slot{g: g, lane: id}, slot{})
 		}
 	}
 	for len(slots) < 2*numCols {
-		slots = append(slots, GlyphSpace)
+		slots = append(slots, slot{})
 	}
-	*slotsBuf = slots
 
 	// Determine right edge: stagger rows render up to the rightmost col with
 	// non-space content; commit rows do the same (with at least col 0).
@@ -85,7 +178,8 @@ func renderRowInto(buf []byte, slotsBuf *[]Glyph, row Row, numCols int, st Style
 	}
 	if row.Commit == nil {
 		if lastActive < 0 {
-			return buf
+			*slotsBuf = slots
+			return nil
 		}
 	} else if lastActive < 0 {
 		lastActive = 0
@@ -97,14 +191,36 @@ func renderRowInto(buf []byte, slotsBuf *[]Glyph, row Row, numCols int, st Style
 	// `|` in git's `|\|` weave) without growing numCols. Trim col-pad
 	// spaces so the tail abuts the last meaningful slot.
 	if len(row.Tail) > 0 {
-		for slotEnd > 0 && slots[slotEnd-1] == GlyphSpace {
+		for slotEnd > 0 && slots[slotEnd-1].g == GlyphSpace {
 			slotEnd--
 		}
+		slots = slots[:slotEnd]
+		for i, g := range row.Tail {
+			var id int
+			if i < len(row.TailLanes) {
+				id = row.TailLanes[i]
+			}
+			slots = append(slots, slot{g: g, lane: id})
+		}
+		slotEnd = len(slots)
 	}
-	buf = writeSlotsTo(buf, slots[:slotEnd], st)
-	if len(row.Tail) > 0 {
-		buf = writeSlotsTo(buf, row.Tail, st)
+	*slotsBuf = slots
+	return slots[:slotEnd]
+}
+
+func renderRowInto(buf []byte, slotsBuf *[]slot, row Row, numCols int, st Style, lanePrefixes []string, hl *dimmer) []byte {
+	slots := packRow(slotsBuf, row, numCols)
+	if slots == nil {
+		return buf
 	}
+	var ns NodeStyle
+	if hl != nil {
+		if row.Commit != nil {
+			ns = st.Node(row.Commit)
+		}
+		hl.row(row, slots, ns)
+	}
+	buf = writeSlotsTo(buf, slots, st, lanePrefixes, ns.Mark)
 
 	if row.Commit == nil {
 		return buf
@@ -123,28 +239,52 @@ func renderRowInto(buf []byte, slotsBuf *[]Glyph, row Row, numCols int, st Style
 	return buf
 }
 
-// writeSlotsTo emits glyph runs of identical Glyph as a single styled
-// write. Lines (`|`/`/`/`\`) are wrapped with Style.LinePrefix/LineSuffix,
-// stars with Style.StarPrefix/StarSuffix; spaces and unstyled cases go
-// straight to the buffer.
-func writeSlotsTo(buf []byte, slots []Glyph, st Style) []byte {
+// writeSlotsTo emits runs of identical glyphs as a single styled write.
+// Lines (`|`/`/`/`\`) are wrapped with the lane's prefix (when
+// lanePrefixes is set and the slot has an owning lane) or
+// Style.LinePrefix, closed with LineSuffix; stars with Style.StarPrefix /
+// StarSuffix, or written as mark when one is given. Dimmed slots take
+// Style.DimPrefix over any of those. Spaces and unstyled cases go
+// straight to the buffer. A run breaks when the line prefix changes, so
+// adjacent lanes keep distinct colours.
+func writeSlotsTo(buf []byte, slots []slot, st Style, lanePrefixes []string, mark string) []byte {
 	if len(slots) == 0 {
 		return buf
 	}
+	prefixOf := func(s slot) string {
+		if s.dim {
+			return st.DimPrefix
+		}
+		if lanePrefixes != nil && s.lane > 0 && s.lane < len(lanePrefixes) {
+			return lanePrefixes[s.lane]
+		}
+		return st.LinePrefix
+	}
 	runStart := 0
 	for i := 1; i <= len(slots); i++ {
-		if i < len(slots) && slots[i] == slots[runStart] {
-			continue
+		if i < len(slots) && slots[i].g == slots[runStart].g && slots[i].dim == slots[runStart].dim {
+			if lanePrefixes == nil || slots[i].g == GlyphSpace || slots[i].g == GlyphStar || prefixOf(slots[i]) == prefixOf(slots[runStart]) {
+				continue
+			}
 		}
-		g := slots[runStart]
+		g := slots[runStart].g
 		n := i - runStart
 		ch := g.String()
-		switch g {
-		case GlyphSpace:
+		if g == GlyphStar && mark != "" {
+			ch = mark
+		}
+		switch {
+		case g == GlyphSpace:
 			for range n {
 				buf = append(buf, ' ')
 			}
-		case GlyphStar:
+		case g == GlyphStar && slots[runStart].dim && st.DimPrefix != "":
+			buf = append(buf, st.DimPrefix...)
+			for range n {
+				buf = append(buf, ch...)
+			}
+			buf = append(buf, st.LineSuffix...)
+		case g == GlyphStar:
 			if st.StarPrefix == "" && st.StarSuffix == "" {
 				for range n {
 					buf = append(buf, ch...)
@@ -156,13 +296,14 @@ func writeSlotsTo(buf []byte, slots []Glyph, st Style) []byte {
 				}
 				buf = append(buf, st.StarSuffix...)
 			}
-		default: // pipe, slash, backslash
-			if st.LinePrefix == "" && st.LineSuffix == "" {
+		default: // pipe, slash, backslash, fold, elided
+			prefix := prefixOf(slots[runStart])
+			if prefix == "" && st.LineSuffix == "" {
 				for range n {
 					buf = append(buf, ch...)
 				}
 			} else {
-				buf = append(buf, st.LinePrefix...)
+				buf = append(buf, prefix...)
 				for range n {
 					buf = append(buf, ch...)
 				}
@@ -1,9 +1,11 @@
 package graph_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/lczyk/assert"
 	"github.com/lczyk/gitgum/src/graph"
 )
 
@@ -39,7 +41,12 @@ func h(name string) int64 {
 
 func assertGraph(t *testing.T, nodes []graph.Node, expected string) {
 	t.Helper()
-	lr := graph.Layout(nodes)
+	assertGraphWith(t, nodes, graph.LayoutOptions{}, expected)
+}
+
+func assertGraphWith(t *testing.T, nodes []graph.Node, opts graph.LayoutOptions, expected string) {
+	t.Helper()
+	lr := graph.Layout(nodes, opts)
 	lines := graph.Render(lr, graph.Style{})
 	got := stripTrailingSpaces(strings.Join(lines, "\n"))
 	expected = stripTrailingSpaces(strings.TrimRight(expected, "\n"))
@@ -216,7 +223,7 @@ func TestScenario_Octopus(t *testing.T) {
 }
 
 func renderTo(nodes []graph.Node) string {
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	return strings.Join(graph.Render(lr, graph.Style{}), "\n")
 }
 
@@ -490,7 +497,7 @@ func TestScenario_SharedParentDualMerge(t *testing.T) {
 	// feature chain (f1, f2) should appear before the main chain (m1...m7)
 	// because the inner merge's walk places non-first parent (m7 chain)
 	// at higher rows. Git --graph produces this order too.
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, graph.Style{})
 
 	// Find commit positions.
@@ -602,7 +609,7 @@ func TestScenario_CrissCross(t *testing.T) {
 	}
 	// Just assert no panic and a non-empty render -- exact glyph layout
 	// here is less important than the crash regression.
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, graph.Style{})
 	if len(lines) == 0 {
 		t.Fatal("expected non-empty render")
@@ -626,7 +633,7 @@ func TestScenario_BackAndForthCatchUps(t *testing.T) {
 		{ID: "f3", Label: "f3", Epoch: iso(7), Parents: []string{"f2", "D"}, Lane: h("f")},
 		{ID: "E", Label: "E", Epoch: iso(8), Parents: []string{"D", "f3"}, Lane: h("main")},
 	}
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	if lr.Columns > 3 {
 		lines := graph.Render(lr, graph.Style{})
 		t.Errorf("expected at most 3 cols (main, feat, single routing); got %d\n%s",
@@ -648,7 +655,7 @@ func TestScenario_OctopusDedupTerms(t *testing.T) {
 		{ID: "D", Label: "D", Epoch: iso(4), Parents: []string{"A"}, Lane: h("d")},
 		{ID: "M", Label: "M", Epoch: iso(5), Parents: []string{"A", "B", "C", "D"}, Lane: h("main")},
 	}
-	lr := graph.Layout(nodes)
+	lr := graph.Layout(nodes, graph.LayoutOptions{})
 	lines := graph.Render(lr, graph.Style{})
 	rendered := stripTrailingSpaces(strings.Join(lines, "\n"))
 	termCount := strings.Count(rendered, "|/")
@@ -679,3 +686,221 @@ func TestScenario_StashWithIndex(t *testing.T) {
 * M`
 	assertGraph(t, nodes, expected)
 }
+
+// ------ ordering modes -----------------------------------------------------
+
+// interleavedSideBranch is a side branch whose commits are dated between
+// mainline commits before it's merged back. The ordering modes only differ
+// in where side1/side2 land relative to main1/main2.
+func interleavedSideBranch() []graph.Node {
+	return []graph.Node{
+		{ID: "base", Label: "base", Epoch: iso(1), Lane: h("main")},
+		{ID: "side1", Label: "side1", Epoch: iso(2), Parents: []string{"base"}},
+		{ID: "main1", Label: "main1", Epoch: iso(3), Parents: []string{"base"}},
+		{ID: "side2", Label: "side2", Epoch: iso(4), Parents: []string{"side1"}, Lane: h("side")},
+		{ID: "main2", Label: "main2", Epoch: iso(5), Parents: []string{"main1"}},
+		{ID: "merge", Label: "merge", Epoch: iso(6), Parents: []string{"main2", "side2"}},
+		{ID: "main3", Label: "main3", Epoch: iso(7), Parents: []string{"merge"}, Lane: h("main")},
+	}
+}
+
+func TestScenario_OrderEngine(t *testing.T) {
+	t.Parallel()
+	expected := `* base
+|\
+* | main1
+* | main2
+| * side1
+| * side2
+|/
+*   merge
+* main3`
+	assertGraphWith(t, interleavedSideBranch(), graph.LayoutOptions{Order: graph.OrderEngine}, expected)
+}
+
+func TestScenario_OrderDate(t *testing.T) {
+	t.Parallel()
+	// git --date-order interleaves by epoch. The side lane has to stay
+	// drawn across main2 (between its last commit and the merge), not
+	// drop out after side2.
+	expected := `* base
+|\
+| * side1
+* | main1
+| * side2
+* | main2
+|/
+*   merge
+* main3`
+	assertGraphWith(t, interleavedSideBranch(), graph.LayoutOptions{Order: graph.OrderDate}, expected)
+}
+
+func TestScenario_OrderTopo(t *testing.T) {
+	t.Parallel()
+	// git --topo-order pops the merge's last parent first, so the side
+	// branch sits right below the merge -- same shape as the engine here.
+	expected := `* base
+|\
+* | main1
+* | main2
+| * side1
+| * side2
+|/
+*   merge
+* main3`
+	assertGraphWith(t, interleavedSideBranch(), graph.LayoutOptions{Order: graph.OrderTopo}, expected)
+}
+
+func TestScenario_OrderFirstParent(t *testing.T) {
+	t.Parallel()
+	// mainline run main3..main1 stays contiguous; the side branch drops
+	// below it and its lane is held open across the mainline commits.
+	expected := `* base
+|\
+| * side1
+| * side2
+* | main1
+* | main2
+|/
+*   merge
+* main3`
+	assertGraphWith(t, interleavedSideBranch(), graph.LayoutOptions{Order: graph.OrderFirstParent}, expected)
+}
+
+// threeFeatureHistory mirrors a small real repo: three feature branches
+// forked and merged at different points, f1 still open past its merge.
+func threeFeatureHistory() []graph.Node {
+	return []graph.Node{
+		{ID: "base", Label: "base", Epoch: iso(1)},
+		{ID: "m1", Label: "m1", Epoch: iso(2), Parents: []string{"base"}},
+		{ID: "f1a", Label: "f1a", Epoch: iso(3), Parents: []string{"m1"}},
+		{ID: "m2", Label: "m2", Epoch: iso(4), Parents: []string{"m1"}},
+		{ID: "f1b", Label: "f1b", Epoch: iso(5), Parents: []string{"f1a"}},
+		{ID: "f2a", Label: "f2a", Epoch: iso(6), Parents: []string{"m2"}},
+		{ID: "m3", Label: "m3", Epoch: iso(7), Parents: []string{"m2"}},
+		{ID: "f2b", Label: "f2b", Epoch: iso(8), Parents: []string{"f2a"}, Lane: h("f2")},
+		{ID: "merge_f1", Label: "merge_f1", Epoch: iso(9), Parents: []string{"m3", "f1b"}},
+		{ID: "m4", Label: "m4", Epoch: iso(10), Parents: []string{"merge_f1"}},
+		{ID: "f1c", Label: "f1c", Epoch: iso(11), Parents: []string{"f1b"}},
+		{ID: "merge_f2", Label: "merge_f2", Epoch: iso(12), Parents: []string{"m4", "f2b"}},
+		{ID: "f3a", Label: "f3a", Epoch: iso(13), Parents: []string{"m3"}, Lane: h("f3")},
+		{ID: "m5", Label: "m5", Epoch: iso(14), Parents: []string{"merge_f2"}},
+		{ID: "merge_f3", Label: "merge_f3", Epoch: iso(15), Parents: []string{"m5", "f3a"}, Lane: h("main")},
+		{ID: "f1d", Label: "f1d", Epoch: iso(16), Parents: []string{"f1c"}, Lane: h("f1")},
+	}
+}
+
+// commitOrder lists commit IDs in row (oldest-first) order.
+func commitOrder(lr graph.LayoutResult) []string {
+	var ids []string
+	for _, row := range lr.Rows {
+		if row.Commit != nil {
+			ids = append(ids, row.Commit.ID)
+		}
+	}
+	return ids
+}
+
+func TestScenario_OrderMatchesGit(t *testing.T) {
+	t.Parallel()
+	// Commit orders captured from `git log --all --date-order` and
+	// `--topo-order` on the repo threeFeatureHistory mirrors, reversed to
+	// oldest-first. Only the row order is pinned; glyph shapes are the
+	// engine's own.
+	cases := map[string]struct {
+		order graph.Order
+		want  []string
+	}{
+		"date": {graph.OrderDate, []string{
+			"base", "m1", "f1a", "m2", "f1b", "f2a", "m3", "f2b",
+			"merge_f1", "m4", "f1c", "merge_f2", "f3a", "m5", "merge_f3", "f1d",
+		}},
+		"topo": {graph.OrderTopo, []string{
+			"base", "m1", "m2", "m3", "f1a", "f1b", "merge_f1", "m4",
+			"f2a", "f2b", "merge_f2", "m5", "f3a", "merge_f3", "f1c", "f1d",
+		}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			lr := graph.Layout(threeFeatureHistory(), graph.LayoutOptions{Order: tc.order})
+			got := commitOrder(lr)
+			if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+				t.Errorf("row order mismatch\n got: %v\nwant: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// longRunsAroundFork is a mainline with a side branch forked off the middle
+// and merged at the end, long straight runs on either side of the fork.
+func longRunsAroundFork() []graph.Node {
+	nodes := []graph.Node{{ID: "root", Label: "root", Epoch: 0}}
+	prev := "root"
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprintf("m%d", i)
+		nodes = append(nodes, graph.Node{ID: id, Label: id, Epoch: int64(i), Parents: []string{prev}})
+		prev = id
+	}
+	nodes = append(nodes, graph.Node{ID: "f1", Label: "f1", Epoch: 7, Parents: []string{"m6"}})
+	for i := 7; i <= 12; i++ {
+		id := fmt.Sprintf("m%d", i)
+		nodes = append(nodes, graph.Node{ID: id, Label: id, Epoch: int64(i + 1), Parents: []string{prev}})
+		prev = id
+	}
+	return append(nodes, graph.Node{ID: "M", Label: "M", Epoch: 20, Parents: []string{prev, "f1"}})
+}
+
+func TestScenario_Collapse(t *testing.T) {
+	t.Parallel()
+	// the fork point, the merge and root (no parent) stay; both runs fold
+	// and the side lane runs straight past the fold row.
+	expected := `* root
+⋮ 5 commits
+* m6
+|\
+⋮ | 6 commits
+| * f1
+|/
+*   M`
+	assertGraphWith(t, longRunsAroundFork(), graph.LayoutOptions{Collapse: 3}, expected)
+}
+
+func TestScenario_CollapseKeepSplitsRun(t *testing.T) {
+	t.Parallel()
+	// a kept commit (a ref tip, say) splits its run; the two commits above
+	// it are below the threshold and stay as they are.
+	keep := func(n *graph.Node) bool { return n.ID == "m9" }
+	expected := `* root
+⋮ 5 commits
+* m6
+|\
+* | m7
+* | m8
+* | m9
+⋮ | 3 commits
+| * f1
+|/
+*   M`
+	assertGraphWith(t, longRunsAroundFork(), graph.LayoutOptions{Collapse: 3, Keep: keep}, expected)
+}
+
+func TestCollapse_FoldedRows(t *testing.T) {
+	t.Parallel()
+	lr := graph.Layout(longRunsAroundFork(), graph.LayoutOptions{Collapse: 3})
+	var folds [][]string
+	for _, row := range lr.Rows {
+		if row.Folded == nil {
+			continue
+		}
+		var ids []string
+		for _, n := range row.Folded {
+			ids = append(ids, n.ID)
+		}
+		folds = append(folds, ids)
+		assert.Equal(t, row.Commit.ID, ids[0])
+	}
+	assert.Equal(t, len(folds), 2)
+	assert.EqualArrays(t, folds[0], []string{"m5", "m4", "m3", "m2", "m1"})
+	assert.EqualArrays(t, folds[1], []string{"m12", "m11", "m10", "m9", "m8", "m7"})
+}
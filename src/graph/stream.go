@@ -0,0 +1,124 @@
+package graph
+
+import "slices"
+
+// segmentMin is the smallest buffer Stream cuts at. Cutting at every
+// linear commit would run one Layout per commit on straight histories;
+// a few hundred amortises the per-call setup while still showing the
+// first rows almost immediately.
+const segmentMin = 256
+
+// Stream lays out a history fed newest-first -- the order `git log
+// --date-order` prints -- without holding the whole DAG at once.
+//
+// Nodes are buffered until the history narrows to one linear edge -- a
+// commit C whose only parent P is the very next node pushed, with no
+// other edge from the buffered nodes reaching further back -- and at
+// least segmentMin nodes are waiting. Everything buffered then descends
+// from C, and everything older starts at P, so the buffer can be laid out
+// on its own (a segment), handed to emit, and dropped. P is pinned to col
+// 0 of the next segment so the two line up.
+//
+// Segments reach emit newest-first; each one's Rows are oldest-first like
+// Layout's, so joining rendered segments in reverse arrival order gives
+// the full graph. Lane ids (Row.Lanes) are per segment; the key of the
+// lane crossing a cut is carried onto P when P has none, so a keyed
+// mainline keeps its colour across segments.
+//
+// A cut can still fail to line up (see flush); the buffer then has to
+// double before the next cut is tried, so a history that never cuts costs
+// a few Layouts rather than one per commit.
+//
+// Memory is bounded by the longest stretch of history with more than one
+// open line. A long-lived parallel branch keeps everything since its fork
+// point buffered; so does feeding nodes out of topological order, which
+// leaves edges that never resolve. Neither breaks the output -- it just
+// degrades to one big Layout at Flush.
+type Stream struct {
+	opts    LayoutOptions
+	emit    func(LayoutResult)
+	buf     []Node
+	pending map[string]int // parent id -> edges from buffered nodes still waiting on it
+	cut     string         // parent id that closes the segment if pushed next, "" if none
+	carry   int64          // lane key of the col-0 lane at the last cut
+	retry   int            // buffer length to reach before trying a cut again, after one failed
+}
+
+// NewStream returns a Stream laying out segments with opts and handing
+// each finished one to emit.
+func NewStream(opts LayoutOptions, emit func(LayoutResult)) *Stream {
+	return &Stream{opts: opts, emit: emit, pending: map[string]int{}}
+}
+
+// Push feeds the next node, newest-first. It may emit a finished segment
+// before buffering n.
+func (s *Stream) Push(n Node) {
+	if s.cut != "" && n.ID == s.cut && len(s.buf) >= max(segmentMin, s.retry) && s.flush(true) {
+		s.opts.head = n.ID
+		if n.Lane == 0 {
+			n.Lane = s.carry
+		}
+	}
+	s.buf = append(s.buf, n)
+
+	delete(s.pending, n.ID)
+	for _, pid := range n.Parents {
+		s.pending[pid]++
+	}
+	s.cut = ""
+	if len(n.Parents) == 1 && len(s.pending) == 1 && s.pending[n.Parents[0]] == 1 {
+		s.cut = n.Parents[0]
+	}
+}
+
+// Flush lays out and emits whatever is still buffered. Call once after
+// the last Push.
+func (s *Stream) Flush() {
+	s.flush(false)
+}
+
+// flush lays out the buffer and emits it. At a cut, the segment's oldest
+// commit must have come out on col 0 to line up with the next segment's
+// pinned head; if something else claimed col 0 (an orphan root newer than
+// the cut, say) the cut is abandoned and the buffer keeps growing, to
+// twice its size before the next try.
+func (s *Stream) flush(atCut bool) bool {
+	if len(s.buf) == 0 {
+		return false
+	}
+	lr := Layout(s.buf, s.opts)
+	if atCut {
+		bottom := -1
+		for i, row := range lr.Rows {
+			if row.Commit != nil {
+				bottom = i
+				break
+			}
+		}
+		c := s.buf[len(s.buf)-1] // the node that set s.cut
+		if bottom < 0 || !rowHolds(lr.Rows[bottom], c.ID) {
+			s.retry = 2 * len(s.buf)
+			return false
+		}
+		s.carry = lr.LaneKeys[lr.Rows[bottom].Lanes[0]]
+	}
+	s.emit(lr)
+	// fresh backing array: the emitted rows point into the old one
+	s.buf = nil
+	s.pending = map[string]int{}
+	s.opts.head = ""
+	s.retry = 0
+	return true
+}
+
+// rowHolds reports whether row draws commit id on col 0, either as its own
+// commit or inside a fold.
+func rowHolds(row Row, id string) bool {
+	switch row.Glyphs[0] {
+	case GlyphStar, GlyphElided:
+		return row.Commit.ID == id
+	case GlyphFold:
+		return slices.ContainsFunc(row.Folded, func(n *Node) bool { return n.ID == id })
+	}
+	return false
+}
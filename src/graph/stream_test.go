@@ -0,0 +1,155 @@
+package graph_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/src/graph"
+)
+
+// stream_test.go covers graph.Stream: segmented layout of a newest-first
+// node feed. The reference for every case is a single Layout over the same
+// nodes -- the stream must reproduce it when its segments are joined.
+
+// newestFirst returns nodes in the row order Layout would give them,
+// newest first -- i.e. what `git log --date-order` would print.
+func newestFirst(nodes []graph.Node, opts graph.LayoutOptions) []graph.Node {
+	byID := make(map[string]graph.Node, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	ids := commitOrder(graph.Layout(nodes, opts))
+	slices.Reverse(ids)
+	out := make([]graph.Node, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, byID[id])
+	}
+	return out
+}
+
+// streamRender pushes nodes through a Stream and returns each emitted
+// segment's rendered lines, in emit order (newest segment first).
+func streamRender(nodes []graph.Node, opts graph.LayoutOptions) [][]string {
+	var segs [][]string
+	s := graph.NewStream(opts, func(lr graph.LayoutResult) {
+		segs = append(segs, graph.Render(lr, graph.Style{}))
+	})
+	for _, n := range nodes {
+		s.Push(n)
+	}
+	s.Flush()
+	return segs
+}
+
+func joinSegments(segs [][]string) string {
+	var lines []string
+	for i := len(segs) - 1; i >= 0; i-- {
+		lines = append(lines, segs[i]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestStream_MatchesLayout(t *testing.T) {
+	t.Parallel()
+	for _, order := range []graph.Order{graph.OrderEngine, graph.OrderDate, graph.OrderTopo, graph.OrderFirstParent} {
+		opts := graph.LayoutOptions{Order: order}
+		nodes := mergeSeries(1000, 5)
+
+		want := strings.Join(graph.Render(graph.Layout(nodes, opts), graph.Style{}), "\n")
+		segs := streamRender(newestFirst(nodes, opts), opts)
+
+		assert.That(t, len(segs) > 1, "order %d: merge series should be cut into several segments, got %d", order, len(segs))
+		if got := joinSegments(segs); got != want {
+			t.Errorf("order %d: joined stream output differs from Layout\n--- want ---\n%s\n--- got ---\n%s", order, want, got)
+		}
+	}
+}
+
+func TestStream_SmallHistoryIsOneSegment(t *testing.T) {
+	t.Parallel()
+	// below segmentMin nothing is cut; Flush emits the lot
+	nodes := linearChain(10)
+	segs := streamRender(newestFirst(nodes, graph.LayoutOptions{}), graph.LayoutOptions{})
+	assert.Equal(t, len(segs), 1)
+	assert.Equal(t, len(segs[0]), 10)
+}
+
+func TestStream_OpenParallelBranchBuffers(t *testing.T) {
+	t.Parallel()
+	// a branch forked at the root and never merged keeps two lines open
+	// across the whole history, so there's no linear point to cut at.
+	nodes := []graph.Node{{ID: "root", Label: "root", Epoch: 0}}
+	mainPrev, sidePrev := "root", "root"
+	for i := range 300 {
+		m := fmt.Sprintf("m%d", i)
+		s := fmt.Sprintf("s%d", i)
+		nodes = append(nodes,
+			graph.Node{ID: m, Label: m, Epoch: int64(2*i + 1), Parents: []string{mainPrev}},
+			graph.Node{ID: s, Label: s, Epoch: int64(2*i + 2), Parents: []string{sidePrev}},
+		)
+		mainPrev, sidePrev = m, s
+	}
+	opts := graph.LayoutOptions{Order: graph.OrderDate}
+	segs := streamRender(newestFirst(nodes, opts), opts)
+	assert.Equal(t, len(segs), 1)
+	want := strings.Join(graph.Render(graph.Layout(nodes, opts), graph.Style{}), "\n")
+	assert.Equal(t, joinSegments(segs), want)
+}
+
+func TestStream_CarriesLaneKeyAcrossCut(t *testing.T) {
+	t.Parallel()
+	// only the tip carries a lane key; the mainline below the cut must
+	// inherit it so a palette colours the whole line the same.
+	nodes := linearChain(600)
+	nodes[len(nodes)-1].Lane = 42
+	var keys []int64
+	s := graph.NewStream(graph.LayoutOptions{}, func(lr graph.LayoutResult) {
+		for _, row := range lr.Rows {
+			keys = append(keys, lr.LaneKeys[row.Lanes[0]])
+		}
+	})
+	for _, n := range newestFirst(nodes, graph.LayoutOptions{}) {
+		s.Push(n)
+	}
+	s.Flush()
+	assert.Equal(t, len(keys), 600)
+	for i, k := range keys {
+		assert.Equal(t, k, int64(42), "row %d", i)
+	}
+}
+
+func TestStream_Collapse(t *testing.T) {
+	t.Parallel()
+	// folds never swallow a cut: the stream still segments, and every
+	// commit turns up exactly once, on its own row or inside a fold.
+	opts := graph.LayoutOptions{Order: graph.OrderDate, Collapse: 3}
+	nodes := mergeSeries(1000, 5)
+	seen := map[string]int{}
+	segs := 0
+	s := graph.NewStream(opts, func(lr graph.LayoutResult) {
+		segs++
+		for _, row := range lr.Rows {
+			switch {
+			case row.Folded != nil:
+				for _, n := range row.Folded {
+					seen[n.ID]++
+				}
+			case row.Commit != nil:
+				seen[row.Commit.ID]++
+			}
+		}
+	})
+	for _, n := range newestFirst(nodes, graph.LayoutOptions{Order: graph.OrderDate}) {
+		s.Push(n)
+	}
+	s.Flush()
+
+	assert.That(t, segs > 1, "want several segments, got %d", segs)
+	assert.Equal(t, len(seen), len(nodes))
+	for id, n := range seen {
+		assert.Equal(t, n, 1, "commit %s", id)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
 )
 
 // StyledRune is a rune with its computed style after SGR application.
@@ -337,3 +338,52 @@ func Strip(s string) string {
 	})
 	return b.String()
 }
+
+// Width returns the display width of s in terminal cells, escape sequences
+// excluded.
+func Width(s string) int {
+	w := 0
+	walk(s, tcell.StyleDefault, func(r rune, _ tcell.Style) {
+		w += runewidth.RuneWidth(r)
+	})
+	return w
+}
+
+// Truncate cuts s down to at most width cells, ending it with tail when
+// anything had to go. Escape sequences before the cut are kept as they
+// are and a reset is written ahead of tail, so a colour open at the cut
+// doesn't bleed into the tail or what follows.
+func Truncate(s string, width int, tail string) string {
+	if Width(s) <= width {
+		return s
+	}
+	limit := width - runewidth.StringWidth(tail)
+	if limit < 0 {
+		return runewidth.Truncate(tail, width, "")
+	}
+	var b strings.Builder
+	styled := false
+	w := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b && i+1 < len(s) {
+			n, _, _ := handleEscape(s[i:], tcell.StyleDefault, tcell.StyleDefault)
+			b.WriteString(s[i : i+n])
+			styled = true
+			i += n
+			continue
+		}
+		r, sz := utf8.DecodeRuneInString(s[i:])
+		rw := runewidth.RuneWidth(r)
+		if w+rw > limit {
+			break
+		}
+		b.WriteString(s[i : i+sz])
+		w += rw
+		i += sz
+	}
+	if styled {
+		b.WriteString("\x1b[0m")
+	}
+	b.WriteString(tail)
+	return b.String()
+}
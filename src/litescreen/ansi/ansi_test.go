@@ -321,6 +321,33 @@ func TestStrip_OnlyEscapes(t *testing.T) {
 	assert.Equal(t, ansi.Strip("\x1b[31m\x1b[0m"), "")
 }
 
+func TestWidth(t *testing.T) {
+	assert.Equal(t, ansi.Width("\x1b[31mhello\x1b[0m"), 5)
+	assert.Equal(t, ansi.Width("日本"), 4)
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		name, in string
+		width    int
+		want     string
+	}{
+		{"fits", "hello", 5, "hello"},
+		{"plain", "hello world", 8, "hello w…"},
+		{"styled fits", "\x1b[31mhi\x1b[0m", 2, "\x1b[31mhi\x1b[0m"},
+		{"styled cut", "\x1b[31mhello\x1b[0m world", 4, "\x1b[31mhel\x1b[0m…"},
+		{"wide runes", "日本語", 4, "日…"},
+		{"narrower than tail", "hello", 0, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ansi.Truncate(tc.in, tc.width, "…")
+			assert.Equal(t, got, tc.want)
+			assert.That(t, ansi.Width(got) <= tc.width, "width", ansi.Width(got), "over", tc.width)
+		})
+	}
+}
+
 func TestParse_MalformedCSI_NoFinalByte(t *testing.T) {
 	// No final byte; parser should consume to end without crashing.
 	got := ansi.Parse("a\x1b[31", tcell.StyleDefault)
@@ -0,0 +1,202 @@
+package syntax
+
+import "strings"
+
+var goLang = &Lang{
+	Name:          "go",
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	strs: []delim{
+		{open: "`", close: "`", multiline: true, raw: true},
+		{open: `"`, close: `"`},
+		{open: "'", close: "'"},
+	},
+	keywords: set(`break case chan const continue default defer else fallthrough
+		for func go goto if import interface map package range return select
+		struct switch type var`),
+	builtins: set(`any bool byte comparable complex64 complex128 error float32
+		float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32
+		uint64 uintptr true false iota nil append cap clear close complex copy
+		delete imag len make max min new panic print println real recover`),
+}
+
+var pythonLang = &Lang{
+	Name:         "python",
+	lineComments: []string{"#"},
+	strs: []delim{
+		{open: `"""`, close: `"""`, multiline: true},
+		{open: "'''", close: "'''", multiline: true},
+		{open: `"`, close: `"`},
+		{open: "'", close: "'"},
+	},
+	keywords: set(`and as assert async await break class continue def del elif
+		else except finally for from global if import in is lambda nonlocal not
+		or pass raise return try while with yield match case`),
+	builtins: set(`True False None self cls print len range int str float bool
+		list dict set tuple bytes object type isinstance super open enumerate
+		zip map filter sorted min max sum any all`),
+}
+
+// jsLang covers TypeScript too: its extra keywords are just identifiers in
+// plain JavaScript, where they don't turn up much.
+var jsLang = &Lang{
+	Name:          "javascript",
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	strs: []delim{
+		{open: "`", close: "`", multiline: true},
+		{open: `"`, close: `"`},
+		{open: "'", close: "'"},
+	},
+	keywords: set(`async await break case catch class const continue debugger
+		default delete do else export extends finally for function if import in
+		instanceof let new of return static super switch this throw try typeof
+		var void while with yield as from type interface enum implements declare
+		namespace readonly keyof`),
+	builtins: set(`true false null undefined NaN Infinity console string number
+		boolean any unknown never object Promise Array Object Map Set Error JSON`),
+}
+
+// rustLang has no char literals: telling 'a' from a lifetime 'a takes more
+// than a lexer wants to know.
+var rustLang = &Lang{
+	Name:          "rust",
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	strs: []delim{
+		{open: `"`, close: `"`, multiline: true},
+	},
+	keywords: set(`as async await break const continue crate dyn else enum
+		extern fn for if impl in let loop match mod move mut pub ref return self
+		Self static struct super trait type unsafe use where while`),
+	builtins: set(`true false Some None Ok Err String Vec Option Result Box i8
+		i16 i32 i64 i128 isize u8 u16 u32 u64 u128 usize f32 f64 bool char str
+		println print format panic vec assert assert_eq`),
+}
+
+var shellLang = &Lang{
+	Name:           "shell",
+	lineComments:   []string{"#"},
+	hashAfterSpace: true,
+	strs: []delim{
+		{open: `"`, close: `"`, multiline: true},
+		{open: "'", close: "'", multiline: true, raw: true},
+	},
+	keywords: set(`if then else elif fi for while until do done case esac in
+		function return local export readonly declare break continue exit`),
+	builtins: set(`echo printf cd test set unset shift source eval exec trap
+		read true false`),
+	extra: shellVariable,
+}
+
+// shellVariable lexes $NAME, ${...}, and the special $1, $@, $? and co.
+func shellVariable(s string, i int) (int, Class) {
+	if s[i] != '$' || i+1 >= len(s) {
+		return i, Plain
+	}
+	c := s[i+1]
+	switch {
+	case c == '{':
+		if end := strings.IndexByte(s[i:], '}'); end > 0 {
+			return i + end + 1, Variable
+		}
+		return len(s), Variable
+	case isIdentStart(c):
+		return scanWord(s, i+1, false), Variable
+	case isDigit(c) || strings.IndexByte("@*#?$!-", c) >= 0:
+		return i + 2, Variable
+	}
+	return i, Plain
+}
+
+var yamlLang = &Lang{
+	Name:           "yaml",
+	lineComments:   []string{"#"},
+	hashAfterSpace: true,
+	strs: []delim{
+		{open: `"`, close: `"`},
+		{open: "'", close: "'", raw: true},
+	},
+	stringKeys: true,
+	builtins:   set(`true false null yes no on off True False Null ~`),
+	extra:      yamlKey,
+}
+
+// yamlKey lexes a plain mapping key: the first thing on a line, or after a
+// list's "- ", up to its ':'.
+func yamlKey(s string, i int) (int, Class) {
+	if c := s[i]; c == ' ' || c == '\t' || c == '-' {
+		return i, Plain
+	}
+	lead := strings.TrimLeft(s[:i], " \t")
+	if lead != "" && strings.TrimRight(lead, " ") != "-" {
+		return i, Plain
+	}
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case ':':
+			if j > i && (j+1 == len(s) || s[j+1] == ' ' || s[j+1] == '\t') {
+				return j, Key
+			}
+		case '#', '"', '\'', '{', '[', ',':
+			return i, Plain
+		}
+	}
+	return i, Plain
+}
+
+var jsonLang = &Lang{
+	Name:       "json",
+	strs:       []delim{{open: `"`, close: `"`}},
+	stringKeys: true,
+	builtins:   set(`true false null`),
+}
+
+var markdownLang = &Lang{
+	Name: "markdown",
+	line: markdownLine,
+}
+
+// markdownLine lexes Markdown: fenced blocks (``` or ~~~) as Code, each
+// line of them whole; headings; and `code spans`. The rest is prose, left
+// plain.
+func markdownLine(s string, st State) ([]Token, State) {
+	trimmed := strings.TrimLeft(s, " ")
+	if st.close != "" {
+		if strings.HasPrefix(trimmed, st.close) {
+			st = State{}
+		}
+		return lineToken(s, Code), st
+	}
+	for _, fence := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, fence) {
+			return lineToken(s, Code), State{close: fence, class: Code, raw: true}
+		}
+	}
+	if strings.HasPrefix(trimmed, "#") && len(s)-len(trimmed) < 4 {
+		if rest := strings.TrimLeft(trimmed, "#"); rest == "" || rest[0] == ' ' {
+			return lineToken(s, Heading), State{}
+		}
+	}
+	var toks []Token
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		end := strings.IndexByte(s[i+1:], '`')
+		if end < 0 {
+			break
+		}
+		toks = append(toks, Token{i, i + end + 2, Code})
+		i += end + 2
+	}
+	return toks, State{}
+}
+
+func lineToken(s string, class Class) []Token {
+	if s == "" {
+		return nil
+	}
+	return []Token{{0, len(s), class}}
+}
@@ -0,0 +1,272 @@
+// Package syntax is a small line-at-a-time highlighter for the file types
+// gg diff colours: Go, Python, JavaScript/TypeScript, Rust, shell, YAML,
+// JSON and Markdown. It's a lexer, not a parser -- comments, strings,
+// numbers, keywords and a few per-language extras -- which is what a diff
+// hunk needs to read well, and all a hunk can support: it starts mid-file,
+// so anything cleverer would be guessing anyway. It marks tokens with a
+// Class and leaves drawing them to the caller.
+//
+// Typical usage:
+//
+//	if lang := syntax.ForPath("main.go"); lang != nil {
+//		toks := lang.Lines(lines) // toks[i] are the tokens of lines[i]
+//	}
+package syntax
+
+import (
+	"path"
+	"strings"
+)
+
+// Class is what kind of token a Token is.
+type Class int
+
+const (
+	Plain Class = iota
+	Keyword
+	Builtin // predeclared types, constants and functions
+	String
+	Number
+	Comment
+	Key      // YAML and JSON keys
+	Variable // shell $VARs
+	Heading  // Markdown headings
+	Code     // Markdown code spans and fenced blocks
+)
+
+// Token is the byte range [Start, End) of a line, of one Class. Plain text
+// between tokens isn't reported.
+type Token struct {
+	Start, End int
+	Class      Class
+}
+
+// State carries a construct that's still open at the end of a line -- a
+// block comment, a multi-line string, a Markdown fence -- into the next.
+// The zero State is the start of a file.
+type State struct {
+	close string // what ends the open construct; "" when none is open
+	class Class
+	raw   bool // backslashes don't escape
+}
+
+// Lang is one file type's lexer.
+type Lang struct {
+	Name string
+
+	lineComments  []string
+	blockComments [][2]string
+	strs          []delim // longer openers first
+	keywords      map[string]bool
+	builtins      map[string]bool
+	// hashAfterSpace: "#" only starts a comment at the start of a line or
+	// after whitespace, as in shell and YAML.
+	hashAfterSpace bool
+	// stringKeys marks a string followed by ':' as a Key.
+	stringKeys bool
+	// extra lexes a language-specific token at s[i], returning its end,
+	// or i when there's none there.
+	extra func(s string, i int) (end int, class Class)
+	// line replaces the generic lexer outright.
+	line func(s string, st State) ([]Token, State)
+}
+
+type delim struct {
+	open, close string
+	multiline   bool // may run on past the end of the line
+	raw         bool
+}
+
+// ForPath picks a Lang by the file's extension (or name, for a few
+// well-known files); nil for a type it doesn't know.
+func ForPath(p string) *Lang {
+	base := path.Base(p)
+	switch base {
+	case "Dockerfile", "Makefile", "go.mod", "go.sum":
+		return nil
+	case ".bashrc", ".zshrc", ".profile", ".bash_profile":
+		return shellLang
+	}
+	switch strings.ToLower(path.Ext(base)) {
+	case ".go":
+		return goLang
+	case ".py", ".pyi":
+		return pythonLang
+	case ".js", ".mjs", ".cjs", ".jsx", ".ts", ".mts", ".cts", ".tsx":
+		return jsLang
+	case ".rs":
+		return rustLang
+	case ".sh", ".bash", ".zsh":
+		return shellLang
+	case ".yaml", ".yml":
+		return yamlLang
+	case ".json":
+		return jsonLang
+	case ".md", ".markdown":
+		return markdownLang
+	}
+	return nil
+}
+
+// Lines lexes consecutive lines of a file, starting from the zero State.
+func (l *Lang) Lines(lines []string) [][]Token {
+	out := make([][]Token, len(lines))
+	var st State
+	for i, s := range lines {
+		out[i], st = l.Line(s, st)
+	}
+	return out
+}
+
+// Line lexes one line, given the State the line before it left, and
+// returns the State it leaves for the next.
+func (l *Lang) Line(s string, st State) ([]Token, State) {
+	if l.line != nil {
+		return l.line(s, st)
+	}
+	var toks []Token
+	i := 0
+	if st.close != "" {
+		end, closed := findClose(s, 0, st.close, st.raw)
+		toks = append(toks, Token{0, end, st.class})
+		if !closed {
+			return toks, st
+		}
+		i = end
+	}
+	for i < len(s) {
+		if open, close, ok := l.blockCommentAt(s, i); ok {
+			end, closed := findClose(s, i+len(open), close, true)
+			toks = append(toks, Token{i, end, Comment})
+			if !closed {
+				return toks, State{close: close, class: Comment, raw: true}
+			}
+			i = end
+			continue
+		}
+		if l.lineCommentAt(s, i) {
+			toks = append(toks, Token{i, len(s), Comment})
+			break
+		}
+		if d, ok := l.stringAt(s, i); ok {
+			end, closed := findClose(s, i+len(d.open), d.close, d.raw)
+			class := String
+			if l.stringKeys && closed && colonFollows(s, end) {
+				class = Key
+			}
+			toks = append(toks, Token{i, end, class})
+			if !closed && d.multiline {
+				return toks, State{close: d.close, class: String, raw: d.raw}
+			}
+			i = end
+			continue
+		}
+		if l.extra != nil {
+			if end, class := l.extra(s, i); end > i {
+				toks = append(toks, Token{i, end, class})
+				i = end
+				continue
+			}
+		}
+		c := s[i]
+		switch {
+		case isDigit(c):
+			end := scanWord(s, i, true)
+			toks = append(toks, Token{i, end, Number})
+			i = end
+		case isIdentStart(c):
+			end := scanWord(s, i, false)
+			if w := s[i:end]; l.keywords[w] {
+				toks = append(toks, Token{i, end, Keyword})
+			} else if l.builtins[w] {
+				toks = append(toks, Token{i, end, Builtin})
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return toks, State{}
+}
+
+func (l *Lang) blockCommentAt(s string, i int) (open, close string, ok bool) {
+	for _, bc := range l.blockComments {
+		if strings.HasPrefix(s[i:], bc[0]) {
+			return bc[0], bc[1], true
+		}
+	}
+	return "", "", false
+}
+
+func (l *Lang) lineCommentAt(s string, i int) bool {
+	for _, lc := range l.lineComments {
+		if !strings.HasPrefix(s[i:], lc) {
+			continue
+		}
+		if lc == "#" && l.hashAfterSpace && i > 0 && s[i-1] != ' ' && s[i-1] != '\t' {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (l *Lang) stringAt(s string, i int) (delim, bool) {
+	for _, d := range l.strs {
+		if strings.HasPrefix(s[i:], d.open) {
+			return d, true
+		}
+	}
+	return delim{}, false
+}
+
+// findClose finds close in s from from on, skipping backslash escapes
+// unless raw. It returns the index just past close, or len(s) if the
+// line ends first.
+func findClose(s string, from int, close string, raw bool) (int, bool) {
+	for j := from; j < len(s); j++ {
+		if !raw && s[j] == '\\' {
+			j++
+			continue
+		}
+		if strings.HasPrefix(s[j:], close) {
+			return j + len(close), true
+		}
+	}
+	return len(s), false
+}
+
+// colonFollows reports whether s has a ':' at i, after any spaces.
+func colonFollows(s string, i int) bool {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i < len(s) && s[i] == ':'
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// scanWord runs to the end of an identifier, or of a number -- which takes
+// in '.' too, for 3.14 -- at s[i].
+func scanWord(s string, i int, number bool) int {
+	for i < len(s) {
+		c := s[i]
+		if !isIdentStart(c) && !isDigit(c) && !(number && c == '.') {
+			break
+		}
+		i++
+	}
+	return i
+}
+
+func set(words string) map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		m[w] = true
+	}
+	return m
+}
@@ -0,0 +1,124 @@
+package syntax_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lczyk/assert"
+	"github.com/lczyk/gitgum/src/syntax"
+)
+
+var classNames = map[syntax.Class]string{
+	syntax.Keyword:  "kw",
+	syntax.Builtin:  "bi",
+	syntax.String:   "str",
+	syntax.Number:   "num",
+	syntax.Comment:  "com",
+	syntax.Key:      "key",
+	syntax.Variable: "var",
+	syntax.Heading:  "h",
+	syntax.Code:     "code",
+}
+
+// tokens lexes lines as one file and lists each token as "class:text",
+// lines apart by "|".
+func tokens(t *testing.T, path string, lines ...string) []string {
+	t.Helper()
+	lang := syntax.ForPath(path)
+	if lang == nil {
+		t.Fatalf("no lang for %s", path)
+	}
+	var out []string
+	for i, toks := range lang.Lines(lines) {
+		if i > 0 {
+			out = append(out, "|")
+		}
+		for _, tok := range toks {
+			out = append(out, fmt.Sprintf("%s:%s", classNames[tok.Class], lines[i][tok.Start:tok.End]))
+		}
+	}
+	return out
+}
+
+func TestForPath(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"main.go":          "go",
+		"a/b/script.py":    "python",
+		"web/app.tsx":      "javascript",
+		"src/lib.rs":       "rust",
+		"install.sh":       "shell",
+		".github/ci.yml":   "yaml",
+		"package.json":     "json",
+		"README.md":        "markdown",
+		"docs/GUIDE.MD":    "markdown",
+		"home/.bashrc":     "shell",
+		"notes.txt":        "",
+		"Makefile":         "",
+		"no-extension":     "",
+		"weird.go.orig":    "",
+		"archive.tar.json": "json",
+	}
+	for path, want := range cases {
+		got := ""
+		if lang := syntax.ForPath(path); lang != nil {
+			got = lang.Name
+		}
+		assert.Equal(t, got, want, "ForPath(%q)", path)
+	}
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name  string
+		path  string
+		lines []string
+		want  []string
+	}{
+		{"go", "a.go", []string{`func f(s string) int { return len(s) + 0x1F } // done`},
+			[]string{"kw:func", "bi:string", "bi:int", "kw:return", "bi:len", "num:0x1F", "com:// done"}},
+		{"go string escapes", "a.go", []string{`x := "a \" // b" + 'c'`},
+			[]string{`str:"a \" // b"`, "str:'c'"}},
+		{"go block comment spans lines", "a.go", []string{"a /* one", "two", "three */ if"},
+			[]string{"com:/* one", "|", "com:two", "|", "com:three */", "kw:if"}},
+		{"go raw string spans lines", "a.go", []string{"s := `a\\", "b` + nil"},
+			[]string{"str:`a\\", "|", "str:b`", "bi:nil"}},
+		{"python", "a.py", []string{`def f(self): return None  # x`},
+			[]string{"kw:def", "bi:self", "kw:return", "bi:None", "com:# x"}},
+		{"python docstring", "a.py", []string{`"""doc`, `still # doc`, `"""`, `pass`},
+			[]string{`str:"""doc`, "|", "str:still # doc", "|", `str:"""`, "|", "kw:pass"}},
+		{"js template", "a.ts", []string{"const s = `x ${y}", "z`; let n = 3.5"},
+			[]string{"kw:const", "str:`x ${y}", "|", "str:z`", "kw:let", "num:3.5"}},
+		{"rust lifetimes stay plain", "a.rs", []string{`fn f<'a>(s: &'a str) -> Option<u8> { None }`},
+			[]string{"kw:fn", "bi:str", "bi:Option", "bi:u8", "bi:None"}},
+		{"shell", "a.sh", []string{`if [ "$1" = x ]; then echo ${HOME}$PATH # hi`},
+			[]string{"kw:if", `str:"$1"`, "kw:then", "bi:echo", "var:${HOME}", "var:$PATH", "com:# hi"}},
+		{"shell hash mid-word", "a.sh", []string{`echo a#b $#`},
+			[]string{"bi:echo", "var:$#"}},
+		{"yaml", "a.yaml", []string{`name: ci # the name`, `  - run: "make"`, `    on: true`},
+			[]string{"key:name", "com:# the name", "|", "key:run", `str:"make"`, "|", "key:on", "bi:true"}},
+		{"yaml url is no key", "a.yml", []string{`url: http://x.y`},
+			[]string{"key:url"}},
+		{"json", "a.json", []string{`{"a": [1, "b", true, null]}`},
+			[]string{`key:"a"`, "num:1", `str:"b"`, "bi:true", "bi:null"}},
+		{"markdown", "a.md", []string{"# Title", "use `gg diff` here", "```go", "func x", "```", "#nope"},
+			[]string{"h:# Title", "|", "code:`gg diff`", "|", "code:```go", "|", "code:func x", "|", "code:```", "|"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.EqualArrays(t, tokens(t, tc.path, tc.lines...), tc.want)
+		})
+	}
+}
+
+func TestLine_UnterminatedString(t *testing.T) {
+	t.Parallel()
+	// a string that can't span lines stops at the end of its own
+	lang := syntax.ForPath("a.go")
+	toks, st := lang.Line(`x := "open`, syntax.State{})
+	assert.Equal(t, len(toks), 1)
+	assert.Equal(t, toks[0], syntax.Token{Start: 5, End: 10, Class: syntax.String})
+	assert.Equal(t, st, syntax.State{})
+}